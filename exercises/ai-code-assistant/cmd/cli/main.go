@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
 	"net/url"
@@ -18,42 +19,47 @@ import (
 	"github.com/kumarlokesh/sysd/exercises/ai-code-assistant/internal/vectorstore"
 )
 
+// options holds the parsed command line
+type options struct {
+	configPath string
+	help       bool
+	version    bool
+	args       []string // Command and its arguments
+}
+
 func main() {
-	cfg := &config.Config{
-		Server: config.ServerConfig{
-			Port: 8080,
-		},
-		ChromaDB: config.ChromaDBConfig{
-			URL:    "http://localhost:8000",
-			APIKey: "",
-		},
-		LLM: config.LLMConfig{
-			Model:       "codellama:7b",
-			Temperature: 0.7,
-			MaxTokens:   2000,
-			Timeout:     30 * time.Second,
-		},
-	}
-
-	help := flag.Bool("help", false, "Show help message")
-	version := flag.Bool("version", false, "Show version information")
-
-	flag.Parse()
-
-	if *help {
+	opts, err := parseFlags(os.Args[1:])
+	if err == flag.ErrHelp {
 		showHelp()
 		os.Exit(0)
 	}
-	if *version {
+	if err != nil {
+		showHelp()
+		os.Exit(1)
+	}
+
+	if opts.help {
+		showHelp()
+		os.Exit(0)
+	}
+	if opts.version {
 		showVersion()
 		os.Exit(0)
 	}
-	if flag.NArg() == 0 {
+	if len(opts.args) == 0 {
 		showHelp()
 		os.Exit(1)
 	}
 
-	args := flag.Args()
+	cfg, err := loadConfig(opts.configPath)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	args := opts.args
 	subcommand := args[0]
 	subcommandArgs := args[1:]
 	switch subcommand {
@@ -96,16 +102,55 @@ func showVersion() {
 	fmt.Println("AI Code Assistant v0.1.0")
 }
 
+// parseFlags parses the command line flags, leaving the command and its
+// arguments in args
+func parseFlags(args []string) (*options, error) {
+	var opts options
+	fs := flag.NewFlagSet("ai-code-assistant", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&opts.configPath, "config", "", "Path to config file")
+	fs.BoolVar(&opts.help, "help", false, "Show help message")
+	fs.BoolVar(&opts.version, "version", false, "Show version information")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	opts.args = fs.Args()
+	return &opts, nil
+}
+
+// loadConfig loads the configuration from path or, if path is empty, from
+// the first config file found in the default locations. Without a config
+// file the defaults are used.
+func loadConfig(path string) (*config.Config, error) {
+	if path == "" {
+		found, err := config.GetConfigPath()
+		if err == nil {
+			path = found
+		}
+	}
+	return config.LoadConfig(path)
+}
+
 func handleConfigCommand(cfg *config.Config, args []string) {
-	fmt.Println("Current configuration:")
-	fmt.Printf("Server: %s:%d\n", cfg.Server.Host, cfg.Server.Port)
-	fmt.Printf("ChromaDB URL: %s\n", cfg.ChromaDB.URL)
+	printConfig(os.Stdout, cfg)
+}
+
+// printConfig writes the effective configuration to w, hiding the API key
+func printConfig(w io.Writer, cfg *config.Config) {
+	fmt.Fprintln(w, "Current configuration:")
+	fmt.Fprintf(w, "Server: %s:%d\n", cfg.Server.Host, cfg.Server.Port)
+	fmt.Fprintf(w, "ChromaDB URL: %s\n", cfg.ChromaDB.URL)
 	if cfg.ChromaDB.APIKey != "" {
-		fmt.Println("ChromaDB API Key: [set]")
+		fmt.Fprintln(w, "ChromaDB API Key: [set]")
 	} else {
-		fmt.Println("ChromaDB API Key: [not set]")
+		fmt.Fprintln(w, "ChromaDB API Key: [not set]")
 	}
-	fmt.Printf("LLM Model: %s\n", cfg.LLM.Model)
+	fmt.Fprintf(w, "LLM Model: %s\n", cfg.LLM.Model)
+	fmt.Fprintf(w, "LLM Temperature: %g\n", cfg.LLM.Temperature)
+	fmt.Fprintf(w, "LLM Max Tokens: %d\n", cfg.LLM.MaxTokens)
+	fmt.Fprintf(w, "LLM Timeout: %s\n", cfg.LLM.Timeout)
+	fmt.Fprintf(w, "Embedding Model: %s\n", cfg.Embedding.Model)
 }
 
 func handleIndexCommand(cfg *config.Config, args []string) {
@@ -167,6 +212,8 @@ func handleIndexCommand(cfg *config.Config, args []string) {
 		storageImpl,
 		indexer.WithLogger(logger),
 		indexer.WithWorkerCount(4),
+		indexer.WithIncremental(true),
+		indexer.WithProgress(printProgress),
 	)
 
 	// Start indexing
@@ -174,6 +221,7 @@ func handleIndexCommand(cfg *config.Config, args []string) {
 	startTime := time.Now()
 
 	err = idx.IndexPath(ctx, abspath)
+	fmt.Fprintln(os.Stderr)
 	if err != nil {
 		logger.Error("Indexing failed", "error", err, "duration", time.Since(startTime).Round(time.Second))
 		os.Exit(1)
@@ -183,6 +231,12 @@ func handleIndexCommand(cfg *config.Config, args []string) {
 	logger.Info("Indexing completed successfully", "duration", duration)
 }
 
+// printProgress prints indexing progress on a single, updating line
+func printProgress(e indexer.ProgressEvent) {
+	fmt.Fprintf(os.Stderr, "\rIndexed %d/%d files (%.0f%%), %d chunks stored, %d errors",
+		e.FilesIndexed+e.Errors, e.FilesDiscovered, e.Percent(), e.ChunksStored, e.Errors)
+}
+
 func handleQueryCommand(cfg *config.Config, args []string) {
 	if len(args) == 0 {
 		log.Fatal("Please provide a query")
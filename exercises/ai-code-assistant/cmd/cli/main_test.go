@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigFlag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := []byte(`
+chromadb:
+  url: "http://chroma.example.com:9000"
+llm:
+  model: "codellama:13b"
+`)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	opts, err := parseFlags([]string{"--config", path, "config"})
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if opts.configPath != path {
+		t.Errorf("config path = %q, want %q", opts.configPath, path)
+	}
+	if len(opts.args) != 1 || opts.args[0] != "config" {
+		t.Errorf("args = %v, want [config]", opts.args)
+	}
+
+	cfg, err := loadConfig(opts.configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.ChromaDB.URL != "http://chroma.example.com:9000" {
+		t.Errorf("ChromaDB URL = %q, want the one from the config file", cfg.ChromaDB.URL)
+	}
+	if cfg.Server.Port != 8080 {
+		t.Errorf("server port = %d, want the default 8080", cfg.Server.Port)
+	}
+
+	var out bytes.Buffer
+	printConfig(&out, cfg)
+	for _, want := range []string{"ChromaDB URL: http://chroma.example.com:9000", "LLM Model: codellama:13b"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("config output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestConfigFlag_MissingFile(t *testing.T) {
+	if _, err := loadConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected an error loading a missing config file")
+	}
+}
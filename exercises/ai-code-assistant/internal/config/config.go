@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"os"
 	"path/filepath"
 	"time"
 
@@ -29,6 +32,8 @@ type ServerConfig struct {
 type ChromaDBConfig struct {
 	URL    string `mapstructure:"url"`
 	APIKey string `mapstructure:"api_key"`
+	// RequireAuth requires an API key even for a local ChromaDB
+	RequireAuth bool `mapstructure:"require_auth"`
 }
 
 // LLMConfig holds LLM related configuration
@@ -90,6 +95,7 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.debug", true)
 	v.SetDefault("chromadb.url", "http://localhost:8000")
 	v.SetDefault("chromadb.api_key", "")
+	v.SetDefault("chromadb.require_auth", false)
 
 	// LLM defaults
 	v.SetDefault("llm.model", "codellama:7b")
@@ -128,8 +134,12 @@ func (c *Config) Validate() error {
 	if c.ChromaDB.URL == "" {
 		return fmt.Errorf("chromadb url is required")
 	}
-	if c.ChromaDB.APIKey == "" {
-		return fmt.Errorf("chromadb api key is required")
+	chromaURL, err := url.Parse(c.ChromaDB.URL)
+	if err != nil || chromaURL.Hostname() == "" {
+		return fmt.Errorf("invalid chromadb url: %s", c.ChromaDB.URL)
+	}
+	if c.ChromaDB.APIKey == "" && (c.ChromaDB.RequireAuth || !isLocalHost(chromaURL.Hostname())) {
+		return fmt.Errorf("chromadb api key is required for %s", c.ChromaDB.URL)
 	}
 	if c.Server.Port <= 0 || c.Server.Port > 65535 {
 		return fmt.Errorf("invalid server port: %d", c.Server.Port)
@@ -142,7 +152,17 @@ func (c *Config) Validate() error {
 	return nil
 }
 
-// GetConfigPath returns the path to the config file
+// isLocalHost reports whether host refers to the local machine
+func isLocalHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// GetConfigPath returns the path of the first config file that exists in
+// the default locations
 func GetConfigPath() (string, error) {
 	// Look for config in the following locations:
 	// 1. Current directory
@@ -159,7 +179,7 @@ func GetConfigPath() (string, error) {
 
 	for _, path := range configPaths {
 		configPath := filepath.Join(path, configName+"."+configType)
-		if _, err := filepath.Abs(configPath); err == nil {
+		if info, err := os.Stat(configPath); err == nil && !info.IsDir() {
 			return configPath, nil
 		}
 	}
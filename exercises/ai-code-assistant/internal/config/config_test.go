@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetConfigPath(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if err := os.Mkdir("configs", 0755); err != nil {
+		t.Fatalf("Failed to create configs directory: %v", err)
+	}
+	want := filepath.Join("configs", "config.yaml")
+	if err := os.WriteFile(want, []byte("server:\n  port: 9090\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	got, err := GetConfigPath()
+	if err != nil {
+		t.Fatalf("GetConfigPath failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("GetConfigPath() = %q, want %q", got, want)
+	}
+
+	// A config in the current directory takes precedence
+	if err := os.WriteFile("config.yaml", []byte("server:\n  port: 9091\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	got, err = GetConfigPath()
+	if err != nil {
+		t.Fatalf("GetConfigPath failed: %v", err)
+	}
+	if got != "config.yaml" {
+		t.Errorf("GetConfigPath() = %q, want %q", got, "config.yaml")
+	}
+}
+
+func TestGetConfigPath_NotFound(t *testing.T) {
+	if _, err := os.Stat("/etc/ai-code-assistant/config.yaml"); err == nil {
+		t.Skip("system config file exists")
+	}
+	t.Chdir(t.TempDir())
+
+	if path, err := GetConfigPath(); err == nil {
+		t.Errorf("Expected an error with no config file, got %q", path)
+	}
+}
+
+func TestValidate_APIKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		apiKey      string
+		requireAuth bool
+		wantErr     bool
+	}{
+		{"local url without key", "http://localhost:8000", "", false, false},
+		{"loopback ip without key", "http://127.0.0.1:8000", "", false, false},
+		{"remote url without key", "https://chroma.example.com", "", false, true},
+		{"remote url with key", "https://chroma.example.com", "secret", false, false},
+		{"local url requiring auth without key", "http://localhost:8000", "", true, true},
+		{"local url requiring auth with key", "http://localhost:8000", "secret", true, false},
+		{"invalid url", "localhost:8000", "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadConfig("")
+			if err != nil {
+				t.Fatalf("Failed to load default config: %v", err)
+			}
+			cfg.ChromaDB.URL = tt.url
+			cfg.ChromaDB.APIKey = tt.apiKey
+			cfg.ChromaDB.RequireAuth = tt.requireAuth
+
+			err = cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -20,6 +20,15 @@ type Chunker struct {
 
 	// Whether to split functions into smaller chunks if they exceed maxChunkSize
 	splitLargeFunctions bool
+
+	// Number of lines at the end of each split chunk that are repeated at
+	// the start of the next one
+	overlapLines int
+
+	// Maximum tokens per chunk. If set, it replaces maxChunkSize as the
+	// threshold for splitting chunks.
+	maxTokens    int
+	tokenCounter TokenCounter
 }
 
 // NewChunker creates a new Chunker with default settings
@@ -28,6 +37,7 @@ func NewChunker() *Chunker {
 		minChunkSize:        100,  // Minimum 100 characters per chunk
 		maxChunkSize:        2000, // Maximum 2000 characters per chunk
 		splitLargeFunctions: true, // Split large functions into smaller chunks
+		tokenCounter:        HeuristicTokenCounter{},
 	}
 }
 
@@ -49,6 +59,28 @@ func (c *Chunker) WithSplitLargeFunctions(split bool) *Chunker {
 	return c
 }
 
+// WithOverlap sets the number of lines shared by adjacent chunks when a
+// large chunk is split, so that context at the boundaries is not lost
+func (c *Chunker) WithOverlap(lines int) *Chunker {
+	c.overlapLines = max(lines, 0)
+	return c
+}
+
+// WithTokenLimit sets the maximum number of tokens per chunk, as estimated
+// by the chunker's TokenCounter. Chunks are then split on their token count
+// instead of their character count, and every chunk stays within the limit.
+// A limit of zero disables token-based splitting.
+func (c *Chunker) WithTokenLimit(maxTokens int) *Chunker {
+	c.maxTokens = max(maxTokens, 0)
+	return c
+}
+
+// WithTokenCounter sets the TokenCounter used with WithTokenLimit
+func (c *Chunker) WithTokenCounter(counter TokenCounter) *Chunker {
+	c.tokenCounter = counter
+	return c
+}
+
 // ChunkFile chunks a file into smaller pieces
 func (c *Chunker) ChunkFile(filePath string, content []byte, language string, tree *sitter.Tree) ([]types.Chunk, error) {
 	// Get the base file name for chunk metadata
@@ -83,6 +115,10 @@ func (c *Chunker) ChunkFile(filePath string, content []byte, language string, tr
 			chunks = c.chunkPython(tree.RootNode(), content, filePath, language)
 		case "javascript", "typescript":
 			chunks = c.chunkJavaScript(tree.RootNode(), content, filePath, language)
+		case "rust":
+			chunks = c.chunkRust(tree.RootNode(), content, filePath, language)
+		case "java":
+			chunks = c.chunkJava(tree.RootNode(), content, filePath, language)
 		default:
 			chunks = c.chunkGeneric(tree.RootNode(), content, filePath, language)
 		}
@@ -103,7 +139,7 @@ func (c *Chunker) processChunks(chunks []types.Chunk, content []byte) []types.Ch
 			// Merge with previous chunk if both are small
 			lastIdx := len(result) - 1
 			lastChunk := &result[lastIdx]
-			if len(lastChunk.Content) < c.minChunkSize {
+			if len(lastChunk.Content) < c.minChunkSize && !c.tooLarge(lastChunk.Content+"\n"+chunk.Content) {
 				lastChunk.Content += "\n" + chunk.Content
 				lastChunk.EndLine = chunk.EndLine
 				continue
@@ -111,7 +147,7 @@ func (c *Chunker) processChunks(chunks []types.Chunk, content []byte) []types.Ch
 		}
 
 		// If chunk is too large, split it
-		if len(chunk.Content) > c.maxChunkSize {
+		if c.tooLarge(chunk.Content) {
 			splitChunks := c.splitLargeChunk(chunk, content)
 			result = append(result, splitChunks...)
 		} else {
@@ -122,48 +158,138 @@ func (c *Chunker) processChunks(chunks []types.Chunk, content []byte) []types.Ch
 	return result
 }
 
+// tooLarge reports whether content exceeds the token limit, or the maximum
+// chunk size if no token limit is set
+func (c *Chunker) tooLarge(content string) bool {
+	if c.maxTokens > 0 {
+		return c.tokenCounter.CountTokens(content) > c.maxTokens
+	}
+	return len(content) > c.maxChunkSize
+}
+
+// lineSegment is a line, or part of a line too long to fit in a chunk
+type lineSegment struct {
+	text string
+	line int // index of the line within the chunk being split
+}
+
 // splitLargeChunk splits a chunk that exceeds the maximum size
 func (c *Chunker) splitLargeChunk(chunk types.Chunk, content []byte) []types.Chunk {
 	// For now, just split by lines and create new chunks
 	// A more sophisticated implementation might want to split at logical boundaries
 	lines := strings.Split(chunk.Content, "\n")
-	if len(lines) <= 1 {
+	if len(lines) <= 1 && c.maxTokens == 0 {
 		return []types.Chunk{chunk}
 	}
 
+	segments := make([]lineSegment, 0, len(lines))
+	for i, line := range lines {
+		if c.maxTokens > 0 && c.tooLarge(line) {
+			for _, part := range c.splitLine(line) {
+				segments = append(segments, lineSegment{text: part, line: i})
+			}
+			continue
+		}
+		segments = append(segments, lineSegment{text: line, line: i})
+	}
+
 	var chunks []types.Chunk
-	var currentChunk strings.Builder
-	currentStartLine := chunk.StartLine
-	lineCount := 0
 
-	for i, line := range lines {
-		currentChunk.WriteString(line)
-		lineCount++
-
-		// If we've reached the max chunk size or this is the last line
-		if currentChunk.Len() >= c.maxChunkSize || i == len(lines)-1 {
-			chunks = append(chunks, types.Chunk{
-				ID:        generateChunkID(chunk.FilePath, currentStartLine, currentStartLine+lineCount-1, 0, uint32(len(chunks))),
-				Content:   currentChunk.String(),
-				FilePath:  chunk.FilePath,
-				Language:  chunk.Language,
-				NodeType:  chunk.NodeType,
-				StartLine: currentStartLine,
-				EndLine:   currentStartLine + lineCount - 1,
-			})
-
-			// Reset for next chunk
-			currentChunk.Reset()
-			currentStartLine += lineCount
-			lineCount = 0
-		} else {
-			currentChunk.WriteString("\n")
+	// start and end are the indexes of the first segment and one past the
+	// last segment of the current chunk, and prevEnd is the end of the
+	// previous chunk
+	prevEnd := 0
+	for start := 0; start < len(segments); {
+		end := c.chunkEnd(segments, start)
+
+		// If the overlap leaves no room for a new segment, drop it
+		if end <= prevEnd && start < prevEnd {
+			start = prevEnd
+			continue
 		}
+
+		startLine := chunk.StartLine + segments[start].line
+		endLine := chunk.StartLine + segments[end-1].line
+		chunks = append(chunks, types.Chunk{
+			ID:        generateChunkID(chunk.FilePath, startLine, endLine, 0, uint32(len(chunks))),
+			Content:   joinSegments(segments[start:end]),
+			FilePath:  chunk.FilePath,
+			Language:  chunk.Language,
+			NodeType:  chunk.NodeType,
+			StartLine: startLine,
+			EndLine:   endLine,
+		})
+
+		if end == len(segments) {
+			break
+		}
+
+		// Start the next chunk with the last lines of this one, always
+		// leaving at least one new line so that the split makes progress
+		overlap := min(c.overlapLines, end-start-1)
+		prevEnd = end
+		start = end - overlap
 	}
 
 	return chunks
 }
 
+// chunkEnd returns one past the index of the last segment of a chunk
+// starting at start. A chunk always has at least one segment.
+func (c *Chunker) chunkEnd(segments []lineSegment, start int) int {
+	end := start + 1
+	if c.maxTokens > 0 {
+		// Add segments while the chunk stays within the token limit
+		for end < len(segments) && !c.tooLarge(joinSegments(segments[start:end+1])) {
+			end++
+		}
+		return end
+	}
+
+	// Add segments until we've reached the max chunk size
+	size := len(segments[start].text)
+	for end < len(segments) && size < c.maxChunkSize {
+		size += 1 + len(segments[end].text) // newline separator
+		end++
+	}
+	return end
+}
+
+// splitLine splits a line that exceeds the token limit into the fewest
+// parts that each fit within it
+func (c *Chunker) splitLine(line string) []string {
+	var parts []string
+	runes := []rune(line)
+	for len(runes) > 0 {
+		// Binary search for the longest prefix within the token limit
+		lo, hi := 1, len(runes)
+		for lo < hi {
+			mid := (lo + hi + 1) / 2
+			if c.tooLarge(string(runes[:mid])) {
+				hi = mid - 1
+			} else {
+				lo = mid
+			}
+		}
+		parts = append(parts, string(runes[:lo]))
+		runes = runes[lo:]
+	}
+	return parts
+}
+
+// joinSegments joins segments back into text, separating segments from
+// different lines with newlines
+func joinSegments(segments []lineSegment) string {
+	var b strings.Builder
+	for i, segment := range segments {
+		if i > 0 && segment.line != segments[i-1].line {
+			b.WriteString("\n")
+		}
+		b.WriteString(segment.text)
+	}
+	return b.String()
+}
+
 // chunkGo extracts chunks from Go code
 func (c *Chunker) chunkGo(node *sitter.Node, content []byte, filePath, language string) []types.Chunk {
 	var chunks []types.Chunk
@@ -282,6 +408,99 @@ func (c *Chunker) chunkJavaScript(node *sitter.Node, content []byte, filePath, l
 	return chunks
 }
 
+// chunkRust extracts chunks from Rust code
+func (c *Chunker) chunkRust(node *sitter.Node, content []byte, filePath, language string) []types.Chunk {
+	var chunks []types.Chunk
+
+	// Extract use declarations
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child == nil {
+			continue
+		}
+		if child.Type() == "use_declaration" {
+			chunks = append(chunks, createChunk(child, content, filePath, language, "imports"))
+		}
+	}
+
+	// Extract top-level functions, types, and impl blocks
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child == nil {
+			continue
+		}
+
+		switch child.Type() {
+		case "function_item", "struct_item", "enum_item", "trait_item", "impl_item":
+			chunks = append(chunks, createChunk(child, content, filePath, language, child.Type()))
+		}
+	}
+
+	return chunks
+}
+
+// chunkJava extracts chunks from Java code
+func (c *Chunker) chunkJava(node *sitter.Node, content []byte, filePath, language string) []types.Chunk {
+	var chunks []types.Chunk
+
+	// Extract imports
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child == nil {
+			continue
+		}
+		if child.Type() == "import_declaration" {
+			chunks = append(chunks, createChunk(child, content, filePath, language, "imports"))
+		}
+	}
+
+	// Extract top-level types
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child == nil {
+			continue
+		}
+		chunks = append(chunks, c.chunkJavaType(child, content, filePath, language)...)
+	}
+
+	return chunks
+}
+
+// chunkJavaType extracts chunks from a Java type declaration. Classes are
+// split into their fields, methods and constructors, including those of
+// nested classes, so a class only becomes a chunk of its own if it is empty.
+func (c *Chunker) chunkJavaType(node *sitter.Node, content []byte, filePath, language string) []types.Chunk {
+	switch node.Type() {
+	case "interface_declaration", "enum_declaration", "record_declaration":
+		return []types.Chunk{createChunk(node, content, filePath, language, node.Type())}
+	case "class_declaration":
+	default:
+		return nil
+	}
+
+	var chunks []types.Chunk
+	if body := node.ChildByFieldName("body"); body != nil {
+		for i := 0; i < int(body.ChildCount()); i++ {
+			member := body.Child(i)
+			if member == nil {
+				continue
+			}
+
+			switch member.Type() {
+			case "field_declaration", "method_declaration", "constructor_declaration":
+				chunks = append(chunks, createChunk(member, content, filePath, language, member.Type()))
+			default:
+				chunks = append(chunks, c.chunkJavaType(member, content, filePath, language)...)
+			}
+		}
+	}
+
+	if len(chunks) == 0 {
+		chunks = append(chunks, createChunk(node, content, filePath, language, "class_declaration"))
+	}
+	return chunks
+}
+
 // chunkGeneric provides a generic chunking strategy for unsupported languages
 func (c *Chunker) chunkGeneric(node *sitter.Node, content []byte, filePath, language string) []types.Chunk {
 	// Just return the entire file as one chunk for unsupported languages
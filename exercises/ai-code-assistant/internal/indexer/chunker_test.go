@@ -0,0 +1,257 @@
+package indexer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kumarlokesh/sysd/exercises/ai-code-assistant/internal/types"
+)
+
+func TestChunkerOverlap(t *testing.T) {
+	const overlap = 3
+
+	var src strings.Builder
+	src.WriteString("package main\n\nfunc long() {\n")
+	for i := 0; i < 60; i++ {
+		fmt.Fprintf(&src, "\tprintln(\"statement %02d\")\n", i)
+	}
+	src.WriteString("}\n")
+	content := []byte(src.String())
+
+	parser := NewParser()
+	defer parser.Close()
+	tree, err := parser.Parse(content, "go")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	chunker := NewChunker().WithMaxChunkSize(300).WithOverlap(overlap)
+	chunks, err := chunker.ChunkFile("main.go", content, "go", tree)
+	if err != nil {
+		t.Fatalf("Failed to chunk: %v", err)
+	}
+
+	var split []int
+	for i, chunk := range chunks {
+		if chunk.NodeType == "function_declaration" {
+			split = append(split, i)
+		}
+	}
+	if len(split) < 3 {
+		t.Fatalf("Expected the function to be split into at least 3 chunks, got %d", len(split))
+	}
+
+	ids := make(map[string]bool)
+	for n, i := range split {
+		chunk := chunks[i]
+		if ids[chunk.ID] {
+			t.Errorf("Duplicate chunk ID %s", chunk.ID)
+		}
+		ids[chunk.ID] = true
+
+		lines := strings.Split(chunk.Content, "\n")
+		if len(lines) != chunk.EndLine-chunk.StartLine+1 {
+			t.Errorf("Chunk %d has %d lines but spans lines %d-%d", n, len(lines), chunk.StartLine, chunk.EndLine)
+		}
+
+		if n == 0 {
+			if chunk.StartLine != 3 {
+				t.Errorf("First chunk starts at line %d, want 3", chunk.StartLine)
+			}
+			continue
+		}
+
+		prev := chunks[split[n-1]]
+		if chunk.StartLine != prev.EndLine-overlap+1 {
+			t.Errorf("Chunk %d starts at line %d, want %d", n, chunk.StartLine, prev.EndLine-overlap+1)
+		}
+		prevLines := strings.Split(prev.Content, "\n")
+		if !slices.Equal(lines[:overlap], prevLines[len(prevLines)-overlap:]) {
+			t.Errorf("Chunk %d does not start with the last %d lines of chunk %d:\n%q\n%q",
+				n, overlap, n-1, lines[:overlap], prevLines[len(prevLines)-overlap:])
+		}
+	}
+
+	if last := chunks[split[len(split)-1]]; last.EndLine != 64 {
+		t.Errorf("Last chunk ends at line %d, want 64", last.EndLine)
+	}
+}
+
+func TestChunkerOverlapLargerThanChunk(t *testing.T) {
+	chunker := NewChunker().WithMaxChunkSize(10).WithOverlap(100)
+	chunks := chunker.splitLargeChunk(types.Chunk{
+		Content:   "0123456789\nabcdefghij\nklmnopqrst",
+		FilePath:  "main.go",
+		StartLine: 1,
+		EndLine:   3,
+	}, nil)
+
+	var starts []int
+	for _, c := range chunks {
+		starts = append(starts, c.StartLine)
+	}
+	if !slices.Equal(starts, []int{1, 2, 3}) {
+		t.Errorf("Expected one new line per chunk, got chunks starting at %v", starts)
+	}
+}
+
+func TestChunkerLanguages(t *testing.T) {
+	tests := []struct {
+		language string
+		path     string
+		source   string
+		want     []string
+	}{
+		{
+			language: "rust",
+			path:     "lib.rs",
+			source: `use std::fmt;
+
+struct Point {
+    x: i32,
+    y: i32,
+}
+
+impl fmt::Display for Point {
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+        write!(f, "({}, {})", self.x, self.y)
+    }
+}
+
+fn origin() -> Point {
+    Point { x: 0, y: 0 }
+}
+`,
+			want: []string{"imports", "struct_item", "impl_item", "function_item"},
+		},
+		{
+			language: "java",
+			path:     "Point.java",
+			source: `import java.util.Objects;
+
+public class Point {
+    private final int x;
+
+    public Point(int x) {
+        this.x = x;
+    }
+
+    public int getX() {
+        return x;
+    }
+
+    static class Empty {}
+}
+
+interface Shape {
+    double area();
+}
+`,
+			want: []string{"imports", "field_declaration", "constructor_declaration", "method_declaration", "class_declaration", "interface_declaration"},
+		},
+	}
+
+	parser := NewParser()
+	defer parser.Close()
+
+	for _, tc := range tests {
+		t.Run(tc.language, func(t *testing.T) {
+			language, err := NewDefaultLanguageDetector().Detect(tc.path, nil)
+			if err != nil || language != tc.language {
+				t.Fatalf("Detect(%q) = %q, %v, want %q", tc.path, language, err, tc.language)
+			}
+
+			content := []byte(tc.source)
+			tree, err := parser.Parse(content, language)
+			if err != nil {
+				t.Fatalf("Failed to parse: %v", err)
+			}
+
+			chunks, err := NewChunker().WithMinChunkSize(0).ChunkFile(tc.path, content, language, tree)
+			if err != nil {
+				t.Fatalf("Failed to chunk: %v", err)
+			}
+
+			var got []string
+			for _, chunk := range chunks {
+				got = append(got, chunk.NodeType)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("Chunk node types = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestChunkerTokenLimit(t *testing.T) {
+	const maxTokens = 50
+
+	longLines := func() string {
+		var src strings.Builder
+		src.WriteString("package main\n\nvar data = []string{\n")
+		for i := 0; i < 5; i++ {
+			fmt.Fprintf(&src, "\t%q,\n", strings.Repeat(fmt.Sprintf("long line %d ", i), 40))
+		}
+		src.WriteString("}\n")
+		return src.String()
+	}
+	shortLines := func() string {
+		var src strings.Builder
+		src.WriteString("package main\n\nfunc short() {\n")
+		for i := 0; i < 200; i++ {
+			fmt.Fprintf(&src, "\tx%d++\n", i)
+		}
+		src.WriteString("}\n")
+		return src.String()
+	}
+
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"long lines", longLines()},
+		{"short lines", shortLines()},
+	}
+
+	parser := NewParser()
+	defer parser.Close()
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			content := []byte(tc.source)
+			tree, err := parser.Parse(content, "go")
+			if err != nil {
+				t.Fatalf("Failed to parse: %v", err)
+			}
+
+			counter := HeuristicTokenCounter{}
+			chunker := NewChunker().WithTokenLimit(maxTokens).WithOverlap(2)
+			chunks, err := chunker.ChunkFile("main.go", content, "go", tree)
+			if err != nil {
+				t.Fatalf("Failed to chunk: %v", err)
+			}
+			if len(chunks) < 3 {
+				t.Fatalf("Expected the code to be split, got %d chunks", len(chunks))
+			}
+
+			ids := make(map[string]bool)
+			for _, chunk := range chunks {
+				if tokens := counter.CountTokens(chunk.Content); tokens > maxTokens {
+					t.Errorf("Chunk at lines %d-%d has %d tokens, limit is %d",
+						chunk.StartLine, chunk.EndLine, tokens, maxTokens)
+				}
+				if ids[chunk.ID] {
+					t.Errorf("Duplicate chunk ID %s", chunk.ID)
+				}
+				ids[chunk.ID] = true
+			}
+
+			last := chunks[len(chunks)-1]
+			if want := bytesCountToLines(content) - 1; last.EndLine != want {
+				t.Errorf("Last chunk ends at line %d, want %d", last.EndLine, want)
+			}
+		})
+	}
+}
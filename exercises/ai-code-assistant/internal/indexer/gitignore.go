@@ -0,0 +1,185 @@
+package indexer
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// gitignoreRule is a single pattern from a .gitignore file
+type gitignoreRule struct {
+	// segments is the pattern split on "/". Patterns without a slash are
+	// prefixed with "**" so that they match at any depth.
+	segments []string
+
+	// negate is set for patterns starting with "!", which re-include paths
+	negate bool
+
+	// dirOnly is set for patterns ending with "/", which only match directories
+	dirOnly bool
+}
+
+// gitignore holds the rules from a single .gitignore file. The rules apply
+// to paths below the directory containing the file.
+type gitignore struct {
+	dir   string
+	rules []gitignoreRule
+}
+
+// loadGitignore reads the .gitignore file in dir. It returns nil if the
+// directory has no .gitignore file.
+func loadGitignore(dir string) (*gitignore, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseGitignore(dir, data), nil
+}
+
+// parseGitignore parses the contents of a .gitignore file in dir
+func parseGitignore(dir string, data []byte) *gitignore {
+	g := &gitignore{dir: dir}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule gitignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+			line = line[1:]
+		}
+
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		// A slash at the start or in the middle anchors the pattern to the
+		// directory of the .gitignore file
+		if !strings.Contains(line, "/") {
+			line = "**/" + line
+		}
+		line = strings.TrimPrefix(line, "/")
+
+		rule.segments = strings.Split(line, "/")
+		g.rules = append(g.rules, rule)
+	}
+
+	return g
+}
+
+// match reports whether the rules ignore the given path. matched is false if
+// no rule applies to the path, in which case rules from parent directories
+// decide.
+func (g *gitignore) match(filePath string, isDir bool) (ignored, matched bool) {
+	rel, err := filepath.Rel(g.dir, filePath)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false, false
+	}
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+
+	// Later rules take precedence over earlier ones
+	for i := len(g.rules) - 1; i >= 0; i-- {
+		rule := g.rules[i]
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if matchSegments(rule.segments, segments) {
+			return !rule.negate, true
+		}
+	}
+	return false, false
+}
+
+// matchSegments matches path segments against pattern segments, where a
+// "**" segment matches zero or more path segments
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], segments[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
+
+// gitignoreStack tracks the .gitignore files found while walking a
+// directory tree, layering them like git does: rules in deeper directories
+// take precedence over rules in their parents
+type gitignoreStack struct {
+	root       string
+	gitignores map[string]*gitignore
+}
+
+// newGitignoreStack creates a stack for a walk starting at root
+func newGitignoreStack(root string) *gitignoreStack {
+	return &gitignoreStack{
+		root:       filepath.Clean(root),
+		gitignores: make(map[string]*gitignore),
+	}
+}
+
+// enter loads the .gitignore file of a directory being walked
+func (s *gitignoreStack) enter(dir string) error {
+	g, err := loadGitignore(dir)
+	if err != nil {
+		return err
+	}
+	if g != nil {
+		s.gitignores[filepath.Clean(dir)] = g
+	}
+	return nil
+}
+
+// ignored reports whether a path below the root is ignored by the
+// .gitignore files of its parent directories
+func (s *gitignoreStack) ignored(filePath string, isDir bool) bool {
+	// Collect the parent directories from the path up to the root
+	var dirs []string
+	for dir := filepath.Dir(filepath.Clean(filePath)); ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+		if dir == s.root || dir == filepath.Dir(dir) {
+			break
+		}
+	}
+
+	// The deepest .gitignore with a matching rule decides
+	for _, dir := range dirs {
+		if g := s.gitignores[dir]; g != nil {
+			if ignored, matched := g.match(filePath, isDir); matched {
+				return ignored
+			}
+		}
+	}
+	return false
+}
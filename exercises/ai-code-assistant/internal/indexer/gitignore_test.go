@@ -0,0 +1,48 @@
+package indexer
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGitignoreMatch(t *testing.T) {
+	g := parseGitignore("/repo", []byte(`
+# comment
+*.log
+!keep.log
+build/
+/root.txt
+docs/*.md
+**/testdata/**
+a/**/z
+\#hash
+`))
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"debug.log", false, true},
+		{"sub/dir/debug.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"sub/build", true, true},
+		{"build", false, false},
+		{"root.txt", false, true},
+		{"sub/root.txt", false, false},
+		{"docs/readme.md", false, true},
+		{"docs/sub/readme.md", false, false},
+		{"pkg/testdata/file.go", false, true},
+		{"a/z", false, true},
+		{"a/b/c/z", false, true},
+		{"#hash", false, true},
+		{"main.go", false, false},
+	}
+	for _, tc := range tests {
+		ignored, _ := g.match(filepath.Join("/repo", tc.path), tc.isDir)
+		if ignored != tc.want {
+			t.Errorf("match(%q, dir=%v) = %v, want %v", tc.path, tc.isDir, ignored, tc.want)
+		}
+	}
+}
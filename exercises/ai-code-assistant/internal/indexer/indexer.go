@@ -36,6 +36,15 @@ type DefaultIndexer struct {
 	// Number of workers for parallel processing
 	workerCount int
 
+	// Whether to skip files whose content is unchanged since they were last stored
+	incremental bool
+
+	// Whether to skip paths matched by .gitignore files found while walking directories
+	gitignore bool
+
+	// Callback receiving progress events as files are processed
+	progress func(ProgressEvent)
+
 	// Logger for the indexer
 	logger *slog.Logger
 }
@@ -100,6 +109,32 @@ func WithWorkerCount(count int) IndexerOption {
 	}
 }
 
+// WithIncremental sets whether files whose content hash matches the
+// stored chunks are skipped instead of being re-indexed
+func WithIncremental(incremental bool) IndexerOption {
+	return func(i *DefaultIndexer) {
+		i.incremental = incremental
+	}
+}
+
+// WithGitignore sets whether directory indexing skips paths matched by the
+// .gitignore files it encounters. It is enabled by default.
+func WithGitignore(enabled bool) IndexerOption {
+	return func(i *DefaultIndexer) {
+		i.gitignore = enabled
+	}
+}
+
+// WithProgress sets a callback that receives a ProgressEvent each time a
+// file has been processed. Events are delivered one at a time, so the
+// callback need not be safe for concurrent use, but it should return quickly
+// as workers wait for it.
+func WithProgress(callback func(ProgressEvent)) IndexerOption {
+	return func(i *DefaultIndexer) {
+		i.progress = callback
+	}
+}
+
 // WithLogger sets the logger for the indexer
 func WithLogger(logger *slog.Logger) IndexerOption {
 	return func(i *DefaultIndexer) {
@@ -133,6 +168,7 @@ func NewDefaultIndexer(storage storage.Storage, opts ...IndexerOption) *DefaultI
 		ignoreDirs:       make(map[string]bool),
 		maxFileSize:      10 * 1024 * 1024, // 10MB
 		workerCount:      4,
+		gitignore:        true,
 		languageDetector: NewDefaultLanguageDetector(),
 		parser:           NewParser(),
 		chunker:          NewChunker(),
@@ -218,26 +254,72 @@ func (i *DefaultIndexer) IndexPath(ctx context.Context, path string) error {
 
 // IndexFile implements the Indexer interface
 func (i *DefaultIndexer) IndexFile(ctx context.Context, path string) error {
+	progress := newProgressTracker(i.progress)
+	progress.discovered()
+	return i.indexFileWithProgress(ctx, path, progress)
+}
+
+// indexFileWithProgress indexes a single file and reports the outcome to
+// the progress tracker
+func (i *DefaultIndexer) indexFileWithProgress(ctx context.Context, path string, progress *progressTracker) error {
+	chunksStored, err := i.indexAndStore(ctx, path)
+	progress.processed(path, chunksStored, err)
+	return err
+}
+
+// indexAndStore indexes a single file, replacing any chunks previously
+// stored for it, and returns the number of chunks stored
+func (i *DefaultIndexer) indexAndStore(ctx context.Context, path string) (int, error) {
 	i.logger.Debug("Indexing file", "path", path)
 
 	// Check if context is done
 	select {
 	case <-ctx.Done():
 		i.logger.Warn("Context canceled before indexing file", "path", path, "error", ctx.Err())
-		return ctx.Err()
+		return 0, ctx.Err()
 	default:
 		// Continue with indexing
 	}
 
-	chunks, err := i.indexFile(path)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		i.logger.Error("Failed to read file", "file", path, "error", err)
+		return 0, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	contentHash := generateContentHash(content)
+	docID := generateDocumentID(path)
+
+	// Look up the chunks stored the last time this file was indexed, which
+	// may have been by another process
+	existing, err := i.storage.GetDocumentChunks(ctx, docID)
+	if err != nil {
+		i.logger.Error("Failed to get existing chunks", "path", path, "error", err)
+		return 0, fmt.Errorf("failed to get existing chunks for file %s: %w", path, err)
+	}
+	if i.incremental && unchanged(existing, contentHash) {
+		i.logger.Info("Skipping unchanged file", "path", path)
+		return 0, nil
+	}
+	previous := chunkIDs(existing)
+
+	chunks, err := i.indexFile(path, content, contentHash)
 	if err != nil {
 		i.logger.Error("Failed to index file", "path", path, "error", err)
-		return fmt.Errorf("failed to index file %s: %w", path, err)
+		return 0, fmt.Errorf("failed to index file %s: %w", path, err)
+	}
+
+	// Remove the chunks stored the last time this file was indexed
+	if len(previous) > 0 {
+		i.logger.Debug("Deleting previous chunks", "path", path, "chunk_count", len(previous))
+		if err := i.storage.DeleteChunks(ctx, previous); err != nil {
+			i.logger.Error("Failed to delete previous chunks", "path", path, "error", err)
+			return 0, fmt.Errorf("failed to delete previous chunks for file %s: %w", path, err)
+		}
 	}
 
 	if len(chunks) == 0 {
 		i.logger.Info("No chunks generated from file", "path", path)
-		return nil
+		return 0, nil
 	}
 
 	i.logger.Debug("Storing chunks in vector store", "path", path, "chunk_count", len(chunks))
@@ -245,13 +327,36 @@ func (i *DefaultIndexer) IndexFile(ctx context.Context, path string) error {
 	// Store chunks in the vector store
 	if err := i.storage.StoreChunks(ctx, chunks); err != nil {
 		i.logger.Error("Failed to store chunks", "path", path, "error", err)
-		return fmt.Errorf("failed to store chunks for file %s: %w", path, err)
+		return 0, fmt.Errorf("failed to store chunks for file %s: %w", path, err)
 	}
 
 	i.logger.Info("Successfully indexed file",
 		"path", path,
 		"chunks", len(chunks))
-	return nil
+	return len(chunks), nil
+}
+
+// unchanged reports whether a document has stored chunks and all of them
+// were generated from content with the given hash
+func unchanged(chunks []types.Chunk, contentHash string) bool {
+	if len(chunks) == 0 {
+		return false
+	}
+	for _, chunk := range chunks {
+		if chunk.Metadata["content_hash"] != contentHash {
+			return false
+		}
+	}
+	return true
+}
+
+// chunkIDs returns the IDs of the given chunks
+func chunkIDs(chunks []types.Chunk) []string {
+	ids := make([]string, len(chunks))
+	for idx, chunk := range chunks {
+		ids[idx] = chunk.ID
+	}
+	return ids
 }
 
 // GetSupportedLanguages returns the list of supported programming languages
@@ -273,6 +378,7 @@ func (i *DefaultIndexer) indexDirectory(ctx context.Context, dirPath string) err
 	// Channel to track worker completion
 	doneCh := make(chan struct{})
 	var wg sync.WaitGroup
+	progress := newProgressTracker(i.progress)
 
 	// Start worker goroutines
 	for w := 0; w < i.workerCount; w++ {
@@ -292,7 +398,7 @@ func (i *DefaultIndexer) indexDirectory(ctx context.Context, dirPath string) err
 						"worker_id", workerID,
 						"file", filePath)
 
-					if err := i.IndexFile(ctx, filePath); err != nil {
+					if err := i.indexFileWithProgress(ctx, filePath, progress); err != nil {
 						i.logger.Error("Failed to index file",
 							"worker_id", workerID,
 							"file", filePath,
@@ -317,6 +423,8 @@ func (i *DefaultIndexer) indexDirectory(ctx context.Context, dirPath string) err
 		defer close(fileCh)
 		i.logger.Debug("Starting directory walk", "path", dirPath)
 
+		gitignores := newGitignoreStack(dirPath)
+
 		err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
 			select {
 			case <-ctx.Done():
@@ -333,12 +441,27 @@ func (i *DefaultIndexer) indexDirectory(ctx context.Context, dirPath string) err
 				return nil // Continue walking on error
 			}
 
+			if i.gitignore && path != dirPath && gitignores.ignored(path, d.IsDir()) {
+				i.logger.Debug("Skipping path matched by .gitignore", "path", path)
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
 			if d.IsDir() {
 				dirName := filepath.Base(path)
-				if i.ignoreDirs[dirName] {
+				if i.ignoreDirs[dirName] || (i.gitignore && dirName == ".git") {
 					i.logger.Debug("Skipping ignored directory", "path", path)
 					return filepath.SkipDir
 				}
+				if i.gitignore {
+					if err := gitignores.enter(path); err != nil {
+						i.logger.Warn("Failed to read .gitignore",
+							"path", path,
+							"error", err)
+					}
+				}
 				return nil
 			}
 
@@ -365,6 +488,7 @@ func (i *DefaultIndexer) indexDirectory(ctx context.Context, dirPath string) err
 				return nil
 			}
 
+			progress.discovered()
 			select {
 			case fileCh <- path:
 				i.logger.Debug("Queued file for processing", "path", path)
@@ -404,16 +528,15 @@ func generateDocumentID(path string) string {
 	return hex.EncodeToString(hash[:])
 }
 
-// indexFile indexes a single file
-func (i *DefaultIndexer) indexFile(filePath string) ([]types.Chunk, error) {
-	i.logger.Debug("Starting to index file", "file", filePath)
+// generateContentHash returns the sha256 hash of a file's content
+func generateContentHash(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
 
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		i.logger.Error("Failed to read file", "file", filePath, "error", err)
-		return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
-	}
-	i.logger.Debug("Successfully read file", "file", filePath, "size_bytes", len(content))
+// indexFile chunks a single file whose content has already been read
+func (i *DefaultIndexer) indexFile(filePath string, content []byte, contentHash string) ([]types.Chunk, error) {
+	i.logger.Debug("Starting to index file", "file", filePath, "size_bytes", len(content))
 
 	// Get file info for metadata
 	fileInfo, err := os.Stat(filePath)
@@ -537,6 +660,7 @@ func (i *DefaultIndexer) indexFile(filePath string) ([]types.Chunk, error) {
 		enrichedChunk.Metadata["file_mode"] = fileInfo.Mode().String()
 		enrichedChunk.Metadata["file_mod_time"] = fileInfo.ModTime().Format(time.RFC3339)
 		enrichedChunk.Metadata["language"] = language
+		enrichedChunk.Metadata["content_hash"] = contentHash
 		enrichedChunk.ChunkIndex = idx
 		enrichedChunk.TotalChunks = len(chunks)
 		enrichedChunk.CreatedAt = time.Now()
@@ -0,0 +1,337 @@
+package indexer_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kumarlokesh/sysd/exercises/ai-code-assistant/internal/indexer"
+	"github.com/kumarlokesh/sysd/exercises/ai-code-assistant/internal/storage"
+	"github.com/kumarlokesh/sysd/exercises/ai-code-assistant/internal/types"
+)
+
+// mockStorage is an in-memory storage.Storage that records calls
+type mockStorage struct {
+	mu      sync.Mutex
+	chunks  map[string]types.Chunk
+	stored  [][]types.Chunk
+	deleted [][]string
+
+	// failFiles makes StoreChunks fail for chunks of these files
+	failFiles map[string]bool
+}
+
+func newMockStorage() *mockStorage {
+	return &mockStorage{chunks: make(map[string]types.Chunk)}
+}
+
+func (m *mockStorage) StoreChunks(ctx context.Context, chunks []types.Chunk) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(chunks) > 0 && m.failFiles[chunks[0].FilePath] {
+		return errors.New("storage unavailable")
+	}
+	m.stored = append(m.stored, chunks)
+	for _, chunk := range chunks {
+		m.chunks[chunk.ID] = chunk
+	}
+	return nil
+}
+
+func (m *mockStorage) Search(ctx context.Context, query string, limit int) ([]storage.SearchResult, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) GetChunk(ctx context.Context, id string) (*types.Chunk, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	chunk, ok := m.chunks[id]
+	if !ok {
+		return nil, nil
+	}
+	return &chunk, nil
+}
+
+func (m *mockStorage) GetDocumentChunks(ctx context.Context, documentID string) ([]types.Chunk, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var chunks []types.Chunk
+	for _, chunk := range m.chunks {
+		if chunk.DocumentID == documentID {
+			chunks = append(chunks, chunk)
+		}
+	}
+	return chunks, nil
+}
+
+func (m *mockStorage) DeleteChunks(ctx context.Context, ids []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleted = append(m.deleted, ids)
+	for _, id := range ids {
+		delete(m.chunks, id)
+	}
+	return nil
+}
+
+func newTestIndexer(store storage.Storage, opts ...indexer.IndexerOption) *indexer.DefaultIndexer {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return indexer.NewDefaultIndexer(store, append([]indexer.IndexerOption{indexer.WithLogger(logger)}, opts...)...)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func chunkIDs(chunks []types.Chunk) []string {
+	ids := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		ids[i] = chunk.ID
+	}
+	return ids
+}
+
+func TestIndexFileDeletesPreviousChunks(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "main.go")
+	writeFile(t, path, "package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n")
+
+	store := newMockStorage()
+	idx := newTestIndexer(store)
+
+	if err := idx.IndexFile(ctx, path); err != nil {
+		t.Fatalf("Failed to index file: %v", err)
+	}
+	if len(store.stored) != 1 || len(store.deleted) != 0 {
+		t.Fatalf("Expected one store and no deletes, got %d and %d", len(store.stored), len(store.deleted))
+	}
+	firstIDs := chunkIDs(store.stored[0])
+
+	writeFile(t, path, "package main\n\nfunc main() {\n\tprintln(\"hello\")\n\tprintln(\"world\")\n}\n")
+	if err := idx.IndexFile(ctx, path); err != nil {
+		t.Fatalf("Failed to re-index file: %v", err)
+	}
+
+	if len(store.deleted) != 1 || !slices.Equal(store.deleted[0], firstIDs) {
+		t.Errorf("Expected previous chunks %v to be deleted, got %v", firstIDs, store.deleted)
+	}
+	if len(store.stored) != 2 {
+		t.Fatalf("Expected chunks to be stored again, got %d stores", len(store.stored))
+	}
+	for _, id := range chunkIDs(store.stored[1]) {
+		if _, ok := store.chunks[id]; !ok {
+			t.Errorf("Re-indexed chunk %s missing from storage", id)
+		}
+	}
+	if len(store.chunks) != len(store.stored[1]) {
+		t.Errorf("Expected %d chunks in storage, got %d", len(store.stored[1]), len(store.chunks))
+	}
+}
+
+func TestIndexFileDeletesChunksFromPreviousRun(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "main.go")
+
+	// Enough functions that the file is split into several chunks
+	var content strings.Builder
+	content.WriteString("package main\n")
+	for n := range 100 {
+		fmt.Fprintf(&content, "\nfunc before%d() {\n\tprintln(\"before %d\")\n}\n", n, n)
+	}
+	writeFile(t, path, content.String())
+
+	store := newMockStorage()
+	if err := newTestIndexer(store).IndexFile(ctx, path); err != nil {
+		t.Fatalf("Failed to index file: %v", err)
+	}
+	if len(store.chunks) < 2 {
+		t.Fatalf("Expected several chunks, got %d", len(store.chunks))
+	}
+
+	// A new indexer, as in a later run of the CLI, must find the chunks
+	// in storage to replace them
+	writeFile(t, path, "package main\n\nfunc main() {\n\tprintln(\"after\")\n}\n")
+	if err := newTestIndexer(store).IndexFile(ctx, path); err != nil {
+		t.Fatalf("Failed to re-index file: %v", err)
+	}
+
+	if len(store.chunks) == 0 {
+		t.Fatal("Expected re-indexed chunks in storage")
+	}
+	for _, chunk := range store.chunks {
+		if strings.Contains(chunk.Content, "before") {
+			t.Errorf("Stale chunk left in storage: %q", chunk.Content)
+		}
+	}
+}
+
+// storedFiles returns the sorted, distinct file paths of all stored chunks
+func (m *mockStorage) storedFiles() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var files []string
+	for _, chunks := range m.stored {
+		for _, chunk := range chunks {
+			if !slices.Contains(files, chunk.FilePath) {
+				files = append(files, chunk.FilePath)
+			}
+		}
+	}
+	slices.Sort(files)
+	return files
+}
+
+func TestIndexPathIncremental(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	unchanged := filepath.Join(dir, "unchanged.go")
+	changed := filepath.Join(dir, "pkg", "changed.go")
+	writeFile(t, unchanged, "package main\n\nfunc main() {\n\tprintln(\"unchanged\")\n}\n")
+	writeFile(t, changed, "package pkg\n\nfunc Changed() string {\n\treturn \"before\"\n}\n")
+
+	store := newMockStorage()
+	if err := newTestIndexer(store, indexer.WithIncremental(true)).IndexPath(ctx, dir); err != nil {
+		t.Fatalf("Failed to index directory: %v", err)
+	}
+	if files := store.storedFiles(); !slices.Equal(files, []string{changed, unchanged}) {
+		t.Fatalf("Expected both files to be stored, got %v", files)
+	}
+
+	// A new indexer has no memory of the first run, so it relies on the
+	// content hashes in storage
+	writeFile(t, changed, "package pkg\n\nfunc Changed() string {\n\treturn \"after\"\n}\n")
+	store.stored = nil
+	if err := newTestIndexer(store, indexer.WithIncremental(true)).IndexPath(ctx, dir); err != nil {
+		t.Fatalf("Failed to re-index directory: %v", err)
+	}
+
+	if files := store.storedFiles(); !slices.Equal(files, []string{changed}) {
+		t.Errorf("Expected only %s to be re-stored, got %v", changed, files)
+	}
+	for _, chunk := range store.chunks {
+		if chunk.FilePath == changed && !strings.Contains(chunk.Content, "after") {
+			t.Errorf("Stale chunk left in storage: %q", chunk.Content)
+		}
+	}
+}
+
+func TestIndexPathGitignore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.go":            "package main\n\nfunc main() {}\n",
+		"secret.go":          "package main\n\nconst secret = \"hunter2\"\n",
+		"build/out.go":       "package build\n\nfunc Generated() {}\n",
+		"pkg/types.gen.go":   "package pkg\n\ntype Generated struct{}\n",
+		"pkg/api/api.gen.go": "package api\n\ntype API struct{}\n",
+		"pkg/api/handler.go": "package api\n\nfunc Handle() {}\n",
+		"pkg/api/.gitignore": "!api.gen.go\n",
+		".gitignore":         "# build output\nbuild/\n/secret.go\n*.gen.go\n",
+		".git/hooks/hook.go": "package hooks\n",
+		"vendor/dep/dep.go":  "package dep\n",
+	}
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, name), content)
+	}
+
+	store := newMockStorage()
+	if err := newTestIndexer(store).IndexPath(ctx, dir); err != nil {
+		t.Fatalf("Failed to index directory: %v", err)
+	}
+
+	var want []string
+	for _, name := range []string{"main.go", "pkg/api/api.gen.go", "pkg/api/handler.go", "vendor/dep/dep.go"} {
+		want = append(want, filepath.Join(dir, name))
+	}
+	slices.Sort(want)
+	if files := store.storedFiles(); !slices.Equal(files, want) {
+		t.Errorf("Indexed files = %v, want %v", files, want)
+	}
+
+	store = newMockStorage()
+	if err := newTestIndexer(store, indexer.WithGitignore(false)).IndexPath(ctx, dir); err != nil {
+		t.Fatalf("Failed to index directory: %v", err)
+	}
+	if files := store.storedFiles(); len(files) != 8 {
+		t.Errorf("Expected all 8 Go files to be indexed without gitignore, got %v", files)
+	}
+}
+
+func TestIndexPathProgress(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	names := []string{"a.go", "b.go", "c.py", "pkg/d.go", "pkg/e.go", "pkg/sub/f.py"}
+	for _, name := range names {
+		content := "package main\n\nfunc f() {}\n"
+		if strings.HasSuffix(name, ".py") {
+			content = "def f():\n    pass\n"
+		}
+		writeFile(t, filepath.Join(dir, name), content)
+	}
+	failing := filepath.Join(dir, "pkg/e.go")
+
+	var events []indexer.ProgressEvent
+	store := newMockStorage()
+	store.failFiles = map[string]bool{failing: true}
+	idx := newTestIndexer(store, indexer.WithProgress(func(e indexer.ProgressEvent) {
+		events = append(events, e)
+	}))
+	if err := idx.IndexPath(ctx, dir); err != nil {
+		t.Fatalf("Failed to index directory: %v", err)
+	}
+
+	if len(events) != len(names) {
+		t.Fatalf("Got %d progress events, want one per file (%d)", len(events), len(names))
+	}
+
+	seen := make(map[string]bool)
+	for n, e := range events {
+		if seen[e.FilePath] {
+			t.Errorf("Got more than one event for %s", e.FilePath)
+		}
+		seen[e.FilePath] = true
+
+		if processed := e.FilesIndexed + e.Errors; processed != n+1 {
+			t.Errorf("Event %d reports %d processed files, want %d", n, processed, n+1)
+		}
+		if (e.Err != nil) != (e.FilePath == failing) {
+			t.Errorf("Event for %s has error %v", e.FilePath, e.Err)
+		}
+	}
+
+	stored := 0
+	for _, chunks := range store.stored {
+		stored += len(chunks)
+	}
+	last := events[len(events)-1]
+	if last.FilesDiscovered != len(names) || last.FilesIndexed != len(names)-1 || last.Errors != 1 || last.ChunksStored != stored {
+		t.Errorf("Final event = %+v, want %d discovered, %d indexed, 1 error, %d chunks",
+			last, len(names), len(names)-1, stored)
+	}
+	if last.Percent() != 100 {
+		t.Errorf("Final event Percent() = %v, want 100", last.Percent())
+	}
+
+	// Indexing a single file reports a single event
+	events = nil
+	if err := idx.IndexFile(ctx, filepath.Join(dir, "a.go")); err != nil {
+		t.Fatalf("Failed to index file: %v", err)
+	}
+	if len(events) != 1 || events[0].FilesDiscovered != 1 || events[0].FilesIndexed != 1 {
+		t.Errorf("IndexFile() progress events = %+v, want one event for a single file", events)
+	}
+}
@@ -5,20 +5,33 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
 	"strings"
 	"sync"
 
 	"github.com/rs/zerolog/log"
 	sitter "github.com/smacker/go-tree-sitter"
 	"github.com/smacker/go-tree-sitter/golang"
+	"github.com/smacker/go-tree-sitter/java"
 	"github.com/smacker/go-tree-sitter/javascript"
 	"github.com/smacker/go-tree-sitter/python"
+	"github.com/smacker/go-tree-sitter/rust"
 )
 
-// Parser is responsible for parsing code files into syntax trees
+// Parser is responsible for parsing code files into syntax trees. It is
+// safe for concurrent use: each Parse call checks out its own tree-sitter
+// parser from a pool, so files are parsed in parallel.
 type Parser struct {
-	parser *sitter.Parser
-	mutex  sync.Mutex
+	mutex sync.Mutex
+
+	// idle holds the tree-sitter parsers that are not in use
+	idle []*sitter.Parser
+
+	// maxIdle is the number of idle parsers kept for reuse; parsers
+	// returned beyond this are closed
+	maxIdle int
+
+	closed bool
 }
 
 // NewParser creates a new Parser instance
@@ -33,8 +46,40 @@ func NewParser() *Parser {
 
 	log.Debug().Msg("Successfully created tree-sitter parser")
 	return &Parser{
-		parser: parser,
+		idle:    []*sitter.Parser{parser},
+		maxIdle: runtime.GOMAXPROCS(0),
+	}
+}
+
+// acquire checks out a tree-sitter parser from the pool, creating one if
+// none is idle
+func (p *Parser) acquire() (*sitter.Parser, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return nil, errors.New("parser is closed")
+	}
+	if n := len(p.idle); n > 0 {
+		parser := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		return parser, nil
 	}
+
+	log.Debug().Msg("Creating additional tree-sitter parser")
+	return sitter.NewParser(), nil
+}
+
+// release returns a tree-sitter parser to the pool
+func (p *Parser) release(parser *sitter.Parser) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed || len(p.idle) >= p.maxIdle {
+		parser.Close()
+		return
+	}
+	p.idle = append(p.idle, parser)
 }
 
 // getLanguageConfig returns the tree-sitter language configuration for the given language name
@@ -63,6 +108,20 @@ func getLanguageConfig(language string) (*sitter.Language, error) {
 			return nil, fmt.Errorf("failed to load JavaScript language configuration")
 		}
 		return lang, nil
+	case "rust":
+		log.Debug().Msg("Loading Rust language configuration")
+		lang := rust.GetLanguage()
+		if lang == nil {
+			return nil, fmt.Errorf("failed to load Rust language configuration")
+		}
+		return lang, nil
+	case "java":
+		log.Debug().Msg("Loading Java language configuration")
+		lang := java.GetLanguage()
+		if lang == nil {
+			return nil, fmt.Errorf("failed to load Java language configuration")
+		}
+		return lang, nil
 	default:
 		err := fmt.Errorf("unsupported language: %s", language)
 		log.Error().Err(err).Str("language", language).Msg("Unsupported language")
@@ -72,7 +131,7 @@ func getLanguageConfig(language string) (*sitter.Language, error) {
 
 // Parse parses the given source code into a syntax tree
 func (p *Parser) Parse(content []byte, language string) (*sitter.Tree, error) {
-	if p == nil || p.parser == nil {
+	if p == nil || p.maxIdle == 0 {
 		return nil, errors.New("parser is not initialized")
 	}
 
@@ -91,19 +150,22 @@ func (p *Parser) Parse(content []byte, language string) (*sitter.Tree, error) {
 		return nil, fmt.Errorf("failed to get language config: %w", err)
 	}
 
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
+	parser, err := p.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer p.release(parser)
 
 	log.Debug().
 		Str("language", language).
 		Msg("Setting language on parser")
 
-	p.parser.SetLanguage(lang)
+	parser.SetLanguage(lang)
 
 	ctx := context.Background()
 
 	log.Debug().Msg("Starting to parse content with tree-sitter")
-	tree, err := p.parser.ParseCtx(ctx, nil, content)
+	tree, err := parser.ParseCtx(ctx, nil, content)
 	if err != nil {
 		log.Error().
 			Err(err).
@@ -130,11 +192,17 @@ func (p *Parser) Parse(content []byte, language string) (*sitter.Tree, error) {
 	return tree, nil
 }
 
-// Close releases resources used by the parser
+// Close releases resources used by the parser. Parsers checked out by
+// in-flight Parse calls are released when those calls return.
 func (p *Parser) Close() {
-	if p.parser != nil {
-		p.parser.Close()
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, parser := range p.idle {
+		parser.Close()
 	}
+	p.idle = nil
+	p.closed = true
 }
 
 // GetNodeContent returns the source code content for a node
@@ -0,0 +1,84 @@
+package indexer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParserConcurrentParse(t *testing.T) {
+	parser := NewParser()
+	defer parser.Close()
+
+	sources := []struct {
+		language string
+		source   string
+		root     string
+		function string
+	}{
+		{
+			language: "go",
+			source:   "package main\n\nfunc f%d() int {\n\treturn %d\n}\n",
+			root:     "source_file",
+			function: "function_declaration",
+		},
+		{
+			language: "python",
+			source:   "def f%d():\n    return %d\n",
+			root:     "module",
+			function: "function_definition",
+		},
+	}
+
+	const goroutines, parsesPerGoroutine = 8, 25
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines*parsesPerGoroutine)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for n := 0; n < parsesPerGoroutine; n++ {
+				src := sources[(g+n)%len(sources)]
+				id := g*parsesPerGoroutine + n
+				content := []byte(fmt.Sprintf(src.source, id, id))
+
+				tree, err := parser.Parse(content, src.language)
+				if err != nil {
+					errs <- fmt.Errorf("%s parse %d: %w", src.language, id, err)
+					continue
+				}
+
+				root := tree.RootNode()
+				if root.Type() != src.root || root.HasError() {
+					errs <- fmt.Errorf("%s parse %d: got root %s (has error %v), want %s",
+						src.language, id, root.Type(), root.HasError(), src.root)
+					continue
+				}
+				fn := root.NamedChild(int(root.NamedChildCount()) - 1)
+				if fn.Type() != src.function || !strings.Contains(GetNodeContent(content, fn), fmt.Sprintf("f%d", id)) {
+					errs <- fmt.Errorf("%s parse %d: got last node %s %q, want %s of f%d",
+						src.language, id, fn.Type(), GetNodeContent(content, fn), src.function, id)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestParserClose(t *testing.T) {
+	parser := NewParser()
+	if _, err := parser.Parse([]byte("package main\n"), "go"); err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	parser.Close()
+	if _, err := parser.Parse([]byte("package main\n"), "go"); err == nil {
+		t.Error("Expected error parsing with a closed parser")
+	}
+}
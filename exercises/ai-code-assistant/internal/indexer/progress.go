@@ -0,0 +1,77 @@
+package indexer
+
+import "sync"
+
+// ProgressEvent reports the progress of an indexing run. An event is
+// emitted each time a file has been processed.
+type ProgressEvent struct {
+	// FilePath is the file that was just processed
+	FilePath string
+
+	// Err is the error that occurred while indexing FilePath, if any
+	Err error
+
+	// FilesDiscovered is the number of files found so far. Directories are
+	// walked while files are being indexed, so it may grow between events.
+	FilesDiscovered int
+
+	// FilesIndexed is the number of files indexed successfully so far,
+	// including unchanged files skipped in incremental mode
+	FilesIndexed int
+
+	// ChunksStored is the number of chunks stored so far
+	ChunksStored int
+
+	// Errors is the number of files that failed to index so far
+	Errors int
+}
+
+// Percent returns the percentage of discovered files that have been
+// processed, whether successfully or not
+func (e ProgressEvent) Percent() float64 {
+	if e.FilesDiscovered == 0 {
+		return 0
+	}
+	return 100 * float64(e.FilesIndexed+e.Errors) / float64(e.FilesDiscovered)
+}
+
+// progressTracker accumulates the progress of an indexing run and reports
+// it to a callback. It is safe for concurrent use by multiple workers.
+type progressTracker struct {
+	mu       sync.Mutex
+	callback func(ProgressEvent)
+	state    ProgressEvent
+}
+
+// newProgressTracker creates a tracker reporting to callback, which may be nil
+func newProgressTracker(callback func(ProgressEvent)) *progressTracker {
+	return &progressTracker{callback: callback}
+}
+
+// discovered records that a file was found and will be indexed
+func (p *progressTracker) discovered() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.state.FilesDiscovered++
+}
+
+// processed records the outcome of indexing a file and emits an event. The
+// callback is invoked with the lock held, so events are delivered one at a
+// time and in order.
+func (p *progressTracker) processed(filePath string, chunksStored int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.state.FilePath = filePath
+	p.state.Err = err
+	if err != nil {
+		p.state.Errors++
+	} else {
+		p.state.FilesIndexed++
+		p.state.ChunksStored += chunksStored
+	}
+
+	if p.callback != nil {
+		p.callback(p.state)
+	}
+}
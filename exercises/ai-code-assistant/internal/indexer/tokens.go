@@ -0,0 +1,16 @@
+package indexer
+
+// TokenCounter estimates the number of LLM tokens in a piece of text
+type TokenCounter interface {
+	// CountTokens returns the estimated number of tokens in text
+	CountTokens(text string) int
+}
+
+// HeuristicTokenCounter estimates tokens as roughly one per four characters,
+// which is a reasonable approximation for code with most tokenizers
+type HeuristicTokenCounter struct{}
+
+// CountTokens implements TokenCounter
+func (HeuristicTokenCounter) CountTokens(text string) int {
+	return (len(text) + 3) / 4
+}
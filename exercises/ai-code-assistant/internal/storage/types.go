@@ -17,6 +17,9 @@ type Storage interface {
 	// GetChunk retrieves a specific chunk by ID
 	GetChunk(ctx context.Context, id string) (*types.Chunk, error)
 
+	// GetDocumentChunks retrieves all chunks stored for a document
+	GetDocumentChunks(ctx context.Context, documentID string) ([]types.Chunk, error)
+
 	// DeleteChunks removes chunks by their IDs
 	DeleteChunks(ctx context.Context, ids []string) error
 }
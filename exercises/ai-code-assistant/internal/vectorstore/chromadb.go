@@ -238,6 +238,112 @@ func (c *ChromaClient) Query(ctx context.Context, collectionName string, query s
 	return docs, nil
 }
 
+// GetByID retrieves a single document from a collection by its ID.
+// The result has the same shape as a Query result without a distance,
+// or is nil if no document has the ID.
+func (c *ChromaClient) GetByID(ctx context.Context, collectionName string, id string) (map[string]interface{}, error) {
+	collection, err := c.client.GetCollection(ctx, collectionName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	results, err := collection.Get(
+		ctx,
+		nil,          // where filter
+		nil,          // where document filter
+		[]string{id}, // ids
+		[]types.QueryEnum{
+			"documents",
+			"metadatas",
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	if len(results.Ids) == 0 {
+		return nil, nil
+	}
+
+	doc := map[string]interface{}{
+		"id": results.Ids[0],
+	}
+	if len(results.Documents) > 0 {
+		doc["document"] = results.Documents[0]
+	}
+	if len(results.Metadatas) > 0 && results.Metadatas[0] != nil {
+		doc["metadata"] = results.Metadatas[0]
+	}
+
+	return doc, nil
+}
+
+// GetWhere retrieves all documents in a collection whose metadata matches
+// the where filter. Each result has the same shape as a GetByID result.
+func (c *ChromaClient) GetWhere(ctx context.Context, collectionName string, where map[string]interface{}) ([]map[string]interface{}, error) {
+	collection, err := c.client.GetCollection(ctx, collectionName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	results, err := collection.Get(
+		ctx,
+		where, // where filter
+		nil,   // where document filter
+		nil,   // ids
+		[]types.QueryEnum{
+			"documents",
+			"metadatas",
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents: %w", err)
+	}
+
+	docs := make([]map[string]interface{}, 0, len(results.Ids))
+	for i, id := range results.Ids {
+		doc := map[string]interface{}{
+			"id": id,
+		}
+		if len(results.Documents) > i {
+			doc["document"] = results.Documents[i]
+		}
+		if len(results.Metadatas) > i && results.Metadatas[i] != nil {
+			doc["metadata"] = results.Metadatas[i]
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+// DeleteDocuments removes documents from a collection by their IDs
+func (c *ChromaClient) DeleteDocuments(ctx context.Context, collectionName string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	collection, err := c.client.GetCollection(ctx, collectionName, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	deleted, err := collection.Delete(ctx, ids, nil, nil)
+	if err != nil {
+		c.logger.Error("Failed to delete documents from collection",
+			"collection", collectionName,
+			"error", err,
+			"document_count", len(ids))
+		return fmt.Errorf("failed to delete documents: %w", err)
+	}
+
+	c.logger.Info("Deleted documents from collection",
+		"collection", collectionName,
+		"requested", len(ids),
+		"deleted", len(deleted))
+	return nil
+}
+
 // Close closes the ChromaDB client
 func (c *ChromaClient) Close() error {
 	// The underlying HTTP client doesn't need explicit cleanup
@@ -0,0 +1,101 @@
+package vectorstore
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	chhttp "github.com/amikos-tech/chroma-go/pkg/commons/http"
+)
+
+// RetryPolicy configures how ChromaStore retries operations that fail with
+// transient errors
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry. It doubles after each
+	// further attempt, and a random jitter of up to half the delay is
+	// subtracted so that concurrent workers don't retry in lockstep.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between attempts. Zero means no cap.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy used by ChromaStore unless
+// overridden with WithRetryPolicy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// IsTransient reports whether an error is likely to succeed on retry:
+// network failures and server errors are transient, while client errors
+// such as invalid requests are not
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var chromaErr *chhttp.ChromaError
+	if errors.As(err, &chromaErr) {
+		// The client reports failures without a response with code 0
+		return chromaErr.ErrorCode == 0 ||
+			chromaErr.ErrorCode == http.StatusTooManyRequests ||
+			chromaErr.ErrorCode >= http.StatusInternalServerError
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// delay returns how long to wait before the given retry, starting at 1
+func (p RetryPolicy) delay(retry int) time.Duration {
+	d := p.BaseDelay << (retry - 1)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d - rand.N(d/2+1)
+}
+
+// do calls fn until it succeeds, fails with an error that is not transient,
+// or the policy runs out of attempts
+func (p RetryPolicy) do(ctx context.Context, logger *slog.Logger, operation string, fn func() error) error {
+	attempts := max(p.MaxAttempts, 1)
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || attempt >= attempts || !IsTransient(err) {
+			return err
+		}
+
+		delay := p.delay(attempt)
+		logger.Warn("Transient ChromaDB failure, retrying",
+			"operation", operation,
+			"attempt", attempt,
+			"max_attempts", attempts,
+			"delay", delay,
+			"error", err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
@@ -10,20 +10,55 @@ import (
 	"github.com/kumarlokesh/sysd/exercises/ai-code-assistant/internal/types"
 )
 
+// DocumentClient is the subset of ChromaClient operations used by ChromaStore
+type DocumentClient interface {
+	// AddDocuments adds documents to a collection, creating it if needed
+	AddDocuments(ctx context.Context, collectionName string, documents []string, ids []string, metadatas []map[string]interface{}) error
+
+	// Query performs a similarity search on a collection
+	Query(ctx context.Context, collectionName string, query string, nResults int) ([]map[string]interface{}, error)
+
+	// GetByID retrieves a single document by its ID, or nil if it is absent
+	GetByID(ctx context.Context, collectionName string, id string) (map[string]interface{}, error)
+
+	// GetWhere retrieves all documents whose metadata matches the filter
+	GetWhere(ctx context.Context, collectionName string, where map[string]interface{}) ([]map[string]interface{}, error)
+
+	// DeleteDocuments removes documents from a collection by their IDs
+	DeleteDocuments(ctx context.Context, collectionName string, ids []string) error
+}
+
 // ChromaStore implements the storage.Storage interface using ChromaDB
 type ChromaStore struct {
-	client         *ChromaClient
+	client         DocumentClient
 	collectionName string
 	logger         *slog.Logger
+	retryPolicy    RetryPolicy
+}
+
+// ChromaStoreOption defines a function that configures a ChromaStore
+type ChromaStoreOption func(*ChromaStore)
+
+// WithRetryPolicy sets the policy for retrying adds and queries that fail
+// with transient errors
+func WithRetryPolicy(policy RetryPolicy) ChromaStoreOption {
+	return func(s *ChromaStore) {
+		s.retryPolicy = policy
+	}
 }
 
 // NewChromaStore creates a new ChromaStore that implements storage.Storage
-func NewChromaStore(client *ChromaClient, collectionName string, logger *slog.Logger) storage.Storage {
-	return &ChromaStore{
+func NewChromaStore(client DocumentClient, collectionName string, logger *slog.Logger, opts ...ChromaStoreOption) storage.Storage {
+	s := &ChromaStore{
 		client:         client,
 		collectionName: collectionName,
 		logger:         logger,
+		retryPolicy:    DefaultRetryPolicy,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // StoreChunks implements Storage.StoreChunks
@@ -35,30 +70,6 @@ func (s *ChromaStore) StoreChunks(ctx context.Context, chunks []types.Chunk) err
 		return nil
 	}
 
-	s.logger.Info("Creating or getting collection",
-		"collection", s.collectionName,
-		"client", fmt.Sprintf("%+v", s.client))
-
-	collection, err := s.client.CreateCollection(ctx, s.collectionName)
-	if err != nil {
-		s.logger.Error("Failed to create or get collection",
-			"collection", s.collectionName,
-			"error", err,
-			"error_type", fmt.Sprintf("%T", err))
-		return fmt.Errorf("failed to create collection: %w", err)
-	}
-
-	if collection == nil {
-		err := fmt.Errorf("created collection is nil")
-		s.logger.Error("Created collection is nil")
-		return err
-	}
-
-	s.logger.Info("Successfully created or retrieved collection",
-		"collection", s.collectionName,
-		"collection_id", collection.ID,
-		"collection_name", collection.Name)
-
 	// Prepare data for ChromaDB
 	s.logger.Info("Preparing chunks for storage", "count", len(chunks))
 
@@ -116,14 +127,10 @@ func (s *ChromaStore) StoreChunks(ctx context.Context, chunks []types.Chunk) err
 		"first_meta", safeGetMap(chromaMetadatas, 0, nil),
 		"total_docs", len(documents))
 
-	s.logger.Debug("Calling collection.Add() with documents", "count", len(documents))
-	_, err = collection.Add(
-		ctx,
-		nil, // embeddings (nil means Chroma will compute them)
-		chromaMetadatas,
-		documents,
-		ids,
-	)
+	s.logger.Debug("Calling AddDocuments() with documents", "count", len(documents))
+	err := s.retryPolicy.do(ctx, s.logger, "add", func() error {
+		return s.client.AddDocuments(ctx, s.collectionName, documents, ids, chromaMetadatas)
+	})
 	duration := time.Since(startTime)
 
 	if err != nil {
@@ -136,7 +143,7 @@ func (s *ChromaStore) StoreChunks(ctx context.Context, chunks []types.Chunk) err
 		return fmt.Errorf("failed to add documents to collection: %w", err)
 	}
 
-	s.logger.Debug("Successfully called collection.Add()",
+	s.logger.Debug("Successfully called AddDocuments()",
 		"duration", duration,
 		"document_count", len(documents))
 
@@ -175,7 +182,12 @@ func safeGetMap(slice []map[string]interface{}, index int, defaultValue map[stri
 
 // Search implements storage.Storage.Search
 func (s *ChromaStore) Search(ctx context.Context, query string, limit int) ([]storage.SearchResult, error) {
-	results, err := s.client.Query(ctx, s.collectionName, query, limit)
+	var results []map[string]interface{}
+	err := s.retryPolicy.do(ctx, s.logger, "query", func() error {
+		var err error
+		results, err = s.client.Query(ctx, s.collectionName, query, limit)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to query documents: %w", err)
 	}
@@ -183,46 +195,12 @@ func (s *ChromaStore) Search(ctx context.Context, query string, limit int) ([]st
 	var searchResults []storage.SearchResult
 
 	for _, r := range results {
-		// Parse metadata
-		metadata, ok := r["metadata"].(map[string]interface{})
+		chunk, metadata, ok := chunkFromResult(r)
 		if !ok {
 			s.logger.Warn("Failed to parse metadata")
 			continue
 		}
 
-		// Convert metadata back to chunk
-		chunk := &types.Chunk{
-			ID:       r["id"].(string),
-			Content:  r["document"].(string),
-			Metadata: make(map[string]string),
-		}
-
-		// Parse metadata fields
-		if documentID, ok := metadata["document_id"].(string); ok {
-			chunk.DocumentID = documentID
-		}
-		if filePath, ok := metadata["file_path"].(string); ok {
-			chunk.FilePath = filePath
-		}
-		if language, ok := metadata["language"].(string); ok {
-			chunk.Language = language
-		}
-		if nodeType, ok := metadata["node_type"].(string); ok {
-			chunk.NodeType = nodeType
-		}
-		if startLine, ok := metadata["start_line"].(float64); ok {
-			chunk.StartLine = int(startLine)
-		}
-		if endLine, ok := metadata["end_line"].(float64); ok {
-			chunk.EndLine = int(endLine)
-		}
-		if chunkIndex, ok := metadata["chunk_index"].(float64); ok {
-			chunk.ChunkIndex = int(chunkIndex)
-		}
-		if totalChunks, ok := metadata["total_chunks"].(float64); ok {
-			chunk.TotalChunks = int(totalChunks)
-		}
-
 		// Get score (inverse of distance)
 		score := 0.0
 		if dist, ok := r["distance"].(float64); ok && dist > 0 {
@@ -253,33 +231,144 @@ func (s *ChromaStore) Search(ctx context.Context, query string, limit int) ([]st
 
 // GetChunk implements Storage.GetChunk
 func (s *ChromaStore) GetChunk(ctx context.Context, id string) (*types.Chunk, error) {
-	// ChromaDB's Get operation would go here
-	// For now, we'll use a search with the ID as the query
-	results, err := s.Search(ctx, id, 1)
+	result, err := s.client.GetByID(ctx, s.collectionName, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get chunk: %w", err)
 	}
 
-	if len(results) == 0 {
+	if result == nil {
 		return nil, nil
 	}
 
-	return results[0].Chunk, nil
+	chunk, _, ok := chunkFromResult(result)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse chunk %s", id)
+	}
+	return chunk, nil
+}
+
+// GetDocumentChunks implements Storage.GetDocumentChunks
+func (s *ChromaStore) GetDocumentChunks(ctx context.Context, documentID string) ([]types.Chunk, error) {
+	results, err := s.client.GetWhere(ctx, s.collectionName, map[string]interface{}{
+		"document_id": documentID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks for document %s: %w", documentID, err)
+	}
+
+	chunks := make([]types.Chunk, 0, len(results))
+	for _, r := range results {
+		chunk, _, ok := chunkFromResult(r)
+		if !ok {
+			s.logger.Warn("Failed to parse metadata", "id", r["id"])
+			continue
+		}
+		chunks = append(chunks, *chunk)
+	}
+	return chunks, nil
+}
+
+// chunkMetadataKeys are the metadata keys StoreChunks sets from chunk fields
+var chunkMetadataKeys = map[string]bool{
+	"document_id":  true,
+	"file_path":    true,
+	"language":     true,
+	"node_type":    true,
+	"start_line":   true,
+	"end_line":     true,
+	"chunk_index":  true,
+	"total_chunks": true,
+	"created_at":   true,
+}
+
+// chunkFromResult converts a document returned by the client back into the
+// chunk that StoreChunks stored. It also returns the raw metadata, and false
+// if the result has no metadata.
+func chunkFromResult(r map[string]interface{}) (*types.Chunk, map[string]interface{}, bool) {
+	metadata, ok := r["metadata"].(map[string]interface{})
+	if !ok {
+		return nil, nil, false
+	}
+
+	id, _ := r["id"].(string)
+	content, _ := r["document"].(string)
+	chunk := &types.Chunk{
+		ID:       id,
+		Content:  content,
+		Metadata: make(map[string]string),
+	}
+
+	// Parse metadata fields
+	if documentID, ok := metadata["document_id"].(string); ok {
+		chunk.DocumentID = documentID
+	}
+	if filePath, ok := metadata["file_path"].(string); ok {
+		chunk.FilePath = filePath
+	}
+	if language, ok := metadata["language"].(string); ok {
+		chunk.Language = language
+	}
+	if nodeType, ok := metadata["node_type"].(string); ok {
+		chunk.NodeType = nodeType
+	}
+	if startLine, ok := metadataInt(metadata["start_line"]); ok {
+		chunk.StartLine = startLine
+	}
+	if endLine, ok := metadataInt(metadata["end_line"]); ok {
+		chunk.EndLine = endLine
+	}
+	if chunkIndex, ok := metadataInt(metadata["chunk_index"]); ok {
+		chunk.ChunkIndex = chunkIndex
+	}
+	if totalChunks, ok := metadataInt(metadata["total_chunks"]); ok {
+		chunk.TotalChunks = totalChunks
+	}
+	if createdAt, ok := metadata["created_at"].(string); ok {
+		if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
+			chunk.CreatedAt = t
+		}
+	}
+
+	// Any other string metadata came from the chunk's own Metadata
+	for k, v := range metadata {
+		if str, ok := v.(string); ok && !chunkMetadataKeys[k] {
+			chunk.Metadata[k] = str
+		}
+	}
+
+	return chunk, metadata, true
+}
+
+// metadataInt converts a numeric metadata value to an int. Values decoded
+// from ChromaDB's JSON responses are float64.
+func metadataInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case float64:
+		return int(n), true
+	case float32:
+		return int(n), true
+	case int:
+		return n, true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	}
+	return 0, false
 }
 
 // DeleteChunks implements Storage.DeleteChunks
 func (s *ChromaStore) DeleteChunks(ctx context.Context, ids []string) error {
-	// ChromaDB's Delete operation would go here
-	// This is a simplified version
-	_, err := s.client.client.GetCollection(ctx, s.collectionName, nil)
-	if err != nil {
-		return fmt.Errorf("failed to get collection: %w", err)
+	if len(ids) == 0 {
+		return nil
 	}
 
-	// Note: The current ChromaDB Go client might not support Delete yet
-	// This is a placeholder for the actual implementation
-	s.logger.Warn("Delete operation not fully implemented",
+	s.logger.Debug("Deleting chunks",
 		"collection", s.collectionName,
-		"ids", ids)
+		"count", len(ids))
+
+	if err := s.client.DeleteDocuments(ctx, s.collectionName, ids); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
 	return nil
 }
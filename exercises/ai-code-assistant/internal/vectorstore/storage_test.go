@@ -0,0 +1,307 @@
+package vectorstore_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	chhttp "github.com/amikos-tech/chroma-go/pkg/commons/http"
+
+	"github.com/kumarlokesh/sysd/exercises/ai-code-assistant/internal/storage"
+	"github.com/kumarlokesh/sysd/exercises/ai-code-assistant/internal/types"
+	"github.com/kumarlokesh/sysd/exercises/ai-code-assistant/internal/vectorstore"
+)
+
+// mockClient is an in-memory vectorstore.DocumentClient
+type mockClient struct {
+	documents map[string]string
+	metadatas map[string]map[string]interface{}
+	deleted   [][]string
+}
+
+func newMockClient() *mockClient {
+	return &mockClient{
+		documents: make(map[string]string),
+		metadatas: make(map[string]map[string]interface{}),
+	}
+}
+
+func (m *mockClient) AddDocuments(ctx context.Context, collectionName string, documents []string, ids []string, metadatas []map[string]interface{}) error {
+	for i, id := range ids {
+		m.documents[id] = documents[i]
+		m.metadatas[id] = metadatas[i]
+	}
+	return nil
+}
+
+func (m *mockClient) Query(ctx context.Context, collectionName string, query string, nResults int) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *mockClient) GetByID(ctx context.Context, collectionName string, id string) (map[string]interface{}, error) {
+	if _, ok := m.documents[id]; !ok {
+		return nil, nil
+	}
+	return m.result(id)
+}
+
+func (m *mockClient) GetWhere(ctx context.Context, collectionName string, where map[string]interface{}) ([]map[string]interface{}, error) {
+	var docs []map[string]interface{}
+	for id, metadata := range m.metadatas {
+		matches := true
+		for k, v := range where {
+			if metadata[k] != v {
+				matches = false
+			}
+		}
+		if !matches {
+			continue
+		}
+		doc, err := m.result(id)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// result returns a stored document as ChromaDB would, with its metadata
+// round-tripped through JSON
+func (m *mockClient) result(id string) (map[string]interface{}, error) {
+	data, err := json.Marshal(m.metadatas[id])
+	if err != nil {
+		return nil, err
+	}
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"id":       id,
+		"document": m.documents[id],
+		"metadata": metadata,
+	}, nil
+}
+
+func (m *mockClient) DeleteDocuments(ctx context.Context, collectionName string, ids []string) error {
+	m.deleted = append(m.deleted, ids)
+	for _, id := range ids {
+		delete(m.documents, id)
+		delete(m.metadatas, id)
+	}
+	return nil
+}
+
+// flakyClient is a mockClient whose adds and queries fail with err until
+// failures attempts have been made
+type flakyClient struct {
+	*mockClient
+	failures int
+	err      error
+
+	addAttempts   int
+	queryAttempts int
+}
+
+func (f *flakyClient) AddDocuments(ctx context.Context, collectionName string, documents []string, ids []string, metadatas []map[string]interface{}) error {
+	f.addAttempts++
+	if f.addAttempts <= f.failures {
+		return f.err
+	}
+	return f.mockClient.AddDocuments(ctx, collectionName, documents, ids, metadatas)
+}
+
+func (f *flakyClient) Query(ctx context.Context, collectionName string, query string, nResults int) ([]map[string]interface{}, error) {
+	f.queryAttempts++
+	if f.queryAttempts <= f.failures {
+		return nil, f.err
+	}
+	return f.mockClient.Query(ctx, collectionName, query, nResults)
+}
+
+func newTestStore(client vectorstore.DocumentClient, opts ...vectorstore.ChromaStoreOption) storage.Storage {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return vectorstore.NewChromaStore(client, "test_collection", logger, opts...)
+}
+
+func TestChromaStoreDeleteChunks(t *testing.T) {
+	ctx := context.Background()
+	client := newMockClient()
+	store := newTestStore(client)
+
+	chunks := []types.Chunk{
+		{ID: "chunk1", Content: "func a() {}", FilePath: "a.go"},
+		{ID: "chunk2", Content: "func b() {}", FilePath: "a.go"},
+		{ID: "chunk3", Content: "func c() {}", FilePath: "c.go"},
+	}
+	if err := store.StoreChunks(ctx, chunks); err != nil {
+		t.Fatalf("Failed to store chunks: %v", err)
+	}
+
+	if err := store.DeleteChunks(ctx, []string{"chunk1", "chunk2"}); err != nil {
+		t.Fatalf("Failed to delete chunks: %v", err)
+	}
+
+	if len(client.deleted) != 1 || !slices.Equal(client.deleted[0], []string{"chunk1", "chunk2"}) {
+		t.Errorf("Expected one delete of [chunk1 chunk2], got %v", client.deleted)
+	}
+	if _, ok := client.documents["chunk3"]; !ok || len(client.documents) != 1 {
+		t.Errorf("Expected only chunk3 to remain, got %v", client.documents)
+	}
+
+	// Deleting nothing should not reach the client
+	if err := store.DeleteChunks(ctx, nil); err != nil {
+		t.Fatalf("Failed to delete no chunks: %v", err)
+	}
+	if len(client.deleted) != 1 {
+		t.Errorf("Expected no additional deletes, got %v", client.deleted)
+	}
+}
+
+func TestChromaStoreGetChunk(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(newMockClient())
+
+	want := types.Chunk{
+		ID:          "chunk1",
+		DocumentID:  "doc1",
+		Content:     "func main() {}",
+		FilePath:    "cmd/main.go",
+		Language:    "go",
+		StartLine:   3,
+		EndLine:     5,
+		NodeType:    "function_declaration",
+		Metadata:    map[string]string{"file_name": "main.go"},
+		ChunkIndex:  1,
+		TotalChunks: 2,
+		CreatedAt:   time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+	}
+	if err := store.StoreChunks(ctx, []types.Chunk{want}); err != nil {
+		t.Fatalf("Failed to store chunk: %v", err)
+	}
+
+	got, err := store.GetChunk(ctx, want.ID)
+	if err != nil {
+		t.Fatalf("Failed to get chunk: %v", err)
+	}
+	if got == nil {
+		t.Fatal("Expected chunk, got nil")
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, want.CreatedAt)
+	}
+	got.CreatedAt = want.CreatedAt
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("GetChunk() = %+v, want %+v", *got, want)
+	}
+
+	missing, err := store.GetChunk(ctx, "missing")
+	if err != nil || missing != nil {
+		t.Errorf("GetChunk(missing) = %v, %v, want nil, nil", missing, err)
+	}
+}
+
+func TestChromaStoreRetry(t *testing.T) {
+	policy := vectorstore.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	tests := []struct {
+		name         string
+		failures     int
+		err          error
+		wantAttempts int
+		wantErr      bool
+	}{
+		{
+			name:         "succeeds without failures",
+			wantAttempts: 1,
+		},
+		{
+			name:         "retries server errors",
+			failures:     2,
+			err:          &chhttp.ChromaError{ErrorCode: 503, Message: "service unavailable"},
+			wantAttempts: 3,
+		},
+		{
+			name:         "retries connection failures",
+			failures:     2,
+			err:          fmt.Errorf("failed to add documents: %w", &chhttp.ChromaError{Message: "connect: connection refused"}),
+			wantAttempts: 3,
+		},
+		{
+			name:         "gives up after max attempts",
+			failures:     5,
+			err:          &chhttp.ChromaError{ErrorCode: 500, Message: "internal error"},
+			wantAttempts: 3,
+			wantErr:      true,
+		},
+		{
+			name:         "does not retry client errors",
+			failures:     2,
+			err:          &chhttp.ChromaError{ErrorCode: 400, Message: "invalid metadata"},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "does not retry validation errors",
+			failures:     2,
+			err:          errors.New("documents and ids length mismatch"),
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			client := &flakyClient{mockClient: newMockClient(), failures: tt.failures, err: tt.err}
+			store := newTestStore(client, vectorstore.WithRetryPolicy(policy))
+
+			err := store.StoreChunks(ctx, []types.Chunk{{ID: "chunk1", Content: "func a() {}", FilePath: "a.go"}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("StoreChunks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if client.addAttempts != tt.wantAttempts {
+				t.Errorf("StoreChunks() made %d attempts, want %d", client.addAttempts, tt.wantAttempts)
+			}
+
+			_, err = store.Search(ctx, "func a", 5)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Search() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if client.queryAttempts != tt.wantAttempts {
+				t.Errorf("Search() made %d attempts, want %d", client.queryAttempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestChromaStoreRetryContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := &flakyClient{
+		mockClient: newMockClient(),
+		failures:   5,
+		err:        &chhttp.ChromaError{ErrorCode: 503},
+	}
+	store := newTestStore(client, vectorstore.WithRetryPolicy(vectorstore.RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   time.Hour,
+	}))
+
+	if err := store.StoreChunks(ctx, []types.Chunk{{ID: "chunk1", Content: "x"}}); err == nil {
+		t.Fatal("Expected error from canceled context")
+	}
+	if client.addAttempts != 1 {
+		t.Errorf("Expected 1 attempt after cancellation, got %d", client.addAttempts)
+	}
+}
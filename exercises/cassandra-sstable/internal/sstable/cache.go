@@ -0,0 +1,64 @@
+package sstable
+
+import (
+	"container/list"
+	"sync"
+)
+
+// blockCache is an LRU cache of checksum-verified block contents, keyed by
+// block offset. It is safe for concurrent use. Cached blocks are shared, so
+// callers must not modify them.
+type blockCache struct {
+	mu       sync.Mutex
+	capacity int                     // Maximum number of blocks kept
+	order    *list.List              // Most recently used at the front
+	items    map[int64]*list.Element // Block offset -> element in order
+}
+
+// cachedBlock is an entry in a blockCache
+type cachedBlock struct {
+	offset int64
+	data   []byte
+}
+
+// newBlockCache creates a cache holding up to capacity blocks
+func newBlockCache(capacity int) *blockCache {
+	return &blockCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[int64]*list.Element, capacity),
+	}
+}
+
+// get returns the block at offset and marks it as recently used
+func (c *blockCache) get(offset int64) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[offset]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cachedBlock).data, true
+}
+
+// put adds the block at offset, evicting the least recently used block if
+// the cache is full
+func (c *blockCache) put(offset int64, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[offset]; ok {
+		c.order.MoveToFront(elem)
+		elem.Value.(*cachedBlock).data = data
+		return
+	}
+
+	if c.order.Len() >= c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cachedBlock).offset)
+	}
+	c.items[offset] = c.order.PushFront(&cachedBlock{offset: offset, data: data})
+}
@@ -0,0 +1,108 @@
+package sstable
+
+import (
+	"bytes"
+	"container/heap"
+	"fmt"
+	"os"
+)
+
+// mergeSource is an input to a merge, positioned at its current entry
+type mergeSource struct {
+	it       EntryIterator
+	priority int // Index of the input; lower indexes win on duplicate keys
+}
+
+// mergeHeap orders merge sources by their current key, then by priority
+type mergeHeap []*mergeSource
+
+func (h mergeHeap) Len() int { return len(h) }
+
+func (h mergeHeap) Less(i, j int) bool {
+	if cmp := bytes.Compare(h[i].it.Key(), h[j].it.Key()); cmp != 0 {
+		return cmp < 0
+	}
+	return h[i].priority < h[j].priority
+}
+
+func (h mergeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *mergeHeap) Push(x any) { *h = append(*h, x.(*mergeSource)) }
+
+func (h *mergeHeap) Pop() any {
+	old := *h
+	n := len(old)
+	source := old[n-1]
+	*h = old[:n-1]
+	return source
+}
+
+// Merge performs a k-way merge of the given SSTables into a new SSTable at
+// outputPath. When a key appears in more than one input, the value from the
+// input that comes first in inputs is kept. Tombstones shadow the key in
+// later inputs and are then dropped, so deleted keys do not appear in the
+// output.
+func Merge(outputPath string, inputs []*Reader) error {
+	writer, err := NewWriter(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create merged SSTable: %w", err)
+	}
+
+	if err := mergeInto(writer, inputs); err != nil {
+		if closeErr := writer.Close(); closeErr != nil {
+			err = fmt.Errorf("%v; failed to close writer: %w", err, closeErr)
+		}
+		if removeErr := os.Remove(outputPath); removeErr != nil {
+			err = fmt.Errorf("%v; failed to remove partial output: %w", err, removeErr)
+		}
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to close merged SSTable: %w", err)
+	}
+
+	return nil
+}
+
+// mergeInto writes the deduplicated, merged contents of inputs to writer
+func mergeInto(writer *Writer, inputs []*Reader) error {
+	h := make(mergeHeap, 0, len(inputs))
+	for i, r := range inputs {
+		it := r.RangeScan(nil, nil, IncludeTombstones())
+		if it.Next() {
+			h = append(h, &mergeSource{it: it, priority: i})
+		} else if err := it.Error(); err != nil {
+			return fmt.Errorf("failed to read input %d: %w", i, err)
+		}
+	}
+	heap.Init(&h)
+
+	var lastKey []byte
+	for h.Len() > 0 {
+		source := h[0]
+
+		// The first source with a given key has the highest precedence;
+		// later sources with the same key are shadowed
+		key := source.it.Key()
+		if lastKey == nil || !bytes.Equal(key, lastKey) {
+			if !source.it.Deleted() {
+				if err := writer.Add(key, source.it.Value()); err != nil {
+					return fmt.Errorf("failed to add key %q: %w", key, err)
+				}
+			}
+			lastKey = key
+		}
+
+		if source.it.Next() {
+			heap.Fix(&h, 0)
+			continue
+		}
+		if err := source.it.Error(); err != nil {
+			return fmt.Errorf("failed to read input %d: %w", source.priority, err)
+		}
+		heap.Pop(&h)
+	}
+
+	return nil
+}
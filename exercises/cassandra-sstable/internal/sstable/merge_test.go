@@ -0,0 +1,128 @@
+package sstable
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "sstable-merge-test-")
+	require.NoError(t, err)
+	defer func() {
+		err := os.RemoveAll(tempDir)
+		assert.NoError(t, err, "failed to clean up temp directory")
+	}()
+
+	// writeTable writes the given key-value pairs to a new SSTable and opens it
+	writeTable := func(t *testing.T, name string, data map[string]string) *Reader {
+		path := filepath.Join(tempDir, name)
+		writer, err := NewWriter(path)
+		require.NoError(t, err)
+		for k, v := range data {
+			require.NoError(t, writer.Add([]byte(k), []byte(v)))
+		}
+		require.NoError(t, writer.Close())
+
+		reader, err := Open(path)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, reader.Close(), "failed to close reader")
+		})
+		return reader
+	}
+
+	t.Run("overlapping tables", func(t *testing.T) {
+		newest := writeTable(t, "newest.sst", map[string]string{
+			"b": "b-newest",
+			"d": "d-newest",
+		})
+		middle := writeTable(t, "middle.sst", map[string]string{
+			"a": "a-middle",
+			"b": "b-middle",
+			"c": "c-middle",
+		})
+		oldest := writeTable(t, "oldest.sst", map[string]string{
+			"a": "a-oldest",
+			"c": "c-oldest",
+			"d": "d-oldest",
+			"e": "e-oldest",
+		})
+
+		path := filepath.Join(tempDir, "merged.sst")
+		require.NoError(t, Merge(path, []*Reader{newest, middle, oldest}))
+
+		merged, err := Open(path)
+		require.NoError(t, err)
+		defer func() {
+			assert.NoError(t, merged.Close(), "failed to close reader")
+		}()
+
+		var keys, values []string
+		it := merged.RangeScan(nil, nil)
+		for it.Next() {
+			keys = append(keys, string(it.Key()))
+			values = append(values, string(it.Value()))
+		}
+		require.NoError(t, it.Error())
+
+		assert.Equal(t, []string{"a", "b", "c", "d", "e"}, keys)
+		assert.Equal(t, []string{"a-middle", "b-newest", "c-middle", "d-newest", "e-oldest"}, values)
+	})
+
+	t.Run("tombstones", func(t *testing.T) {
+		path := filepath.Join(tempDir, "deletes.sst")
+		writer, err := NewWriter(path)
+		require.NoError(t, err)
+		require.NoError(t, writer.Delete([]byte("a")))
+		require.NoError(t, writer.Add([]byte("b"), []byte("b-newest")))
+		require.NoError(t, writer.Close())
+
+		deletes, err := Open(path)
+		require.NoError(t, err)
+		defer func() {
+			assert.NoError(t, deletes.Close(), "failed to close reader")
+		}()
+
+		older := writeTable(t, "older.sst", map[string]string{
+			"a": "a-older",
+			"b": "b-older",
+			"c": "c-older",
+		})
+
+		mergedPath := filepath.Join(tempDir, "merged-tombstones.sst")
+		require.NoError(t, Merge(mergedPath, []*Reader{deletes, older}))
+
+		merged, err := Open(mergedPath)
+		require.NoError(t, err)
+		defer func() {
+			assert.NoError(t, merged.Close(), "failed to close reader")
+		}()
+
+		// The tombstone hides the older value and is not written itself
+		var keys []string
+		it := merged.RangeScan(nil, nil, IncludeTombstones())
+		for it.Next() {
+			keys = append(keys, string(it.Key()))
+		}
+		require.NoError(t, it.Error())
+		assert.Equal(t, []string{"b", "c"}, keys)
+	})
+
+	t.Run("no inputs", func(t *testing.T) {
+		path := filepath.Join(tempDir, "merged-empty.sst")
+		require.NoError(t, Merge(path, nil))
+
+		merged, err := Open(path)
+		require.NoError(t, err)
+		defer func() {
+			assert.NoError(t, merged.Close(), "failed to close reader")
+		}()
+
+		_, err = merged.Get([]byte("key"))
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+	})
+}
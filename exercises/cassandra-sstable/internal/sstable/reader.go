@@ -3,23 +3,69 @@ package sstable
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"os"
+	"sort"
+	"sync"
 
 	"github.com/kumarlokesh/sysd/exercises/cassandra-sstable/internal/trie"
 )
 
-// Reader implements reading from an SSTable file
+var (
+	// ErrKeyNotFound is returned by Get when the key is not in the table
+	ErrKeyNotFound = errors.New("key not found")
+
+	// ErrKeyDeleted is returned by Get when the key has been deleted
+	ErrKeyDeleted = errors.New("key deleted")
+)
+
+// Reader implements reading from an SSTable file. It is safe for concurrent
+// use: Get, RangeScan and the iterators it returns may be used from multiple
+// goroutines, with each iterator owned by a single goroutine.
 type Reader struct {
-	file        *os.File
+	mu          sync.RWMutex // Guards file against Close during reads
+	file        blockFile
+	cache       *blockCache // Recently read blocks; nil if caching is disabled
 	index       *trie.Trie
 	indexOffset int64
 	indexSize   int64
+	blocks      []indexedBlock // Data blocks ordered by first key
+	stats       TableStats
+}
+
+// blockFile is the file blocks are read from
+type blockFile interface {
+	io.ReaderAt
+	io.Closer
+}
+
+// OpenOptions configures a Reader
+type OpenOptions struct {
+	// CacheSize is the number of data blocks kept in an LRU cache, so that
+	// lookups in recently read blocks don't go to disk. Defaults to 0,
+	// which disables the cache.
+	CacheSize int
+}
+
+// indexedBlock is a data block along with the first key stored in it
+type indexedBlock struct {
+	firstKey []byte
+	info     BlockInfo
 }
 
 // Open opens an existing SSTable file for reading
-func Open(filename string) (*Reader, error) {
+func Open(filename string, opts ...OpenOptions) (*Reader, error) {
+	cacheSize := 0
+	for _, opt := range opts {
+		if opt.CacheSize < 0 {
+			return nil, fmt.Errorf("invalid cache size: %d", opt.CacheSize)
+		}
+		cacheSize = opt.CacheSize
+	}
+
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open SSTable file: %w", err)
@@ -34,16 +80,16 @@ func Open(filename string) (*Reader, error) {
 	}
 	fileSize := fileInfo.Size()
 
-	// Read the footer (last 24 bytes)
-	if fileSize < 24 {
+	// Read the footer
+	if fileSize < footerSize {
 		if closeErr := file.Close(); closeErr != nil {
 			return nil, fmt.Errorf("file too small to be a valid SSTable; failed to close file: %w", closeErr)
 		}
 		return nil, fmt.Errorf("file too small to be a valid SSTable")
 	}
 
-	footer := make([]byte, 24)
-	if _, err := file.ReadAt(footer, fileSize-24); err != nil {
+	footer := make([]byte, footerSize)
+	if _, err := file.ReadAt(footer, fileSize-footerSize); err != nil {
 		if closeErr := file.Close(); closeErr != nil {
 			err = fmt.Errorf("%v; failed to close file: %w", err, closeErr)
 		}
@@ -51,7 +97,7 @@ func Open(filename string) (*Reader, error) {
 	}
 
 	// Verify magic number
-	magic := binary.BigEndian.Uint64(footer[16:24])
+	magic := binary.BigEndian.Uint64(footer[32:40])
 	if magic != magicNumber {
 		if closeErr := file.Close(); closeErr != nil {
 			return nil, fmt.Errorf("invalid magic number: %x; failed to close file: %w", magic, closeErr)
@@ -88,16 +134,96 @@ func Open(filename string) (*Reader, error) {
 		return nil, fmt.Errorf("failed to deserialize index: %w", err)
 	}
 
-	return &Reader{
+	r := &Reader{
 		file:        file,
 		index:       trieIndex,
 		indexOffset: indexOffset,
 		indexSize:   indexSize,
-	}, nil
+	}
+	if cacheSize > 0 {
+		r.cache = newBlockCache(cacheSize)
+	}
+
+	if err := r.loadBlockIndex(); err != nil {
+		if closeErr := file.Close(); closeErr != nil {
+			err = fmt.Errorf("%v; failed to close file: %w", err, closeErr)
+		}
+		return nil, fmt.Errorf("failed to load block index: %w", err)
+	}
+
+	// Read the stats
+	statsOffset := int64(binary.BigEndian.Uint64(footer[16:24]))
+	statsSize := int64(binary.BigEndian.Uint64(footer[24:32]))
+	if err := r.readStats(statsOffset, statsSize, fileSize); err != nil {
+		if closeErr := file.Close(); closeErr != nil {
+			err = fmt.Errorf("%v; failed to close file: %w", err, closeErr)
+		}
+		return nil, fmt.Errorf("failed to read stats: %w", err)
+	}
+
+	return r, nil
+}
+
+// readStats reads and decodes the stats section written by the Writer
+func (r *Reader) readStats(offset, size, fileSize int64) error {
+	if offset < 0 || size < 0 || offset+size > fileSize {
+		return fmt.Errorf("invalid stats offset or size")
+	}
+
+	data := make([]byte, size)
+	if _, err := r.file.ReadAt(data, offset); err != nil {
+		return err
+	}
+
+	if len(data) < 24 {
+		return fmt.Errorf("stats section too small (size: %d)", len(data))
+	}
+	stats := TableStats{
+		EntryCount: int64(binary.BigEndian.Uint64(data[0:8])),
+		NumBlocks:  int(binary.BigEndian.Uint64(data[8:16])),
+		DataSize:   int64(binary.BigEndian.Uint64(data[16:24])),
+	}
+
+	// readKey reads a length-prefixed key, advancing data past it
+	data = data[24:]
+	readKey := func() ([]byte, error) {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("stats section truncated")
+		}
+		keyLen := int(binary.BigEndian.Uint32(data))
+		if len(data)-4 < keyLen {
+			return nil, fmt.Errorf("stats section truncated")
+		}
+		key := data[4 : 4+keyLen]
+		data = data[4+keyLen:]
+		return key, nil
+	}
+
+	var err error
+	if stats.MinKey, err = readKey(); err != nil {
+		return err
+	}
+	if stats.MaxKey, err = readKey(); err != nil {
+		return err
+	}
+
+	r.stats = stats
+	return nil
+}
+
+// Stats returns statistics about the table's contents
+func (r *Reader) Stats() TableStats {
+	stats := r.stats
+	stats.MinKey = append([]byte(nil), r.stats.MinKey...)
+	stats.MaxKey = append([]byte(nil), r.stats.MaxKey...)
+	return stats
 }
 
 // Close closes the reader and its underlying file
 func (r *Reader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if r.file == nil {
 		return nil // Already closed
 	}
@@ -106,7 +232,9 @@ func (r *Reader) Close() error {
 	return err
 }
 
-// Get retrieves the value for the given key
+// Get retrieves the value for the given key. It returns ErrKeyNotFound if
+// the key is not in the table and ErrKeyDeleted if it has been deleted;
+// any other error means the table could not be read.
 func (r *Reader) Get(key []byte) ([]byte, error) {
 	// Find the block that might contain the key
 	blockInfo, err := r.findBlockFor(key)
@@ -115,14 +243,54 @@ func (r *Reader) Get(key []byte) ([]byte, error) {
 	}
 
 	// Read the block
-	blockData := make([]byte, blockInfo.size)
-	if _, err := r.file.ReadAt(blockData, blockInfo.offset); err != nil {
-		return nil, fmt.Errorf("failed to read block: %w", err)
+	blockData, err := r.readBlock(blockInfo)
+	if err != nil {
+		return nil, err
 	}
 
 	return r.searchInBlock(blockData, key)
 }
 
+// readBlock reads a block from the cache or the file and verifies its
+// checksum, returning the block contents without the checksum. The contents
+// may be shared through the cache, so they must not be modified.
+func (r *Reader) readBlock(blockInfo *BlockInfo) ([]byte, error) {
+	if blockInfo.size < 4 {
+		return nil, fmt.Errorf("block at offset %d too small to contain checksum (size: %d)", blockInfo.offset, blockInfo.size)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.file == nil {
+		return nil, fmt.Errorf("reader is closed")
+	}
+
+	if r.cache != nil {
+		if contents, ok := r.cache.get(blockInfo.offset); ok {
+			return contents, nil
+		}
+	}
+
+	blockData := make([]byte, blockInfo.size)
+	n, err := r.file.ReadAt(blockData, blockInfo.offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read block at offset %d (size: %d, read: %d): %w",
+			blockInfo.offset, blockInfo.size, n, err)
+	}
+
+	contents := blockData[:len(blockData)-4]
+	expected := binary.BigEndian.Uint32(blockData[len(contents):])
+	if actual := crc32.ChecksumIEEE(contents); actual != expected {
+		return nil, fmt.Errorf("corrupted block at offset %d: checksum mismatch (expected %08x, got %08x)",
+			blockInfo.offset, expected, actual)
+	}
+
+	if r.cache != nil {
+		r.cache.put(blockInfo.offset, contents)
+	}
+	return contents, nil
+}
+
 // EntryIterator is an iterator over key-value pairs in the SSTable
 type EntryIterator interface {
 	// Next advances the iterator to the next key-value pair.
@@ -132,6 +300,12 @@ type EntryIterator interface {
 	Key() []byte
 	// Value returns the current value.
 	Value() []byte
+	// Deleted reports whether the current entry is a tombstone.
+	Deleted() bool
+	// Seek repositions the iterator at the first key >= key within the
+	// scan's range, which may be before or after the current position.
+	// Returns false if there is no such key.
+	Seek(key []byte) bool
 	// Error returns any error encountered during iteration.
 	Error() error
 }
@@ -140,147 +314,166 @@ type entryIterator struct {
 	reader     *Reader
 	startKey   []byte
 	endKey     []byte
+	lowerBound []byte // Entries before this key are skipped; startKey unless seeking
+	prefix     []byte // If set, iteration stops at the first key without it
+	limit      []byte // If set, blocks starting at or after this key are not loaded
 	blockData  []byte
 	blockIdx   int
 	numInBlock int
 	key        []byte
 	value      []byte
-	blockInfo  *BlockInfo // Track current block info
-	blockNum   int        // Track which block we're in
+	deleted    bool
+	blockNum   int  // Index of the next block to load
+	started    bool // Whether the first block has been located
+	done       bool
 	err        error
+
+	includeTombstones bool
 }
 
 func (it *entryIterator) Next() bool {
-	iteration := 0
-	// TODO: Consider making maxIterations configurable or removing it in production
-	// with proper logging/metrics for error cases. Current value is a safety measure
-	// during development to prevent infinite loops from bugs in iteration logic.
-	maxIterations := 1000
-
-	for iteration < maxIterations {
-		iteration++
+	if it.done {
+		return false
+	}
 
+	for {
 		// If we have entries in the current block, process them
-		if it.blockData != nil && it.blockIdx < len(it.blockData) {
-			// Read key length
-			if it.blockIdx+4 > len(it.blockData) {
-				it.err = fmt.Errorf("invalid block data: cannot read key length")
-				return false
+		if it.blockIdx < len(it.blockData) {
+			entry, next, err := entryAt(it.blockData, it.blockIdx)
+			if err != nil {
+				return it.fail(err)
 			}
-			keyLen := int(binary.BigEndian.Uint32(it.blockData[it.blockIdx:]))
-			it.blockIdx += 4
+			it.blockIdx = next
 
-			// Read key
-			if it.blockIdx+keyLen > len(it.blockData) {
-				it.err = fmt.Errorf("invalid block data: cannot read key")
-				return false
-			}
-			key := make([]byte, keyLen)
-			copy(key, it.blockData[it.blockIdx:it.blockIdx+keyLen])
-			it.blockIdx += keyLen
-
-			// Read value length
-			if it.blockIdx+4 > len(it.blockData) {
-				it.err = fmt.Errorf("invalid block data: cannot read value length")
-				return false
+			// Skip if before start key
+			if it.lowerBound != nil && bytes.Compare(entry.Key, it.lowerBound) < 0 {
+				continue
 			}
-			valueLen := int(binary.BigEndian.Uint32(it.blockData[it.blockIdx:]))
-			it.blockIdx += 4
 
-			// Read value
-			if it.blockIdx+valueLen > len(it.blockData) {
-				it.err = fmt.Errorf("invalid block data: cannot read value")
-				return false
+			// Stop if after end key
+			if it.endKey != nil && bytes.Compare(entry.Key, it.endKey) > 0 {
+				return it.fail(nil)
 			}
 
-			// Skip if before start key
-			if it.startKey != nil && bytes.Compare(key, it.startKey) < 0 {
-				it.blockIdx += valueLen // Skip the value
-				continue
+			// Stop once past the keys sharing the prefix
+			if it.prefix != nil && !bytes.HasPrefix(entry.Key, it.prefix) {
+				return it.fail(nil)
 			}
 
-			// Stop if after end key
-			if it.endKey != nil && bytes.Compare(key, it.endKey) > 0 {
-				return false
+			// Skip tombstones unless the caller asked for them
+			if entry.Deleted && !it.includeTombstones {
+				continue
 			}
 
 			// If we get here, we have a valid key-value pair within our range
-			value := make([]byte, valueLen)
-			copy(value, it.blockData[it.blockIdx:it.blockIdx+valueLen])
-			it.blockIdx += valueLen
-
-			it.key = key
-			it.value = value
+			it.key = make([]byte, len(entry.Key))
+			copy(it.key, entry.Key)
+			it.value = make([]byte, len(entry.Value))
+			copy(it.value, entry.Value)
+			it.deleted = entry.Deleted
 			return true
 		}
 
-		// Get the next block
-		if it.blockInfo == nil {
-			// First block - find the block containing the start key
-			blockInfo, err := it.reader.findBlockFor(it.startKey)
-			if err != nil {
-				it.err = fmt.Errorf("failed to find first block: %w", err)
-				return false
-			}
-			it.blockInfo = blockInfo
-		} else {
-			// Next block - find any block with a key > our current block's last key
-			// We can use the block's last key + 1 to find the next block
-			lastKey := string(append(it.key, 0)) // Next possible key after current key
-			blockInfo, err := it.reader.findBlockFor([]byte(lastKey))
-			if err != nil || blockInfo.offset == it.blockInfo.offset {
-				// No more blocks or we're stuck in the same block
-				return false
+		// Start from the block that may contain the start key
+		if !it.started {
+			it.started = true
+			if i := it.reader.findBlockIndex(it.lowerBound); i > 0 {
+				it.blockNum = i
 			}
-			it.blockInfo = blockInfo
 		}
 
-		it.loadBlock(it.blockInfo)
-		it.blockNum++
+		// Move on to the next block, unless it starts past the end key
+		if it.blockNum >= len(it.reader.blocks) {
+			return it.fail(nil)
+		}
+		block := it.reader.blocks[it.blockNum]
+		if it.endKey != nil && bytes.Compare(block.firstKey, it.endKey) > 0 {
+			return it.fail(nil)
+		}
+		if it.limit != nil && bytes.Compare(block.firstKey, it.limit) >= 0 {
+			return it.fail(nil)
+		}
 
-		if it.blockData == nil {
-			return false
+		if err := it.loadBlock(&block.info); err != nil {
+			return it.fail(err)
 		}
+		it.blockNum++
+	}
+}
+
+func (it *entryIterator) Seek(key []byte) bool {
+	// Never seek before the start of the range
+	it.lowerBound = it.startKey
+	if it.startKey == nil || bytes.Compare(key, it.startKey) > 0 {
+		it.lowerBound = make([]byte, len(key))
+		copy(it.lowerBound, key)
 	}
 
-	it.err = fmt.Errorf("reached maximum number of iterations (%d)", maxIterations)
+	// Reset to the block that may contain the key
+	it.started = false
+	it.blockNum = 0
+	it.blockData = nil
+	it.blockIdx = 0
+	it.done = false
+	it.err = nil
+
+	return it.Next()
+}
+
+// fail ends the iteration, recording err if it is not nil
+func (it *entryIterator) fail(err error) bool {
+	it.done = true
+	it.err = err
+	it.blockData = nil
+	it.key = nil
+	it.value = nil
+	it.deleted = false
 	return false
 }
 
-func (it *entryIterator) loadBlock(blockInfo *BlockInfo) {
-	blockData := make([]byte, blockInfo.size)
-	n, err := it.reader.file.ReadAt(blockData, blockInfo.offset)
+func (it *entryIterator) loadBlock(blockInfo *BlockInfo) error {
+	blockData, err := it.reader.readBlock(blockInfo)
 	if err != nil {
-		if closeErr := it.reader.file.Close(); closeErr != nil {
-			err = fmt.Errorf("%v; failed to close file: %w", err, closeErr)
-		}
-		it.err = fmt.Errorf("failed to read block at offset %d (size: %d, read: %d): %w",
-			blockInfo.offset, blockInfo.size, n, err)
-		return
+		return err
 	}
 
 	if len(blockData) < 4 {
-		it.err = fmt.Errorf("block too small to contain entry count (size: %d)", len(blockData))
-		return
+		return fmt.Errorf("block too small to contain entry count (size: %d)", len(blockData))
 	}
 
 	// Read the number of entries in the block
 	it.numInBlock = int(binary.BigEndian.Uint32(blockData[:4]))
-	it.blockData = blockData[4:] // Skip the count
+	restarts, err := blockRestarts(blockData)
+	if err != nil {
+		return err
+	}
+	it.blockData = blockData[4 : len(blockData)-len(restarts)] // Skip the count and restart points
 	it.blockIdx = 0
 
-	it.key = nil
-	it.value = nil
+	return nil
 }
 
 func (it *entryIterator) Key() []byte   { return it.key }
 func (it *entryIterator) Value() []byte { return it.value }
+func (it *entryIterator) Deleted() bool { return it.deleted }
 func (it *entryIterator) Error() error  { return it.err }
 
+// ScanOption configures a range scan
+type ScanOption func(*entryIterator)
+
+// IncludeTombstones makes a range scan return deleted entries, which are
+// skipped by default. Use Deleted to tell them apart from live entries.
+func IncludeTombstones() ScanOption {
+	return func(it *entryIterator) {
+		it.includeTombstones = true
+	}
+}
+
 // RangeScan returns an iterator over all key-value pairs where startKey <= key <= endKey.
 // If startKey is nil, the range starts from the first key.
 // If endKey is nil, the range continues to the last key.
-func (r *Reader) RangeScan(startKey, endKey []byte) EntryIterator {
+// Deleted keys are skipped unless IncludeTombstones is given.
+func (r *Reader) RangeScan(startKey, endKey []byte, opts ...ScanOption) EntryIterator {
 	// Make copies of the keys to prevent modification of the original slices
 	var startCopy, endCopy []byte
 	if startKey != nil {
@@ -292,47 +485,95 @@ func (r *Reader) RangeScan(startKey, endKey []byte) EntryIterator {
 		copy(endCopy, endKey)
 	}
 
-	return &entryIterator{
-		reader:   r,
-		startKey: startCopy,
-		endKey:   endCopy,
+	it := &entryIterator{
+		reader:     r,
+		startKey:   startCopy,
+		endKey:     endCopy,
+		lowerBound: startCopy,
+	}
+	for _, opt := range opts {
+		opt(it)
+	}
+
+	return it
+}
+
+// PrefixScan returns an iterator over all key-value pairs whose key starts
+// with prefix. An empty prefix scans the whole table. Deleted keys are
+// skipped unless IncludeTombstones is given.
+func (r *Reader) PrefixScan(prefix []byte, opts ...ScanOption) EntryIterator {
+	if len(prefix) == 0 {
+		return r.RangeScan(nil, nil, opts...)
+	}
+
+	it := r.RangeScan(prefix, nil, opts...).(*entryIterator)
+	it.prefix = it.startKey
+	it.limit = prefixUpperBound(it.startKey)
+	return it
+}
+
+// prefixUpperBound returns the smallest key greater than every key starting
+// with prefix, or nil if there is none because prefix is all 0xFF bytes
+func prefixUpperBound(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xFF {
+			limit := make([]byte, i+1)
+			copy(limit, prefix)
+			limit[i]++
+			return limit
+		}
 	}
+	return nil
 }
 
 // findBlockFor finds the block that might contain the given key
 // If key is nil or empty, returns the first block in the SSTable
 func (r *Reader) findBlockFor(key []byte) (*BlockInfo, error) {
-	keyStr := string(key)
-	var bestKey string
-	var bestValue []byte
+	i := r.findBlockIndex(key)
+	if i < 0 {
+		return nil, fmt.Errorf("no blocks found in SSTable: %w", ErrKeyNotFound)
+	}
+	return &r.blocks[i].info, nil
+}
 
-	// Traverse the trie to find the best matching key
-	r.index.Traverse("", func(k string, v []byte) bool {
-		// If we're looking for the first key (empty key), take the first one we find
-		if keyStr == "" {
-			bestKey = k
-			bestValue = v
-			return false // Stop after first key
-		}
+// findBlockIndex returns the index of the last block whose first key is
+// <= key, or -1 if there is none. If key is nil or empty, the first block
+// is returned.
+func (r *Reader) findBlockIndex(key []byte) int {
+	if len(r.blocks) == 0 {
+		return -1
+	}
+	if len(key) == 0 {
+		return 0
+	}
+	return sort.Search(len(r.blocks), func(i int) bool {
+		return bytes.Compare(r.blocks[i].firstKey, key) > 0
+	}) - 1
+}
 
-		// Otherwise, find the largest key that is <= our target key
-		if k <= keyStr && (bestKey == "" || k > bestKey) {
-			bestKey = k
-			bestValue = v
+// loadBlockIndex reads the block boundaries from the trie index, ordered
+// by their first key
+func (r *Reader) loadBlockIndex() error {
+	var err error
+	r.blocks = r.blocks[:0]
+	r.index.Traverse("", func(k string, v []byte) bool {
+		var blockInfo *BlockInfo
+		blockInfo, err = r.parseBlockInfo(v)
+		if err != nil {
+			err = fmt.Errorf("failed to parse block info for key %q: %w", k, err)
+			return false
 		}
+		r.blocks = append(r.blocks, indexedBlock{firstKey: []byte(k), info: *blockInfo})
 		return true
 	})
-
-	if bestKey == "" {
-		return nil, fmt.Errorf("no blocks found in SSTable")
-	}
-
-	blockInfo, err := r.parseBlockInfo(bestValue)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse block info: %w", err)
+		return err
 	}
 
-	return blockInfo, nil
+	sort.Slice(r.blocks, func(i, j int) bool {
+		return bytes.Compare(r.blocks[i].firstKey, r.blocks[j].firstKey) < 0
+	})
+	return nil
 }
 
 // parseBlockInfo parses the block info from the format "offset:size"
@@ -349,50 +590,91 @@ func (r *Reader) parseBlockInfo(blockData []byte) (*BlockInfo, error) {
 	}, nil
 }
 
-// searchInBlock searches for a key in a block of data
+// searchInBlock binary searches a block of data for a key using the block's
+// restart points
 func (r *Reader) searchInBlock(blockData []byte, key []byte) ([]byte, error) {
-	reader := bytes.NewReader(blockData)
-
-	// Read the number of entries in the block
-	var numEntries uint32
-	if err := binary.Read(reader, binary.BigEndian, &numEntries); err != nil {
-		return nil, fmt.Errorf("failed to read number of entries: %w", err)
+	restarts, err := blockRestarts(blockData)
+	if err != nil {
+		return nil, err
 	}
-
-	// Search for the key in the block
-	for i := uint32(0); i < numEntries; i++ {
-		// Read key length
-		var keyLen uint32
-		if err := binary.Read(reader, binary.BigEndian, &keyLen); err != nil {
-			return nil, fmt.Errorf("failed to read key length: %w", err)
+	entries := blockData[:len(blockData)-len(restarts)]
+
+	lo, hi := 0, len(restarts)/4
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		offset := int(binary.BigEndian.Uint32(restarts[4*mid:]))
+		entry, _, err := entryAt(entries, offset)
+		if err != nil {
+			return nil, err
 		}
 
-		// Read key
-		currentKey := make([]byte, keyLen)
-		if _, err := io.ReadFull(reader, currentKey); err != nil {
-			return nil, fmt.Errorf("failed to read key: %w", err)
+		switch cmp := bytes.Compare(entry.Key, key); {
+		case cmp == 0:
+			if entry.Deleted {
+				return nil, ErrKeyDeleted
+			}
+			result := make([]byte, len(entry.Value))
+			copy(result, entry.Value)
+			return result, nil
+		case cmp < 0:
+			lo = mid + 1
+		default:
+			hi = mid
 		}
+	}
 
-		// Read value length
-		var valueLen uint32
-		if err := binary.Read(reader, binary.BigEndian, &valueLen); err != nil {
-			return nil, fmt.Errorf("failed to read value length: %w", err)
-		}
+	return nil, ErrKeyNotFound
+}
 
-		// If this is the key we're looking for, read and return the value
-		if bytes.Equal(currentKey, key) {
-			value := make([]byte, valueLen)
-			if _, err := io.ReadFull(reader, value); err != nil {
-				return nil, fmt.Errorf("failed to read value: %w", err)
-			}
-			return value, nil
-		}
+// blockRestarts returns the restart points that follow the entries in a
+// block, one big-endian uint32 offset per entry
+func blockRestarts(blockData []byte) ([]byte, error) {
+	if len(blockData) < 4 {
+		return nil, fmt.Errorf("failed to read number of entries: block too small (size: %d)", len(blockData))
+	}
+	numEntries := int(binary.BigEndian.Uint32(blockData[:4]))
 
-		// Otherwise, skip the value
-		if _, err := reader.Seek(int64(valueLen), io.SeekCurrent); err != nil {
-			return nil, fmt.Errorf("failed to skip value: %w", err)
-		}
+	if numEntries > len(blockData)/4-1 {
+		return nil, fmt.Errorf("invalid block data: cannot read %d restart points", numEntries)
 	}
+	return blockData[len(blockData)-4*numEntries:], nil
+}
 
-	return nil, fmt.Errorf("key not found")
+// entryAt decodes the entry starting at offset within a block, returning it
+// along with the offset of the following entry. The returned key and value
+// alias blockData.
+func entryAt(blockData []byte, offset int) (Entry, int, error) {
+	if offset+1 > len(blockData) {
+		return Entry{}, 0, fmt.Errorf("invalid block data: cannot read entry flags")
+	}
+	flags := blockData[offset]
+	offset++
+
+	if offset+4 > len(blockData) {
+		return Entry{}, 0, fmt.Errorf("invalid block data: cannot read key length")
+	}
+	keyLen := int(binary.BigEndian.Uint32(blockData[offset:]))
+	offset += 4
+
+	if offset+keyLen > len(blockData) {
+		return Entry{}, 0, fmt.Errorf("invalid block data: cannot read key")
+	}
+	key := blockData[offset : offset+keyLen]
+	offset += keyLen
+
+	if offset+4 > len(blockData) {
+		return Entry{}, 0, fmt.Errorf("invalid block data: cannot read value length")
+	}
+	valueLen := int(binary.BigEndian.Uint32(blockData[offset:]))
+	offset += 4
+
+	if offset+valueLen > len(blockData) {
+		return Entry{}, 0, fmt.Errorf("invalid block data: cannot read value")
+	}
+	entry := Entry{
+		Key:     key,
+		Value:   blockData[offset : offset+valueLen],
+		Deleted: flags&entryFlagDeleted != 0,
+	}
+	return entry, offset + valueLen, nil
 }
@@ -1,8 +1,14 @@
 package sstable
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -41,7 +47,7 @@ func TestSSTableReader(t *testing.T) {
 		assert.Equal(t, []byte("test-value"), value)
 
 		_, err = reader.Get([]byte("non-existent"))
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, ErrKeyNotFound)
 	})
 
 	t.Run("read multiple entries", func(t *testing.T) {
@@ -81,7 +87,7 @@ func TestSSTableReader(t *testing.T) {
 		}
 
 		_, err = reader.Get([]byte("non-existent"))
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, ErrKeyNotFound)
 	})
 
 	t.Run("read large value", func(t *testing.T) {
@@ -114,6 +120,33 @@ func TestSSTableReader(t *testing.T) {
 		assert.Equal(t, largeValue, value)
 	})
 
+	t.Run("open prints nothing", func(t *testing.T) {
+		path := filepath.Join(tempDir, "test-quiet.sst")
+
+		// Capture stdout while writing and opening a table
+		stdout := os.Stdout
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		os.Stdout = w
+		defer func() { os.Stdout = stdout }()
+
+		writer, err := NewWriter(path)
+		require.NoError(t, err)
+		require.NoError(t, writer.Add([]byte("key1"), []byte("value1")))
+		require.NoError(t, writer.Add([]byte("key2"), []byte("value2")))
+		require.NoError(t, writer.Close())
+
+		reader, err := Open(path)
+		require.NoError(t, err)
+		require.NoError(t, reader.Close())
+
+		os.Stdout = stdout
+		require.NoError(t, w.Close())
+		output, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Empty(t, string(output))
+	})
+
 	t.Run("invalid file", func(t *testing.T) {
 		_, err := Open("non-existent-file.sst")
 		assert.Error(t, err)
@@ -128,6 +161,256 @@ func TestSSTableReader(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("corrupted_block", func(t *testing.T) {
+		path := filepath.Join(tempDir, "corrupted-block.sst")
+
+		writer, err := NewWriter(path)
+		require.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			err = writer.Add([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i)))
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+
+		// Flip a byte inside the first block, just past the 16-byte header
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		data[16+8] ^= 0xff
+		require.NoError(t, os.WriteFile(path, data, 0644))
+
+		reader, err := Open(path)
+		require.NoError(t, err)
+		defer func() {
+			err := reader.Close()
+			assert.NoError(t, err, "failed to close reader")
+		}()
+
+		_, err = reader.Get([]byte("key1"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum mismatch")
+		assert.NotErrorIs(t, err, ErrKeyNotFound)
+
+		it := reader.RangeScan(nil, nil)
+		assert.False(t, it.Next())
+		require.Error(t, it.Error())
+		assert.Contains(t, it.Error().Error(), "checksum mismatch")
+	})
+
+	t.Run("tombstones", func(t *testing.T) {
+		path := filepath.Join(tempDir, "test-tombstones.sst")
+
+		writer, err := NewWriter(path)
+		require.NoError(t, err)
+		require.NoError(t, writer.Add([]byte("a"), []byte("value-a")))
+		require.NoError(t, writer.Delete([]byte("b")))
+		require.NoError(t, writer.Add([]byte("c"), []byte("value-c")))
+		require.NoError(t, writer.Close())
+
+		reader, err := Open(path)
+		require.NoError(t, err)
+		defer func() {
+			err := reader.Close()
+			assert.NoError(t, err, "failed to close reader")
+		}()
+
+		t.Run("get_after_delete", func(t *testing.T) {
+			_, err := reader.Get([]byte("b"))
+			assert.ErrorIs(t, err, ErrKeyDeleted)
+
+			value, err := reader.Get([]byte("c"))
+			require.NoError(t, err)
+			assert.Equal(t, []byte("value-c"), value)
+		})
+
+		t.Run("scan_skips_tombstones", func(t *testing.T) {
+			var results []string
+			it := reader.RangeScan(nil, nil)
+			for it.Next() {
+				results = append(results, string(it.Key()))
+			}
+			require.NoError(t, it.Error())
+			assert.Equal(t, []string{"a", "c"}, results)
+		})
+
+		t.Run("scan_includes_tombstones", func(t *testing.T) {
+			var results []string
+			var deleted []bool
+			it := reader.RangeScan(nil, nil, IncludeTombstones())
+			for it.Next() {
+				results = append(results, string(it.Key()))
+				deleted = append(deleted, it.Deleted())
+			}
+			require.NoError(t, it.Error())
+			assert.Equal(t, []string{"a", "b", "c"}, results)
+			assert.Equal(t, []bool{false, true, false}, deleted)
+		})
+	})
+
+	t.Run("multi_block_scan", func(t *testing.T) {
+		path := filepath.Join(tempDir, "test-multi-block.sst")
+
+		// Values of a quarter block give a few entries per block, and a long
+		// run of tombstones spans many blocks without returning an entry
+		const numKeys = 3000
+		value := bytes.Repeat([]byte("v"), blockSize/4)
+		writer, err := NewWriter(path)
+		require.NoError(t, err)
+		for i := 0; i < numKeys; i++ {
+			key := []byte(fmt.Sprintf("key-%05d", i))
+			if i >= 1000 && i < 2500 {
+				require.NoError(t, writer.Delete(key))
+				continue
+			}
+			require.NoError(t, writer.Add(key, value))
+		}
+		require.NoError(t, writer.Close())
+
+		reader, err := Open(path)
+		require.NoError(t, err)
+		defer func() {
+			err := reader.Close()
+			assert.NoError(t, err, "failed to close reader")
+		}()
+		require.Greater(t, len(reader.blocks), 500)
+
+		var keys []string
+		it := reader.RangeScan(nil, nil, IncludeTombstones())
+		for it.Next() {
+			keys = append(keys, string(it.Key()))
+		}
+		require.NoError(t, it.Error())
+		require.Len(t, keys, numKeys)
+		for i, key := range keys {
+			assert.Equal(t, fmt.Sprintf("key-%05d", i), key)
+		}
+
+		var live int
+		it = reader.RangeScan([]byte("key-00500"), []byte("key-02999"))
+		for it.Next() {
+			live++
+		}
+		require.NoError(t, it.Error())
+		assert.Equal(t, 500+500, live)
+		assert.False(t, it.Next(), "exhausted iterator should stay exhausted")
+
+		// Seek across blocks in both directions
+		it = reader.RangeScan(nil, nil)
+		require.True(t, it.Seek([]byte("key-02750")))
+		assert.Equal(t, "key-02750", string(it.Key()))
+		require.True(t, it.Seek([]byte("key-00999")))
+		require.True(t, it.Next())
+		assert.Equal(t, "key-02500", string(it.Key()), "tombstones should be skipped after a seek")
+	})
+
+	t.Run("concurrent_reads", func(t *testing.T) {
+		path := filepath.Join(tempDir, "test-concurrent.sst")
+
+		const numKeys = 500
+		writer, err := NewWriter(path, WriterOptions{BlockSize: 512})
+		require.NoError(t, err)
+		for i := 0; i < numKeys; i++ {
+			require.NoError(t, writer.Add([]byte(fmt.Sprintf("key-%03d", i)), []byte(fmt.Sprintf("value-%03d", i))))
+		}
+		require.NoError(t, writer.Close())
+
+		reader, err := Open(path)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 50)
+		for g := 0; g < 50; g++ {
+			wg.Add(1)
+			go func(seed int64) {
+				defer wg.Done()
+				rng := rand.New(rand.NewSource(seed))
+				for i := 0; i < 100; i++ {
+					n := rng.Intn(numKeys)
+					if i%10 != 0 {
+						value, err := reader.Get([]byte(fmt.Sprintf("key-%03d", n)))
+						if err != nil || string(value) != fmt.Sprintf("value-%03d", n) {
+							errs <- fmt.Errorf("get key-%03d: value %q, err %v", n, value, err)
+							return
+						}
+						continue
+					}
+
+					// Scan up to 20 keys from a random start
+					end := n + 19
+					it := reader.RangeScan([]byte(fmt.Sprintf("key-%03d", n)), []byte(fmt.Sprintf("key-%03d", end)))
+					count := 0
+					for it.Next() {
+						if string(it.Key()) != fmt.Sprintf("key-%03d", n+count) {
+							errs <- fmt.Errorf("scan from key-%03d: unexpected key %q", n, it.Key())
+							return
+						}
+						count++
+					}
+					if it.Error() != nil || count != min(end, numKeys-1)-n+1 {
+						errs <- fmt.Errorf("scan from key-%03d: %d keys, err %v", n, count, it.Error())
+						return
+					}
+				}
+			}(int64(g))
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Error(err)
+		}
+
+		require.NoError(t, reader.Close())
+		_, err = reader.Get([]byte("key-001"))
+		assert.Error(t, err, "reads after close should fail")
+	})
+
+	t.Run("empty_table_scan", func(t *testing.T) {
+		path := filepath.Join(tempDir, "test-empty.sst")
+
+		writer, err := NewWriter(path)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		reader, err := Open(path)
+		require.NoError(t, err)
+		defer func() {
+			err := reader.Close()
+			assert.NoError(t, err, "failed to close reader")
+		}()
+
+		it := reader.RangeScan(nil, nil)
+		assert.False(t, it.Next())
+		assert.NoError(t, it.Error())
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		path := filepath.Join(tempDir, "test-stats.sst")
+
+		// Two flushes, with the smallest and largest keys in different
+		// batches, and a block size that fits two entries per block
+		writer, err := NewWriter(path, WriterOptions{BlockSize: 45})
+		require.NoError(t, err)
+		require.NoError(t, writer.Add([]byte("m"), []byte("value-m")))
+		require.NoError(t, writer.Add([]byte("z"), []byte("value-z")))
+		require.NoError(t, writer.Flush())
+		require.NoError(t, writer.Add([]byte("a"), []byte("value-a")))
+		require.NoError(t, writer.Delete([]byte("b")))
+		require.NoError(t, writer.Close())
+
+		reader, err := Open(path)
+		require.NoError(t, err)
+		defer func() {
+			err := reader.Close()
+			assert.NoError(t, err, "failed to close reader")
+		}()
+
+		stats := reader.Stats()
+		assert.Equal(t, int64(4), stats.EntryCount)
+		assert.Equal(t, []byte("a"), stats.MinKey)
+		assert.Equal(t, []byte("z"), stats.MaxKey)
+		assert.Equal(t, 2, stats.NumBlocks)
+		assert.Equal(t, reader.blocks[0].info.size+reader.blocks[1].info.size, stats.DataSize)
+	})
+
 	t.Run("range_scan", func(t *testing.T) {
 		path := filepath.Join(tempDir, "test-range-scan.sst")
 
@@ -208,6 +491,46 @@ func TestSSTableReader(t *testing.T) {
 			assert.NoError(t, it.Error())
 		})
 
+		t.Run("seek", func(t *testing.T) {
+			// next returns the keys remaining in the iterator
+			next := func(it EntryIterator) []string {
+				var results []string
+				for it.Next() {
+					results = append(results, string(it.Key()))
+				}
+				return results
+			}
+
+			it := reader.RangeScan(nil, nil)
+			require.True(t, it.Next())
+			require.True(t, it.Next())
+			assert.Equal(t, "a2", string(it.Key()))
+
+			// Forward to an existing key
+			require.True(t, it.Seek([]byte("b2")))
+			assert.Equal(t, "b2", string(it.Key()))
+			assert.Equal(t, "value-b2", string(it.Value()))
+			require.True(t, it.Next())
+			assert.Equal(t, "c1", string(it.Key()))
+
+			// Backward to a key between stored keys
+			require.True(t, it.Seek([]byte("a15")))
+			assert.Equal(t, "a2", string(it.Key()))
+			assert.Equal(t, []string{"b1", "b2", "c1", "c2"}, next(it))
+
+			// Seeking an exhausted iterator restarts it
+			require.True(t, it.Seek([]byte("c2")))
+			assert.Empty(t, next(it))
+			assert.False(t, it.Seek([]byte("d")))
+			require.NoError(t, it.Error())
+
+			// Seeks stay within the scan's range
+			it = reader.RangeScan([]byte("a2"), []byte("b2"))
+			require.True(t, it.Seek([]byte("a")))
+			assert.Equal(t, "a2", string(it.Key()))
+			assert.False(t, it.Seek([]byte("c1")))
+		})
+
 		t.Run("single_key", func(t *testing.T) {
 			var results []string
 			it := reader.RangeScan([]byte("b1"), []byte("b1"))
@@ -218,4 +541,218 @@ func TestSSTableReader(t *testing.T) {
 			assert.Equal(t, []string{"b1"}, results)
 		})
 	})
+
+	t.Run("prefix_scan", func(t *testing.T) {
+		path := filepath.Join(tempDir, "test-prefix-scan.sst")
+
+		keys := []string{"a", "a:1", "a:2", "a;", "b:1", "\xff\xff", "\xff\xff:1", "\xff\xff\xff"}
+		writer, err := NewWriter(path)
+		require.NoError(t, err)
+		for _, key := range keys {
+			require.NoError(t, writer.Add([]byte(key), []byte("value-"+key)))
+		}
+		require.NoError(t, writer.Close())
+
+		reader, err := Open(path)
+		require.NoError(t, err)
+		defer func() {
+			err := reader.Close()
+			assert.NoError(t, err, "failed to close reader")
+		}()
+
+		// scan returns the keys of a prefix scan
+		scan := func(prefix string) []string {
+			var results []string
+			it := reader.PrefixScan([]byte(prefix))
+			for it.Next() {
+				results = append(results, string(it.Key()))
+			}
+			require.NoError(t, it.Error())
+			return results
+		}
+
+		assert.Equal(t, []string{"a:1", "a:2"}, scan("a:"))
+		assert.Equal(t, []string{"b:1"}, scan("b"))
+		assert.Equal(t, []string{"a:2"}, scan("a:2"))
+		assert.Empty(t, scan("c"))
+		assert.Equal(t, keys, scan(""), "an empty prefix should scan the whole table")
+		assert.Equal(t, []string{"\xff\xff", "\xff\xff:1", "\xff\xff\xff"}, scan("\xff\xff"),
+			"an all-0xFF prefix should scan to the end of the table")
+
+		// Seeks stay within the prefix
+		it := reader.PrefixScan([]byte("a:"))
+		require.True(t, it.Seek([]byte("a:15")))
+		assert.Equal(t, "a:2", string(it.Key()))
+		assert.False(t, it.Seek([]byte("a;")))
+
+		assert.Equal(t, []byte("a;"), prefixUpperBound([]byte("a:")))
+		assert.Equal(t, []byte("b"), prefixUpperBound([]byte("a\xff\xff")))
+		assert.Nil(t, prefixUpperBound([]byte("\xff\xff")))
+	})
+
+	t.Run("multi_block_prefix_scan", func(t *testing.T) {
+		path := filepath.Join(tempDir, "test-multi-block-prefix.sst")
+
+		value := bytes.Repeat([]byte("v"), blockSize/4)
+		writer, err := NewWriter(path)
+		require.NoError(t, err)
+		for _, user := range []string{"user:1:", "user:2:", "user:3:"} {
+			for i := 0; i < 100; i++ {
+				require.NoError(t, writer.Add([]byte(fmt.Sprintf("%s%03d", user, i)), value))
+			}
+		}
+		require.NoError(t, writer.Close())
+
+		reader, err := Open(path)
+		require.NoError(t, err)
+		defer func() {
+			err := reader.Close()
+			assert.NoError(t, err, "failed to close reader")
+		}()
+		require.Greater(t, len(reader.blocks), 30)
+
+		var keys []string
+		it := reader.PrefixScan([]byte("user:2:"))
+		for it.Next() {
+			keys = append(keys, string(it.Key()))
+		}
+		require.NoError(t, it.Error())
+		require.Len(t, keys, 100)
+		assert.Equal(t, "user:2:000", keys[0])
+		assert.Equal(t, "user:2:099", keys[99])
+	})
+
+	t.Run("block_cache", func(t *testing.T) {
+		path := filepath.Join(tempDir, "test-block-cache.sst")
+
+		const numKeys = 40
+		value := bytes.Repeat([]byte("v"), blockSize/4)
+		writer, err := NewWriter(path)
+		require.NoError(t, err)
+		for i := 0; i < numKeys; i++ {
+			require.NoError(t, writer.Add([]byte(fmt.Sprintf("key-%02d", i)), value))
+		}
+		require.NoError(t, writer.Close())
+
+		// open returns a reader with the given cache size whose block reads
+		// are counted
+		open := func(cacheSize int) (*Reader, *countingFile) {
+			reader, err := Open(path, OpenOptions{CacheSize: cacheSize})
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				assert.NoError(t, reader.Close(), "failed to close reader")
+			})
+			file := &countingFile{blockFile: reader.file}
+			reader.file = file
+			return reader, file
+		}
+
+		reader, file := open(numKeys)
+		numBlocks := len(reader.blocks)
+		require.Greater(t, numBlocks, 5)
+
+		// Gets and scans read each block from disk once
+		for round := 0; round < 10; round++ {
+			for i := 0; i < numKeys; i++ {
+				got, err := reader.Get([]byte(fmt.Sprintf("key-%02d", i)))
+				require.NoError(t, err)
+				require.Equal(t, value, got)
+			}
+		}
+		it := reader.RangeScan(nil, nil)
+		for it.Next() {
+		}
+		require.NoError(t, it.Error())
+		assert.Equal(t, int64(numBlocks), file.reads.Load())
+
+		// A cache smaller than the working set evicts the least recently
+		// used block
+		reader, file = open(2)
+		for round := 0; round < 10; round++ {
+			_, err := reader.Get([]byte("key-00"))
+			require.NoError(t, err)
+			_, err = reader.Get([]byte("key-39"))
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int64(2), file.reads.Load())
+		_, err = reader.Get([]byte("key-20"))
+		require.NoError(t, err)
+		_, err = reader.Get([]byte("key-39"))
+		require.NoError(t, err)
+		_, err = reader.Get([]byte("key-00"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), file.reads.Load(), "key-00's block should have been evicted")
+
+		// Without a cache every lookup reads its block
+		reader, file = open(0)
+		for i := 0; i < 10; i++ {
+			_, err := reader.Get([]byte("key-00"))
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int64(10), file.reads.Load())
+
+		_, err = Open(path, OpenOptions{CacheSize: -1})
+		assert.Error(t, err)
+	})
+
+	t.Run("search_within_block", func(t *testing.T) {
+		entries := make([]Entry, 100)
+		for i := range entries {
+			entries[i] = Entry{
+				Key:   []byte(fmt.Sprintf("key-%03d", i*2)),
+				Value: []byte(fmt.Sprintf("value-%03d", i*2)),
+			}
+		}
+
+		blockData, err := encodeBlock(entries)
+		require.NoError(t, err)
+
+		reader := &Reader{}
+		for _, e := range entries {
+			value, err := reader.searchInBlock(blockData, e.Key)
+			require.NoError(t, err)
+			assert.Equal(t, e.Value, value)
+		}
+
+		// Keys between, before and after the stored keys are not found
+		for _, key := range []string{"key-001", "key-099", "a", "key-999"} {
+			_, err := reader.searchInBlock(blockData, []byte(key))
+			assert.ErrorIs(t, err, ErrKeyNotFound, "key %q should not be found", key)
+		}
+	})
+}
+
+func BenchmarkSearchInBlock(b *testing.B) {
+	const numKeys = 1000
+
+	entries := make([]Entry, numKeys)
+	for i := range entries {
+		entries[i] = Entry{
+			Key:   []byte(fmt.Sprintf("key-%06d", i)),
+			Value: []byte(fmt.Sprintf("value-%06d", i)),
+		}
+	}
+
+	blockData, err := encodeBlock(entries)
+	require.NoError(b, err)
+
+	reader := &Reader{}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := entries[i%numKeys].Key
+		if _, err := reader.searchInBlock(blockData, key); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// countingFile counts the reads made through a blockFile
+type countingFile struct {
+	blockFile
+	reads atomic.Int64
+}
+
+func (f *countingFile) ReadAt(p []byte, off int64) (int, error) {
+	f.reads.Add(1)
+	return f.blockFile.ReadAt(p, off)
 }
@@ -6,8 +6,19 @@ type BlockInfo struct {
 	size   int64
 }
 
-// Entry represents a key-value pair in the SSTable
+// Entry represents a key-value pair in the SSTable. A deleted entry is a
+// tombstone: it has no value and shadows the key in older tables.
 type Entry struct {
-	Key   []byte
-	Value []byte
+	Key     []byte
+	Value   []byte
+	Deleted bool
+}
+
+// TableStats summarizes the contents of an SSTable
+type TableStats struct {
+	EntryCount int64  // Number of entries, including tombstones
+	MinKey     []byte // Smallest key in the table
+	MaxKey     []byte // Largest key in the table
+	NumBlocks  int    // Number of data blocks
+	DataSize   int64  // Total size of the data blocks in bytes
 }
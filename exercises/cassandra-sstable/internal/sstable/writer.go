@@ -3,7 +3,9 @@ package sstable
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"hash/crc32"
 	"os"
 	"sort"
 
@@ -15,14 +17,36 @@ const (
 	magicNumber = 0x53535442 // 'SSTB' in ASCII
 
 	// Current version of the SSTable format
-	version = 1
+	version = 3
 
-	// Block size for data storage (4KB)
+	// Default block size for data storage (4KB)
 	blockSize = 4 * 1024
+
+	// Size of the footer: index offset, index size, stats offset, stats size and magic
+	footerSize = 40
+
+	// entryFlagDeleted marks an entry as a tombstone
+	entryFlagDeleted = 1 << 0
 )
 
+// ErrDuplicateKey is returned by Flush and Close in strict mode when a key
+// was added more than once
+var ErrDuplicateKey = errors.New("duplicate key")
+
 // Entry and BlockInfo types are now defined in types.go
 
+// WriterOptions configures a Writer
+type WriterOptions struct {
+	// BlockSize is the target size of a data block in bytes. Entries larger
+	// than this are written to a block of their own. Defaults to 4KB.
+	BlockSize int
+
+	// StrictKeys makes Flush fail with ErrDuplicateKey when a key was added
+	// or deleted more than once. By default the last Add or Delete of a key
+	// wins.
+	StrictKeys bool
+}
+
 // Writer implements writing data to an SSTable file
 type Writer struct {
 	file       *os.File
@@ -30,10 +54,30 @@ type Writer struct {
 	index      *trie.Trie
 	entries    []Entry
 	blockInfos []BlockInfo
+	blockSize  int
+	strictKeys bool
+
+	// Statistics written to the stats section on Close
+	entryCount int64
+	minKey     []byte
+	maxKey     []byte
+	dataSize   int64
 }
 
 // NewWriter creates a new SSTable writer for the given file
-func NewWriter(filename string) (*Writer, error) {
+func NewWriter(filename string, opts ...WriterOptions) (*Writer, error) {
+	targetBlockSize := blockSize
+	strictKeys := false
+	for _, opt := range opts {
+		strictKeys = strictKeys || opt.StrictKeys
+		if opt.BlockSize < 0 {
+			return nil, fmt.Errorf("invalid block size: %d", opt.BlockSize)
+		}
+		if opt.BlockSize > 0 {
+			targetBlockSize = opt.BlockSize
+		}
+	}
+
 	file, err := os.Create(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create SSTable file: %w", err)
@@ -57,12 +101,15 @@ func NewWriter(filename string) (*Writer, error) {
 		index:      trie.New(),
 		entries:    make([]Entry, 0, 1024),
 		blockInfos: make([]BlockInfo, 0, 128),
+		blockSize:  targetBlockSize,
+		strictKeys: strictKeys,
 	}
 
 	return w, nil
 }
 
-// Add adds a key-value pair to the SSTable
+// Add adds a key-value pair to the SSTable. Adding a key again replaces its
+// value, unless the writer uses strict keys.
 func (w *Writer) Add(key, value []byte) error {
 	// Create a copy of the key and value to avoid potential issues with the original slices
 	keyCopy := make([]byte, len(key))
@@ -78,34 +125,78 @@ func (w *Writer) Add(key, value []byte) error {
 	return nil
 }
 
-// writeBlock writes a block of entries to the file
-func (w *Writer) writeBlock(entries []Entry) (BlockInfo, error) {
+// Delete adds a tombstone for key to the SSTable
+func (w *Writer) Delete(key []byte) error {
+	keyCopy := make([]byte, len(key))
+	copy(keyCopy, key)
+
+	w.entries = append(w.entries, Entry{
+		Key:     keyCopy,
+		Deleted: true,
+	})
+
+	return nil
+}
+
+// encodeBlock serializes a block of sorted entries. The layout is
+//
+//	[count u32][flags u8][keyLen u32][key][valueLen u32][value]...[restart u32]...
+//
+// where each restart point is the offset of an entry from the start of the
+// block, allowing readers to binary search the block.
+func encodeBlock(entries []Entry) ([]byte, error) {
 	var buf bytes.Buffer
 
 	// Write the number of entries in this block
 	if err := binary.Write(&buf, binary.BigEndian, uint32(len(entries))); err != nil {
-		return BlockInfo{}, fmt.Errorf("failed to write entry count: %w", err)
+		return nil, fmt.Errorf("failed to write entry count: %w", err)
 	}
 
-	// Write each entry (key length, key, value length, value)
+	// Write each entry (flags, key length, key, value length, value)
+	restarts := make([]uint32, 0, len(entries))
 	for _, entry := range entries {
+		restarts = append(restarts, uint32(buf.Len()))
+
+		var flags byte
+		if entry.Deleted {
+			flags |= entryFlagDeleted
+		}
+		if err := buf.WriteByte(flags); err != nil {
+			return nil, fmt.Errorf("failed to write entry flags: %w", err)
+		}
 		if err := binary.Write(&buf, binary.BigEndian, uint32(len(entry.Key))); err != nil {
-			return BlockInfo{}, fmt.Errorf("failed to write key length: %w", err)
+			return nil, fmt.Errorf("failed to write key length: %w", err)
 		}
 		if _, err := buf.Write(entry.Key); err != nil {
-			return BlockInfo{}, fmt.Errorf("failed to write key: %w", err)
+			return nil, fmt.Errorf("failed to write key: %w", err)
 		}
 		if err := binary.Write(&buf, binary.BigEndian, uint32(len(entry.Value))); err != nil {
-			return BlockInfo{}, fmt.Errorf("failed to write value length: %w", err)
+			return nil, fmt.Errorf("failed to write value length: %w", err)
 		}
 		if _, err := buf.Write(entry.Value); err != nil {
-			return BlockInfo{}, fmt.Errorf("failed to write value: %w", err)
+			return nil, fmt.Errorf("failed to write value: %w", err)
 		}
 	}
 
+	// Write the restart points after the entries
+	if err := binary.Write(&buf, binary.BigEndian, restarts); err != nil {
+		return nil, fmt.Errorf("failed to write restart points: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeBlock writes a block of entries to the file, followed by a CRC32
+// checksum of the block contents
+func (w *Writer) writeBlock(entries []Entry) (BlockInfo, error) {
+	blockData, err := encodeBlock(entries)
+	if err != nil {
+		return BlockInfo{}, err
+	}
+	blockData = binary.BigEndian.AppendUint32(blockData, crc32.ChecksumIEEE(blockData))
+
 	// Write the block to the file
 	blockOffset := w.offset
-	blockData := buf.Bytes()
 	n, err := w.file.Write(blockData)
 	if err != nil {
 		return BlockInfo{}, fmt.Errorf("failed to write block data: %w", err)
@@ -141,16 +232,60 @@ func (w *Writer) writeIndex() (int64, int64, error) {
 	return indexOffset, int64(n), nil
 }
 
-// Flush writes all buffered data to disk
-func (w *Writer) Flush() error {
-	if len(w.entries) == 0 {
-		return nil // Nothing to flush
+// writeStats writes the table statistics to the file. The layout is
+//
+//	[entryCount u64][numBlocks u64][dataSize u64][minKeyLen u32][minKey][maxKeyLen u32][maxKey]
+func (w *Writer) writeStats() (int64, int64, error) {
+	var buf bytes.Buffer
+	fields := []any{
+		uint64(w.entryCount),
+		uint64(len(w.blockInfos)),
+		uint64(w.dataSize),
+		uint32(len(w.minKey)), w.minKey,
+		uint32(len(w.maxKey)), w.maxKey,
+	}
+	for _, field := range fields {
+		if err := binary.Write(&buf, binary.BigEndian, field); err != nil {
+			return 0, 0, fmt.Errorf("failed to encode stats: %w", err)
+		}
 	}
 
-	// Sort entries by key
-	sort.Slice(w.entries, func(i, j int) bool {
+	statsOffset := w.offset
+	n, err := w.file.Write(buf.Bytes())
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to write stats: %w", err)
+	}
+
+	// Update the offset
+	w.offset += int64(n)
+
+	return statsOffset, int64(n), nil
+}
+
+// Flush sorts the buffered entries and resolves keys added more than once.
+// The entries stay buffered and are written to disk on Close, since a later
+// Add may replace a key that has already been flushed and blocks must hold
+// keys in order across the whole table.
+func (w *Writer) Flush() error {
+	// Sort entries by key, keeping entries for the same key in the order
+	// they were added so that the last one wins
+	sort.SliceStable(w.entries, func(i, j int) bool {
 		return bytes.Compare(w.entries[i].Key, w.entries[j].Key) < 0
 	})
+	entries, err := w.dedupEntries()
+	if err != nil {
+		return err
+	}
+	w.entries = entries
+	return nil
+}
+
+// writeEntries writes the sorted entries to data blocks and indexes the
+// first key of each block
+func (w *Writer) writeEntries() error {
+	if len(w.entries) == 0 {
+		return nil // Nothing to write
+	}
 
 	// Process entries in blocks
 	for i := 0; i < len(w.entries); {
@@ -158,9 +293,9 @@ func (w *Writer) Flush() error {
 		currentBlockSize := 0
 		j := i
 		for ; j < len(w.entries); j++ {
-			// Estimate entry size: 4 (key len) + key + 4 (value len) + value
-			extra := 8 + len(w.entries[j].Key) + len(w.entries[j].Value)
-			if currentBlockSize+extra > blockSize && j > i {
+			// Estimate entry size: 1 (flags) + 4 (key len) + key + 4 (value len) + value + 4 (restart point)
+			extra := 13 + len(w.entries[j].Key) + len(w.entries[j].Value)
+			if currentBlockSize+extra > w.blockSize && j > i {
 				break // This entry would exceed the block size
 			}
 			currentBlockSize += extra
@@ -180,23 +315,49 @@ func (w *Writer) Flush() error {
 		}
 
 		w.blockInfos = append(w.blockInfos, blockInfo)
+		w.dataSize += blockInfo.size
 		i = j
 	}
 
+	// Update the table statistics
+	w.minKey = w.entries[0].Key
+	w.maxKey = w.entries[len(w.entries)-1].Key
+	w.entryCount = int64(len(w.entries))
+
 	// Clear the entries since they've been written
 	w.entries = w.entries[:0]
 
 	return nil
 }
 
+// dedupEntries removes all but the last of each run of sorted entries with
+// the same key, or fails with ErrDuplicateKey in strict mode
+func (w *Writer) dedupEntries() ([]Entry, error) {
+	deduped := w.entries[:0]
+	for i, entry := range w.entries {
+		if i+1 < len(w.entries) && bytes.Equal(entry.Key, w.entries[i+1].Key) {
+			if w.strictKeys {
+				return nil, fmt.Errorf("%w: %q", ErrDuplicateKey, entry.Key)
+			}
+			continue // Superseded by a later entry
+		}
+		deduped = append(deduped, entry)
+	}
+	return deduped, nil
+}
+
 // Close closes the writer and writes any remaining data
 func (w *Writer) Close() error {
 	if w.file == nil {
 		return nil // Already closed
 	}
 
-	// Flush any remaining entries
-	if err := w.Flush(); err != nil {
+	// Flush any remaining entries and write them out
+	err := w.Flush()
+	if err == nil {
+		err = w.writeEntries()
+	}
+	if err != nil {
 		if closeErr := w.file.Close(); closeErr != nil {
 			err = fmt.Errorf("failed to flush remaining data: %v; failed to close file: %w", err, closeErr)
 		}
@@ -212,11 +373,22 @@ func (w *Writer) Close() error {
 		return fmt.Errorf("failed to write index: %w", err)
 	}
 
+	// Write the stats
+	statsOffset, statsSize, err := w.writeStats()
+	if err != nil {
+		if closeErr := w.file.Close(); closeErr != nil {
+			err = fmt.Errorf("%v; failed to close file: %w", err, closeErr)
+		}
+		return fmt.Errorf("failed to write stats: %w", err)
+	}
+
 	// Write the footer
-	footer := make([]byte, 24) // index offset (8) + index size (8) + magic (8)
+	footer := make([]byte, footerSize) // index offset (8) + index size (8) + stats offset (8) + stats size (8) + magic (8)
 	binary.BigEndian.PutUint64(footer[0:8], uint64(indexOffset))
 	binary.BigEndian.PutUint64(footer[8:16], uint64(indexSize))
-	binary.BigEndian.PutUint64(footer[16:24], magicNumber) // Magic number at the end for validation
+	binary.BigEndian.PutUint64(footer[16:24], uint64(statsOffset))
+	binary.BigEndian.PutUint64(footer[24:32], uint64(statsSize))
+	binary.BigEndian.PutUint64(footer[32:40], magicNumber) // Magic number at the end for validation
 
 	if _, err := w.file.Write(footer); err != nil {
 		if closeErr := w.file.Close(); closeErr != nil {
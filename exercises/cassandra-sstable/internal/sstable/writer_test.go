@@ -2,6 +2,7 @@ package sstable
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -131,4 +132,130 @@ func TestSSTableWriter(t *testing.T) {
 		require.NoError(t, err)
 		assert.True(t, info.Size() > 0, "file should not be empty")
 	})
+
+	t.Run("configurable block size", func(t *testing.T) {
+		// numBlocks writes the same entries with the given options and
+		// returns the number of blocks in the resulting table
+		numBlocks := func(t *testing.T, name string, opts WriterOptions) int {
+			path := filepath.Join(tempDir, name)
+			writer, err := NewWriter(path, opts)
+			require.NoError(t, err)
+			for i := 0; i < 200; i++ {
+				key := []byte(fmt.Sprintf("key%03d", i))
+				require.NoError(t, writer.Add(key, bytes.Repeat([]byte("v"), 100)))
+			}
+			require.NoError(t, writer.Close())
+
+			reader, err := Open(path)
+			require.NoError(t, err)
+			defer func() {
+				assert.NoError(t, reader.Close(), "failed to close reader")
+			}()
+
+			value, err := reader.Get([]byte("key123"))
+			require.NoError(t, err)
+			assert.Len(t, value, 100)
+
+			return reader.Stats().NumBlocks
+		}
+
+		small := numBlocks(t, "test-default-blocks.sst", WriterOptions{})
+		large := numBlocks(t, "test-large-blocks.sst", WriterOptions{BlockSize: 16 * 1024})
+		assert.Greater(t, small, large, "larger blocks should produce fewer of them")
+		assert.Equal(t, 2, large)
+	})
+
+	t.Run("duplicate keys keep the last write", func(t *testing.T) {
+		path := filepath.Join(tempDir, "test-duplicates.sst")
+		writer, err := NewWriter(path)
+		require.NoError(t, err)
+
+		require.NoError(t, writer.Add([]byte("b"), []byte("first")))
+		require.NoError(t, writer.Add([]byte("a"), []byte("a-value")))
+		require.NoError(t, writer.Add([]byte("b"), []byte("second")))
+		require.NoError(t, writer.Add([]byte("c"), []byte("c-value")))
+		require.NoError(t, writer.Add([]byte("b"), []byte("third")))
+		require.NoError(t, writer.Add([]byte("c"), []byte("replaced")))
+		require.NoError(t, writer.Delete([]byte("c")))
+		require.NoError(t, writer.Close())
+
+		reader, err := Open(path)
+		require.NoError(t, err)
+		defer func() {
+			assert.NoError(t, reader.Close(), "failed to close reader")
+		}()
+
+		value, err := reader.Get([]byte("b"))
+		require.NoError(t, err)
+		assert.Equal(t, "third", string(value))
+
+		_, err = reader.Get([]byte("c"))
+		assert.ErrorIs(t, err, ErrKeyDeleted)
+
+		assert.Equal(t, int64(3), reader.Stats().EntryCount)
+	})
+
+	t.Run("strict keys reject duplicates", func(t *testing.T) {
+		path := filepath.Join(tempDir, "test-strict.sst")
+		writer, err := NewWriter(path, WriterOptions{StrictKeys: true})
+		require.NoError(t, err)
+
+		require.NoError(t, writer.Add([]byte("a"), []byte("1")))
+		require.NoError(t, writer.Add([]byte("b"), []byte("2")))
+		require.NoError(t, writer.Flush())
+
+		require.NoError(t, writer.Add([]byte("c"), []byte("3")))
+		require.NoError(t, writer.Add([]byte("c"), []byte("4")))
+		assert.ErrorIs(t, writer.Flush(), ErrDuplicateKey)
+		assert.ErrorIs(t, writer.Close(), ErrDuplicateKey)
+	})
+
+	t.Run("duplicate keys across flushes", func(t *testing.T) {
+		path := filepath.Join(tempDir, "test-flushes.sst")
+		writer, err := NewWriter(path)
+		require.NoError(t, err)
+
+		require.NoError(t, writer.Add([]byte("a"), []byte("first")))
+		require.NoError(t, writer.Add([]byte("b"), []byte("value-b")))
+		require.NoError(t, writer.Flush())
+		require.NoError(t, writer.Add([]byte("a"), []byte("second")))
+		require.NoError(t, writer.Flush())
+		require.NoError(t, writer.Close())
+
+		reader, err := Open(path)
+		require.NoError(t, err)
+		defer func() {
+			assert.NoError(t, reader.Close())
+		}()
+
+		value, err := reader.Get([]byte("a"))
+		require.NoError(t, err)
+		assert.Equal(t, "second", string(value))
+
+		var keys []string
+		it := reader.RangeScan(nil, nil)
+		for it.Next() {
+			keys = append(keys, string(it.Key()))
+		}
+		require.NoError(t, it.Error())
+		assert.Equal(t, []string{"a", "b"}, keys)
+		assert.Equal(t, int64(2), reader.Stats().EntryCount)
+	})
+
+	t.Run("strict keys reject duplicates across flushes", func(t *testing.T) {
+		path := filepath.Join(tempDir, "test-strict-flushes.sst")
+		writer, err := NewWriter(path, WriterOptions{StrictKeys: true})
+		require.NoError(t, err)
+
+		require.NoError(t, writer.Add([]byte("a"), []byte("1")))
+		require.NoError(t, writer.Flush())
+		require.NoError(t, writer.Add([]byte("a"), []byte("2")))
+		assert.ErrorIs(t, writer.Flush(), ErrDuplicateKey)
+		assert.ErrorIs(t, writer.Close(), ErrDuplicateKey)
+	})
+
+	t.Run("invalid block size", func(t *testing.T) {
+		_, err := NewWriter(filepath.Join(tempDir, "test-invalid.sst"), WriterOptions{BlockSize: -1})
+		assert.Error(t, err)
+	})
 }
@@ -1,5 +1,7 @@
 package trie
 
+import "log/slog"
+
 // Node represents a node in the trie
 type Node struct {
 	// children maps the next character to the child node
@@ -24,6 +26,22 @@ func newNode() *Node {
 // Trie represents a trie data structure
 type Trie struct {
 	root *Node
+
+	// logger traces serialization when set; nil disables tracing
+	logger *slog.Logger
+}
+
+// SetLogger sets the logger used to trace serialization at debug level.
+// A nil logger, the default, disables tracing.
+func (t *Trie) SetLogger(logger *slog.Logger) {
+	t.logger = logger
+}
+
+// debug logs a serialization trace message if a logger is set
+func (t *Trie) debug(msg string, args ...any) {
+	if t.logger != nil {
+		t.logger.Debug(msg, args...)
+	}
 }
 
 // New creates a new empty trie
@@ -42,8 +42,8 @@ func (t *Trie) serializeNode(node *Node, w io.Writer) error {
 	default:
 		nodeTypeStr = "internal"
 	}
-	fmt.Printf("Serializing %s node with value: %v, children: %d\n",
-		nodeTypeStr, string(node.value), len(node.children))
+	t.debug("serializing node",
+		"type", nodeTypeStr, "value", string(node.value), "children", len(node.children))
 
 	// First, collect all children in a consistent order
 	children := make([]rune, 0, len(node.children))
@@ -138,7 +138,7 @@ func (t *Trie) Deserialize(data []byte) error {
 	}
 
 	r := bytes.NewReader(data)
-	node, _, err := deserializeNode(r, 0)
+	node, _, err := t.deserializeNode(r, 0)
 	if err != nil {
 		return fmt.Errorf("failed to deserialize trie: %w", err)
 	}
@@ -147,8 +147,8 @@ func (t *Trie) Deserialize(data []byte) error {
 }
 
 // deserializeNode reads a node and its children from the reader
-func deserializeNode(r io.ReadSeeker, offset int64) (*Node, int64, error) {
-	fmt.Printf("Deserializing node at offset: %d\n", offset)
+func (t *Trie) deserializeNode(r io.ReadSeeker, offset int64) (*Node, int64, error) {
+	t.debug("deserializing node", "offset", offset)
 	node := newNode()
 
 	// Seek to the node's position
@@ -215,7 +215,7 @@ func deserializeNode(r io.ReadSeeker, offset int64) (*Node, int64, error) {
 		}
 
 		// The offset is relative to the start of this node
-		childNode, _, err := deserializeNode(r, offset+childOffset)
+		childNode, _, err := t.deserializeNode(r, offset+childOffset)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to deserialize child node: %w", err)
 		}
@@ -2,6 +2,8 @@ package trie
 
 import (
 	"bytes"
+	"log/slog"
+	"strings"
 	"testing"
 )
 
@@ -135,3 +137,29 @@ func TestTrie_Serialization_RoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestTrie_SetLogger(t *testing.T) {
+	trie := New()
+	trie.Insert("key", []byte("value"))
+
+	var logs bytes.Buffer
+	trie.SetLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	data, err := trie.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	if !strings.Contains(logs.String(), "serializing node") {
+		t.Errorf("expected serialization trace, got %q", logs.String())
+	}
+
+	logs.Reset()
+	newTrie := New()
+	newTrie.SetLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	if err := newTrie.Deserialize(data); err != nil {
+		t.Fatalf("Deserialize() error = %v", err)
+	}
+	if !strings.Contains(logs.String(), "deserializing node") {
+		t.Errorf("expected deserialization trace, got %q", logs.String())
+	}
+}
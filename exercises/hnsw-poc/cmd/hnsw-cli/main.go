@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"log"
 	"math/rand"
 	"time"
 
@@ -23,15 +24,22 @@ func main() {
 		for j := range v {
 			v[j] = rand.Float32()
 		}
-		h.Insert(i, v)
+		if err := h.Insert(i, v); err != nil {
+			log.Fatalf("Failed to insert vector %d: %v", i, err)
+		}
 	}
 
+	fmt.Printf("Index has %d vectors, layer sizes %v\n", h.Len(), h.LayerSizes())
+
 	query := make([]float32, *dim)
 	for i := range query {
 		query[i] = rand.Float32()
 	}
 	start := time.Now()
-	neighbors := h.Search(query, *k)
+	neighbors, err := h.Search(query, *k)
+	if err != nil {
+		log.Fatalf("Search failed: %v", err)
+	}
 	duration := time.Since(start)
 
 	fmt.Printf("Found %d nearest neighbors in %v:\n", len(neighbors), duration)
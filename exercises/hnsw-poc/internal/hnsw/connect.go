@@ -27,9 +27,6 @@ func (h *HNSW) connectNode(node *Node, neighbors []*priorityQueueItem, layer int
 	connected := make(map[int]bool)
 	connectionsMade := 0
 
-	h.nodesMutex.RLock()
-	defer h.nodesMutex.RUnlock()
-
 	// Sort neighbors by distance (ascending)
 	sort.Slice(neighbors, func(i, j int) bool {
 		return neighbors[i].distance < neighbors[j].distance
@@ -42,7 +39,7 @@ func (h *HNSW) connectNode(node *Node, neighbors []*priorityQueueItem, layer int
 			continue
 		}
 
-		neighborNode := h.nodes[neighbor.nodeID]
+		neighborNode := h.getNode(neighbor.nodeID)
 		if neighborNode == nil {
 			continue
 		}
@@ -94,7 +91,7 @@ func (h *HNSW) connectNode(node *Node, neighbors []*priorityQueueItem, layer int
 			node.OutEdges[layer] = append(node.OutEdges[layer], item.nodeID)
 			connected[item.nodeID] = true
 			connectionsMade++
-			neighborNode := h.nodes[item.nodeID]
+			neighborNode := h.getNode(item.nodeID)
 			if neighborNode != nil {
 				reverseConnected := false
 				neighborEdges := neighborNode.OutEdges[layer]
@@ -0,0 +1,112 @@
+package hnsw
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Delete removes the vector with the given ID from the index.
+// Neighbors that were linked to the deleted node are reconnected to the
+// deleted node's other neighbors so that the graph stays navigable.
+func (h *HNSW) Delete(id int) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	node := h.getNode(id)
+	if node == nil {
+		return fmt.Errorf("node %d not found", id)
+	}
+
+	h.nodesMutex.Lock()
+	delete(h.nodes, id)
+	h.nodesMutex.Unlock()
+
+	for l := 0; l <= node.Level && l < len(h.layers); l++ {
+		h.removeNodeFromLayer(node, l)
+	}
+
+	// Drop layers that no longer contain any nodes
+	for h.maxLayer >= 0 && len(h.layers[h.maxLayer].nodes) == 0 {
+		h.maxLayer--
+	}
+	h.layers = h.layers[:max(h.maxLayer+1, 1)]
+
+	if h.maxLayer < 0 {
+		h.entryPointID = -1
+		return nil
+	}
+
+	// The entry point must be on the top layer
+	if h.entryPointID == id || h.getNode(h.entryPointID).Level < h.maxLayer {
+		h.entryPointID = h.layers[h.maxLayer].nodes[0].ID
+	}
+
+	return nil
+}
+
+// removeNodeFromLayer unlinks a node from every node in a layer and repairs
+// the neighborhoods of the nodes that pointed to it
+func (h *HNSW) removeNodeFromLayer(node *Node, layer int) {
+	nodes := h.layers[layer].nodes
+	h.layers[layer].nodes = slices.DeleteFunc(nodes, func(n *Node) bool {
+		return n.ID == node.ID
+	})
+
+	// Candidates for reconnection are the deleted node's own neighbors
+	candidates := make([]*Node, 0, len(node.OutEdges[layer]))
+	for _, neighborID := range node.OutEdges[layer] {
+		if neighbor := h.getNode(neighborID); neighbor != nil {
+			candidates = append(candidates, neighbor)
+		}
+	}
+
+	for _, n := range h.layers[layer].nodes {
+		edges := n.OutEdges[layer]
+		if !slices.Contains(edges, node.ID) {
+			continue
+		}
+		n.OutEdges[layer] = slices.DeleteFunc(edges, func(neighborID int) bool {
+			return neighborID == node.ID
+		})
+		h.reconnect(n, candidates, layer)
+	}
+}
+
+// reconnect replaces the edge a node lost to a deletion with an edge to the
+// closest candidate it is not already linked to. A node left without any
+// neighbors or candidates is linked to the closest node in the layer instead.
+func (h *HNSW) reconnect(n *Node, candidates []*Node, layer int) {
+	if len(n.OutEdges[layer]) >= h.getM(layer) {
+		return
+	}
+
+	closest := h.closestUnlinked(n, candidates, layer)
+	if closest == nil && len(n.OutEdges[layer]) == 0 {
+		closest = h.closestUnlinked(n, h.layers[layer].nodes, layer)
+	}
+	if closest == nil {
+		return
+	}
+
+	n.OutEdges[layer] = append(n.OutEdges[layer], closest.ID)
+	if !slices.Contains(closest.OutEdges[layer], n.ID) {
+		closest.OutEdges[layer] = append(closest.OutEdges[layer], n.ID)
+	}
+}
+
+// closestUnlinked returns the candidate closest to n that n has no edge to
+// in the given layer, or nil if there is none
+func (h *HNSW) closestUnlinked(n *Node, candidates []*Node, layer int) *Node {
+	var closest *Node
+	var minDist float32
+	for _, c := range candidates {
+		if c.ID == n.ID || slices.Contains(n.OutEdges[layer], c.ID) {
+			continue
+		}
+		if dist := h.distanceFunc(n.Vector, c.Vector); closest == nil || dist < minDist {
+			closest = c
+			minDist = dist
+		}
+	}
+	return closest
+}
@@ -2,6 +2,33 @@ package hnsw
 
 import "math"
 
+// Metric selects one of the built-in distance functions
+type Metric int
+
+const (
+	// MetricEuclidean measures the straight-line distance between vectors
+	MetricEuclidean Metric = iota
+
+	// MetricCosine measures the angle between vectors, ignoring their
+	// magnitude. Vectors are normalized when they are inserted or searched.
+	MetricCosine
+
+	// MetricDotProduct ranks vectors by their inner product, largest first
+	MetricDotProduct
+)
+
+// distanceFunc returns the distance function for the metric
+func (m Metric) distanceFunc() func(a, b []float32) float32 {
+	switch m {
+	case MetricCosine:
+		return normalizedCosineDistance
+	case MetricDotProduct:
+		return NegativeDotProduct
+	default:
+		return euclideanDistance
+	}
+}
+
 func euclideanDistance(a, b []float32) float32 {
 	if len(a) != len(b) {
 		return float32(math.Inf(1))
@@ -14,3 +41,72 @@ func euclideanDistance(a, b []float32) float32 {
 	}
 	return float32(math.Sqrt(float64(sum)))
 }
+
+// CosineDistance returns 1 minus the cosine similarity of a and b, ranging
+// from 0 for vectors pointing the same way to 2 for opposite vectors.
+// A zero vector is at distance 1 from everything.
+func CosineDistance(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return float32(math.Inf(1))
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 1
+	}
+	return float32(1 - dot/math.Sqrt(normA*normB))
+}
+
+// NegativeDotProduct returns the negated inner product of a and b, so that
+// vectors with larger inner products are closer
+func NegativeDotProduct(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return float32(math.Inf(1))
+	}
+
+	var dot float32
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return -dot
+}
+
+// normalizedCosineDistance is CosineDistance for vectors already scaled to
+// unit length
+func normalizedCosineDistance(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return float32(math.Inf(1))
+	}
+
+	var dot float32
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return 1 - dot
+}
+
+// normalize returns a copy of v scaled to unit length. A zero vector is
+// returned unchanged.
+func normalize(v []float32) []float32 {
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+
+	out := make([]float32, len(v))
+	copy(out, v)
+	if norm == 0 {
+		return out
+	}
+
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range out {
+		out[i] *= scale
+	}
+	return out
+}
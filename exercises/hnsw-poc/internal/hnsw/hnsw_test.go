@@ -1,7 +1,15 @@
 package hnsw
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
 	"math/rand"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -42,7 +50,7 @@ func TestHNSWInsertAndSearch(t *testing.T) {
 		}
 		t.Logf("Created %d test vectors", len(vectors))
 
-		h.Insert(0, vectors[0])
+		mustInsert(t, h, 0, vectors[0])
 
 		// Verify the graph state after first insert
 		if h.entryPointID != 0 {
@@ -52,7 +60,7 @@ func TestHNSWInsertAndSearch(t *testing.T) {
 		// Insert remaining vectors
 		for i := 1; i < len(vectors); i++ {
 			t.Logf("Inserting vector %d: %v", i, vectors[i])
-			h.Insert(i, vectors[i])
+			mustInsert(t, h, i, vectors[i])
 			t.Logf("Successfully inserted vector %d", i)
 		}
 
@@ -67,7 +75,7 @@ func TestHNSWInsertAndSearch(t *testing.T) {
 			t.Logf("Layer %d: %d nodes", i, len(layer.nodes))
 		}
 
-		results := h.Search(query, 2)
+		results := mustSearch(t, h, query, 2)
 		t.Logf("Search results: %v", results)
 
 		if len(results) == 0 {
@@ -81,14 +89,14 @@ func TestHNSWInsertAndSearch(t *testing.T) {
 
 		// Test search with a different query
 		query = []float32{0.1, 0.9, 0.1, 0.1}
-		results = h.Search(query, 1)
+		results = mustSearch(t, h, query, 1)
 		if len(results) == 0 || results[0] != 1 {
 			t.Errorf("Expected most similar vector to be at index 1, got %v", results)
 		}
 
 		t.Log("Testing exact match search")
 		exactMatch := []float32{0.0, 1.0, 0.0, 0.0}
-		exactResults := h.Search(exactMatch, 1)
+		exactResults := mustSearch(t, h, exactMatch, 1)
 		t.Logf("Exact match search results: %v", exactResults)
 
 		if len(exactResults) == 0 || exactResults[0] != 1 {
@@ -112,12 +120,12 @@ func TestHNSWMultipleLayers(t *testing.T) {
 	}
 
 	for i, p := range points {
-		h.Insert(i, p)
+		mustInsert(t, h, i, p)
 	}
 
 	// Test search near the center
 	query := []float32{0.6, 0.6}
-	results := h.Search(query, 1)
+	results := mustSearch(t, h, query, 1)
 
 	if len(results) == 0 || results[0] != 4 { // Point 4 is {0.5, 0.5}
 		t.Errorf("Expected point 4 as nearest to %v, got %v", query, results)
@@ -141,8 +149,7 @@ func TestHNSWParallelInserts(t *testing.T) {
 				for j := range vector {
 					vector[j] = rand.Float32()
 				}
-				h.Insert(id, vector)
-				errs <- nil
+				errs <- h.Insert(id, vector)
 			}(i)
 		}
 
@@ -153,12 +160,12 @@ func TestHNSWParallelInserts(t *testing.T) {
 			}
 		}
 
-		query := make([]float32, 10)
+		query := make([]float32, dim)
 		for i := range query {
 			query[i] = rand.Float32()
 		}
 
-		results := h.Search(query, 5)
+		results := mustSearch(t, h, query, 5)
 		if len(results) == 0 {
 			t.Error("Search returned no results")
 		}
@@ -182,3 +189,845 @@ func TestRandomLevel(t *testing.T) {
 		t.Error("Expected multiple levels, got", len(levels))
 	}
 }
+
+func TestHNSWRangeSearch(t *testing.T) {
+	h := New(2, Config{
+		M:              4,
+		EfConstruction: 50,
+		EfSearch:       10,
+		Seed:           1,
+	})
+
+	// A 5x5 grid of points one unit apart, with ID 5*y+x
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 5; x++ {
+			mustInsert(t, h, 5*y+x, []float32{float32(x), float32(y)})
+		}
+	}
+
+	tests := []struct {
+		name   string
+		query  []float32
+		radius float32
+		want   []int
+	}{
+		{"no points", []float32{2.5, 2.5}, 0.5, nil},
+		{"exact match", []float32{2, 2}, 0, []int{12}},
+		{"plus shape", []float32{2, 2}, 1, []int{7, 11, 12, 13, 17}},
+		{"square", []float32{2, 2}, 1.5, []int{6, 7, 8, 11, 12, 13, 16, 17, 18}},
+		{"corner", []float32{0, 0}, 1, []int{0, 1, 5}},
+		{"outside the grid", []float32{-3, 2}, 3, []int{10}},
+		{"all points", []float32{2, 2}, 3, nil}, // filled in below
+	}
+	for i := 0; i < 25; i++ {
+		tests[len(tests)-1].want = append(tests[len(tests)-1].want, i)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := h.RangeSearch(tt.query, tt.radius)
+			if err != nil {
+				t.Fatalf("RangeSearch failed: %v", err)
+			}
+
+			var ids []int
+			for i, r := range results {
+				if r.Distance > tt.radius {
+					t.Errorf("Result %d at distance %v is outside radius %v", r.ID, r.Distance, tt.radius)
+				}
+				if i > 0 && r.Distance < results[i-1].Distance {
+					t.Errorf("Results not sorted by distance: %v", results)
+				}
+				ids = append(ids, r.ID)
+			}
+			slices.Sort(ids)
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("RangeSearch(%v, %v) = %v, want %v", tt.query, tt.radius, ids, tt.want)
+			}
+		})
+	}
+
+	if _, err := h.RangeSearch([]float32{1, 2, 3}, 1); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("Expected ErrDimensionMismatch, got %v", err)
+	}
+
+	empty := New(2)
+	if results, err := empty.RangeSearch([]float32{0, 0}, 10); err != nil || len(results) != 0 {
+		t.Errorf("RangeSearch on empty index = %v, %v, want no results", results, err)
+	}
+}
+
+func TestHNSWDelete(t *testing.T) {
+	const (
+		dim = 8
+		n   = 200
+		k   = 5
+	)
+	rng := rand.New(rand.NewSource(42))
+	h := New(dim, Config{
+		M:              8,
+		EfConstruction: 100,
+		EfSearch:       50,
+	})
+
+	vectors := make([][]float32, n)
+	for i := range vectors {
+		vectors[i] = make([]float32, dim)
+		for j := range vectors[i] {
+			vectors[i][j] = rng.Float32()
+		}
+		mustInsert(t, h, i, vectors[i])
+	}
+
+	// Delete every fourth vector, including the entry point
+	deleted := map[int]bool{h.entryPointID: true}
+	for i := 0; i < n; i += 4 {
+		deleted[i] = true
+	}
+	for id := range deleted {
+		if err := h.Delete(id); err != nil {
+			t.Fatalf("Delete(%d) failed: %v", id, err)
+		}
+	}
+
+	if err := h.Delete(0); err == nil {
+		t.Error("Expected error deleting an already deleted node")
+	}
+	if deleted[h.entryPointID] {
+		t.Fatalf("Entry point %d was deleted", h.entryPointID)
+	}
+
+	// Check that deleted vectors are gone and remaining ones are still found
+	var hits, total int
+	for i := 0; i < n; i++ {
+		results := mustSearch(t, h, vectors[i], k)
+		for _, id := range results {
+			if deleted[id] {
+				t.Fatalf("Search returned deleted node %d", id)
+			}
+		}
+
+		want := bruteForceNearest(vectors, deleted, vectors[i], k)
+		for _, id := range results {
+			if slices.Contains(want, id) {
+				hits++
+			}
+		}
+		total += len(want)
+	}
+
+	recall := float64(hits) / float64(total)
+	t.Logf("recall %.3f", recall)
+	if recall < 0.8 {
+		t.Errorf("Expected recall of at least 0.8 after deletes, got %.2f", recall)
+	}
+}
+
+func TestHNSWDeleteAll(t *testing.T) {
+	h := New(2, Config{M: 2})
+	points := [][]float32{{0, 0}, {1, 0}, {0, 1}, {1, 1}}
+	for i, p := range points {
+		mustInsert(t, h, i, p)
+	}
+
+	for i := range points {
+		if err := h.Delete(i); err != nil {
+			t.Fatalf("Delete(%d) failed: %v", i, err)
+		}
+	}
+
+	if h.maxLayer != -1 || h.entryPointID != -1 {
+		t.Errorf("Expected empty index, got maxLayer=%d entryPointID=%d", h.maxLayer, h.entryPointID)
+	}
+	if results := mustSearch(t, h, []float32{0, 0}, 1); len(results) != 0 {
+		t.Errorf("Expected no results from empty index, got %v", results)
+	}
+
+	// The index is usable again after being emptied
+	mustInsert(t, h, 10, []float32{0.5, 0.5})
+	if results := mustSearch(t, h, []float32{0, 0}, 1); len(results) != 1 || results[0] != 10 {
+		t.Errorf("Expected node 10 after reinserting, got %v", results)
+	}
+}
+
+func TestHNSWUpdate(t *testing.T) {
+	const (
+		dim = 8
+		n   = 200
+	)
+	rng := rand.New(rand.NewSource(7))
+	h := New(dim, Config{
+		M:              8,
+		EfConstruction: 100,
+		EfSearch:       50,
+		Seed:           7,
+	})
+
+	vectors := make([][]float32, n)
+	for i := range vectors {
+		vectors[i] = make([]float32, dim)
+		for j := range vectors[i] {
+			vectors[i][j] = rng.Float32()
+		}
+		mustInsert(t, h, i, vectors[i])
+	}
+
+	// A query right next to vector 0 finds it first
+	query := slices.Clone(vectors[0])
+	query[0] += 0.001
+	if results := mustSearch(t, h, query, 1); len(results) == 0 || results[0] != 0 {
+		t.Fatalf("Expected vector 0 to be the nearest neighbor before the update, got %v", results)
+	}
+
+	// Move vector 0 to the opposite corner of the space, and the entry
+	// point too
+	opposite := func(v []float32) []float32 {
+		o := make([]float32, len(v))
+		for j := range v {
+			o[j] = 1 - v[j]
+		}
+		return o
+	}
+	far := opposite(vectors[0])
+	if err := h.Update(0, far); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	vectors[0] = far
+
+	entryPoint := h.entryPointID
+	moved := opposite(vectors[entryPoint])
+	if err := h.Update(entryPoint, moved); err != nil {
+		t.Fatalf("Update of the entry point failed: %v", err)
+	}
+	vectors[entryPoint] = moved
+
+	results := mustSearch(t, h, query, 1)
+	want := bruteForceNearest(vectors, nil, query, 1)
+	if len(results) == 0 || results[0] == 0 {
+		t.Fatalf("Expected vector 0 to no longer be the nearest neighbor, got %v", results)
+	}
+	if results[0] != want[0] {
+		t.Errorf("Expected nearest neighbor %d after the update, got %d", want[0], results[0])
+	}
+
+	// The updated vectors are found at their new positions, and the rest of
+	// the graph is still navigable
+	for i := range vectors {
+		if results := mustSearch(t, h, vectors[i], 1); len(results) == 0 || results[0] != i {
+			t.Errorf("Search for vector %d returned %v", i, results)
+		}
+	}
+	for _, node := range h.nodes {
+		for l, edges := range node.OutEdges {
+			if slices.Contains(edges, node.ID) {
+				t.Errorf("Node %d links to itself on layer %d", node.ID, l)
+			}
+		}
+	}
+
+	if err := h.Update(n, far); err == nil {
+		t.Error("Expected error updating a missing node")
+	}
+	if err := h.Update(1, []float32{1, 2}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("Expected ErrDimensionMismatch, got %v", err)
+	}
+	if h.Len() != n {
+		t.Errorf("Expected %d vectors after updates, got %d", n, h.Len())
+	}
+}
+
+func TestHNSWSaveLoad(t *testing.T) {
+	const (
+		dim = 16
+		n   = 1000
+	)
+	rng := rand.New(rand.NewSource(7))
+	h := New(dim, Config{
+		M:              8,
+		EfConstruction: 50,
+		EfSearch:       20,
+	})
+	for i := 0; i < n; i++ {
+		vector := make([]float32, dim)
+		for j := range vector {
+			vector[j] = rng.Float32()
+		}
+		mustInsert(t, h, i, vector)
+	}
+
+	var buf bytes.Buffer
+	if err := h.Save(&buf); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	saved := buf.Bytes()
+
+	loaded, err := Load(bytes.NewReader(saved))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if loaded.M != h.M || loaded.M0 != h.M0 || loaded.efConstruction != h.efConstruction ||
+		loaded.efSearch != h.efSearch || loaded.mL != h.mL {
+		t.Errorf("Config not preserved: got M=%d M0=%d efC=%d efS=%d mL=%v",
+			loaded.M, loaded.M0, loaded.efConstruction, loaded.efSearch, loaded.mL)
+	}
+	if loaded.entryPointID != h.entryPointID || loaded.maxLayer != h.maxLayer {
+		t.Errorf("Expected entry point %d at layer %d, got %d at layer %d",
+			h.entryPointID, h.maxLayer, loaded.entryPointID, loaded.maxLayer)
+	}
+
+	for q := 0; q < 50; q++ {
+		query := make([]float32, dim)
+		for j := range query {
+			query[j] = rng.Float32()
+		}
+		want := mustSearch(t, h, query, 10)
+		got := mustSearch(t, loaded, query, 10)
+		if !slices.Equal(want, got) {
+			t.Fatalf("Query %d: expected %v after load, got %v", q, want, got)
+		}
+	}
+
+	// Saving the loaded index produces the same bytes
+	buf.Reset()
+	if err := loaded.Save(&buf); err != nil {
+		t.Fatalf("Save of loaded index failed: %v", err)
+	}
+	if !bytes.Equal(saved, buf.Bytes()) {
+		t.Error("Expected identical output when saving a loaded index")
+	}
+
+	// Corrupted and truncated input is rejected
+	if _, err := Load(bytes.NewReader([]byte("not an index"))); err == nil {
+		t.Error("Expected error loading invalid data")
+	}
+	if _, err := Load(bytes.NewReader(saved[:len(saved)/2])); err == nil {
+		t.Error("Expected error loading truncated data")
+	}
+}
+
+func TestHNSWSaveLoadEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New(4).Save(&buf); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if results := mustSearch(t, loaded, []float32{0, 0, 0, 0}, 1); len(results) != 0 {
+		t.Errorf("Expected no results from empty index, got %v", results)
+	}
+
+	mustInsert(t, loaded, 1, []float32{1, 0, 0, 0})
+	if results := mustSearch(t, loaded, []float32{1, 0, 0, 0}, 1); len(results) != 1 || results[0] != 1 {
+		t.Errorf("Expected node 1 after insert, got %v", results)
+	}
+}
+
+func TestHNSWMetrics(t *testing.T) {
+	// Each metric ranks a different point as nearest to the query: b by
+	// Euclidean distance, c by angle and d by inner product
+	points := [][]float32{
+		{1, 0},   // a
+		{10, 1},  // b
+		{1, 1},   // c
+		{100, 0}, // d
+	}
+	query := []float32{10, 10}
+
+	tests := []struct {
+		name   string
+		metric Metric
+		want   int
+	}{
+		{"euclidean", MetricEuclidean, 1},
+		{"cosine", MetricCosine, 2},
+		{"dot product", MetricDotProduct, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(2, Config{M: 2, Metric: tt.metric})
+			for i, p := range points {
+				mustInsert(t, h, i, p)
+			}
+
+			results := mustSearch(t, h, query, 1)
+			if len(results) != 1 || results[0] != tt.want {
+				t.Errorf("Expected nearest neighbor %d, got %v", tt.want, results)
+			}
+		})
+	}
+
+	t.Run("cosine survives save and load", func(t *testing.T) {
+		h := New(2, Config{M: 2, Metric: MetricCosine})
+		for i, p := range points {
+			mustInsert(t, h, i, p)
+		}
+
+		var buf bytes.Buffer
+		if err := h.Save(&buf); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		loaded, err := Load(&buf)
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if results := mustSearch(t, loaded, query, 1); len(results) != 1 || results[0] != 2 {
+			t.Errorf("Expected node 2 after reload, got %v", results)
+		}
+	})
+}
+
+func TestDistanceFunctions(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(a, b []float32) float32
+		a, b []float32
+		want float32
+	}{
+		{"cosine same direction", CosineDistance, []float32{1, 1}, []float32{3, 3}, 0},
+		{"cosine orthogonal", CosineDistance, []float32{1, 0}, []float32{0, 2}, 1},
+		{"cosine opposite", CosineDistance, []float32{1, 0}, []float32{-1, 0}, 2},
+		{"cosine zero vector", CosineDistance, []float32{0, 0}, []float32{1, 0}, 1},
+		{"negative dot product", NegativeDotProduct, []float32{1, 2}, []float32{3, 4}, -11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(tt.a, tt.b); math.Abs(float64(got-tt.want)) > 1e-6 {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHNSWCustomDistanceFunction(t *testing.T) {
+	var calls atomic.Int64
+	h := New(2, Config{
+		M:      2,
+		Metric: MetricCosine, // Ignored in favor of the custom function
+		DistanceFunction: func(a, b []float32) float32 {
+			calls.Add(1)
+			return euclideanDistance(a, b)
+		},
+	})
+
+	points := [][]float32{{0, 0}, {1, 0}, {5, 5}}
+	for i, p := range points {
+		mustInsert(t, h, i, p)
+	}
+
+	results := mustSearch(t, h, []float32{4, 4}, 1)
+	if calls.Load() == 0 {
+		t.Fatal("Expected the custom distance function to be called")
+	}
+	if len(results) != 1 || results[0] != 2 {
+		t.Errorf("Expected node 2, got %v", results)
+	}
+
+	// Vectors are stored as given rather than normalized for cosine
+	if v := h.getNode(2).Vector; !slices.Equal(v, points[2]) {
+		t.Errorf("Expected stored vector %v, got %v", points[2], v)
+	}
+
+	// The custom function has to be supplied again on load
+	var buf bytes.Buffer
+	if err := h.Save(&buf); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	saved := buf.Bytes()
+	if _, err := Load(bytes.NewReader(saved)); err == nil {
+		t.Error("Expected error loading without the custom distance function")
+	}
+	if _, err := Load(bytes.NewReader(saved), LoadOptions{DistanceFunction: euclideanDistance}); err != nil {
+		t.Errorf("Load with distance function failed: %v", err)
+	}
+}
+
+func TestHNSWDimensionMismatch(t *testing.T) {
+	h := New(4)
+
+	tests := []struct {
+		name   string
+		vector []float32
+	}{
+		{"too short", []float32{1, 2, 3}},
+		{"too long", make([]float32, 10)},
+		{"empty", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := h.Insert(1, tt.vector); !errors.Is(err, ErrDimensionMismatch) {
+				t.Errorf("Expected ErrDimensionMismatch from Insert, got %v", err)
+			}
+			if _, err := h.Search(tt.vector, 1); !errors.Is(err, ErrDimensionMismatch) {
+				t.Errorf("Expected ErrDimensionMismatch from Search, got %v", err)
+			}
+		})
+	}
+
+	if len(h.nodes) != 0 {
+		t.Errorf("Expected no nodes after failed inserts, got %d", len(h.nodes))
+	}
+
+	mustInsert(t, h, 1, []float32{1, 2, 3, 4})
+	if err := h.Insert(1, []float32{4, 3, 2, 1}); err == nil {
+		t.Error("Expected error inserting a duplicate ID")
+	}
+}
+
+// mustInsert inserts a vector, failing the test on error
+func mustInsert(t *testing.T, h *HNSW, id int, vector []float32) {
+	t.Helper()
+	if err := h.Insert(id, vector); err != nil {
+		t.Fatalf("Insert(%d) failed: %v", id, err)
+	}
+}
+
+// mustSearch searches the index, failing the test on error
+func mustSearch(t *testing.T, h *HNSW, query []float32, k int) []int {
+	t.Helper()
+	results, err := h.Search(query, k)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	return results
+}
+
+// bruteForceNearest returns the IDs of the k vectors closest to query,
+// excluding skipped IDs
+func bruteForceNearest(vectors [][]float32, skip map[int]bool, query []float32, k int) []int {
+	ids := make([]int, 0, len(vectors))
+	for id := range vectors {
+		if !skip[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return euclideanDistance(query, vectors[ids[i]]) < euclideanDistance(query, vectors[ids[j]])
+	})
+	return ids[:min(k, len(ids))]
+}
+
+func TestHNSWConcurrentInsertAndSearch(t *testing.T) {
+	runTestWithTimeout(t, 60*time.Second, func(t *testing.T) {
+		const (
+			dim      = 8
+			writers  = 4
+			perWrite = 50
+			readers  = 4
+		)
+		h := New(dim, Config{
+			M:              8,
+			EfConstruction: 50,
+			EfSearch:       20,
+		})
+		// Seed the index so that searches always have an entry point
+		mustInsert(t, h, writers*perWrite, make([]float32, dim))
+
+		var wg sync.WaitGroup
+		errs := make(chan error, writers*perWrite+readers)
+		done := make(chan struct{})
+
+		for w := 0; w < writers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				rng := rand.New(rand.NewSource(int64(w)))
+				for i := 0; i < perWrite; i++ {
+					vector := make([]float32, dim)
+					for j := range vector {
+						vector[j] = rng.Float32()
+					}
+					if err := h.Insert(w*perWrite+i, vector); err != nil {
+						errs <- err
+					}
+				}
+			}(w)
+		}
+
+		var readWg sync.WaitGroup
+		for r := 0; r < readers; r++ {
+			readWg.Add(1)
+			go func(r int) {
+				defer readWg.Done()
+				rng := rand.New(rand.NewSource(int64(100 + r)))
+				query := make([]float32, dim)
+				for {
+					select {
+					case <-done:
+						return
+					default:
+					}
+					for j := range query {
+						query[j] = rng.Float32()
+					}
+					results, err := h.Search(query, 5)
+					if err != nil {
+						errs <- err
+						return
+					}
+					if len(results) == 0 {
+						errs <- errors.New("search returned no results")
+						return
+					}
+				}
+			}(r)
+		}
+
+		wg.Wait()
+		close(done)
+		readWg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Error(err)
+		}
+
+		results := mustSearch(t, h, make([]float32, dim), 1)
+		if len(results) == 0 {
+			t.Error("Search returned no results after concurrent inserts")
+		}
+	})
+}
+
+func TestHNSWInsertBatch(t *testing.T) {
+	const (
+		dim = 4
+		n   = 100
+	)
+	ids, vectors := randomBatch(rand.New(rand.NewSource(42)), n, dim)
+
+	h := New(dim, Config{M: 8, EfConstruction: 50, EfSearch: 50})
+	if err := h.InsertBatch(ids, vectors); err != nil {
+		t.Fatalf("InsertBatch failed: %v", err)
+	}
+
+	for i, v := range vectors {
+		results := mustSearch(t, h, v, 1)
+		if len(results) == 0 || results[0] != ids[i] {
+			t.Errorf("Expected %d as nearest to its own vector, got %v", ids[i], results)
+		}
+	}
+
+	t.Run("empty batch", func(t *testing.T) {
+		if err := h.InsertBatch(nil, nil); err != nil {
+			t.Errorf("InsertBatch with no vectors failed: %v", err)
+		}
+	})
+
+	invalid := []struct {
+		name    string
+		ids     []int
+		vectors [][]float32
+	}{
+		{"length mismatch", []int{n, n + 1}, [][]float32{make([]float32, dim)}},
+		{"dimension mismatch", []int{n, n + 1}, [][]float32{make([]float32, dim), make([]float32, dim+1)}},
+		{"existing id", []int{n, 0}, [][]float32{make([]float32, dim), make([]float32, dim)}},
+		{"duplicate id", []int{n, n}, [][]float32{make([]float32, dim), make([]float32, dim)}},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := h.InsertBatch(tc.ids, tc.vectors); err == nil {
+				t.Fatal("Expected an error")
+			}
+			if h.getNode(n) != nil {
+				t.Error("Failed batch should not insert any vectors")
+			}
+		})
+	}
+}
+
+// randomBatch returns n sequential IDs and random vectors of the given dimension
+func randomBatch(rng *rand.Rand, n, dim int) ([]int, [][]float32) {
+	ids := make([]int, n)
+	vectors := make([][]float32, n)
+	for i := range vectors {
+		ids[i] = i
+		vectors[i] = make([]float32, dim)
+		for j := range vectors[i] {
+			vectors[i][j] = rng.Float32()
+		}
+	}
+	return ids, vectors
+}
+
+var benchConfig = Config{M: 8, EfConstruction: 50, EfSearch: 50}
+
+func BenchmarkHNSWInsert(b *testing.B) {
+	const dim = 16
+	ids, vectors := randomBatch(rand.New(rand.NewSource(42)), 10000, dim)
+
+	for b.Loop() {
+		h := New(dim, benchConfig)
+		for i, v := range vectors {
+			if err := h.Insert(ids[i], v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkHNSWInsertBatch(b *testing.B) {
+	const dim = 16
+	ids, vectors := randomBatch(rand.New(rand.NewSource(42)), 10000, dim)
+
+	for b.Loop() {
+		h := New(dim, benchConfig)
+		if err := h.InsertBatch(ids, vectors); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestHNSWSeed(t *testing.T) {
+	const (
+		dim = 8
+		n   = 300
+	)
+	ids, vectors := randomBatch(rand.New(rand.NewSource(1)), n, dim)
+	cfg := Config{M: 4, EfConstruction: 50, EfSearch: 50, Seed: 7}
+
+	build := func() *HNSW {
+		h := New(dim, cfg)
+		for i, v := range vectors {
+			mustInsert(t, h, ids[i], v)
+		}
+		return h
+	}
+	a, b := build(), build()
+
+	if a.entryPointID != b.entryPointID || a.maxLayer != b.maxLayer {
+		t.Fatalf("Entry points differ: %d at layer %d vs %d at layer %d",
+			a.entryPointID, a.maxLayer, b.entryPointID, b.maxLayer)
+	}
+	for _, id := range ids {
+		na, nb := a.getNode(id), b.getNode(id)
+		if na.Level != nb.Level {
+			t.Fatalf("Node %d has level %d and %d", id, na.Level, nb.Level)
+		}
+		for l := range na.OutEdges {
+			if !slices.Equal(na.OutEdges[l], nb.OutEdges[l]) {
+				t.Fatalf("Node %d has different edges at layer %d: %v vs %v",
+					id, l, na.OutEdges[l], nb.OutEdges[l])
+			}
+		}
+	}
+
+	rng := rand.New(rand.NewSource(2))
+	query := make([]float32, dim)
+	for i := 0; i < 20; i++ {
+		for j := range query {
+			query[j] = rng.Float32()
+		}
+		ra, rb := mustSearch(t, a, query, 10), mustSearch(t, b, query, 10)
+		if !slices.Equal(ra, rb) {
+			t.Errorf("Search results differ for query %d: %v vs %v", i, ra, rb)
+		}
+	}
+}
+
+func TestHNSWStats(t *testing.T) {
+	const (
+		dim = 8
+		n   = 1000
+		M   = 4
+	)
+	h := New(dim, Config{M: M, EfConstruction: 50, EfSearch: 50, Seed: 42})
+	if h.Len() != 0 || h.MaxLayer() != -1 || len(h.LayerSizes()) != 0 {
+		t.Fatalf("Empty index has Len %d, MaxLayer %d, LayerSizes %v", h.Len(), h.MaxLayer(), h.LayerSizes())
+	}
+
+	ids, vectors := randomBatch(rand.New(rand.NewSource(42)), n, dim)
+	if err := h.InsertBatch(ids, vectors); err != nil {
+		t.Fatal(err)
+	}
+
+	sizes := h.LayerSizes()
+	t.Logf("Layer sizes: %v", sizes)
+	if h.Len() != n {
+		t.Errorf("Expected Len %d, got %d", n, h.Len())
+	}
+	if h.MaxLayer() != len(sizes)-1 {
+		t.Errorf("MaxLayer %d does not match %d layers", h.MaxLayer(), len(sizes))
+	}
+	if len(sizes) == 0 {
+		t.Fatal("Expected at least one layer")
+	}
+	if sizes[0] != h.Len() {
+		t.Errorf("Bottom layer has %d nodes, expected all %d", sizes[0], h.Len())
+	}
+
+	// Every node appears in layers 0 through its level
+	total, wantTotal := 0, 0
+	for _, size := range sizes {
+		total += size
+	}
+	for _, id := range ids {
+		wantTotal += h.getNode(id).Level + 1
+	}
+	if total != wantTotal {
+		t.Errorf("Layer sizes sum to %d, expected %d", total, wantTotal)
+	}
+
+	// Upper layers hold a subset of the nodes below them
+	for l := 1; l < len(sizes); l++ {
+		if sizes[l] > sizes[l-1] {
+			t.Errorf("Layer %d has more nodes than layer %d: %v", l, l-1, sizes)
+		}
+	}
+}
+
+func TestHNSWLoadRejectsHostileSizes(t *testing.T) {
+	const dim = 4
+	h := New(dim, Config{M: 4, Seed: 1})
+	for i := 0; i < 10; i++ {
+		mustInsert(t, h, i, []float32{float32(i), 0, 0, 0})
+	}
+	var buf bytes.Buffer
+	if err := h.Save(&buf); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	saved := buf.Bytes()
+
+	// Offsets of fields in the saved index: the header follows the magic and
+	// version, and the first node follows the header
+	const (
+		headerDim     = 8
+		nodeStart     = 64
+		nodeLevel     = nodeStart + 8
+		nodeDim       = nodeStart + 12
+		nodeEdgeCount = nodeStart + 16 + dim*4
+	)
+
+	tests := []struct {
+		name  string
+		patch func(data []byte)
+	}{
+		{"node dimension differs from header", func(data []byte) {
+			binary.BigEndian.PutUint32(data[nodeDim:], 0xFFFFFFFF)
+		}},
+		{"huge dimension in header and node", func(data []byte) {
+			binary.BigEndian.PutUint32(data[headerDim:], 0xFFFFFFFF)
+			binary.BigEndian.PutUint32(data[nodeDim:], 0xFFFFFFFF)
+		}},
+		{"level above max layer", func(data []byte) {
+			binary.BigEndian.PutUint32(data[nodeLevel:], 0xFFFFFFFF)
+		}},
+		{"more edges than nodes", func(data []byte) {
+			binary.BigEndian.PutUint32(data[nodeEdgeCount:], 0xFFFFFFFF)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := bytes.Clone(saved)
+			tt.patch(data)
+			if _, err := Load(bytes.NewReader(data)); err == nil {
+				t.Error("Expected error loading index with hostile sizes")
+			}
+		})
+	}
+}
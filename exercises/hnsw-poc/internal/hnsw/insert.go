@@ -1,26 +1,72 @@
 package hnsw
 
 import (
+	"fmt"
 	"log"
 	"time"
 )
 
-// Insert adds a new vector to the HNSW index
-func (h *HNSW) Insert(id int, vector []float32) {
+// Insert adds a new vector to the HNSW index. It returns an error if the
+// vector's length does not match the index's dimension or the ID is in use.
+// The write lock is held for the whole insertion, so concurrent searches
+// never observe a partially linked node.
+func (h *HNSW) Insert(id int, vector []float32) error {
+	if err := h.checkDimension(vector); err != nil {
+		return err
+	}
+
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
 	_ = time.Now() // Keep for potential future metrics
 
 	// Check if node already exists
-	h.nodesMutex.RLock()
-	if existingNode, exists := h.nodes[id]; exists {
-		h.nodesMutex.RUnlock()
-		log.Printf("Node %d already exists at level %d", id, existingNode.Level)
-		return
+	if existingNode := h.getNode(id); existingNode != nil {
+		return fmt.Errorf("node %d already exists at level %d", id, existingNode.Level)
+	}
+
+	h.insert(id, vector)
+	return nil
+}
+
+// InsertBatch adds multiple vectors to the index, acquiring the write lock
+// once for the whole batch. ids[i] is the ID of vectors[i], and vectors are
+// inserted in order. The batch is validated before anything is inserted, so
+// on error the index is left unchanged.
+func (h *HNSW) InsertBatch(ids []int, vectors [][]float32) error {
+	if len(ids) != len(vectors) {
+		return fmt.Errorf("got %d ids for %d vectors", len(ids), len(vectors))
+	}
+	for i, vector := range vectors {
+		if err := h.checkDimension(vector); err != nil {
+			return fmt.Errorf("vector %d: %w", i, err)
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if existingNode := h.getNode(id); existingNode != nil {
+			return fmt.Errorf("node %d already exists at level %d", id, existingNode.Level)
+		}
+		if seen[id] {
+			return fmt.Errorf("duplicate id %d in batch", id)
+		}
+		seen[id] = true
 	}
-	h.nodesMutex.RUnlock()
 
+	for i, id := range ids {
+		h.insert(id, vectors[i])
+	}
+	return nil
+}
+
+// insert adds a new node to the graph. The caller must hold the write lock
+// and have validated the ID and vector.
+func (h *HNSW) insert(id int, vector []float32) {
+	vector = h.prepareVector(vector)
 	level := h.randomLevel()
 	node := NewNode(id, vector, level)
 
@@ -43,37 +89,17 @@ func (h *HNSW) Insert(id int, vector []float32) {
 	for l := min(level, h.maxLayer); l >= 0; l-- {
 		// Find nearest neighbors in this layer
 		efConstruction := max(h.efConstruction, 1)
+		entryPoint := h.getNode(h.entryPointID)
 		neighbors := h.searchLayer(vector, []*priorityQueueItem{{
-			nodeID:   h.entryPointID,
-			distance: h.distanceFunc(vector, h.nodes[h.entryPointID].Vector),
-			node:     h.nodes[h.entryPointID],
+			nodeID:   entryPoint.ID,
+			distance: h.distanceFunc(vector, entryPoint.Vector),
+			node:     entryPoint,
 		}}, efConstruction, l)
 
-		// Connect the node to its nearest neighbors
+		// Connect the node to its nearest neighbors. connectNode also adds
+		// the reverse edges, so existing nodes need no further updates.
 		if len(neighbors) > 0 {
 			h.connectNode(node, neighbors, l)
-
-			// Update connections for existing nodes
-			h.nodesMutex.RLock()
-			for _, neighbor := range neighbors {
-				if neighbor == nil || neighbor.nodeID == id {
-					continue
-				}
-
-				neighborNode := h.nodes[neighbor.nodeID]
-				if neighborNode == nil {
-					continue
-				}
-
-				// Create a priority queue item for the reverse connection
-				reverseItem := &priorityQueueItem{
-					nodeID:   id,
-					distance: neighbor.distance,
-					node:     node,
-				}
-				h.connectNode(neighborNode, []*priorityQueueItem{reverseItem}, l)
-			}
-			h.nodesMutex.RUnlock()
 		} else {
 			log.Printf("No neighbors found to connect at layer %d for node %d", l, id)
 		}
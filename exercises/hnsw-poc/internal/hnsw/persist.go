@@ -0,0 +1,322 @@
+package hnsw
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Serialization format (all integers big-endian):
+// [magic: 4 bytes "HNSW"][version: 4 bytes]
+// [dim: 4][M: 4][M0: 4][efConstruction: 4][efSearch: 4][mL: 8][metric: 4][entryPointID: 8][maxLayer: 8][node count: 8]
+// followed by each node in ascending ID order:
+// [id: 8][level: 4][dim: 4][vector: dim * 4][for each layer 0..level: edge count: 4, edges: count * 8]
+
+const (
+	persistMagic   = "HNSW"
+	persistVersion = 3
+
+	// persistCustomMetric is the metric saved for indexes built with a custom
+	// distance function
+	persistCustomMetric = -1
+)
+
+// persistHeader is the fixed-size index header that follows the magic and version
+type persistHeader struct {
+	Dim            uint32
+	M              uint32
+	M0             uint32
+	EfConstruction uint32
+	EfSearch       uint32
+	ML             float64
+	Metric         int32
+	EntryPointID   int64
+	MaxLayer       int64
+	NodeCount      uint64
+}
+
+// LoadOptions configures how a saved index is loaded
+type LoadOptions struct {
+	// DistanceFunction calculates the distance between two vectors.
+	// Functions cannot be saved, so this is required for indexes built with
+	// a custom distance function and must match it. If nil, the index's
+	// saved Metric is used.
+	DistanceFunction func(a, b []float32) float32
+}
+
+// Save writes the index, including its graph structure and configuration,
+// to w. The metric is saved, but a custom distance function is not and must
+// be passed to Load again.
+func (h *HNSW) Save(w io.Writer) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	h.nodesMutex.RLock()
+	defer h.nodesMutex.RUnlock()
+
+	bw := bufio.NewWriter(w)
+
+	if _, err := bw.WriteString(persistMagic); err != nil {
+		return fmt.Errorf("failed to write magic: %w", err)
+	}
+
+	metric := int32(h.metric)
+	if h.customDistance {
+		metric = persistCustomMetric
+	}
+
+	header := persistHeader{
+		Dim:            uint32(h.dim),
+		M:              uint32(h.M),
+		M0:             uint32(h.M0),
+		EfConstruction: uint32(h.efConstruction),
+		EfSearch:       uint32(h.efSearch),
+		ML:             h.mL,
+		Metric:         metric,
+		EntryPointID:   int64(h.entryPointID),
+		MaxLayer:       int64(h.maxLayer),
+		NodeCount:      uint64(len(h.nodes)),
+	}
+	for _, v := range []any{uint32(persistVersion), header} {
+		if err := binary.Write(bw, binary.BigEndian, v); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+	}
+
+	// Write nodes in ID order so the output is deterministic
+	ids := make([]int, 0, len(h.nodes))
+	for id := range h.nodes {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		if err := writeNode(bw, h.nodes[id]); err != nil {
+			return fmt.Errorf("failed to write node %d: %w", id, err)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// writeNode writes a single node and its edges
+func writeNode(w io.Writer, node *Node) error {
+	fields := []any{
+		int64(node.ID),
+		uint32(node.Level),
+		uint32(len(node.Vector)),
+		node.Vector,
+	}
+	for _, edges := range node.OutEdges {
+		ids := make([]int64, len(edges))
+		for i, id := range edges {
+			ids[i] = int64(id)
+		}
+		fields = append(fields, uint32(len(ids)), ids)
+	}
+
+	for _, field := range fields {
+		if err := binary.Write(w, binary.BigEndian, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load reads an index previously written by Save
+func Load(r io.Reader, opts ...LoadOptions) (*HNSW, error) {
+	opt := LoadOptions{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(persistMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, fmt.Errorf("failed to read magic: %w", err)
+	}
+	if string(magic) != persistMagic {
+		return nil, fmt.Errorf("invalid magic %q", magic)
+	}
+
+	var version uint32
+	if err := binary.Read(br, binary.BigEndian, &version); err != nil {
+		return nil, fmt.Errorf("failed to read version: %w", err)
+	}
+	if version != persistVersion {
+		return nil, fmt.Errorf("unsupported version %d", version)
+	}
+
+	var header persistHeader
+	if err := binary.Read(br, binary.BigEndian, &header); err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	metric := Metric(header.Metric)
+	if header.Metric != persistCustomMetric && (metric < MetricEuclidean || metric > MetricDotProduct) {
+		return nil, fmt.Errorf("unknown metric %d", header.Metric)
+	}
+	distanceFunc := opt.DistanceFunction
+	if distanceFunc == nil {
+		if header.Metric == persistCustomMetric {
+			return nil, fmt.Errorf("index was built with a custom distance function, which must be provided")
+		}
+		distanceFunc = metric.distanceFunc()
+	}
+
+	h := &HNSW{
+		dim:            int(header.Dim),
+		layers:         []*Layer{{nodes: make([]*Node, 0)}},
+		nodes:          make(map[int]*Node, preallocated(header.NodeCount)),
+		M:              int(header.M),
+		M0:             int(header.M0),
+		efConstruction: int(header.EfConstruction),
+		efSearch:       int(header.EfSearch),
+		mL:             header.ML,
+		distanceFunc:   distanceFunc,
+		metric:         metric,
+		customDistance: opt.DistanceFunction != nil,
+		entryPointID:   int(header.EntryPointID),
+		maxLayer:       int(header.MaxLayer),
+		rand:           rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	for i := uint64(0); i < header.NodeCount; i++ {
+		node, err := readNode(br, &header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read node %d of %d: %w", i, header.NodeCount, err)
+		}
+		if _, exists := h.nodes[node.ID]; exists {
+			return nil, fmt.Errorf("duplicate node %d", node.ID)
+		}
+		h.nodes[node.ID] = node
+		for l := 0; l <= node.Level; l++ {
+			h.addNodeToLayer(node, l)
+		}
+	}
+
+	if err := h.validate(); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// readNode reads a single node and its edges. Sizes read from the input are
+// checked against the header before anything is allocated, and slices grow
+// as their data is read, so a corrupt or hostile file fails with an error
+// rather than exhausting memory.
+func readNode(r io.Reader, header *persistHeader) (*Node, error) {
+	var fixed struct {
+		ID    int64
+		Level uint32
+		Dim   uint32
+	}
+	if err := binary.Read(r, binary.BigEndian, &fixed); err != nil {
+		return nil, err
+	}
+	if fixed.Dim != header.Dim {
+		return nil, fmt.Errorf("node %d has dimension %d, expected %d", fixed.ID, fixed.Dim, header.Dim)
+	}
+	if int64(fixed.Level) > header.MaxLayer {
+		return nil, fmt.Errorf("node %d has level %d above the max layer %d", fixed.ID, fixed.Level, header.MaxLayer)
+	}
+
+	vector, err := readValues[float32](r, uint64(fixed.Dim))
+	if err != nil {
+		return nil, err
+	}
+	node := &Node{
+		ID:       int(fixed.ID),
+		Vector:   vector,
+		Level:    int(fixed.Level),
+		OutEdges: make([][]int, 0, preallocated(uint64(fixed.Level)+1)),
+	}
+
+	for l := 0; l <= node.Level; l++ {
+		var count uint32
+		if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+			return nil, err
+		}
+		if uint64(count) > header.NodeCount {
+			return nil, fmt.Errorf("node %d has %d edges at layer %d but the index has %d nodes", fixed.ID, count, l, header.NodeCount)
+		}
+		ids, err := readValues[int64](r, uint64(count))
+		if err != nil {
+			return nil, err
+		}
+		edges := make([]int, count)
+		for i, id := range ids {
+			edges[i] = int(id)
+		}
+		node.OutEdges = append(node.OutEdges, edges)
+	}
+
+	return node, nil
+}
+
+// maxPreallocated caps the number of elements allocated ahead of reading
+// them, since the sizes in a file can't be trusted
+const maxPreallocated = 4096
+
+// preallocated returns the capacity to allocate for n elements read from a
+// file
+func preallocated(n uint64) int {
+	if n > maxPreallocated {
+		return maxPreallocated
+	}
+	return int(n)
+}
+
+// readValues reads n big-endian values of type T, in chunks of at most
+// maxPreallocated so that memory is only allocated for data actually read
+func readValues[T float32 | int64](r io.Reader, n uint64) ([]T, error) {
+	values := make([]T, 0, preallocated(n))
+	for remaining := n; remaining > 0; {
+		chunk := make([]T, preallocated(remaining))
+		if err := binary.Read(r, binary.BigEndian, chunk); err != nil {
+			return nil, err
+		}
+		values = append(values, chunk...)
+		remaining -= uint64(len(chunk))
+	}
+	return values, nil
+}
+
+// validate checks that a loaded index is internally consistent
+func (h *HNSW) validate() error {
+	if len(h.nodes) == 0 {
+		if h.entryPointID != -1 || h.maxLayer != -1 {
+			return fmt.Errorf("empty index has entry point %d at layer %d", h.entryPointID, h.maxLayer)
+		}
+		return nil
+	}
+
+	entryPoint := h.nodes[h.entryPointID]
+	if entryPoint == nil {
+		return fmt.Errorf("entry point %d not found", h.entryPointID)
+	}
+	if h.maxLayer != len(h.layers)-1 || entryPoint.Level != h.maxLayer {
+		return fmt.Errorf("inconsistent max layer %d", h.maxLayer)
+	}
+
+	for _, node := range h.nodes {
+		if err := h.checkDimension(node.Vector); err != nil {
+			return fmt.Errorf("node %d: %w", node.ID, err)
+		}
+		for l, edges := range node.OutEdges {
+			for _, id := range edges {
+				neighbor := h.nodes[id]
+				if neighbor == nil || neighbor.Level < l {
+					return fmt.Errorf("node %d has an invalid edge to %d at layer %d", node.ID, id, l)
+				}
+			}
+		}
+	}
+
+	return nil
+}
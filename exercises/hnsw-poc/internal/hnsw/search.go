@@ -5,26 +5,131 @@ import (
 	"sort"
 )
 
-// Search finds the k nearest neighbors to the query vector
-func (h *HNSW) Search(query []float32, k int) []int {
+// Search finds the k nearest neighbors to the query vector. It returns an
+// error if the query's length does not match the index's dimension.
+func (h *HNSW) Search(query []float32, k int) ([]int, error) {
+	if err := h.checkDimension(query); err != nil {
+		return nil, err
+	}
+
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
 	if len(h.layers) == 0 || h.entryPointID == -1 {
-		return nil
+		return nil, nil
 	}
+	query = h.prepareVector(query)
 
 	// Ensure we have enough exploration factor
 	ef := max(h.efSearch, k*4) // Explore at least 4x the requested k
 	ef = max(ef, 20)           // But at least 20
 
 	// Start from the top layer
+	currentNode := h.descend(query)
+	if currentNode == nil {
+		return nil, nil
+	}
+
+	// Search in bottom layer with full ef
+	candidates := h.searchLayer(query, []*priorityQueueItem{{
+		nodeID:   currentNode.ID,
+		distance: h.distanceFunc(query, currentNode.Vector),
+		node:     currentNode,
+	}}, ef, 0)
+
+	// Collect results
+	results := h.selectNeighborsSimple(candidates, k, 0)
+	neighbors := make([]int, 0, len(results))
+	for _, item := range results {
+		neighbors = append(neighbors, item.nodeID)
+	}
+
+	return neighbors, nil
+}
+
+// RangeSearch finds the vectors within radius of the query vector, in order
+// of increasing distance. Distances are measured with the index's metric.
+// Like Search it is approximate: it expands outwards from the nodes closest
+// to the query through the bottom layer, following edges while the closest
+// unvisited candidate is within the radius. It returns an error if the
+// query's length does not match the index's dimension.
+func (h *HNSW) RangeSearch(query []float32, radius float32) ([]Neighbor, error) {
+	if err := h.checkDimension(query); err != nil {
+		return nil, err
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.layers) == 0 || h.entryPointID == -1 {
+		return nil, nil
+	}
+	query = h.prepareVector(query)
+
+	entry := h.descend(query)
+	if entry == nil {
+		return nil, nil
+	}
+
+	// Seed the expansion with the closest nodes, so that it starts inside
+	// the radius even if the descent stopped short of it
+	seeds := h.searchLayer(query, []*priorityQueueItem{{
+		nodeID:   entry.ID,
+		distance: h.distanceFunc(query, entry.Vector),
+		node:     entry,
+	}}, h.efSearch, 0)
+
+	candidates := &priorityQueue{}
+	visited := make(map[int]bool)
+	for _, seed := range seeds {
+		if !visited[seed.nodeID] {
+			visited[seed.nodeID] = true
+			heap.Push(candidates, seed)
+		}
+	}
+
+	var results []Neighbor
+	for candidates.Len() > 0 {
+		candidate := heap.Pop(candidates).(*priorityQueueItem)
+		if candidate.distance > radius {
+			break // Every remaining candidate is further away
+		}
+		results = append(results, Neighbor{ID: candidate.nodeID, Distance: candidate.distance})
+
+		for _, neighborID := range candidate.node.OutEdges[0] {
+			if visited[neighborID] {
+				continue
+			}
+			visited[neighborID] = true
+
+			neighbor := h.getNode(neighborID)
+			if neighbor == nil {
+				continue
+			}
+			heap.Push(candidates, &priorityQueueItem{
+				nodeID:   neighborID,
+				distance: h.distanceFunc(query, neighbor.Vector),
+				node:     neighbor,
+			})
+		}
+	}
+
+	// Neighbors found later can be closer than nodes already collected
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Distance < results[j].Distance
+	})
+	return results, nil
+}
+
+// descend greedily walks from the entry point down to layer 1, moving to
+// the neighbor closest to the query on each layer, and returns the node to
+// start the bottom layer search from. The caller must hold the read lock.
+func (h *HNSW) descend(query []float32) *Node {
 	currentNode := h.getNode(h.entryPointID)
 	if currentNode == nil {
 		return nil
 	}
 
-	// Find the entry point in the top layer
 	for l := h.maxLayer; l >= 1; l-- {
 		changed := true
 		for changed {
@@ -48,21 +153,7 @@ func (h *HNSW) Search(query []float32, k int) []int {
 		}
 	}
 
-	// Search in bottom layer with full ef
-	candidates := h.searchLayer(query, []*priorityQueueItem{{
-		nodeID:   currentNode.ID,
-		distance: h.distanceFunc(query, currentNode.Vector),
-		node:     currentNode,
-	}}, ef, 0)
-
-	// Collect results
-	results := h.selectNeighborsSimple(candidates, k, 0)
-	neighbors := make([]int, 0, len(results))
-	for _, item := range results {
-		neighbors = append(neighbors, item.nodeID)
-	}
-
-	return neighbors
+	return currentNode
 }
 
 // searchLayer performs a search in a specific layer
@@ -0,0 +1,30 @@
+package hnsw
+
+// Len returns the number of vectors in the index
+func (h *HNSW) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	h.nodesMutex.RLock()
+	defer h.nodesMutex.RUnlock()
+	return len(h.nodes)
+}
+
+// MaxLayer returns the index of the top layer, or -1 if the index is empty
+func (h *HNSW) MaxLayer() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.maxLayer
+}
+
+// LayerSizes returns the number of nodes in each layer, starting with the
+// bottom layer, which contains every node
+func (h *HNSW) LayerSizes() []int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	sizes := make([]int, h.maxLayer+1)
+	for l := range sizes {
+		sizes[l] = len(h.layers[l].nodes)
+	}
+	return sizes
+}
@@ -3,12 +3,18 @@
 package hnsw
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"math/rand"
 	"sync"
 	"time"
 )
 
+// ErrDimensionMismatch is returned when a vector's length does not match the
+// dimension of the index
+var ErrDimensionMismatch = errors.New("vector dimension mismatch")
+
 // Node represents a vector in the HNSW graph.
 // Each node maintains connections to other nodes at different layers of the graph.
 // The bottom layer (index 0) contains all nodes, while higher layers contain
@@ -28,6 +34,15 @@ type Node struct {
 	OutEdges [][]int
 }
 
+// Neighbor is a vector found by a search, with its distance to the query
+type Neighbor struct {
+	// ID is the ID of the vector
+	ID int
+
+	// Distance is the distance from the query to the vector
+	Distance float32
+}
+
 // Layer represents a single level in the HNSW hierarchy.
 // Each layer is a graph where nodes are connected to their nearest neighbors.
 // Higher layers have fewer nodes, enabling efficient search through the hierarchy.
@@ -40,6 +55,9 @@ type Layer struct {
 // It maintains multiple layers of graphs with decreasing densities, allowing for efficient search
 // through the hierarchy.
 type HNSW struct {
+	// dim is the number of dimensions of every vector in the index
+	dim int
+
 	// layers contains the hierarchical graph structure
 	// layers[0] is the bottom layer containing all nodes
 	// layers[maxLayer] is the top layer with the fewest nodes
@@ -64,6 +82,12 @@ type HNSW struct {
 	// distanceFunc calculates the distance between two vectors
 	distanceFunc func([]float32, []float32) float32
 
+	// metric is the built-in metric in use, ignored if customDistance is set
+	metric Metric
+
+	// customDistance is set when distanceFunc was supplied by the caller
+	customDistance bool
+
 	// entryPointID is the ID of the entry point at the top layer
 	entryPointID int
 
@@ -73,11 +97,14 @@ type HNSW struct {
 	// nodes maps node IDs to their corresponding Node structs
 	nodes map[int]*Node
 
-	// nodesMux provides concurrent read/write access to the nodes map
+	// nodesMutex provides concurrent read/write access to the nodes map.
+	// It is always acquired after mu and never held across calls that
+	// acquire it again.
 	nodesMutex sync.RWMutex
 
-	// mu protects the entire graph structure during modifications
-	// Synchronization
+	// mu protects the entire graph structure: layers, node edges,
+	// entryPointID, maxLayer and rand. Insert and Delete hold the write lock
+	// for the whole mutation; Search and Save hold the read lock.
 	mu sync.RWMutex
 
 	// Random number generator
@@ -111,10 +138,18 @@ type Config struct {
 	// The default value of 1/ln(M) usually works well.
 	ML float64
 
+	// Metric selects a built-in distance function. Defaults to MetricEuclidean.
+	Metric Metric
+
 	// DistanceFunction calculates the distance between two vectors.
-	// If nil, Euclidean distance is used by default.
+	// If set, it takes precedence over Metric.
 	// The function should return smaller values for more similar vectors.
 	DistanceFunction func(a, b []float32) float32
+
+	// Seed seeds the random number generator used for level generation.
+	// Indexes built with the same non-zero seed and insertion order have
+	// identical graphs. If zero, a time-based seed is used.
+	Seed int64
 }
 
 // priorityQueueItem represents an item in the priority queue used during search.
@@ -194,11 +229,22 @@ func New(dim int, config ...Config) *HNSW {
 		mL = 1.0 / math.Log(float64(cfg.M))
 	}
 
+	// Pick the distance function, preferring a custom one
+	distanceFunc := cfg.Metric.distanceFunc()
+	if cfg.DistanceFunction != nil {
+		distanceFunc = cfg.DistanceFunction
+	}
+
 	// Create a new random number generator
-	randSrc := rand.NewSource(time.Now().UnixNano())
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	randSrc := rand.NewSource(seed)
 	randGen := rand.New(randSrc)
 
 	h := &HNSW{
+		dim:            dim,
 		layers:         []*Layer{{nodes: make([]*Node, 0)}},
 		nodes:          make(map[int]*Node),
 		M:              cfg.M,
@@ -206,7 +252,9 @@ func New(dim int, config ...Config) *HNSW {
 		efConstruction: cfg.EfConstruction,
 		efSearch:       cfg.EfSearch,
 		mL:             mL,
-		distanceFunc:   euclideanDistance,
+		distanceFunc:   distanceFunc,
+		metric:         cfg.Metric,
+		customDistance: cfg.DistanceFunction != nil,
 		entryPointID:   -1,
 		maxLayer:       -1,
 		rand:           randGen,
@@ -215,6 +263,23 @@ func New(dim int, config ...Config) *HNSW {
 	return h
 }
 
+// checkDimension returns an error if v does not have the index's dimension
+func (h *HNSW) checkDimension(v []float32) error {
+	if len(v) != h.dim {
+		return fmt.Errorf("%w: expected %d dimensions, got %d", ErrDimensionMismatch, h.dim, len(v))
+	}
+	return nil
+}
+
+// prepareVector returns the form of v that is stored or searched for,
+// normalizing it when the cosine metric is in use
+func (h *HNSW) prepareVector(v []float32) []float32 {
+	if h.metric == MetricCosine && !h.customDistance {
+		return normalize(v)
+	}
+	return v
+}
+
 // getM returns the maximum number of connections for a given layer
 func (h *HNSW) getM(layer int) int {
 	if layer == 0 {
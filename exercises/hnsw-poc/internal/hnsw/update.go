@@ -0,0 +1,66 @@
+package hnsw
+
+import "fmt"
+
+// Update replaces the vector of the node with the given ID and re-links the
+// node to match its new position. On each of its layers the node is
+// unlinked from its old neighbors, whose neighborhoods are repaired as for
+// Delete, and then connected to the nearest nodes around the new vector as
+// for Insert. The node keeps its ID and level. It returns an error if the
+// vector's length does not match the index's dimension or the node does not
+// exist.
+func (h *HNSW) Update(id int, vector []float32) error {
+	if err := h.checkDimension(vector); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	node := h.getNode(id)
+	if node == nil {
+		return fmt.Errorf("node %d not found", id)
+	}
+
+	// Unlink the node from its old neighborhood on every layer
+	for l := 0; l <= node.Level; l++ {
+		h.removeNodeFromLayer(node, l)
+		node.OutEdges[l] = node.OutEdges[l][:0]
+		h.addNodeToLayer(node, l)
+	}
+
+	node.Vector = append([]float32(nil), h.prepareVector(vector)...)
+
+	// Connect the node around its new position, from the top layer down
+	efConstruction := max(h.efConstruction, 1)
+	for l := node.Level; l >= 0; l-- {
+		entryPoint := h.updateEntryPoint(node, l)
+		if entryPoint == nil {
+			continue // The node is alone in this layer
+		}
+
+		neighbors := h.searchLayer(node.Vector, []*priorityQueueItem{{
+			nodeID:   entryPoint.ID,
+			distance: h.distanceFunc(node.Vector, entryPoint.Vector),
+			node:     entryPoint,
+		}}, efConstruction, l)
+		h.connectNode(node, neighbors, l)
+	}
+
+	return nil
+}
+
+// updateEntryPoint returns the node to start searching a layer from when
+// re-linking node, which is the graph's entry point unless that is the node
+// being updated, since it has no edges to follow yet
+func (h *HNSW) updateEntryPoint(node *Node, layer int) *Node {
+	if h.entryPointID != node.ID {
+		return h.getNode(h.entryPointID)
+	}
+	for _, n := range h.layers[layer].nodes {
+		if n.ID != node.ID {
+			return n
+		}
+	}
+	return nil
+}
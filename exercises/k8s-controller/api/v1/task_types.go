@@ -23,6 +23,22 @@ import (
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// ConcurrencyPolicy describes how a run that is due while an earlier run
+// of the task is still running is handled.
+// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+type ConcurrencyPolicy string
+
+const (
+	// AllowConcurrent starts the run alongside the earlier ones
+	AllowConcurrent ConcurrencyPolicy = "Allow"
+
+	// ForbidConcurrent skips the run, leaving the earlier ones running
+	ForbidConcurrent ConcurrencyPolicy = "Forbid"
+
+	// ReplaceConcurrent cancels the earlier runs and starts the new one
+	ReplaceConcurrent ConcurrencyPolicy = "Replace"
+)
+
 // TaskSpec defines the desired state of Task.
 type TaskSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
@@ -36,10 +52,83 @@ type TaskSpec struct {
 	// +optional
 	Args []string `json:"args,omitempty"`
 
+	// Image is the container image the command runs in
+	// +optional
+	// +kubebuilder:default="busybox:1.36"
+	Image string `json:"image,omitempty"`
+
+	// Env are the environment variables to set for the command
+	// +optional
+	Env []EnvVar `json:"env,omitempty"`
+
+	// WorkingDir is the directory the command runs in, defaulting to the
+	// image's working directory
+	// +optional
+	WorkingDir string `json:"workingDir,omitempty"`
+
 	// Schedule is a cron expression for recurring tasks
 	// +optional
 	// +kubebuilder:validation:Pattern=`^(@(annually|yearly|monthly|weekly|daily|hourly|reboot))|(@every (\d+(ns|us|µs|ms|s|m|h))+)|((((\d+,)+\d+|(\d+(\/|-|\#)\d+)|\d+L?|\*(\/\d+)?|L(-\d+)?|\?|[A-Z]{3}(-\d{4})?) ?){5,7})$`
 	Schedule string `json:"schedule,omitempty"`
+
+	// ConcurrencyPolicy specifies how to treat a scheduled run that is due
+	// while an earlier run is still running
+	// +optional
+	// +kubebuilder:default=Allow
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// HistoryLimit is the number of finished runs kept in the status
+	// history, dropping the oldest first
+	// +optional
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=0
+	HistoryLimit *int32 `json:"historyLimit,omitempty"`
+}
+
+// EnvVar is an environment variable set for a task's command
+type EnvVar struct {
+	// Name is the name of the variable
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Value is the value of the variable
+	// +optional
+	Value string `json:"value,omitempty"`
+}
+
+// ExecutionResult is the outcome of a run
+type ExecutionResult string
+
+const (
+	// ExecutionSucceeded means the command exited successfully
+	ExecutionSucceeded ExecutionResult = "Succeeded"
+
+	// ExecutionFailed means the command failed or couldn't be run
+	ExecutionFailed ExecutionResult = "Failed"
+)
+
+// ExecutionRecord describes a finished run of a task
+type ExecutionRecord struct {
+	// Job is the name of the Job that ran the command
+	Job string `json:"job"`
+
+	// StartTime is when the run started
+	StartTime metav1.Time `json:"startTime"`
+
+	// Duration is how long the run took
+	Duration metav1.Duration `json:"duration"`
+
+	// Result is the outcome of the run
+	Result ExecutionResult `json:"result"`
+
+	// Output is the start of the command's output
+	// +optional
+	Output string `json:"output,omitempty"`
+
+	// Error describes why the run failed
+	// +optional
+	Error string `json:"error,omitempty"`
 }
 
 // TaskStatus defines the observed state of Task.
@@ -48,6 +137,11 @@ type TaskStatus struct {
 	// +optional
 	LastExecutionTime *metav1.Time `json:"lastExecutionTime,omitempty"`
 
+	// LastScheduleTime is the last time a scheduled run was due, whether
+	// it was started or skipped by the concurrency policy
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
 	// LastExecutionOutput contains the output of the last command execution
 	// +optional
 	LastExecutionOutput string `json:"lastExecutionOutput,omitempty"`
@@ -59,6 +153,15 @@ type TaskStatus struct {
 	// ExecutionCount is the number of times the command has been executed
 	// +optional
 	ExecutionCount int32 `json:"executionCount,omitempty"`
+
+	// ActiveJobs are the names of the Jobs running the command that
+	// haven't finished yet
+	// +optional
+	ActiveJobs []string `json:"activeJobs,omitempty"`
+
+	// History records the most recent finished runs, oldest first
+	// +optional
+	History []ExecutionRecord `json:"history,omitempty"`
 }
 
 // +kubebuilder:object:root=true
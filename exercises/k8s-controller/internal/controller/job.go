@@ -0,0 +1,258 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	taskv1 "github.com/kumarlokesh/sysd/exercises/k8s-controller/api/v1"
+)
+
+const (
+	// taskNameLabel labels the Jobs and pods run for a Task with its name
+	taskNameLabel = "task.task.sysd.io/name"
+
+	// taskContainerName is the name of the container running the command
+	taskContainerName = "task"
+
+	// maxOutputBytes limits the output recorded in a Task's status
+	maxOutputBytes = 32 * 1024
+
+	// maxHistoryOutputBytes limits the output recorded for each run in a
+	// Task's history
+	maxHistoryOutputBytes = 1024
+
+	// defaultHistoryLimit is the number of runs kept in a Task's history
+	// if it doesn't set a limit
+	defaultHistoryLimit = 10
+
+	// maxJobNamePrefixLen is the longest part of a Job's name taken from
+	// its task's name, leaving room for the scheduled time so that the
+	// name fits in the 63 characters of the job-name label
+	maxJobNamePrefixLen = 52
+
+	// jobNameHashLen is the length of the hash of the task's name ending
+	// a truncated Job name prefix
+	jobNameHashLen = 8
+)
+
+// LogReader reads the output of the Jobs run for tasks
+type LogReader interface {
+	// JobLogs returns the logs of the command run by a Job
+	JobLogs(ctx context.Context, job *batchv1.Job) (string, error)
+}
+
+// PodLogReader is a LogReader that reads the logs of a Job's pod through
+// the Kubernetes API
+type PodLogReader struct {
+	Clientset kubernetes.Interface
+}
+
+// JobLogs returns the logs of the most recent pod run by a Job, up to
+// maxOutputBytes
+func (r *PodLogReader) JobLogs(ctx context.Context, job *batchv1.Job) (string, error) {
+	pods, err := r.Clientset.CoreV1().Pods(job.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.Set{batchv1.JobNameLabel: job.Name}.String(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list pods of job %s: %w", job.Name, err)
+	}
+	if len(pods.Items) == 0 {
+		return "", fmt.Errorf("no pods found for job %s", job.Name)
+	}
+
+	pod := pods.Items[0]
+	for _, p := range pods.Items[1:] {
+		if pod.CreationTimestamp.Before(&p.CreationTimestamp) {
+			pod = p
+		}
+	}
+
+	logs, err := r.Clientset.CoreV1().Pods(job.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container:  taskContainerName,
+		LimitBytes: ptr.To[int64](maxOutputBytes),
+	}).DoRaw(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to read logs of pod %s: %w", pod.Name, err)
+	}
+	return string(logs), nil
+}
+
+// jobName returns the name of the Job for the task's run scheduled at
+// scheduledTime. Like a CronJob's, it ends with the scheduled time, which
+// differs between runs. Task names too long to leave room for it are
+// truncated and end with a hash of the full name instead, so that tasks
+// sharing a long prefix don't share Job names.
+func jobName(task *taskv1.Task, scheduledTime time.Time) string {
+	prefix := task.Name
+	if len(prefix) > maxJobNamePrefixLen {
+		sum := sha256.Sum256([]byte(task.Name))
+		hash := hex.EncodeToString(sum[:])[:jobNameHashLen]
+		prefix = prefix[:maxJobNamePrefixLen-jobNameHashLen-1] + "-" + hash
+	}
+	return fmt.Sprintf("%s-%d", prefix, scheduledTime.Unix())
+}
+
+// jobForTask returns a Job for the task's run scheduled at scheduledTime,
+// owned by the task so that it is garbage collected with it. The Job
+// doesn't retry a failed run; the next run is left to the task's schedule.
+func (r *TaskReconciler) jobForTask(task *taskv1.Task, scheduledTime time.Time) (*batchv1.Job, error) {
+	podLabels := map[string]string{taskNameLabel: task.Name}
+
+	var env []corev1.EnvVar
+	for _, e := range task.Spec.Env {
+		env = append(env, corev1.EnvVar{Name: e.Name, Value: e.Value})
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName(task, scheduledTime),
+			Namespace: task.Namespace,
+			Labels:    podLabels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To[int32](0),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:       taskContainerName,
+						Image:      task.Spec.Image,
+						Command:    []string{task.Spec.Command},
+						Args:       task.Spec.Args,
+						Env:        env,
+						WorkingDir: task.Spec.WorkingDir,
+					}},
+				},
+			},
+		},
+	}
+
+	if err := controllerutil.SetControllerReference(task, job, r.Scheme); err != nil {
+		return nil, fmt.Errorf("failed to set owner of job %s: %w", job.Name, err)
+	}
+	return job, nil
+}
+
+// validateEnv checks that each of the task's environment variables has a
+// name, which the API server checks too but a fake client doesn't
+func validateEnv(task *taskv1.Task) error {
+	for i, e := range task.Spec.Env {
+		if e.Name == "" {
+			return fmt.Errorf("env[%d]: name must not be empty", i)
+		}
+	}
+	return nil
+}
+
+// jobFinished reports whether a Job has finished and, if it failed, why
+func jobFinished(job *batchv1.Job) (finished bool, failure string) {
+	c := finishedCondition(job)
+	switch {
+	case c == nil:
+		return false, ""
+	case c.Type == batchv1.JobComplete:
+		return true, ""
+	case c.Message != "":
+		return true, fmt.Sprintf("job failed: %s", c.Message)
+	default:
+		return true, fmt.Sprintf("job failed: %s", c.Reason)
+	}
+}
+
+// finishedCondition returns the condition marking a Job as complete or
+// failed, or nil if it hasn't finished
+func finishedCondition(job *batchv1.Job) *batchv1.JobCondition {
+	for i, c := range job.Status.Conditions {
+		if c.Status == corev1.ConditionTrue && (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) {
+			return &job.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// executionRecord describes a finished Job's run. Times the Job doesn't
+// report fall back to its creation and to now.
+func executionRecord(job *batchv1.Job, output, failure string, now time.Time) taskv1.ExecutionRecord {
+	start := job.CreationTimestamp.Time
+	if job.Status.StartTime != nil {
+		start = job.Status.StartTime.Time
+	}
+	end := now
+	if job.Status.CompletionTime != nil {
+		end = job.Status.CompletionTime.Time
+	} else if c := finishedCondition(job); c != nil && !c.LastTransitionTime.IsZero() {
+		end = c.LastTransitionTime.Time
+	}
+
+	record := taskv1.ExecutionRecord{
+		Job:       job.Name,
+		StartTime: metav1.NewTime(start),
+		Duration:  metav1.Duration{Duration: max(end.Sub(start), 0)},
+		Result:    taskv1.ExecutionSucceeded,
+		Output:    truncate(output, maxHistoryOutputBytes),
+		Error:     failure,
+	}
+	if failure != "" {
+		record.Result = taskv1.ExecutionFailed
+	}
+	return record
+}
+
+// appendHistory adds a record to the task's history, dropping the oldest
+// records beyond its limit
+func appendHistory(task *taskv1.Task, record taskv1.ExecutionRecord) {
+	limit := defaultHistoryLimit
+	if task.Spec.HistoryLimit != nil {
+		limit = int(*task.Spec.HistoryLimit)
+	}
+
+	history := append(task.Status.History, record)
+	if len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+	if len(history) == 0 {
+		history = nil
+	}
+	task.Status.History = history
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8
+// character
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -19,12 +19,17 @@ package controller
 import (
 	"context"
 	"fmt"
-	"os/exec"
+	"slices"
 	"time"
 
+	"github.com/robfig/cron/v3"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -33,17 +38,43 @@ import (
 	taskv1 "github.com/kumarlokesh/sysd/exercises/k8s-controller/api/v1"
 )
 
+// scheduleParser parses Task schedules: standard five-field cron
+// expressions, optionally with a leading seconds field, and descriptors
+// such as @hourly and @every 10m
+var scheduleParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
 // TaskReconciler reconciles a Task object
 type TaskReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 	Log    logr.Logger
+
+	// Clock tells the time when deciding whether a scheduled task is due.
+	// If nil, the real clock is used.
+	Clock clock.PassiveClock
+
+	// LogReader reads the output of finished Jobs into the Task status. If
+	// nil, no output is recorded.
+	LogReader LogReader
+}
+
+// now returns the current time from the reconciler's clock
+func (r *TaskReconciler) now() time.Time {
+	if r.Clock == nil {
+		return time.Now()
+	}
+	return r.Clock.Now()
 }
 
 // +kubebuilder:rbac:groups=task.task.sysd.io,resources=tasks,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=task.task.sysd.io,resources=tasks/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=task.task.sysd.io,resources=tasks/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods/log,verbs=get
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -72,72 +103,201 @@ func (r *TaskReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 
 	log.Info("Processing Task", "command", task.Spec.Command, "args", task.Spec.Args, "schedule", task.Spec.Schedule)
 
+	// Create a copy of the task to update status
+	taskCopy := task.DeepCopy()
+
+	// Record the results of runs that have finished
+	if err := r.syncActiveJobs(ctx, taskCopy); err != nil {
+		log.Error(err, "unable to check active Jobs")
+		return ctrl.Result{}, err
+	}
+
+	if err := validateEnv(task); err != nil {
+		// As for an invalid schedule, wait for the task to be fixed
+		log.Error(err, "invalid env")
+		taskCopy.Status.LastError = fmt.Sprintf("invalid env: %v", err)
+		return ctrl.Result{}, r.updateStatus(ctx, task, taskCopy)
+	}
+
 	// If the task has a schedule, check if it's time to run
-	requeueAfter := time.Duration(0)
+	now := r.now()
+	var schedule cron.Schedule
+	due := false
+	scheduledTime := now
 	if task.Spec.Schedule != "" {
-		// For simplicity, we'll just log that we would schedule this task
-		// In a real implementation, you would use a cron library to parse the schedule
-		log.Info("Task has a schedule, will run at the specified time", "schedule", task.Spec.Schedule)
-		// For now, we'll just run the task immediately for demonstration
-		requeueAfter = time.Minute * 5 // Requeue after 5 minutes for demonstration
+		var err error
+		schedule, err = scheduleParser.Parse(task.Spec.Schedule)
+		if err != nil {
+			// Retrying won't help until the schedule is fixed, which
+			// triggers a new reconcile
+			log.Error(err, "invalid schedule", "schedule", task.Spec.Schedule)
+			taskCopy.Status.LastError = fmt.Sprintf("invalid schedule %q: %v", task.Spec.Schedule, err)
+			return ctrl.Result{}, r.updateStatus(ctx, task, taskCopy)
+		}
+
+		// The first run is the first scheduled time after the task was
+		// created, and each later one the first after the previous run
+		nextRun := schedule.Next(lastScheduleBase(task))
+		due = !now.Before(nextRun)
+		scheduledTime = nextRun
+		log.Info("Task has a schedule", "schedule", task.Spec.Schedule, "nextRun", nextRun, "due", due)
 	} else {
-		log.Info("Task has no schedule, executing immediately")
+		// A task without a schedule runs once
+		due = task.Status.LastExecutionTime == nil
 	}
 
-	// Execute the command
-	log.Info("Executing command", "command", task.Spec.Command, "args", task.Spec.Args)
-	output, err := r.executeCommand(task.Spec.Command, task.Spec.Args...)
+	policy := task.Spec.ConcurrencyPolicy
+	switch {
+	case !due:
+	case len(taskCopy.Status.ActiveJobs) > 0 && policy == taskv1.ForbidConcurrent:
+		log.Info("Skipping run since the previous one hasn't finished", "activeJobs", taskCopy.Status.ActiveJobs)
+		scheduleTime := metav1.NewTime(now)
+		taskCopy.Status.LastScheduleTime = &scheduleTime
+	default:
+		if len(taskCopy.Status.ActiveJobs) > 0 && policy == taskv1.ReplaceConcurrent {
+			if err := r.deleteActiveJobs(ctx, taskCopy); err != nil {
+				log.Error(err, "unable to delete active Jobs")
+				return ctrl.Result{}, err
+			}
+		}
+		if err := r.startJob(ctx, task, taskCopy, now, scheduledTime); err != nil {
+			log.Error(err, "unable to start Job")
+			return ctrl.Result{}, err
+		}
+	}
 
-	log.Info("Command execution result", "output", output, "error", err)
+	if err := r.updateStatus(ctx, task, taskCopy); err != nil {
+		return ctrl.Result{}, err
+	}
 
-	// Create a copy of the task to update status
-	taskCopy := task.DeepCopy()
+	// Requeue for the next scheduled run
+	if schedule != nil {
+		if requeueAfter := schedule.Next(lastScheduleBase(taskCopy)).Sub(now); requeueAfter > 0 {
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+	}
 
-	// Update status
-	now := metav1.Now()
-	taskCopy.Status.LastExecutionTime = &now
-	if taskCopy.Status.ExecutionCount == 0 {
-		taskCopy.Status.ExecutionCount = 1
-	} else {
-		taskCopy.Status.ExecutionCount++
+	return ctrl.Result{}, nil
+}
+
+// syncActiveJobs records the results of the task's Jobs that have finished
+// in its status and removes them from its active Jobs
+func (r *TaskReconciler) syncActiveJobs(ctx context.Context, task *taskv1.Task) error {
+	log := r.Log.WithValues("task", client.ObjectKeyFromObject(task))
+
+	var active []string
+	for _, name := range task.Status.ActiveJobs {
+		job := &batchv1.Job{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: task.Namespace, Name: name}, job)
+		if errors.IsNotFound(err) {
+			task.Status.LastError = fmt.Sprintf("job %s was deleted before it finished", name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		finished, failure := jobFinished(job)
+		if !finished {
+			active = append(active, name)
+			continue
+		}
+
+		output := ""
+		if r.LogReader != nil {
+			output, err = r.LogReader.JobLogs(ctx, job)
+			if err != nil {
+				// The run's result is still worth recording without its output
+				log.Error(err, "unable to read Job logs", "job", name)
+			}
+		}
+
+		log.Info("Job finished", "job", name, "failure", failure)
+		task.Status.ExecutionCount++
+		task.Status.LastExecutionOutput = output
+		task.Status.LastError = failure
+		appendHistory(task, executionRecord(job, output, failure, r.now()))
 	}
+	task.Status.ActiveJobs = active
 
-	if err != nil {
-		taskCopy.Status.LastError = err.Error()
-		taskCopy.Status.LastExecutionOutput = ""
-		log.Error(err, "error executing command")
-	} else {
-		taskCopy.Status.LastError = ""
-		taskCopy.Status.LastExecutionOutput = output
+	return nil
+}
+
+// deleteActiveJobs deletes the task's Jobs that are still running, along
+// with their pods, and removes them from its active Jobs
+func (r *TaskReconciler) deleteActiveJobs(ctx context.Context, task *taskv1.Task) error {
+	for _, name := range task.Status.ActiveJobs {
+		r.Log.Info("Deleting Job", "task", client.ObjectKeyFromObject(task), "job", name)
+		job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: task.Namespace, Name: name}}
+		err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete job %s: %w", name, err)
+		}
 	}
+	task.Status.ActiveJobs = nil
 
-	log.Info("Updating Task status", "status", taskCopy.Status)
+	return nil
+}
 
-	// Update the Task status
-	if err := r.Status().Update(ctx, taskCopy); err != nil {
-		log.Error(err, "unable to update Task status")
-		return ctrl.Result{}, err
+// startJob creates a Job for the run scheduled at scheduledTime, running the
+// task's command, and records it in the task's status
+func (r *TaskReconciler) startJob(ctx context.Context, task, taskCopy *taskv1.Task, now, scheduledTime time.Time) error {
+	executionTime := metav1.NewTime(now)
+	taskCopy.Status.LastExecutionTime = &executionTime
+	taskCopy.Status.LastScheduleTime = &executionTime
+
+	if task.Spec.Command == "" {
+		taskCopy.Status.LastError = "no command specified"
+		return nil
 	}
 
-	log.Info("Successfully updated Task status")
+	job, err := r.jobForTask(task, scheduledTime)
+	if err != nil {
+		return err
+	}
 
-	// If we have a requeue time, return it
-	if requeueAfter > 0 {
-		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	r.Log.Info("Creating Job", "task", client.ObjectKeyFromObject(task), "job", job.Name,
+		"image", task.Spec.Image, "command", task.Spec.Command, "args", task.Spec.Args)
+	if err := r.Create(ctx, job); err != nil {
+		// The Job is named after the scheduled time, so it already exists
+		// if an earlier reconcile created it but failed to record it
+		if !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create job %s: %w", job.Name, err)
+		}
+		r.Log.Info("Job already exists", "task", client.ObjectKeyFromObject(task), "job", job.Name)
+		if slices.Contains(taskCopy.Status.ActiveJobs, job.Name) {
+			return nil
+		}
 	}
 
-	return ctrl.Result{}, nil
+	taskCopy.Status.ActiveJobs = append(taskCopy.Status.ActiveJobs, job.Name)
+	return nil
 }
 
-// executeCommand executes the given command with arguments
-func (r *TaskReconciler) executeCommand(command string, args ...string) (string, error) {
-	if command == "" {
-		return "", fmt.Errorf("no command specified")
+// updateStatus writes the status of taskCopy if it differs from task's
+func (r *TaskReconciler) updateStatus(ctx context.Context, task, taskCopy *taskv1.Task) error {
+	if equality.Semantic.DeepEqual(task.Status, taskCopy.Status) {
+		return nil
 	}
 
-	cmd := exec.Command(command, args...)
-	output, err := cmd.CombinedOutput()
-	return string(output), err
+	r.Log.Info("Updating Task status", "task", client.ObjectKeyFromObject(task), "status", taskCopy.Status)
+	if err := r.Status().Update(ctx, taskCopy); err != nil {
+		r.Log.Error(err, "unable to update Task status")
+		return err
+	}
+	return nil
+}
+
+// lastScheduleBase returns the time after which a scheduled task's next
+// run falls: its last scheduled run, or its creation if it hasn't had one
+func lastScheduleBase(task *taskv1.Task) time.Time {
+	if task.Status.LastScheduleTime != nil {
+		return task.Status.LastScheduleTime.Time
+	}
+	if task.Status.LastExecutionTime != nil {
+		return task.Status.LastExecutionTime.Time
+	}
+	return task.CreationTimestamp.Time
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -148,8 +308,9 @@ func (r *TaskReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// Create a new controller
 	r.Log.Info("Setting up controller with manager")
 
-	// Build the controller
+	// Build the controller, reconciling a Task when one of its Jobs changes
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&taskv1.Task{}).
+		Owns(&batchv1.Job{}).
 		Complete(r)
 }
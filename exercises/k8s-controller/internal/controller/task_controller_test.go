@@ -18,11 +18,21 @@ package controller
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -30,7 +40,66 @@ import (
 	taskv1 "github.com/kumarlokesh/sysd/exercises/k8s-controller/api/v1"
 )
 
+// fakeLogReader returns the same logs for every Job
+type fakeLogReader struct {
+	logs string
+}
+
+func (f fakeLogReader) JobLogs(context.Context, *batchv1.Job) (string, error) {
+	return f.logs, nil
+}
+
+// finishJob marks a Job as having run from start to end and finished with
+// the given condition
+func finishJob(ctx context.Context, c client.Client, namespace, name string,
+	condition batchv1.JobConditionType, start, end time.Time) {
+	job := &batchv1.Job{}
+	Expect(c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, job)).To(Succeed())
+	job.Status.StartTime = &metav1.Time{Time: start}
+	if condition == batchv1.JobComplete {
+		job.Status.CompletionTime = &metav1.Time{Time: end}
+	}
+	job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+		Type:               condition,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Time{Time: end},
+	})
+	Expect(c.Status().Update(ctx, job)).To(Succeed())
+}
+
+// newFakeScheme returns a scheme holding the built-in types and Tasks, so
+// that the fake client doesn't need the envtest control plane
+func newFakeScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(taskv1.AddToScheme(scheme)).To(Succeed())
+	return scheme
+}
+
+// newFakeReconciler returns a reconciler backed by a fake client holding
+// task, telling the time with clock
+func newFakeReconciler(task *taskv1.Task, clock *clocktesting.FakeClock) *TaskReconciler {
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(newFakeScheme()).
+		WithObjects(task).
+		WithStatusSubresource(task, &batchv1.Job{}).
+		Build()
+
+	return &TaskReconciler{
+		Client:    fakeClient,
+		Scheme:    fakeClient.Scheme(),
+		Clock:     clock,
+		LogReader: fakeLogReader{logs: "hello\n"},
+	}
+}
+
 var _ = Describe("Task Controller", func() {
+	BeforeEach(func() {
+		if k8sClient == nil {
+			Skip("the envtest control plane is not available")
+		}
+	})
+
 	Context("When reconciling a resource", func() {
 		const resourceName = "test-resource"
 
@@ -81,4 +150,480 @@ var _ = Describe("Task Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
+
+	Context("When running a task", func() {
+		const resourceName = "job-task"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating a Task with an image")
+			resource := &taskv1.Task{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: taskv1.TaskSpec{
+					Command: "echo",
+					Args:    []string{"hello", "world"},
+					Image:   "busybox:1.36",
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &taskv1.Task{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			// envtest runs no garbage collector, so remove the Jobs too
+			Expect(k8sClient.DeleteAllOf(ctx, &batchv1.Job{},
+				client.InNamespace("default"),
+				client.MatchingLabels{taskNameLabel: resourceName},
+				client.PropagationPolicy(metav1.DeletePropagationBackground),
+			)).To(Succeed())
+		})
+
+		It("should create a Job owned by the Task running its command", func() {
+			controllerReconciler := &TaskReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			task := &taskv1.Task{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, task)).To(Succeed())
+			Expect(task.Status.ActiveJobs).To(HaveLen(1))
+			Expect(task.Status.LastExecutionTime).NotTo(BeNil())
+
+			job := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Namespace: "default",
+				Name:      task.Status.ActiveJobs[0],
+			}, job)).To(Succeed())
+
+			Expect(job.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+			Expect(job.Spec.Template.Spec.Containers).To(HaveLen(1))
+			container := job.Spec.Template.Spec.Containers[0]
+			Expect(container.Image).To(Equal("busybox:1.36"))
+			Expect(container.Command).To(Equal([]string{"echo"}))
+			Expect(container.Args).To(Equal([]string{"hello", "world"}))
+
+			owner := metav1.GetControllerOf(job)
+			Expect(owner).NotTo(BeNil())
+			Expect(owner.Kind).To(Equal("Task"))
+			Expect(owner.UID).To(Equal(task.UID))
+
+			By("not starting another run while the Job is active")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			jobs := &batchv1.JobList{}
+			Expect(k8sClient.List(ctx, jobs, client.InNamespace("default"),
+				client.MatchingLabels{taskNameLabel: resourceName})).To(Succeed())
+			Expect(jobs.Items).To(HaveLen(1))
+		})
+	})
+})
+
+var _ = Describe("Task scheduling", func() {
+	const resourceName = "scheduled-task"
+
+	ctx := context.Background()
+
+	typeNamespacedName := types.NamespacedName{
+		Name:      resourceName,
+		Namespace: "default",
+	}
+
+	var (
+		fakeClock  *clocktesting.FakeClock
+		reconciler *TaskReconciler
+	)
+
+	// reconcile runs a reconcile at the fake clock's time and returns the
+	// result and the updated task
+	reconcileTask := func() (reconcile.Result, *taskv1.Task) {
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		task := &taskv1.Task{}
+		Expect(reconciler.Get(ctx, typeNamespacedName, task)).To(Succeed())
+		return result, task
+	}
+
+	BeforeEach(func() {
+		created := time.Date(2025, 1, 1, 10, 0, 30, 0, time.UTC)
+		fakeClock = clocktesting.NewFakeClock(created)
+
+		task := &taskv1.Task{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              resourceName,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: taskv1.TaskSpec{
+				Command:  "echo",
+				Args:     []string{"hello"},
+				Image:    "busybox:1.36",
+				Schedule: "*/5 * * * *",
+			},
+		}
+		reconciler = newFakeReconciler(task, fakeClock)
+	})
+
+	It("should run a */5 task on schedule and not on intervening reconciles", func() {
+		By("waiting for the first scheduled time after creation")
+		result, task := reconcileTask()
+		Expect(task.Status.ExecutionCount).To(BeZero())
+		Expect(task.Status.LastExecutionTime).To(BeNil())
+		Expect(result.RequeueAfter).To(Equal(4*time.Minute + 30*time.Second))
+
+		fakeClock.SetTime(time.Date(2025, 1, 1, 10, 3, 0, 0, time.UTC))
+		result, task = reconcileTask()
+		Expect(task.Status.ExecutionCount).To(BeZero())
+		Expect(result.RequeueAfter).To(Equal(2 * time.Minute))
+
+		By("starting a Job at the scheduled time")
+		fakeClock.SetTime(time.Date(2025, 1, 1, 10, 5, 0, 0, time.UTC))
+		result, task = reconcileTask()
+		Expect(task.Status.ActiveJobs).To(HaveLen(1))
+		Expect(task.Status.LastExecutionTime.Time).To(BeTemporally("==", fakeClock.Now()))
+		Expect(result.RequeueAfter).To(Equal(5 * time.Minute))
+
+		By("recording the result once the Job finishes")
+		finishJob(ctx, reconciler.Client, "default", task.Status.ActiveJobs[0], batchv1.JobComplete,
+			fakeClock.Now(), fakeClock.Now().Add(time.Minute))
+		fakeClock.SetTime(time.Date(2025, 1, 1, 10, 7, 0, 0, time.UTC))
+		result, task = reconcileTask()
+		Expect(task.Status.ActiveJobs).To(BeEmpty())
+		Expect(task.Status.ExecutionCount).To(Equal(int32(1)))
+		Expect(task.Status.LastExecutionOutput).To(Equal("hello\n"))
+		Expect(task.Status.LastError).To(BeEmpty())
+
+		By("not running again before the next scheduled time")
+		Expect(result.RequeueAfter).To(Equal(3 * time.Minute))
+
+		By("running again at the next scheduled time")
+		fakeClock.SetTime(time.Date(2025, 1, 1, 10, 10, 0, 0, time.UTC))
+		result, task = reconcileTask()
+		Expect(task.Status.ActiveJobs).To(HaveLen(1))
+		Expect(result.RequeueAfter).To(Equal(5 * time.Minute))
+
+		By("recording a failed run")
+		finishJob(ctx, reconciler.Client, "default", task.Status.ActiveJobs[0], batchv1.JobFailed,
+			fakeClock.Now(), fakeClock.Now().Add(time.Minute))
+		_, task = reconcileTask()
+		Expect(task.Status.ExecutionCount).To(Equal(int32(2)))
+		Expect(task.Status.LastError).To(ContainSubstring("job failed"))
+		jobs := &batchv1.JobList{}
+		Expect(reconciler.List(ctx, jobs)).To(Succeed())
+		Expect(jobs.Items).To(HaveLen(2))
+	})
+})
+
+var _ = Describe("Task concurrency policy", func() {
+	ctx := context.Background()
+
+	typeNamespacedName := types.NamespacedName{
+		Name:      "concurrent-task",
+		Namespace: "default",
+	}
+
+	DescribeTable("a run due while the previous one is still running",
+		func(policy taskv1.ConcurrencyPolicy, expectFirstActive bool, expectActive int) {
+			created := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+			fakeClock := clocktesting.NewFakeClock(created)
+			reconciler := newFakeReconciler(&taskv1.Task{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              typeNamespacedName.Name,
+					Namespace:         typeNamespacedName.Namespace,
+					CreationTimestamp: metav1.NewTime(created),
+				},
+				Spec: taskv1.TaskSpec{
+					Command:           "sleep",
+					Args:              []string{"600"},
+					Image:             "busybox:1.36",
+					Schedule:          "*/5 * * * *",
+					ConcurrencyPolicy: policy,
+				},
+			}, fakeClock)
+
+			reconcileTask := func() (reconcile.Result, *taskv1.Task) {
+				result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+
+				task := &taskv1.Task{}
+				Expect(reconciler.Get(ctx, typeNamespacedName, task)).To(Succeed())
+				return result, task
+			}
+
+			By("starting the first run")
+			fakeClock.SetTime(time.Date(2025, 1, 1, 10, 5, 0, 0, time.UTC))
+			_, task := reconcileTask()
+			Expect(task.Status.ActiveJobs).To(HaveLen(1))
+			first := task.Status.ActiveJobs[0]
+
+			By("reaching the next run before the first finishes")
+			fakeClock.SetTime(time.Date(2025, 1, 1, 10, 10, 0, 0, time.UTC))
+			result, task := reconcileTask()
+			Expect(task.Status.ActiveJobs).To(HaveLen(expectActive))
+			Expect(task.Status.LastScheduleTime.Time).To(BeTemporally("==", fakeClock.Now()))
+			Expect(result.RequeueAfter).To(Equal(5 * time.Minute))
+
+			err := reconciler.Get(ctx, types.NamespacedName{Namespace: "default", Name: first}, &batchv1.Job{})
+			if expectFirstActive {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(task.Status.ActiveJobs).To(ContainElement(first))
+			} else {
+				Expect(errors.IsNotFound(err)).To(BeTrue(), "the first Job should be deleted")
+				Expect(task.Status.ActiveJobs).NotTo(ContainElement(first))
+			}
+
+			By("not starting another run on an intervening reconcile")
+			fakeClock.SetTime(time.Date(2025, 1, 1, 10, 11, 0, 0, time.UTC))
+			_, task = reconcileTask()
+			Expect(task.Status.ActiveJobs).To(HaveLen(expectActive))
+		},
+		Entry("Allow runs both", taskv1.AllowConcurrent, true, 2),
+		Entry("Forbid skips the new run", taskv1.ForbidConcurrent, true, 1),
+		Entry("Replace cancels the first run", taskv1.ReplaceConcurrent, false, 1),
+	)
+})
+
+var _ = Describe("Task Job names", func() {
+	ctx := context.Background()
+
+	It("should name each Job after its scheduled time", func() {
+		created := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+		fakeClock := clocktesting.NewFakeClock(created)
+		typeNamespacedName := types.NamespacedName{Name: "every-second-task", Namespace: "default"}
+		reconciler := newFakeReconciler(&taskv1.Task{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              typeNamespacedName.Name,
+				Namespace:         typeNamespacedName.Namespace,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: taskv1.TaskSpec{
+				Command:           "sleep",
+				Args:              []string{"600"},
+				Image:             "busybox:1.36",
+				Schedule:          "* * * * * *",
+				ConcurrencyPolicy: taskv1.AllowConcurrent,
+			},
+		}, fakeClock)
+
+		// The second reconcile comes late, after the run due at 10:00:02
+		for _, t := range []time.Time{
+			time.Date(2025, 1, 1, 10, 0, 1, 0, time.UTC),
+			time.Date(2025, 1, 1, 10, 0, 4, 500000000, time.UTC),
+		} {
+			fakeClock.SetTime(t)
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		task := &taskv1.Task{}
+		Expect(reconciler.Get(ctx, typeNamespacedName, task)).To(Succeed())
+		Expect(task.Status.ActiveJobs).To(Equal([]string{
+			"every-second-task-1735725601",
+			"every-second-task-1735725602",
+		}))
+	})
+
+	It("should record a Job that already exists for the scheduled time", func() {
+		created := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+		fakeClock := clocktesting.NewFakeClock(time.Date(2025, 1, 1, 10, 5, 0, 0, time.UTC))
+		typeNamespacedName := types.NamespacedName{Name: "retried-task", Namespace: "default"}
+		reconciler := newFakeReconciler(&taskv1.Task{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              typeNamespacedName.Name,
+				Namespace:         typeNamespacedName.Namespace,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: taskv1.TaskSpec{
+				Command:  "echo",
+				Image:    "busybox:1.36",
+				Schedule: "*/5 * * * *",
+			},
+		}, fakeClock)
+
+		By("creating the Job without recording it, as a failed status update would")
+		existing := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Name:      "retried-task-1735725900",
+			Namespace: "default",
+		}}
+		Expect(reconciler.Create(ctx, existing)).To(Succeed())
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		task := &taskv1.Task{}
+		Expect(reconciler.Get(ctx, typeNamespacedName, task)).To(Succeed())
+		Expect(task.Status.ActiveJobs).To(Equal([]string{existing.Name}))
+	})
+
+	It("should keep Job names of long task names within the label limit", func() {
+		scheduledTime := time.Date(2025, 1, 1, 10, 5, 0, 0, time.UTC)
+		long := &taskv1.Task{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 62) + "1"}}
+		other := &taskv1.Task{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 62) + "2"}}
+
+		name := jobName(long, scheduledTime)
+		Expect(len(name)).To(BeNumerically("<=", 63))
+		Expect(name).To(HavePrefix(strings.Repeat("a", 43) + "-"))
+		Expect(name).To(HaveSuffix("-1735725900"))
+		Expect(jobName(other, scheduledTime)).NotTo(Equal(name))
+
+		Expect(jobName(&taskv1.Task{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 52)}}, scheduledTime)).
+			To(Equal(strings.Repeat("a", 52) + "-1735725900"))
+	})
+})
+
+var _ = Describe("Task execution history", func() {
+	ctx := context.Background()
+
+	typeNamespacedName := types.NamespacedName{
+		Name:      "history-task",
+		Namespace: "default",
+	}
+
+	It("should record each run, keeping the most recent up to the limit", func() {
+		created := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+		fakeClock := clocktesting.NewFakeClock(created)
+		reconciler := newFakeReconciler(&taskv1.Task{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              typeNamespacedName.Name,
+				Namespace:         typeNamespacedName.Namespace,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: taskv1.TaskSpec{
+				Command:      "echo",
+				Args:         []string{"hello"},
+				Image:        "busybox:1.36",
+				Schedule:     "*/5 * * * *",
+				HistoryLimit: ptr.To[int32](2),
+			},
+		}, fakeClock)
+		reconciler.LogReader = fakeLogReader{logs: strings.Repeat("x", 2*maxHistoryOutputBytes)}
+
+		// run starts a Job at the given minute past ten, finishes it with
+		// condition after 30 seconds and returns the updated task
+		run := func(minute int, condition batchv1.JobConditionType) *taskv1.Task {
+			start := time.Date(2025, 1, 1, 10, minute, 0, 0, time.UTC)
+			fakeClock.SetTime(start)
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			task := &taskv1.Task{}
+			Expect(reconciler.Get(ctx, typeNamespacedName, task)).To(Succeed())
+			Expect(task.Status.ActiveJobs).To(HaveLen(1))
+			finishJob(ctx, reconciler.Client, "default", task.Status.ActiveJobs[0], condition,
+				start, start.Add(30*time.Second))
+
+			fakeClock.SetTime(start.Add(time.Minute))
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reconciler.Get(ctx, typeNamespacedName, task)).To(Succeed())
+			return task
+		}
+
+		By("recording a successful run")
+		task := run(5, batchv1.JobComplete)
+		Expect(task.Status.History).To(HaveLen(1))
+		record := task.Status.History[0]
+		Expect(record.Job).To(Equal("history-task-1735725900"))
+		Expect(record.StartTime.Time).To(BeTemporally("==", time.Date(2025, 1, 1, 10, 5, 0, 0, time.UTC)))
+		Expect(record.Duration.Duration).To(Equal(30 * time.Second))
+		Expect(record.Result).To(Equal(taskv1.ExecutionSucceeded))
+		Expect(record.Output).To(HaveLen(maxHistoryOutputBytes))
+		Expect(record.Error).To(BeEmpty())
+
+		By("recording a failed run")
+		task = run(10, batchv1.JobFailed)
+		Expect(task.Status.History).To(HaveLen(2))
+		record = task.Status.History[1]
+		Expect(record.Result).To(Equal(taskv1.ExecutionFailed))
+		Expect(record.Error).To(ContainSubstring("job failed"))
+		Expect(record.Duration.Duration).To(Equal(30 * time.Second))
+
+		By("dropping the oldest run beyond the limit")
+		task = run(15, batchv1.JobComplete)
+		Expect(task.Status.History).To(HaveLen(2))
+		Expect(task.Status.History[0].Result).To(Equal(taskv1.ExecutionFailed))
+		Expect(task.Status.History[0].StartTime.Time).To(BeTemporally("==", time.Date(2025, 1, 1, 10, 10, 0, 0, time.UTC)))
+		Expect(task.Status.History[1].Result).To(Equal(taskv1.ExecutionSucceeded))
+		Expect(task.Status.ExecutionCount).To(Equal(int32(3)))
+	})
+})
+
+var _ = Describe("Task environment", func() {
+	ctx := context.Background()
+
+	typeNamespacedName := types.NamespacedName{
+		Name:      "env-task",
+		Namespace: "default",
+	}
+
+	newTask := func(env ...taskv1.EnvVar) *taskv1.Task {
+		return &taskv1.Task{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      typeNamespacedName.Name,
+				Namespace: typeNamespacedName.Namespace,
+			},
+			Spec: taskv1.TaskSpec{
+				Command:    "echo",
+				Args:       []string{"$(GREETING), world"},
+				Image:      "busybox:1.36",
+				Env:        env,
+				WorkingDir: "/tmp",
+			},
+		}
+	}
+
+	It("should run the command with the task's env and working directory", func() {
+		fakeClock := clocktesting.NewFakeClock(time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC))
+		reconciler := newFakeReconciler(newTask(taskv1.EnvVar{Name: "GREETING", Value: "hello"}), fakeClock)
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		task := &taskv1.Task{}
+		Expect(reconciler.Get(ctx, typeNamespacedName, task)).To(Succeed())
+		Expect(task.Status.ActiveJobs).To(HaveLen(1))
+
+		job := &batchv1.Job{}
+		Expect(reconciler.Get(ctx, types.NamespacedName{Namespace: "default", Name: task.Status.ActiveJobs[0]}, job)).To(Succeed())
+		container := job.Spec.Template.Spec.Containers[0]
+		Expect(container.Env).To(Equal([]corev1.EnvVar{{Name: "GREETING", Value: "hello"}}))
+		Expect(container.WorkingDir).To(Equal("/tmp"))
+		Expect(container.Args).To(Equal([]string{"$(GREETING), world"}))
+	})
+
+	It("should not run a task with an unnamed env var", func() {
+		fakeClock := clocktesting.NewFakeClock(time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC))
+		reconciler := newFakeReconciler(newTask(taskv1.EnvVar{Value: "hello"}), fakeClock)
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		task := &taskv1.Task{}
+		Expect(reconciler.Get(ctx, typeNamespacedName, task)).To(Succeed())
+		Expect(task.Status.ActiveJobs).To(BeEmpty())
+		Expect(task.Status.LastError).To(ContainSubstring("env[0]: name must not be empty"))
+
+		jobs := &batchv1.JobList{}
+		Expect(reconciler.List(ctx, jobs)).To(Succeed())
+		Expect(jobs.Items).To(BeEmpty())
+	})
 })
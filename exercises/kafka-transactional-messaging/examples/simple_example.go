@@ -21,7 +21,7 @@ func main() {
 	prod := producer.NewProducer("example-producer-1", coord, messageLog)
 
 	// Create a consumer group
-	consumer1 := consumer.NewConsumer("example-group-1", messageLog)
+	consumer1 := consumer.NewConsumer("example-group-1", messageLog, consumer.ReadCommitted)
 
 	// Subscribe to a topic
 	topic := common.Topic("test-topic")
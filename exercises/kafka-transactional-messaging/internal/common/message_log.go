@@ -2,6 +2,9 @@ package common
 
 import (
 	"errors"
+	"fmt"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 )
@@ -16,22 +19,104 @@ type MessageLogEntry struct {
 	IsMarker  bool
 }
 
+var (
+	// ErrDuplicateSequence is returned when appending a message whose
+	// sequence number has already been appended for its producer and
+	// partition
+	ErrDuplicateSequence = errors.New("duplicate sequence number")
+	// ErrProducerFenced is returned for requests from a producer epoch
+	// that a later epoch of the same producer has replaced
+	ErrProducerFenced = errors.New("producer fenced")
+)
+
 // MessageLog represents an in-memory message log
 type MessageLog struct {
 	partitions map[TopicPartition][]*MessageLogEntry
 	offsets    map[TopicPartition]Offset
+	sequences  map[producerPartition]producerSequence
+	markers    map[TopicPartition]int
 	mu         sync.RWMutex
 }
 
+// LogMetrics is a snapshot of the message log's entry counts
+type LogMetrics struct {
+	Partitions map[TopicPartition]PartitionMetrics
+}
+
+// PartitionMetrics counts the entries of a partition
+type PartitionMetrics struct {
+	Messages int
+	Markers  int
+}
+
+// producerPartition identifies the messages of a producer to a partition,
+// which are numbered by a sequence of their own
+type producerPartition struct {
+	producerID string
+	tp         TopicPartition
+}
+
+// producerSequence is the last sequence number appended for a producer
+// and partition, and the producer epoch it was sent with
+type producerSequence struct {
+	epoch    int32
+	sequence int64
+}
+
 // NewMessageLog creates a new message log
 func NewMessageLog() *MessageLog {
 	return &MessageLog{
 		partitions: make(map[TopicPartition][]*MessageLogEntry),
 		offsets:    make(map[TopicPartition]Offset),
+		sequences:  make(map[producerPartition]producerSequence),
+		markers:    make(map[TopicPartition]int),
+	}
+}
+
+// CreateTopic creates the partitions 0 to numPartitions-1 of a topic,
+// leaving any that already exist as they are. Partitions are also created
+// by the first message appended to them.
+func (l *MessageLog) CreateTopic(topic Topic, numPartitions int) error {
+	if numPartitions <= 0 {
+		return fmt.Errorf("invalid partition count %d", numPartitions)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for p := range numPartitions {
+		tp := TopicPartition{Topic: topic, Partition: Partition(p)}
+		if _, exists := l.partitions[tp]; !exists {
+			l.partitions[tp] = make([]*MessageLogEntry, 0)
+			l.offsets[tp] = 0
+		}
+	}
+
+	return nil
+}
+
+// Partitions returns the partitions of a topic in order
+func (l *MessageLog) Partitions(topic Topic) []Partition {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var partitions []Partition
+	for tp := range l.partitions {
+		if tp.Topic == topic {
+			partitions = append(partitions, tp.Partition)
+		}
 	}
+	slices.Sort(partitions)
+
+	return partitions
 }
 
-// Append adds a message to the log
+// Append adds a message to the log. A message from an idempotent producer
+// whose sequence number is not above the last one appended for that
+// producer and partition is a retry of a message already in the log, and
+// is rejected with ErrDuplicateSequence. A message from an earlier epoch of
+// the producer than the last one appended is rejected with
+// ErrProducerFenced, and a later epoch starts a new sequence.
 func (l *MessageLog) Append(topic Topic, partition Partition, msg *Message, txID TransactionID) (Offset, error) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -41,6 +126,21 @@ func (l *MessageLog) Append(topic Topic, partition Partition, msg *Message, txID
 		l.partitions[tp] = make([]*MessageLogEntry, 0)
 	}
 
+	if msg.ProducerID != "" {
+		key := producerPartition{producerID: msg.ProducerID, tp: tp}
+		if last, exists := l.sequences[key]; exists {
+			if msg.ProducerEpoch < last.epoch {
+				return 0, fmt.Errorf("%w: producer %s sent epoch %d to %s after %d",
+					ErrProducerFenced, msg.ProducerID, msg.ProducerEpoch, tp, last.epoch)
+			}
+			if msg.ProducerEpoch == last.epoch && msg.Sequence <= last.sequence {
+				return 0, fmt.Errorf("%w: producer %s sent sequence %d to %s after %d",
+					ErrDuplicateSequence, msg.ProducerID, msg.Sequence, tp, last.sequence)
+			}
+		}
+		l.sequences[key] = producerSequence{epoch: msg.ProducerEpoch, sequence: msg.Sequence}
+	}
+
 	offset := l.offsets[tp]
 	entry := &MessageLogEntry{
 		Message:   msg,
@@ -83,6 +183,7 @@ func (l *MessageLog) AddTransactionMarker(
 
 	l.partitions[tp] = append(l.partitions[tp], entry)
 	l.offsets[tp] = offset + 1
+	l.markers[tp]++
 
 	return nil
 }
@@ -155,6 +256,45 @@ func (l *MessageLog) GetCommittedMessages(
 	return result, nil
 }
 
+// OffsetForTimestamp returns the offset of the first entry in a partition
+// whose timestamp is at or after ts, or the latest offset if there is none.
+// Entries are appended in time order, so this is a binary search.
+func (l *MessageLog) OffsetForTimestamp(topic Topic, partition Partition, ts time.Time) (Offset, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	tp := TopicPartition{Topic: topic, Partition: partition}
+	entries, exists := l.partitions[tp]
+	if !exists {
+		return 0, errors.New("partition not found")
+	}
+
+	i := sort.Search(len(entries), func(i int) bool {
+		return !entries[i].Timestamp.Before(ts)
+	})
+	if i == len(entries) {
+		return l.offsets[tp], nil
+	}
+	return entries[i].Offset, nil
+}
+
+// Metrics returns a snapshot of the number of messages and markers in
+// each partition
+func (l *MessageLog) Metrics() LogMetrics {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	metrics := LogMetrics{Partitions: make(map[TopicPartition]PartitionMetrics, len(l.partitions))}
+	for tp, entries := range l.partitions {
+		metrics.Partitions[tp] = PartitionMetrics{
+			Messages: len(entries) - l.markers[tp],
+			Markers:  l.markers[tp],
+		}
+	}
+
+	return metrics
+}
+
 // GetLatestOffset returns the latest offset for a partition
 func (l *MessageLog) GetLatestOffset(topic Topic, partition Partition) (Offset, error) {
 	l.mu.RLock()
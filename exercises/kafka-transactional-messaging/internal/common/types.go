@@ -36,6 +36,17 @@ type Message struct {
 	Topic     Topic
 	Partition Partition
 	Offset    Offset
+
+	// ProducerID and Sequence identify a message sent by an idempotent
+	// producer, which numbers its messages to each partition from zero.
+	// Messages without a ProducerID are not deduplicated.
+	ProducerID string
+	Sequence   int64
+
+	// ProducerEpoch is the epoch of the producer's transaction. Once a
+	// partition has a message from a later epoch of the producer, messages
+	// from earlier epochs are fenced.
+	ProducerEpoch int32
 }
 
 // TransactionState represents the state of a transaction
@@ -59,6 +70,7 @@ type Transaction struct {
 	ID             TransactionID
 	State          TransactionState
 	ProducerID     string
+	ProducerEpoch  int32
 	Timeout        time.Duration
 	Partitions     []TopicPartition
 	StartTimestamp time.Time
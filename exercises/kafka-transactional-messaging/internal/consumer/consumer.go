@@ -3,145 +3,285 @@ package consumer
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/kumarlokesh/sysd/exercises/kafka-transactional-messaging/internal/common"
 )
 
+// IsolationLevel controls which transactional messages a consumer reads
+type IsolationLevel int
+
+const (
+	// ReadCommitted returns only the messages of committed transactions,
+	// once their commit marker has been read
+	ReadCommitted IsolationLevel = iota
+	// ReadUncommitted returns every message as soon as it is in the log,
+	// including those of open and aborted transactions
+	ReadUncommitted
+)
+
+// String returns a string representation of the isolation level
+func (l IsolationLevel) String() string {
+	switch l {
+	case ReadCommitted:
+		return "read_committed"
+	case ReadUncommitted:
+		return "read_uncommitted"
+	default:
+		return fmt.Sprintf("IsolationLevel(%d)", int(l))
+	}
+}
+
 // Consumer represents a transactional message consumer
 type Consumer struct {
 	groupID    string
 	messageLog *common.MessageLog
+	isolation  IsolationLevel
+	store      OffsetStore
 	offsets    map[common.TopicPartition]common.Offset
+	pending    map[common.TopicPartition]*pendingMessages
 	offsetsMux sync.RWMutex
 }
 
-// NewConsumer creates a new transactional consumer
-func NewConsumer(groupID string, messageLog *common.MessageLog) *Consumer {
+// pendingMessages holds the messages of a partition that have been read
+// from the log under ReadCommitted but not yet returned by Poll. They are
+// kept across polls, since a transaction's marker can be read in a later
+// poll than its messages.
+type pendingMessages struct {
+	// open holds the messages of transactions whose marker hasn't been
+	// read yet, in log order
+	open map[common.TransactionID][]*common.MessageLogEntry
+
+	// ready holds the messages of committed transactions that didn't fit
+	// in the poll that read the commit marker
+	ready []*common.MessageLogEntry
+}
+
+// NewConsumer creates a new transactional consumer reading with the given
+// isolation level, which keeps its committed offsets in memory
+func NewConsumer(groupID string, messageLog *common.MessageLog, isolation IsolationLevel) *Consumer {
+	return NewConsumerWithOffsetStore(groupID, messageLog, isolation, NewMemoryOffsetStore())
+}
+
+// NewConsumerWithOffsetStore creates a new transactional consumer that
+// commits its offsets to store, and starts reading each partition it
+// subscribes to from the offset its group last committed there
+func NewConsumerWithOffsetStore(groupID string, messageLog *common.MessageLog, isolation IsolationLevel, store OffsetStore) *Consumer {
 	return &Consumer{
 		groupID:    groupID,
 		messageLog: messageLog,
+		isolation:  isolation,
+		store:      store,
 		offsets:    make(map[common.TopicPartition]common.Offset),
+		pending:    make(map[common.TopicPartition]*pendingMessages),
 	}
 }
 
-// Subscribe sets the consumer to read from the specified topic and partition
+// Subscribe sets the consumer to read from the specified topic and
+// partition, starting from the group's committed offset if it has one
 func (c *Consumer) Subscribe(topic common.Topic, partition common.Partition) error {
 	tp := common.TopicPartition{Topic: topic, Partition: partition}
 	c.offsetsMux.Lock()
 	defer c.offsetsMux.Unlock()
 
-	// Initialize offset to 0 if not set
-	if _, exists := c.offsets[tp]; !exists {
-		c.offsets[tp] = 0
+	return c.subscribe(tp)
+}
+
+// subscribe starts reading a partition from the group's committed offset,
+// or from 0 if it hasn't committed one. The caller must hold offsetsMux.
+func (c *Consumer) subscribe(tp common.TopicPartition) error {
+	if _, exists := c.offsets[tp]; exists {
+		return nil
+	}
+
+	offset, _, err := c.store.Fetch(c.groupID, tp)
+	if err != nil {
+		return fmt.Errorf("failed to fetch committed offset for %s: %w", tp, err)
 	}
+	c.offsets[tp] = offset
 
 	return nil
 }
 
-// Poll fetches messages from the subscribed partitions
+// assign replaces the consumer's subscriptions with partitions. The
+// offsets of partitions no longer assigned are committed first, so that
+// the member they are assigned to resumes where this one stopped.
+func (c *Consumer) assign(partitions []common.TopicPartition) error {
+	c.offsetsMux.Lock()
+	defer c.offsetsMux.Unlock()
+
+	revoked := make(map[common.TopicPartition]common.Offset)
+	for tp := range c.offsets {
+		if !slices.Contains(partitions, tp) {
+			revoked[tp] = c.committableOffset(tp)
+		}
+	}
+	if len(revoked) > 0 {
+		if err := c.store.Commit(c.groupID, revoked); err != nil {
+			return fmt.Errorf("failed to commit revoked offsets: %w", err)
+		}
+	}
+	for tp := range revoked {
+		delete(c.offsets, tp)
+		delete(c.pending, tp)
+	}
+
+	for _, tp := range partitions {
+		if err := c.subscribe(tp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Poll fetches up to maxMessages messages from the subscribed partitions.
+// Under ReadCommitted, messages are returned once their transaction's
+// commit marker has been read and dropped once its abort marker has been
+// read.
 func (c *Consumer) Poll(maxMessages int) ([]*common.Message, error) {
-	c.offsetsMux.RLock()
-	defer c.offsetsMux.RUnlock()
+	c.offsetsMux.Lock()
+	defer c.offsetsMux.Unlock()
 
 	var messages []*common.Message
 
 	// Process each subscribed partition
-	for tp, offset := range c.offsets {
-		// Get all messages (including transaction markers) from the current offset
-		// Use a larger batch size to ensure we get enough messages after filtering
-		batchSize := maxMessages * 10
-		entries, err := c.messageLog.GetMessages(
-			tp.Topic,
-			tp.Partition,
-			offset,
-			batchSize,
-		)
-
+	for tp := range c.offsets {
+		var err error
+		messages, err = c.pollPartition(tp, messages, maxMessages)
 		if err != nil {
-			return nil, fmt.Errorf("error fetching messages from %s: %w", tp, err)
+			return nil, err
 		}
 
-		// If no entries, continue to next partition
-		if len(entries) == 0 {
-			continue
+		// If we've collected enough messages, stop processing partitions
+		if len(messages) >= maxMessages {
+			break
 		}
+	}
 
-		// Track the highest offset we've seen
-		lastOffset := entries[len(entries)-1].Offset + 1
+	return messages, nil
+}
+
+// pollPartition appends messages from a partition to messages until it
+// holds maxMessages or the end of the partition is reached. The offset is
+// advanced past every log entry processed, so nothing is skipped when a
+// poll stops partway through a batch. The caller must hold offsetsMux.
+func (c *Consumer) pollPartition(tp common.TopicPartition, messages []*common.Message, maxMessages int) ([]*common.Message, error) {
+	pending := c.pending[tp]
+	if pending == nil {
+		pending = &pendingMessages{open: make(map[common.TransactionID][]*common.MessageLogEntry)}
+		c.pending[tp] = pending
+	}
 
-		// Process entries to filter out messages from aborted transactions
-		txStates := make(map[common.TransactionID]common.TransactionState)
-		var pendingMessages []*common.MessageLogEntry
+	for len(messages) < maxMessages {
+		// Return messages of committed transactions left over from earlier
+		if len(pending.ready) > 0 {
+			n := min(len(pending.ready), maxMessages-len(messages))
+			for _, entry := range pending.ready[:n] {
+				messages = append(messages, entry.Message)
+			}
+			pending.ready = pending.ready[n:]
+			continue
+		}
+
+		// Get messages (including transaction markers) from the current
+		// offset, in batches larger than needed since markers and messages
+		// of aborted transactions are filtered out
+		entries, err := c.messageLog.GetMessages(tp.Topic, tp.Partition, c.offsets[tp], maxMessages*10)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching messages from %s: %w", tp, err)
+		}
+		if len(entries) == 0 {
+			break
+		}
 
 		for _, entry := range entries {
-			// Update transaction state if this is a marker
-			if entry.IsMarker {
-				txStates[entry.TxID] = entry.TxState
-				// Process any pending messages for this transaction
-				for _, pending := range pendingMessages {
-					if pending.TxID == entry.TxID && entry.TxState == common.TransactionStateCommitted {
-						messages = append(messages, pending.Message)
-					}
-				}
-				// Clear processed pending messages
-				var newPending []*common.MessageLogEntry
-				for _, pending := range pendingMessages {
-					if pending.TxID != entry.TxID {
-						newPending = append(newPending, pending)
-					}
+			c.offsets[tp] = entry.Offset + 1
+
+			if c.isolation == ReadUncommitted {
+				if !entry.IsMarker {
+					messages = append(messages, entry.Message)
 				}
-				pendingMessages = newPending
 			} else {
-				// For regular messages, check if we know the transaction state
-				if state, exists := txStates[entry.TxID]; exists {
-					if state == common.TransactionStateCommitted {
-						messages = append(messages, entry.Message)
-					}
-				} else {
-					// If we don't know the state yet, queue it
-					pendingMessages = append(pendingMessages, entry)
-				}
+				pending.add(entry)
 			}
 
-			// Stop if we've collected enough messages
-			if len(messages) >= maxMessages {
+			// Stop once enough messages are available for this poll
+			if len(messages)+len(pending.ready) >= maxMessages {
 				break
 			}
 		}
-
-		// Update the offset to the last processed entry
-		c.offsets[tp] = lastOffset
-
-		// If we've collected enough messages, stop processing partitions
-		if len(messages) >= maxMessages {
-			break
-		}
-	}
-
-	// Return only the requested number of messages
-	if len(messages) > maxMessages {
-		messages = messages[:maxMessages]
 	}
 
 	return messages, nil
 }
 
-// CommitOffsets commits the current offsets for all subscribed partitions
+// add processes a log entry read under ReadCommitted, moving the messages
+// of a transaction to ready when its commit marker is read and dropping
+// them when its abort marker is read
+func (p *pendingMessages) add(entry *common.MessageLogEntry) {
+	switch {
+	case !entry.IsMarker && entry.TxID == "":
+		// Messages outside a transaction are visible immediately
+		p.ready = append(p.ready, entry)
+	case !entry.IsMarker:
+		p.open[entry.TxID] = append(p.open[entry.TxID], entry)
+	case entry.TxState == common.TransactionStateCommitted:
+		p.ready = append(p.ready, p.open[entry.TxID]...)
+		delete(p.open, entry.TxID)
+	case entry.TxState == common.TransactionStateAborted:
+		delete(p.open, entry.TxID)
+	}
+}
+
+// CommitOffsets commits the offsets of all subscribed partitions to the
+// offset store and returns them. The offset committed for a partition is
+// that of the earliest message read but not yet returned by Poll, so a
+// consumer resuming from it doesn't miss messages held back for an open
+// transaction, though it may see again messages returned after them.
 func (c *Consumer) CommitOffsets() (map[common.TopicPartition]common.Offset, error) {
 	c.offsetsMux.RLock()
 	defer c.offsetsMux.RUnlock()
 
-	// In a real implementation, this would persist the offsets
-	// For this PoC, we just return the current offsets
 	offsets := make(map[common.TopicPartition]common.Offset, len(c.offsets))
-	for tp, offset := range c.offsets {
-		offsets[tp] = offset
+	for tp := range c.offsets {
+		offsets[tp] = c.committableOffset(tp)
+	}
+
+	if err := c.store.Commit(c.groupID, offsets); err != nil {
+		return nil, fmt.Errorf("failed to commit offsets: %w", err)
 	}
 
 	return offsets, nil
 }
 
+// committableOffset returns the offset to commit for a partition. The
+// caller must hold offsetsMux.
+func (c *Consumer) committableOffset(tp common.TopicPartition) common.Offset {
+	offset := c.offsets[tp]
+	if pending := c.pending[tp]; pending != nil {
+		offset = pending.earliestOffset(offset)
+	}
+	return offset
+}
+
+// earliestOffset returns the offset of the earliest pending message, or
+// def if there are none
+func (p *pendingMessages) earliestOffset(def common.Offset) common.Offset {
+	earliest := def
+	for _, entry := range p.ready {
+		earliest = min(earliest, entry.Offset)
+	}
+	for _, entries := range p.open {
+		if len(entries) > 0 {
+			earliest = min(earliest, entries[0].Offset)
+		}
+	}
+	return earliest
+}
+
 // Seek sets the offset for a specific partition
 func (c *Consumer) Seek(topic common.Topic, partition common.Partition, offset common.Offset) error {
 	tp := common.TopicPartition{Topic: topic, Partition: partition}
@@ -159,10 +299,24 @@ func (c *Consumer) Seek(topic common.Topic, partition common.Partition, offset c
 	c.offsetsMux.Lock()
 	defer c.offsetsMux.Unlock()
 
+	// Messages read before the seek are no longer pending
 	c.offsets[tp] = offset
+	delete(c.pending, tp)
 	return nil
 }
 
+// SeekToTimestamp sets the offset for a specific partition to that of the
+// first log entry at or after ts, or to the end of the partition if every
+// entry is older
+func (c *Consumer) SeekToTimestamp(topic common.Topic, partition common.Partition, ts time.Time) error {
+	offset, err := c.messageLog.OffsetForTimestamp(topic, partition, ts)
+	if err != nil {
+		return fmt.Errorf("failed to get offset for timestamp: %w", err)
+	}
+
+	return c.Seek(topic, partition, offset)
+}
+
 // GetCommittedOffset returns the current committed offset for a partition
 func (c *Consumer) GetCommittedOffset(topic common.Topic, partition common.Partition) (common.Offset, error) {
 	tp := common.TopicPartition{Topic: topic, Partition: partition}
@@ -1,16 +1,20 @@
 package consumer_test
 
 import (
+	"fmt"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/kumarlokesh/sysd/exercises/kafka-transactional-messaging/internal/common"
 	"github.com/kumarlokesh/sysd/exercises/kafka-transactional-messaging/internal/consumer"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestConsumer_SubscribeAndPoll(t *testing.T) {
 	messageLog := common.NewMessageLog()
-	cons := consumer.NewConsumer("test-group", messageLog)
+	cons := consumer.NewConsumer("test-group", messageLog, consumer.ReadCommitted)
 
 	// Add some messages to the log directly first
 	topic := common.Topic("test-topic")
@@ -46,7 +50,7 @@ func TestConsumer_SubscribeAndPoll(t *testing.T) {
 
 func TestConsumer_FiltersAbortedTransactions(t *testing.T) {
 	messageLog := common.NewMessageLog()
-	consumer := consumer.NewConsumer("test-group", messageLog)
+	consumer := consumer.NewConsumer("test-group", messageLog, consumer.ReadCommitted)
 
 	// Subscribe to a topic
 	_ = consumer.Subscribe("test-topic", 0)
@@ -77,7 +81,7 @@ func TestConsumer_FiltersAbortedTransactions(t *testing.T) {
 
 func TestConsumer_SeeksToOffset(t *testing.T) {
 	messageLog := common.NewMessageLog()
-	consumer := consumer.NewConsumer("test-group", messageLog)
+	consumer := consumer.NewConsumer("test-group", messageLog, consumer.ReadCommitted)
 
 	// Add some messages to the log
 	topic := common.Topic("test-topic")
@@ -109,3 +113,293 @@ func TestConsumer_SeeksToOffset(t *testing.T) {
 	assert.Equal(t, "1", string(messages[0].Value))
 	assert.Equal(t, "2", string(messages[1].Value))
 }
+
+func TestConsumer_IsolationLevels(t *testing.T) {
+	topic := common.Topic("test-topic")
+	partition := common.Partition(0)
+
+	// Both transactions span two polls: each has a message before the first
+	// poll, and another message and its marker before the second
+	send := func(log *common.MessageLog, txID common.TransactionID, value string) {
+		_, err := log.Append(topic, partition,
+			&common.Message{Value: []byte(value), Topic: topic, Partition: partition}, txID)
+		require.NoError(t, err)
+	}
+	values := func(messages []*common.Message) []string {
+		result := make([]string, 0, len(messages))
+		for _, msg := range messages {
+			result = append(result, string(msg.Value))
+		}
+		return result
+	}
+
+	tests := []struct {
+		name       string
+		isolation  consumer.IsolationLevel
+		firstPoll  []string
+		secondPoll []string
+	}{
+		{"read committed", consumer.ReadCommitted, []string{}, []string{"committed1", "committed2"}},
+		{"read uncommitted", consumer.ReadUncommitted, []string{"committed1", "aborted1"}, []string{"committed2", "aborted2"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			messageLog := common.NewMessageLog()
+			cons := consumer.NewConsumer("test-group", messageLog, tc.isolation)
+			require.NoError(t, cons.Subscribe(topic, partition))
+
+			send(messageLog, "tx-committed", "committed1")
+			send(messageLog, "tx-aborted", "aborted1")
+
+			messages, err := cons.Poll(10)
+			require.NoError(t, err)
+			assert.Equal(t, tc.firstPoll, values(messages))
+
+			send(messageLog, "tx-committed", "committed2")
+			send(messageLog, "tx-aborted", "aborted2")
+			require.NoError(t, messageLog.AddTransactionMarker(topic, partition, "tx-aborted", common.TransactionStateAborted))
+			require.NoError(t, messageLog.AddTransactionMarker(topic, partition, "tx-committed", common.TransactionStateCommitted))
+
+			messages, err = cons.Poll(10)
+			require.NoError(t, err)
+			assert.Equal(t, tc.secondPoll, values(messages))
+
+			messages, err = cons.Poll(10)
+			require.NoError(t, err)
+			assert.Empty(t, messages)
+		})
+	}
+
+	t.Run("read committed carries released messages across polls", func(t *testing.T) {
+		messageLog := common.NewMessageLog()
+		cons := consumer.NewConsumer("test-group", messageLog, consumer.ReadCommitted)
+		require.NoError(t, cons.Subscribe(topic, partition))
+
+		for _, value := range []string{"first", "second", "third"} {
+			send(messageLog, "tx1", value)
+		}
+		require.NoError(t, messageLog.AddTransactionMarker(topic, partition, "tx1", common.TransactionStateCommitted))
+
+		var got []string
+		for i := 0; i < 3; i++ {
+			messages, err := cons.Poll(1)
+			require.NoError(t, err)
+			require.Len(t, messages, 1)
+			got = append(got, string(messages[0].Value))
+		}
+		assert.Equal(t, []string{"first", "second", "third"}, got)
+	})
+}
+
+func TestConsumer_ResumesFromCommittedOffset(t *testing.T) {
+	messageLog := common.NewMessageLog()
+	topic := common.Topic("test-topic")
+	partition := common.Partition(0)
+	path := filepath.Join(t.TempDir(), "offsets.json")
+
+	appendCommitted := func(txID common.TransactionID, values ...string) {
+		for _, v := range values {
+			_, err := messageLog.Append(topic, partition,
+				&common.Message{Value: []byte(v), Topic: topic, Partition: partition}, txID)
+			require.NoError(t, err)
+		}
+		require.NoError(t, messageLog.AddTransactionMarker(topic, partition, txID, common.TransactionStateCommitted))
+	}
+	values := func(messages []*common.Message) []string {
+		var vs []string
+		for _, m := range messages {
+			vs = append(vs, string(m.Value))
+		}
+		return vs
+	}
+
+	appendCommitted("tx1", "m1", "m2")
+	appendCommitted("tx2", "m3", "m4", "m5")
+
+	store, err := consumer.NewFileOffsetStore(path)
+	require.NoError(t, err)
+	cons := consumer.NewConsumerWithOffsetStore("test-group", messageLog, consumer.ReadCommitted, store)
+	require.NoError(t, cons.Subscribe(topic, partition))
+
+	// Stop partway through tx2, leaving m5 pending
+	messages, err := cons.Poll(4)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"m1", "m2", "m3", "m4"}, values(messages))
+
+	offsets, err := cons.CommitOffsets()
+	require.NoError(t, err)
+	assert.Equal(t, common.Offset(5), offsets[common.TopicPartition{Topic: topic, Partition: partition}],
+		"commit should stop at the first message not yet returned")
+	require.NoError(t, cons.Close())
+	require.NoError(t, store.Close())
+
+	t.Run("Same group resumes", func(t *testing.T) {
+		store, err := consumer.NewFileOffsetStore(path)
+		require.NoError(t, err)
+		defer store.Close()
+
+		cons := consumer.NewConsumerWithOffsetStore("test-group", messageLog, consumer.ReadCommitted, store)
+		require.NoError(t, cons.Subscribe(topic, partition))
+
+		messages, err := cons.Poll(10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"m5"}, values(messages))
+	})
+
+	t.Run("Other group starts from the beginning", func(t *testing.T) {
+		store, err := consumer.NewFileOffsetStore(path)
+		require.NoError(t, err)
+		defer store.Close()
+
+		cons := consumer.NewConsumerWithOffsetStore("other-group", messageLog, consumer.ReadCommitted, store)
+		require.NoError(t, cons.Subscribe(topic, partition))
+
+		messages, err := cons.Poll(10)
+		require.NoError(t, err)
+		assert.Len(t, messages, 5)
+	})
+}
+
+func TestConsumer_SeekToTimestamp(t *testing.T) {
+	messageLog := common.NewMessageLog()
+	topic := common.Topic("test-topic")
+	partition := common.Partition(0)
+
+	appendMessages := func(values ...string) {
+		for _, v := range values {
+			_, err := messageLog.Append(topic, partition,
+				&common.Message{Value: []byte(v), Topic: topic, Partition: partition}, "")
+			require.NoError(t, err)
+		}
+	}
+
+	before := time.Now()
+	time.Sleep(time.Millisecond)
+	appendMessages("m0", "m1")
+	time.Sleep(time.Millisecond)
+	between := time.Now()
+	time.Sleep(time.Millisecond)
+	appendMessages("m2", "m3")
+	time.Sleep(time.Millisecond)
+	after := time.Now()
+
+	testCases := []struct {
+		name     string
+		ts       time.Time
+		offset   common.Offset
+		expected []string
+	}{
+		{"Before all messages", before, 0, []string{"m0", "m1", "m2", "m3"}},
+		{"Between messages", between, 2, []string{"m2", "m3"}},
+		{"After all messages", after, 4, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			offset, err := messageLog.OffsetForTimestamp(topic, partition, tc.ts)
+			require.NoError(t, err)
+			assert.Equal(t, tc.offset, offset)
+
+			cons := consumer.NewConsumer("test-group", messageLog, consumer.ReadCommitted)
+			require.NoError(t, cons.Subscribe(topic, partition))
+			_, err = cons.Poll(10)
+			require.NoError(t, err)
+
+			require.NoError(t, cons.SeekToTimestamp(topic, partition, tc.ts))
+			position, err := cons.GetCommittedOffset(topic, partition)
+			require.NoError(t, err)
+			assert.Equal(t, tc.offset, position)
+
+			messages, err := cons.Poll(10)
+			require.NoError(t, err)
+			var values []string
+			for _, m := range messages {
+				values = append(values, string(m.Value))
+			}
+			assert.Equal(t, tc.expected, values)
+		})
+	}
+
+	cons := consumer.NewConsumer("test-group", messageLog, consumer.ReadCommitted)
+	err := cons.SeekToTimestamp("missing-topic", 0, before)
+	assert.Error(t, err)
+}
+
+func TestGroupCoordinator_AssignsPartitions(t *testing.T) {
+	messageLog := common.NewMessageLog()
+	topic := common.Topic("orders")
+	require.NoError(t, messageLog.CreateTopic(topic, 4))
+
+	appendMessage := func(partition common.Partition, value string) {
+		_, err := messageLog.Append(topic, partition,
+			&common.Message{Value: []byte(value), Topic: topic, Partition: partition}, "")
+		require.NoError(t, err)
+	}
+	poll := func(cons *consumer.Consumer) map[common.Partition][]string {
+		messages, err := cons.Poll(100)
+		require.NoError(t, err)
+		polled := make(map[common.Partition][]string)
+		for _, m := range messages {
+			polled[m.Partition] = append(polled[m.Partition], string(m.Value))
+		}
+		return polled
+	}
+	for p := range common.Partition(4) {
+		appendMessage(p, fmt.Sprintf("p%d-0", p))
+	}
+
+	// Members of a group share an offset store to hand partitions over
+	store := consumer.NewMemoryOffsetStore()
+	groups := consumer.NewGroupCoordinator(messageLog)
+	cons1 := consumer.NewConsumerWithOffsetStore("test-group", messageLog, consumer.ReadCommitted, store)
+	cons2 := consumer.NewConsumerWithOffsetStore("test-group", messageLog, consumer.ReadCommitted, store)
+
+	require.NoError(t, groups.Join(cons1, topic))
+	assignment1, err := groups.Assignment(cons1)
+	require.NoError(t, err)
+	assert.Len(t, assignment1, 4, "a lone member should be assigned every partition")
+	assert.ErrorIs(t, groups.Join(cons1, topic), consumer.ErrAlreadyMember)
+
+	require.NoError(t, groups.Join(cons2, topic))
+	assignment1, err = groups.Assignment(cons1)
+	require.NoError(t, err)
+	assignment2, err := groups.Assignment(cons2)
+	require.NoError(t, err)
+	assert.Len(t, assignment1, 2)
+	assert.Len(t, assignment2, 2)
+	for _, tp := range assignment1 {
+		assert.NotContains(t, assignment2, tp, "assignments should not overlap")
+	}
+	assert.ElementsMatch(t, []common.TopicPartition{
+		{Topic: topic, Partition: 0}, {Topic: topic, Partition: 1},
+		{Topic: topic, Partition: 2}, {Topic: topic, Partition: 3},
+	}, append(assignment1, assignment2...))
+
+	// Each member reads only its own partitions
+	polled1, polled2 := poll(cons1), poll(cons2)
+	for _, tp := range assignment1 {
+		assert.Equal(t, []string{fmt.Sprintf("p%d-0", tp.Partition)}, polled1[tp.Partition])
+		assert.NotContains(t, polled2, tp.Partition)
+	}
+	for _, tp := range assignment2 {
+		assert.Equal(t, []string{fmt.Sprintf("p%d-0", tp.Partition)}, polled2[tp.Partition])
+	}
+
+	// When a member leaves, the rest take over its partitions from where
+	// it stopped
+	require.NoError(t, groups.Leave(cons2))
+	_, err = groups.Assignment(cons2)
+	assert.ErrorIs(t, err, consumer.ErrNotMember)
+	assignment1, err = groups.Assignment(cons1)
+	require.NoError(t, err)
+	assert.Len(t, assignment1, 4)
+
+	for p := range common.Partition(4) {
+		appendMessage(p, fmt.Sprintf("p%d-1", p))
+	}
+	polled1 = poll(cons1)
+	for p := range common.Partition(4) {
+		assert.Equal(t, []string{fmt.Sprintf("p%d-1", p)}, polled1[p], "partition %d", p)
+	}
+}
@@ -0,0 +1,206 @@
+package consumer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/kumarlokesh/sysd/exercises/kafka-transactional-messaging/internal/common"
+)
+
+var (
+	// ErrAlreadyMember is returned when a consumer joins a group it is
+	// already a member of
+	ErrAlreadyMember = errors.New("consumer is already a group member")
+	// ErrNotMember is returned for a consumer that isn't a group member
+	ErrNotMember = errors.New("consumer is not a group member")
+	// ErrNoTopics is returned when a consumer joins without any topics
+	ErrNoTopics = errors.New("no topics provided")
+)
+
+// GroupCoordinator manages the membership of consumer groups and assigns
+// the partitions of the topics a group reads among its members, so that
+// each partition is read by exactly one member. The partitions are
+// reassigned whenever a member joins or leaves.
+//
+// Members hand partitions over through their offset store, so the members
+// of a group should share one.
+type GroupCoordinator struct {
+	messageLog *common.MessageLog
+	groups     map[string]*group
+	mu         sync.Mutex
+}
+
+// group is the membership of a consumer group, in join order
+type group struct {
+	members []*member
+}
+
+// member is a consumer in a group and the topics it reads
+type member struct {
+	consumer   *Consumer
+	topics     []common.Topic
+	assignment []common.TopicPartition
+}
+
+// NewGroupCoordinator creates a group coordinator assigning the partitions
+// of messageLog
+func NewGroupCoordinator(messageLog *common.MessageLog) *GroupCoordinator {
+	return &GroupCoordinator{
+		messageLog: messageLog,
+		groups:     make(map[string]*group),
+	}
+}
+
+// Join adds a consumer to its group, reading the given topics, and
+// rebalances the group
+func (g *GroupCoordinator) Join(c *Consumer, topics ...common.Topic) error {
+	if len(topics) == 0 {
+		return ErrNoTopics
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	grp := g.groups[c.groupID]
+	if grp == nil {
+		grp = &group{}
+		g.groups[c.groupID] = grp
+	}
+	if grp.find(c) != nil {
+		return fmt.Errorf("%w: %s", ErrAlreadyMember, c.groupID)
+	}
+
+	grp.members = append(grp.members, &member{consumer: c, topics: slices.Clone(topics)})
+	return g.rebalance(grp)
+}
+
+// Leave removes a consumer from its group, committing the offsets of its
+// partitions, and rebalances the group
+func (g *GroupCoordinator) Leave(c *Consumer) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	grp := g.groups[c.groupID]
+	if grp == nil || grp.find(c) == nil {
+		return fmt.Errorf("%w: %s", ErrNotMember, c.groupID)
+	}
+
+	if err := c.assign(nil); err != nil {
+		return err
+	}
+	grp.members = slices.DeleteFunc(grp.members, func(m *member) bool {
+		return m.consumer == c
+	})
+	if len(grp.members) == 0 {
+		delete(g.groups, c.groupID)
+		return nil
+	}
+
+	return g.rebalance(grp)
+}
+
+// Rebalance reassigns the partitions of a group, picking up partitions
+// created since its last rebalance
+func (g *GroupCoordinator) Rebalance(groupID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	grp := g.groups[groupID]
+	if grp == nil {
+		return nil
+	}
+
+	return g.rebalance(grp)
+}
+
+// Assignment returns the partitions assigned to a consumer
+func (g *GroupCoordinator) Assignment(c *Consumer) ([]common.TopicPartition, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var m *member
+	if grp := g.groups[c.groupID]; grp != nil {
+		m = grp.find(c)
+	}
+	if m == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotMember, c.groupID)
+	}
+
+	return slices.Clone(m.assignment), nil
+}
+
+// rebalance assigns each topic's partitions to the members reading it in
+// contiguous ranges, the earlier members taking one extra partition when
+// they don't divide evenly. Partitions are revoked from every member
+// before any is assigned to its new owner, so that the offsets the new
+// owner starts from have been committed. The caller must hold mu.
+func (g *GroupCoordinator) rebalance(grp *group) error {
+	assignments := make(map[*member][]common.TopicPartition, len(grp.members))
+
+	var topics []common.Topic
+	for _, m := range grp.members {
+		for _, topic := range m.topics {
+			if !slices.Contains(topics, topic) {
+				topics = append(topics, topic)
+			}
+		}
+	}
+
+	for _, topic := range topics {
+		var readers []*member
+		for _, m := range grp.members {
+			if slices.Contains(m.topics, topic) {
+				readers = append(readers, m)
+			}
+		}
+
+		partitions := g.messageLog.Partitions(topic)
+		per, extra := len(partitions)/len(readers), len(partitions)%len(readers)
+		start := 0
+		for i, m := range readers {
+			n := per
+			if i < extra {
+				n++
+			}
+			for _, p := range partitions[start : start+n] {
+				assignments[m] = append(assignments[m], common.TopicPartition{Topic: topic, Partition: p})
+			}
+			start += n
+		}
+	}
+
+	// Revoke partitions moving to another member
+	for _, m := range grp.members {
+		var kept []common.TopicPartition
+		for _, tp := range m.assignment {
+			if slices.Contains(assignments[m], tp) {
+				kept = append(kept, tp)
+			}
+		}
+		if err := m.consumer.assign(kept); err != nil {
+			return err
+		}
+		m.assignment = kept
+	}
+
+	for _, m := range grp.members {
+		if err := m.consumer.assign(assignments[m]); err != nil {
+			return err
+		}
+		m.assignment = assignments[m]
+	}
+
+	return nil
+}
+
+// find returns the member for a consumer, or nil if it isn't a member
+func (grp *group) find(c *Consumer) *member {
+	for _, m := range grp.members {
+		if m.consumer == c {
+			return m
+		}
+	}
+	return nil
+}
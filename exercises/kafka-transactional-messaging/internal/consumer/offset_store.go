@@ -0,0 +1,173 @@
+package consumer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/kumarlokesh/sysd/exercises/kafka-transactional-messaging/internal/common"
+)
+
+// OffsetStore records the offsets committed by consumer groups, so that a
+// consumer can resume where its group left off
+type OffsetStore interface {
+	// Commit records offsets for a group. It must not return until the
+	// offsets are durable.
+	Commit(groupID string, offsets map[common.TopicPartition]common.Offset) error
+
+	// Fetch returns the offset last committed by a group for a partition,
+	// and whether one has been committed
+	Fetch(groupID string, tp common.TopicPartition) (common.Offset, bool, error)
+
+	// Close releases the store's resources
+	Close() error
+}
+
+// groupPartition identifies a partition read by a consumer group
+type groupPartition struct {
+	groupID string
+	tp      common.TopicPartition
+}
+
+// MemoryOffsetStore is an OffsetStore that keeps offsets in memory, so
+// they are shared by consumers created with it but lost with the process
+type MemoryOffsetStore struct {
+	offsets map[groupPartition]common.Offset
+	mu      sync.RWMutex
+}
+
+// NewMemoryOffsetStore creates an empty in-memory offset store
+func NewMemoryOffsetStore() *MemoryOffsetStore {
+	return &MemoryOffsetStore{offsets: make(map[groupPartition]common.Offset)}
+}
+
+// Commit records offsets for a group
+func (s *MemoryOffsetStore) Commit(groupID string, offsets map[common.TopicPartition]common.Offset) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for tp, offset := range offsets {
+		s.offsets[groupPartition{groupID: groupID, tp: tp}] = offset
+	}
+	return nil
+}
+
+// Fetch returns the offset last committed by a group for a partition
+func (s *MemoryOffsetStore) Fetch(groupID string, tp common.TopicPartition) (common.Offset, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	offset, exists := s.offsets[groupPartition{groupID: groupID, tp: tp}]
+	return offset, exists, nil
+}
+
+// Close does nothing for the in-memory store
+func (s *MemoryOffsetStore) Close() error {
+	return nil
+}
+
+// committedOffset is a committed offset, as stored in a FileOffsetStore
+type committedOffset struct {
+	GroupID   string           `json:"group_id"`
+	Topic     common.Topic     `json:"topic"`
+	Partition common.Partition `json:"partition"`
+	Offset    common.Offset    `json:"offset"`
+}
+
+// FileOffsetStore is an OffsetStore that keeps offsets in a JSON file. The
+// file is rewritten on each commit by writing a temporary file and
+// renaming it into place, so a crash leaves either the old or new offsets.
+type FileOffsetStore struct {
+	path string
+	mem  *MemoryOffsetStore
+	mu   sync.Mutex
+}
+
+// NewFileOffsetStore opens the offset file at path, loading the offsets
+// committed to it. The file is created on the first commit.
+func NewFileOffsetStore(path string) (*FileOffsetStore, error) {
+	s := &FileOffsetStore{path: path, mem: NewMemoryOffsetStore()}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read offset file: %w", err)
+	}
+
+	var committed []committedOffset
+	if err := json.Unmarshal(data, &committed); err != nil {
+		return nil, fmt.Errorf("failed to decode offset file: %w", err)
+	}
+	for _, c := range committed {
+		tp := common.TopicPartition{Topic: c.Topic, Partition: c.Partition}
+		s.mem.offsets[groupPartition{groupID: c.GroupID, tp: tp}] = c.Offset
+	}
+
+	return s, nil
+}
+
+// Commit records offsets for a group and writes every committed offset to
+// the file
+func (s *FileOffsetStore) Commit(groupID string, offsets map[common.TopicPartition]common.Offset) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.mem.Commit(groupID, offsets); err != nil {
+		return err
+	}
+
+	committed := make([]committedOffset, 0, len(s.mem.offsets))
+	for gp, offset := range s.mem.offsets {
+		committed = append(committed, committedOffset{
+			GroupID:   gp.groupID,
+			Topic:     gp.tp.Topic,
+			Partition: gp.tp.Partition,
+			Offset:    offset,
+		})
+	}
+	data, err := json.Marshal(committed)
+	if err != nil {
+		return fmt.Errorf("failed to encode offsets: %w", err)
+	}
+
+	return writeFileAtomic(s.path, data)
+}
+
+// writeFileAtomic replaces the file at path with data, syncing it before
+// renaming it into place
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create offset file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write offset file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync offset file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close offset file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace offset file: %w", err)
+	}
+	return nil
+}
+
+// Fetch returns the offset last committed by a group for a partition
+func (s *FileOffsetStore) Fetch(groupID string, tp common.TopicPartition) (common.Offset, bool, error) {
+	return s.mem.Fetch(groupID, tp)
+}
+
+// Close does nothing, since the file is only open during a commit
+func (s *FileOffsetStore) Close() error {
+	return nil
+}
@@ -3,6 +3,7 @@ package coordinator
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -20,22 +21,146 @@ var (
 	ErrInvalidTimeout = errors.New("invalid timeout value")
 	// ErrNoPartitions is returned when no partitions are provided
 	ErrNoPartitions = errors.New("no partitions provided")
+	// ErrInvalidInterval is returned for invalid reaper intervals
+	ErrInvalidInterval = errors.New("invalid interval value")
+	// ErrReaperRunning is returned when starting a reaper that is already running
+	ErrReaperRunning = errors.New("reaper already running")
+	// ErrProducerFenced is returned for requests from a stale producer epoch
+	ErrProducerFenced = common.ErrProducerFenced
 )
 
-// Coordinator manages the lifecycle of transactions
+// Coordinator manages the lifecycle of transactions. Every state
+// transition is recorded in its TransactionStore before it takes effect.
+//
+// Each transaction a producer begins is given a new epoch for the
+// producer, fencing off earlier instances of the producer that are still
+// running: their requests carry a stale epoch and are rejected with
+// ErrProducerFenced.
 type Coordinator struct {
 	transactions map[common.TransactionID]*common.Transaction
+	epochs       map[string]int32
+	store        TransactionStore
+	metrics      CoordinatorMetrics
 	mu           sync.RWMutex
+
+	// stopReaper and reaperDone control the background expiration
+	// cleanup, and are nil when it isn't running
+	stopReaper chan struct{}
+	reaperDone chan struct{}
+	reaperMu   sync.Mutex
+}
+
+// CoordinatorMetrics is a snapshot of the coordinator's transaction counts
+type CoordinatorMetrics struct {
+	// ActiveTransactions is the number of transactions begun and not yet
+	// committed, aborted or expired
+	ActiveTransactions int
+	// CommittedTotal is the number of transactions committed
+	CommittedTotal int64
+	// AbortedTotal is the number of transactions aborted by AbortTransaction
+	AbortedTotal int64
+	// ExpiredTotal is the number of transactions that timed out while
+	// active
+	ExpiredTotal int64
 }
 
-// NewCoordinator creates a new transaction coordinator
+// NewCoordinator creates a new transaction coordinator that keeps its
+// state in memory
 func NewCoordinator() *Coordinator {
 	return &Coordinator{
 		transactions: make(map[common.TransactionID]*common.Transaction),
+		epochs:       make(map[string]int32),
+		store:        NewMemoryTransactionStore(),
+	}
+}
+
+// NewCoordinatorWithStore creates a transaction coordinator that records
+// its state transitions in store, rebuilding the transactions recorded
+// there by an earlier coordinator
+func NewCoordinatorWithStore(store TransactionStore) (*Coordinator, error) {
+	c := &Coordinator{
+		transactions: make(map[common.TransactionID]*common.Transaction),
+		epochs:       make(map[string]int32),
+		store:        store,
+	}
+	if err := store.Replay(c.apply); err != nil {
+		return nil, fmt.Errorf("failed to replay transaction log: %w", err)
+	}
+	return c, nil
+}
+
+// apply replays a recorded state transition
+func (c *Coordinator) apply(record TransactionRecord) error {
+	if record.Type == RecordBegin {
+		tx := common.NewTransaction(record.TxID, record.ProducerID, record.Timeout)
+		tx.ProducerEpoch = record.ProducerEpoch
+		tx.StartTimestamp = record.Timestamp
+		tx.LastUpdated = record.Timestamp
+		c.transactions[tx.ID] = tx
+		c.epochs[tx.ProducerID] = max(c.epochs[tx.ProducerID], tx.ProducerEpoch)
+		c.metrics.ActiveTransactions++
+		return nil
+	}
+
+	tx, exists := c.transactions[record.TxID]
+	if !exists {
+		return fmt.Errorf("%w: %s record for %s", ErrTransactionNotFound, record.Type, record.TxID)
+	}
+
+	switch record.Type {
+	case RecordAddPartitions:
+		for _, p := range record.Partitions {
+			tx.AddPartition(p.Topic, p.Partition)
+		}
+	case RecordPrepare:
+		tx.UpdateState(common.TransactionStatePrepared)
+	case RecordCommit:
+		tx.UpdateState(common.TransactionStateCommitted)
+		c.metrics.ActiveTransactions--
+		c.metrics.CommittedTotal++
+	case RecordAbort:
+		tx.UpdateState(common.TransactionStateAborted)
+		c.metrics.ActiveTransactions--
+		if record.Expired {
+			c.metrics.ExpiredTotal++
+		} else {
+			c.metrics.AbortedTotal++
+		}
+	default:
+		return fmt.Errorf("unknown transaction record type %q", record.Type)
 	}
+	tx.LastUpdated = record.Timestamp
+	return nil
 }
 
-// BeginTransaction starts a new transaction
+// record durably records a state transition. The caller must hold mu.
+func (c *Coordinator) record(recordType RecordType, txID common.TransactionID) error {
+	return c.recordTransition(TransactionRecord{Type: recordType, TxID: txID})
+}
+
+// recordTransition durably records a state transition, stamping it with
+// the current time. The caller must hold mu.
+func (c *Coordinator) recordTransition(record TransactionRecord) error {
+	record.Timestamp = time.Now()
+	if err := c.store.Append(record); err != nil {
+		return fmt.Errorf("failed to record %s of transaction %s: %w", record.Type, record.TxID, err)
+	}
+	return nil
+}
+
+// checkEpoch returns ErrProducerFenced unless epoch is the transaction's
+// epoch and still the current epoch of its producer. The caller must hold
+// mu.
+func (c *Coordinator) checkEpoch(tx *common.Transaction, epoch int32) error {
+	if epoch != tx.ProducerEpoch || epoch < c.epochs[tx.ProducerID] {
+		return fmt.Errorf("%w: producer %s sent epoch %d for transaction %s, current epoch is %d",
+			ErrProducerFenced, tx.ProducerID, epoch, tx.ID, c.epochs[tx.ProducerID])
+	}
+	return nil
+}
+
+// BeginTransaction starts a new transaction under a new epoch for the
+// producer, fencing any earlier epoch
 func (c *Coordinator) BeginTransaction(producerID string, timeout time.Duration) (*common.Transaction, error) {
 	if timeout <= 0 {
 		return nil, ErrInvalidTimeout
@@ -47,17 +172,37 @@ func (c *Coordinator) BeginTransaction(producerID string, timeout time.Duration)
 	txID := common.TransactionID(fmt.Sprintf("tx-%d", time.Now().UnixNano()))
 	tx := common.NewTransaction(txID, producerID, timeout)
 
+	epoch, seen := c.epochs[producerID]
+	if seen {
+		epoch++
+	}
+	tx.ProducerEpoch = epoch
+
 	// Check for duplicate transaction ID (should be extremely rare with UUIDs)
 	if _, exists := c.transactions[tx.ID]; exists {
 		return nil, fmt.Errorf("%w: %s", ErrTransactionAlreadyExists, tx.ID)
 	}
 
+	err := c.recordTransition(TransactionRecord{
+		Type:          RecordBegin,
+		TxID:          tx.ID,
+		ProducerID:    producerID,
+		ProducerEpoch: epoch,
+		Timeout:       timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	c.transactions[tx.ID] = tx
+	c.epochs[producerID] = epoch
+	c.metrics.ActiveTransactions++
 	return tx, nil
 }
 
-// AddPartitionsToTransaction adds partitions to a transaction
-func (c *Coordinator) AddPartitionsToTransaction(txID common.TransactionID, partitions []common.TopicPartition) ([]common.TopicPartition, error) {
+// AddPartitionsToTransaction adds partitions to a transaction on behalf of
+// the producer epoch that began it
+func (c *Coordinator) AddPartitionsToTransaction(txID common.TransactionID, epoch int32, partitions []common.TopicPartition) ([]common.TopicPartition, error) {
 	if len(partitions) == 0 {
 		return nil, ErrNoPartitions
 	}
@@ -70,6 +215,10 @@ func (c *Coordinator) AddPartitionsToTransaction(txID common.TransactionID, part
 		return nil, fmt.Errorf("%w: %s", ErrTransactionNotFound, txID)
 	}
 
+	if err := c.checkEpoch(tx, epoch); err != nil {
+		return nil, err
+	}
+
 	if tx.State != common.TransactionStateBegin {
 		return nil, fmt.Errorf("%w: cannot add partitions to transaction in state %s",
 			ErrInvalidTransactionState, tx.State)
@@ -78,28 +227,36 @@ func (c *Coordinator) AddPartitionsToTransaction(txID common.TransactionID, part
 	// Track newly added partitions
 	var added []common.TopicPartition
 
-	// Add each partition to the transaction if not already present
+	// Find the partitions not already in the transaction
 	for _, p := range partitions {
 		// Check if partition already exists
-		found := false
-		for _, existing := range tx.Partitions {
-			if existing == p {
-				found = true
-				break
-			}
-		}
+		found := slices.Contains(tx.Partitions, p) || slices.Contains(added, p)
 		if !found {
-			tx.AddPartition(p.Topic, p.Partition)
 			added = append(added, p)
 		}
 	}
 
+	if len(added) > 0 {
+		err := c.recordTransition(TransactionRecord{
+			Type:       RecordAddPartitions,
+			TxID:       txID,
+			Partitions: added,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range added {
+		tx.AddPartition(p.Topic, p.Partition)
+	}
+
 	// Return only the newly added partitions
 	return added, nil
 }
 
-// PrepareTransaction prepares a transaction for commit
-func (c *Coordinator) PrepareTransaction(txID common.TransactionID) (*common.Transaction, error) {
+// PrepareTransaction prepares a transaction for commit on behalf of the
+// producer epoch that began it
+func (c *Coordinator) PrepareTransaction(txID common.TransactionID, epoch int32) (*common.Transaction, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -108,17 +265,25 @@ func (c *Coordinator) PrepareTransaction(txID common.TransactionID) (*common.Tra
 		return nil, fmt.Errorf("%w: %s", ErrTransactionNotFound, txID)
 	}
 
+	if err := c.checkEpoch(tx, epoch); err != nil {
+		return nil, err
+	}
+
 	if tx.State != common.TransactionStateBegin {
 		return nil, fmt.Errorf("%w: cannot prepare transaction in state %s",
 			ErrInvalidTransactionState, tx.State)
 	}
 
+	if err := c.record(RecordPrepare, txID); err != nil {
+		return nil, err
+	}
 	tx.UpdateState(common.TransactionStatePrepared)
 	return tx, nil
 }
 
-// CommitTransaction commits a transaction
-func (c *Coordinator) CommitTransaction(txID common.TransactionID) (*common.Transaction, error) {
+// CommitTransaction commits a transaction on behalf of the producer epoch
+// that began it
+func (c *Coordinator) CommitTransaction(txID common.TransactionID, epoch int32) (*common.Transaction, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -127,16 +292,26 @@ func (c *Coordinator) CommitTransaction(txID common.TransactionID) (*common.Tran
 		return nil, fmt.Errorf("%w: %s", ErrTransactionNotFound, txID)
 	}
 
+	if err := c.checkEpoch(tx, epoch); err != nil {
+		return nil, err
+	}
+
 	if tx.State != common.TransactionStatePrepared {
 		return nil, fmt.Errorf("%w: cannot commit transaction in state %s",
 			ErrInvalidTransactionState, tx.State)
 	}
 
+	if err := c.record(RecordCommit, txID); err != nil {
+		return nil, err
+	}
 	tx.UpdateState(common.TransactionStateCommitted)
+	c.metrics.ActiveTransactions--
+	c.metrics.CommittedTotal++
 	return tx, nil
 }
 
-// AbortTransaction aborts a transaction
+// AbortTransaction aborts a transaction. It doesn't check the producer
+// epoch, so a fenced producer can still abort the transaction it began.
 func (c *Coordinator) AbortTransaction(txID common.TransactionID) (*common.Transaction, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -152,7 +327,12 @@ func (c *Coordinator) AbortTransaction(txID common.TransactionID) (*common.Trans
 			ErrInvalidTransactionState, tx.State)
 	}
 
+	if err := c.record(RecordAbort, txID); err != nil {
+		return nil, err
+	}
 	tx.UpdateState(common.TransactionStateAborted)
+	c.metrics.ActiveTransactions--
+	c.metrics.AbortedTotal++
 	return tx, nil
 }
 
@@ -172,7 +352,12 @@ func (c *Coordinator) GetTransaction(txID common.TransactionID) (*common.Transac
 
 	// Check if transaction has expired
 	if tx.IsExpired() {
-		// Clean up the expired transaction
+		// Clean up the expired transaction, aborting it if it's still open
+		if tx.State == common.TransactionStateBegin || tx.State == common.TransactionStatePrepared {
+			if err := c.expire(tx); err != nil {
+				return nil, err
+			}
+		}
 		delete(c.transactions, txID)
 		return nil, fmt.Errorf("%w: transaction %s has expired", ErrTransactionNotFound, txID)
 	}
@@ -180,7 +365,15 @@ func (c *Coordinator) GetTransaction(txID common.TransactionID) (*common.Transac
 	return tx, nil
 }
 
-// CleanupExpiredTransactions removes transactions that have timed out
+// Metrics returns a snapshot of the coordinator's transaction counts
+func (c *Coordinator) Metrics() CoordinatorMetrics {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.metrics
+}
+
+// CleanupExpiredTransactions aborts and removes transactions that have
+// timed out, returning their IDs
 func (c *Coordinator) CleanupExpiredTransactions() []common.TransactionID {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -192,10 +385,94 @@ func (c *Coordinator) CleanupExpiredTransactions() []common.TransactionID {
 		if tx.State != common.TransactionStateCommitted &&
 			tx.State != common.TransactionStateAborted &&
 			now.Sub(tx.StartTimestamp) > tx.Timeout {
-			tx.UpdateState(common.TransactionStateAborted)
+			// Leave the transaction for the next cleanup if the abort
+			// can't be recorded
+			if err := c.expire(tx); err != nil {
+				continue
+			}
+			delete(c.transactions, id)
 			expired = append(expired, id)
 		}
 	}
 
 	return expired
 }
+
+// expire records the abort of an open transaction that timed out, so that
+// it stays aborted after a restart, and marks it aborted. The caller must
+// hold mu.
+func (c *Coordinator) expire(tx *common.Transaction) error {
+	err := c.recordTransition(TransactionRecord{Type: RecordAbort, TxID: tx.ID, Expired: true})
+	if err != nil {
+		return err
+	}
+	tx.UpdateState(common.TransactionStateAborted)
+	c.metrics.ActiveTransactions--
+	c.metrics.ExpiredTotal++
+	return nil
+}
+
+// StartReaper starts a goroutine that calls CleanupExpiredTransactions every
+// interval, so abandoned transactions are aborted without waiting for a
+// GetTransaction call to notice them. If reaped is not nil, the ID of each
+// transaction aborted is sent to it; the reaper waits for each send to be
+// received unless it is stopped.
+func (c *Coordinator) StartReaper(interval time.Duration, reaped chan<- common.TransactionID) error {
+	if interval <= 0 {
+		return ErrInvalidInterval
+	}
+
+	c.reaperMu.Lock()
+	defer c.reaperMu.Unlock()
+
+	if c.stopReaper != nil {
+		return ErrReaperRunning
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	c.stopReaper, c.reaperDone = stop, done
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			for _, txID := range c.CleanupExpiredTransactions() {
+				if reaped == nil {
+					continue
+				}
+				select {
+				case reaped <- txID:
+				case <-stop:
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop halts the reaper started by StartReaper, waiting for it to exit. It
+// does nothing if the reaper isn't running.
+func (c *Coordinator) Stop() {
+	c.reaperMu.Lock()
+	defer c.reaperMu.Unlock()
+
+	if c.stopReaper == nil {
+		return
+	}
+
+	close(c.stopReaper)
+	<-c.reaperDone
+	c.stopReaper, c.reaperDone = nil, nil
+}
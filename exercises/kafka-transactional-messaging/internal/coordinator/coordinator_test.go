@@ -1,6 +1,9 @@
 package coordinator_test
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -30,7 +33,7 @@ func TestCoordinator_AddPartitionsToTransaction(t *testing.T) {
 	}
 
 	// AddPartitionsToTransaction now returns the list of added partitions
-	addedParts, err := c.AddPartitionsToTransaction(tx.ID, partitions)
+	addedParts, err := c.AddPartitionsToTransaction(tx.ID, tx.ProducerEpoch, partitions)
 	assert.NoError(t, err)
 	assert.Len(t, addedParts, 2)
 
@@ -53,27 +56,27 @@ func TestCoordinator_TransactionLifecycle(t *testing.T) {
 	assert.Equal(t, common.TransactionStateBegin, tx.State)
 
 	// Test AddPartitionsToTransaction with empty partitions
-	_, err = c.AddPartitionsToTransaction(tx.ID, []common.TopicPartition{})
+	_, err = c.AddPartitionsToTransaction(tx.ID, tx.ProducerEpoch, []common.TopicPartition{})
 	assert.ErrorIs(t, err, coordinator.ErrNoPartitions)
 
 	// Add partitions
 	partitions := []common.TopicPartition{{Topic: "test-topic", Partition: 0}}
-	addedParts, err := c.AddPartitionsToTransaction(tx.ID, partitions)
+	addedParts, err := c.AddPartitionsToTransaction(tx.ID, tx.ProducerEpoch, partitions)
 	assert.NoError(t, err)
 	assert.Len(t, addedParts, 1)
 
 	// Try to add the same partition again (should be idempotent)
-	addedParts, err = c.AddPartitionsToTransaction(tx.ID, partitions)
+	addedParts, err = c.AddPartitionsToTransaction(tx.ID, tx.ProducerEpoch, partitions)
 	assert.NoError(t, err)
 	assert.Len(t, addedParts, 0) // No new partitions added (idempotent)
 
 	// Test PrepareTransaction with invalid state (should work from Begin state)
-	tx, err = c.PrepareTransaction(tx.ID)
+	tx, err = c.PrepareTransaction(tx.ID, tx.ProducerEpoch)
 	assert.NoError(t, err)
 	assert.Equal(t, common.TransactionStatePrepared, tx.State)
 
 	// Test CommitTransaction with invalid state (should work from Prepared state)
-	tx, err = c.CommitTransaction(tx.ID)
+	tx, err = c.CommitTransaction(tx.ID, tx.ProducerEpoch)
 	assert.NoError(t, err)
 	assert.Equal(t, common.TransactionStateCommitted, tx.State)
 	tx, err = c.GetTransaction(tx.ID)
@@ -94,7 +97,7 @@ func TestCoordinator_AbortTransaction(t *testing.T) {
 
 	// Add some partitions
 	partitions := []common.TopicPartition{{Topic: "test-topic", Partition: 0}}
-	_, err = c.AddPartitionsToTransaction(tx.ID, partitions)
+	_, err = c.AddPartitionsToTransaction(tx.ID, tx.ProducerEpoch, partitions)
 	require.NoError(t, err)
 
 	// Test aborting from Begin state
@@ -109,9 +112,9 @@ func TestCoordinator_AbortTransaction(t *testing.T) {
 	// Test aborting committed transaction
 	tx, err = c.BeginTransaction("prod1", 30*time.Second)
 	require.NoError(t, err)
-	_, err = c.PrepareTransaction(tx.ID)
+	_, err = c.PrepareTransaction(tx.ID, tx.ProducerEpoch)
 	require.NoError(t, err)
-	_, err = c.CommitTransaction(tx.ID)
+	_, err = c.CommitTransaction(tx.ID, tx.ProducerEpoch)
 	require.NoError(t, err)
 	_, err = c.AbortTransaction(tx.ID)
 	assert.ErrorIs(t, err, coordinator.ErrInvalidTransactionState)
@@ -141,11 +144,11 @@ func TestCoordinator_TransactionExpiration(t *testing.T) {
 	require.Error(t, err, "expected error for expired transaction")
 
 	// Try to prepare the expired transaction - should return not found
-	_, err = c.PrepareTransaction(tx.ID)
+	_, err = c.PrepareTransaction(tx.ID, tx.ProducerEpoch)
 	require.Error(t, err, "expected error for expired transaction")
 
 	// Try to add partitions to expired transaction - should return not found
-	_, err = c.AddPartitionsToTransaction(tx.ID, []common.TopicPartition{{Topic: "test", Partition: 0}})
+	_, err = c.AddPartitionsToTransaction(tx.ID, tx.ProducerEpoch, []common.TopicPartition{{Topic: "test", Partition: 0}})
 	require.Error(t, err, "expected error for expired transaction")
 
 	// Test cleanup of expired transactions with staggered timeouts
@@ -197,3 +200,178 @@ func TestCoordinator_TransactionExpiration(t *testing.T) {
 	_, err = c.GetTransaction(tx3.ID)
 	require.Error(t, err, "tx3 should be expired and cleaned up")
 }
+
+func TestCoordinator_Reaper(t *testing.T) {
+	c := coordinator.NewCoordinator()
+
+	assert.ErrorIs(t, c.StartReaper(0, nil), coordinator.ErrInvalidInterval)
+
+	// Begin transactions that are abandoned, and one that outlives the test
+	expiring := make(map[common.TransactionID]bool)
+	for i := 0; i < 3; i++ {
+		tx, err := c.BeginTransaction(fmt.Sprintf("prod%d", i), 20*time.Millisecond)
+		require.NoError(t, err)
+		expiring[tx.ID] = true
+	}
+	live, err := c.BeginTransaction("prod-live", time.Minute)
+	require.NoError(t, err)
+
+	reaped := make(chan common.TransactionID)
+	require.NoError(t, c.StartReaper(10*time.Millisecond, reaped))
+	defer c.Stop()
+	assert.ErrorIs(t, c.StartReaper(10*time.Millisecond, nil), coordinator.ErrReaperRunning)
+
+	got := make(map[common.TransactionID]bool)
+	timeout := time.After(2 * time.Second)
+	for len(got) < len(expiring) {
+		select {
+		case txID := <-reaped:
+			got[txID] = true
+		case <-timeout:
+			t.Fatalf("reaped %d of %d expired transactions", len(got), len(expiring))
+		}
+	}
+	assert.Equal(t, expiring, got)
+
+	c.Stop()
+	c.Stop() // stopping again is a no-op
+
+	// Reaped transactions were aborted and removed; the live one remains
+	for txID := range expiring {
+		_, err := c.AbortTransaction(txID)
+		assert.ErrorIs(t, err, coordinator.ErrTransactionNotFound)
+	}
+	tx, err := c.GetTransaction(live.ID)
+	require.NoError(t, err)
+	assert.Equal(t, common.TransactionStateBegin, tx.State)
+
+	// The reaper can be restarted after stopping
+	require.NoError(t, c.StartReaper(10*time.Millisecond, nil))
+}
+
+func TestCoordinator_RecoverFromStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transactions.log")
+
+	store, err := coordinator.NewFileTransactionStore(path)
+	require.NoError(t, err)
+	coord, err := coordinator.NewCoordinatorWithStore(store)
+	require.NoError(t, err)
+
+	partitions := []common.TopicPartition{
+		{Topic: "test-topic", Partition: 0},
+		{Topic: "test-topic", Partition: 1},
+	}
+
+	prepared, err := coord.BeginTransaction("producer-1", time.Minute)
+	require.NoError(t, err)
+	_, err = coord.AddPartitionsToTransaction(prepared.ID, prepared.ProducerEpoch, partitions)
+	require.NoError(t, err)
+	_, err = coord.PrepareTransaction(prepared.ID, prepared.ProducerEpoch)
+	require.NoError(t, err)
+
+	aborted, err := coord.BeginTransaction("producer-2", time.Minute)
+	require.NoError(t, err)
+	_, err = coord.AbortTransaction(aborted.ID)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	// Simulate a crash part way through appending a record
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"type":"commit","tx_id":`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// Restart the coordinator from the same log
+	store, err = coordinator.NewFileTransactionStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	coord, err = coordinator.NewCoordinatorWithStore(store)
+	require.NoError(t, err)
+
+	tx, err := coord.GetTransaction(prepared.ID)
+	require.NoError(t, err)
+	assert.Equal(t, common.TransactionStatePrepared, tx.State)
+	assert.Equal(t, "producer-1", tx.ProducerID)
+	assert.ElementsMatch(t, partitions, tx.Partitions)
+
+	tx, err = coord.GetTransaction(aborted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, common.TransactionStateAborted, tx.State)
+
+	// The recovered transaction can be resolved
+	tx, err = coord.CommitTransaction(prepared.ID, prepared.ProducerEpoch)
+	require.NoError(t, err)
+	assert.Equal(t, common.TransactionStateCommitted, tx.State)
+
+	// Producer epochs carry on from the recovered ones
+	tx, err = coord.BeginTransaction("producer-1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, prepared.ProducerEpoch+1, tx.ProducerEpoch)
+}
+
+func TestCoordinator_RecoverLazilyExpired(t *testing.T) {
+	store := coordinator.NewMemoryTransactionStore()
+	c, err := coordinator.NewCoordinatorWithStore(store)
+	require.NoError(t, err)
+
+	tx, err := c.BeginTransaction("producer-1", time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+
+	// GetTransaction notices the timeout before the reaper does
+	_, err = c.GetTransaction(tx.ID)
+	assert.ErrorIs(t, err, coordinator.ErrTransactionNotFound)
+	expected := coordinator.CoordinatorMetrics{ExpiredTotal: 1}
+	assert.Equal(t, expected, c.Metrics())
+
+	// After a restart the transaction is still aborted, so the reaper
+	// doesn't expire it again
+	restarted, err := coordinator.NewCoordinatorWithStore(store)
+	require.NoError(t, err)
+	assert.Equal(t, expected, restarted.Metrics())
+	assert.Empty(t, restarted.CleanupExpiredTransactions())
+	assert.Equal(t, expected, restarted.Metrics())
+}
+
+func TestCoordinator_Metrics(t *testing.T) {
+	store := coordinator.NewMemoryTransactionStore()
+	c, err := coordinator.NewCoordinatorWithStore(store)
+	require.NoError(t, err)
+	assert.Equal(t, coordinator.CoordinatorMetrics{}, c.Metrics())
+
+	for range 3 {
+		tx, err := c.BeginTransaction("producer-1", time.Minute)
+		require.NoError(t, err)
+		_, err = c.PrepareTransaction(tx.ID, tx.ProducerEpoch)
+		require.NoError(t, err)
+		_, err = c.CommitTransaction(tx.ID, tx.ProducerEpoch)
+		require.NoError(t, err)
+	}
+	for range 2 {
+		tx, err := c.BeginTransaction("producer-2", time.Minute)
+		require.NoError(t, err)
+		_, err = c.AbortTransaction(tx.ID)
+		require.NoError(t, err)
+	}
+	_, err = c.BeginTransaction("producer-3", time.Minute)
+	require.NoError(t, err)
+	_, err = c.BeginTransaction("producer-4", time.Millisecond)
+	require.NoError(t, err)
+
+	time.Sleep(10 * time.Millisecond)
+	require.Len(t, c.CleanupExpiredTransactions(), 1)
+
+	expected := coordinator.CoordinatorMetrics{
+		ActiveTransactions: 1,
+		CommittedTotal:     3,
+		AbortedTotal:       2,
+		ExpiredTotal:       1,
+	}
+	assert.Equal(t, expected, c.Metrics())
+
+	// The counts are rebuilt from the store after a restart
+	restarted, err := coordinator.NewCoordinatorWithStore(store)
+	require.NoError(t, err)
+	assert.Equal(t, expected, restarted.Metrics())
+}
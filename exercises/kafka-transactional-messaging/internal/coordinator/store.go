@@ -0,0 +1,219 @@
+package coordinator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kumarlokesh/sysd/exercises/kafka-transactional-messaging/internal/common"
+)
+
+// RecordType identifies a transaction state transition
+type RecordType string
+
+const (
+	// RecordBegin records that a transaction has begun
+	RecordBegin RecordType = "BEGIN"
+	// RecordAddPartitions records partitions added to a transaction
+	RecordAddPartitions RecordType = "ADD_PARTITIONS"
+	// RecordPrepare records that a transaction is prepared to commit
+	RecordPrepare RecordType = "PREPARE"
+	// RecordCommit records that a transaction is committed
+	RecordCommit RecordType = "COMMIT"
+	// RecordAbort records that a transaction is aborted
+	RecordAbort RecordType = "ABORT"
+)
+
+// TransactionRecord is a transaction state transition, as recorded in a
+// TransactionStore
+type TransactionRecord struct {
+	Type      RecordType           `json:"type"`
+	TxID      common.TransactionID `json:"tx_id"`
+	Timestamp time.Time            `json:"timestamp"`
+
+	// ProducerID, ProducerEpoch and Timeout are set for RecordBegin
+	ProducerID    string        `json:"producer_id,omitempty"`
+	ProducerEpoch int32         `json:"producer_epoch,omitempty"`
+	Timeout       time.Duration `json:"timeout,omitempty"`
+
+	// Partitions is set for RecordAddPartitions
+	Partitions []common.TopicPartition `json:"partitions,omitempty"`
+
+	// Expired is set for a RecordAbort of a transaction that timed out
+	Expired bool `json:"expired,omitempty"`
+}
+
+// TransactionStore durably records the coordinator's transaction state
+// transitions, so that its state can be rebuilt after a restart
+type TransactionStore interface {
+	// Append records a transition. It must not return until the record is
+	// durable.
+	Append(record TransactionRecord) error
+
+	// Replay calls fn with every record appended, in order
+	Replay(fn func(TransactionRecord) error) error
+
+	// Close releases the store's resources
+	Close() error
+}
+
+// MemoryTransactionStore is a TransactionStore that keeps records in
+// memory, so they survive the coordinator but not the process. It keeps
+// every record, and is meant for tests and short-lived coordinators.
+type MemoryTransactionStore struct {
+	records []TransactionRecord
+	mu      sync.Mutex
+}
+
+// NewMemoryTransactionStore creates an empty in-memory transaction store
+func NewMemoryTransactionStore() *MemoryTransactionStore {
+	return &MemoryTransactionStore{}
+}
+
+// Append records a transition
+func (s *MemoryTransactionStore) Append(record TransactionRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+	return nil
+}
+
+// Replay calls fn with every record appended, in order
+func (s *MemoryTransactionStore) Replay(fn func(TransactionRecord) error) error {
+	s.mu.Lock()
+	records := make([]TransactionRecord, len(s.records))
+	copy(records, s.records)
+	s.mu.Unlock()
+
+	for _, record := range records {
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close does nothing for the in-memory store
+func (s *MemoryTransactionStore) Close() error {
+	return nil
+}
+
+// FileTransactionStore is a TransactionStore that appends records to a
+// file as JSON lines, syncing the file after each record
+type FileTransactionStore struct {
+	path string
+	file *os.File
+	mu   sync.Mutex
+}
+
+// NewFileTransactionStore opens the transaction log at path, creating it if
+// it doesn't exist. A partial record left at the end of the file by a crash
+// during an append is discarded.
+func NewFileTransactionStore(path string) (*FileTransactionStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transaction log: %w", err)
+	}
+
+	// Truncate any partial record so that new records start on a new line
+	end, err := validLength(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Truncate(end); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to truncate transaction log: %w", err)
+	}
+	if _, err := file.Seek(end, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to seek transaction log: %w", err)
+	}
+
+	return &FileTransactionStore{path: path, file: file}, nil
+}
+
+// validLength returns the length of the complete lines at the start of the
+// file
+func validLength(file *os.File) (int64, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to seek transaction log: %w", err)
+	}
+
+	reader := bufio.NewReader(file)
+	var end int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return end, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read transaction log: %w", err)
+		}
+		end += int64(len(line))
+	}
+}
+
+// Append writes a record to the end of the log and syncs it to disk
+func (s *FileTransactionStore) Append(record TransactionRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction record: %w", err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(data); err != nil {
+		return fmt.Errorf("failed to write transaction record: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync transaction log: %w", err)
+	}
+	return nil
+}
+
+// Replay reads the log from the start, calling fn with each record
+func (s *FileTransactionStore) Replay(fn func(TransactionRecord) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to open transaction log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var record TransactionRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("failed to decode transaction record on line %d: %w", line, err)
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read transaction log: %w", err)
+	}
+	return nil
+}
+
+// Close closes the log file
+func (s *FileTransactionStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -19,12 +19,19 @@ var (
 	ErrPartitionAddFailed    = errors.New("failed to add partition to transaction")
 )
 
-// Producer represents a transactional message producer
+// Producer represents a transactional message producer. It is idempotent:
+// each message sent to a partition carries the next sequence number for
+// that partition, so the log discards a retried message it already holds.
+// Its requests carry the producer epoch the coordinator issued for its
+// current transaction, so once another producer with the same ID begins a
+// transaction, this one is fenced.
 type Producer struct {
 	producerID   string
 	coordinator  *coordinator.Coordinator
 	messageLog   *common.MessageLog
 	currentTx    *common.Transaction
+	epoch        int32
+	sequences    map[common.TopicPartition]int64
 	currentTxMux sync.Mutex
 }
 
@@ -34,6 +41,7 @@ func NewProducer(producerID string, coordinator *coordinator.Coordinator, messag
 		producerID:  producerID,
 		coordinator: coordinator,
 		messageLog:  messageLog,
+		sequences:   make(map[common.TopicPartition]int64),
 	}
 }
 
@@ -52,6 +60,7 @@ func (p *Producer) BeginTransaction(timeout time.Duration) error {
 	}
 
 	p.currentTx = tx
+	p.epoch = tx.ProducerEpoch
 	return nil
 }
 
@@ -69,19 +78,23 @@ func (p *Producer) Send(topic common.Topic, partition common.Partition, key, val
 	found := slices.Contains(p.currentTx.Partitions, tp)
 
 	if !found {
-		_, err := p.coordinator.AddPartitionsToTransaction(p.currentTx.ID, []common.TopicPartition{tp})
+		_, err := p.coordinator.AddPartitionsToTransaction(p.currentTx.ID, p.epoch, []common.TopicPartition{tp})
 		if err != nil {
 			return 0, fmt.Errorf("%w: %w", ErrPartitionAddFailed, err)
 		}
 	}
 
-	// Create and append the message
+	// Create and append the message, numbered with the partition's next
+	// sequence number
 	msg := &common.Message{
-		Key:       key,
-		Value:     value,
-		Headers:   make(map[string]string),
-		Topic:     topic,
-		Partition: partition,
+		Key:           key,
+		Value:         value,
+		Headers:       make(map[string]string),
+		Topic:         topic,
+		Partition:     partition,
+		ProducerID:    p.producerID,
+		Sequence:      p.sequences[tp],
+		ProducerEpoch: p.epoch,
 	}
 
 	offset, err := p.messageLog.Append(topic, partition, msg, p.currentTx.ID)
@@ -89,6 +102,7 @@ func (p *Producer) Send(topic common.Topic, partition common.Partition, key, val
 		return 0, fmt.Errorf("%w: %w", ErrMessageLogFailure, err)
 	}
 
+	p.sequences[tp]++
 	return offset, nil
 }
 
@@ -102,7 +116,7 @@ func (p *Producer) CommitTransaction() error {
 	}
 
 	// Prepare the transaction
-	tx, err := p.coordinator.PrepareTransaction(p.currentTx.ID)
+	tx, err := p.coordinator.PrepareTransaction(p.currentTx.ID, p.epoch)
 	if err != nil {
 		return fmt.Errorf("failed to prepare transaction: %w", err)
 	}
@@ -116,7 +130,7 @@ func (p *Producer) CommitTransaction() error {
 	}
 
 	// Commit the transaction
-	_, err = p.coordinator.CommitTransaction(tx.ID)
+	_, err = p.coordinator.CommitTransaction(tx.ID, p.epoch)
 	if err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
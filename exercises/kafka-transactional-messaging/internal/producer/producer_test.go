@@ -1,6 +1,7 @@
 package producer_test
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/kumarlokesh/sysd/exercises/kafka-transactional-messaging/internal/coordinator"
 	"github.com/kumarlokesh/sysd/exercises/kafka-transactional-messaging/internal/producer"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestProducer_SendAndCommit(t *testing.T) {
@@ -92,3 +94,107 @@ func TestProducer_DoubleBegin(t *testing.T) {
 	err = prod.BeginTransaction(30 * time.Second)
 	assert.Error(t, err)
 }
+
+func TestProducer_IdempotentRetry(t *testing.T) {
+	coord := coordinator.NewCoordinator()
+	messageLog := common.NewMessageLog()
+	prod := producer.NewProducer("test-producer", coord, messageLog)
+
+	require.NoError(t, prod.BeginTransaction(30*time.Second))
+	_, err := prod.Send("test-topic", 0, []byte("key1"), []byte("value1"))
+	require.NoError(t, err)
+	_, err = prod.Send("test-topic", 0, []byte("key2"), []byte("value2"))
+	require.NoError(t, err)
+	_, err = prod.Send("test-topic", 1, []byte("key3"), []byte("value3"))
+	require.NoError(t, err)
+
+	// Each partition has its own sequence
+	entries, err := messageLog.GetMessages("test-topic", 0, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, int64(0), entries[0].Message.Sequence)
+	assert.Equal(t, int64(1), entries[1].Message.Sequence)
+	entries, err = messageLog.GetMessages("test-topic", 1, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, int64(0), entries[0].Message.Sequence)
+
+	// Retrying a message whose append succeeded doesn't duplicate it
+	retry := *entries[0].Message
+	_, err = messageLog.Append("test-topic", 1, &retry, entries[0].TxID)
+	assert.ErrorIs(t, err, common.ErrDuplicateSequence)
+
+	latest, err := messageLog.GetLatestOffset("test-topic", 1)
+	require.NoError(t, err)
+	assert.Equal(t, common.Offset(1), latest, "only one entry should land")
+
+	// The producer continues with the next sequence number
+	_, err = prod.Send("test-topic", 1, []byte("key4"), []byte("value4"))
+	require.NoError(t, err)
+	entries, err = messageLog.GetMessages("test-topic", 1, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, int64(1), entries[1].Message.Sequence)
+	require.NoError(t, prod.CommitTransaction())
+}
+
+func TestProducer_EpochFencing(t *testing.T) {
+	coord := coordinator.NewCoordinator()
+	messageLog := common.NewMessageLog()
+	zombie := producer.NewProducer("shared-producer", coord, messageLog)
+	successor := producer.NewProducer("shared-producer", coord, messageLog)
+
+	require.NoError(t, zombie.BeginTransaction(30*time.Second))
+	_, err := zombie.Send("test-topic", 0, []byte("key1"), []byte("zombie1"))
+	require.NoError(t, err)
+
+	// A new instance with the same ID takes over, bumping the epoch
+	require.NoError(t, successor.BeginTransaction(30*time.Second))
+	assert.Greater(t, successor.CurrentTransaction().ProducerEpoch, zombie.CurrentTransaction().ProducerEpoch)
+	_, err = successor.Send("test-topic", 0, []byte("key1"), []byte("successor1"))
+	require.NoError(t, err)
+
+	// The old instance's late writes are rejected, by the log for a
+	// partition already in its transaction and by the coordinator for a
+	// new one
+	_, err = zombie.Send("test-topic", 0, []byte("key2"), []byte("zombie2"))
+	assert.ErrorIs(t, err, common.ErrProducerFenced)
+	_, err = zombie.Send("test-topic", 1, []byte("key3"), []byte("zombie3"))
+	assert.ErrorIs(t, err, coordinator.ErrProducerFenced)
+
+	err = zombie.CommitTransaction()
+	assert.ErrorIs(t, err, coordinator.ErrProducerFenced)
+	tx, err := coord.GetTransaction(zombie.CurrentTransaction().ID)
+	require.NoError(t, err)
+	assert.Equal(t, common.TransactionStateBegin, tx.State, "fenced commit should not prepare the transaction")
+
+	// The fenced instance can still abort its own transaction
+	require.NoError(t, zombie.AbortTransaction())
+	require.NoError(t, successor.CommitTransaction())
+}
+
+func TestMessageLog_Metrics(t *testing.T) {
+	coord := coordinator.NewCoordinator()
+	messageLog := common.NewMessageLog()
+	prod := producer.NewProducer("test-producer", coord, messageLog)
+
+	require.NoError(t, prod.BeginTransaction(30*time.Second))
+	for i := range 3 {
+		_, err := prod.Send("test-topic", 0, []byte("key"), []byte(fmt.Sprintf("value%d", i)))
+		require.NoError(t, err)
+	}
+	_, err := prod.Send("test-topic", 1, []byte("key"), []byte("value"))
+	require.NoError(t, err)
+	require.NoError(t, prod.CommitTransaction())
+
+	require.NoError(t, prod.BeginTransaction(30*time.Second))
+	_, err = prod.Send("test-topic", 1, []byte("key"), []byte("aborted"))
+	require.NoError(t, err)
+	require.NoError(t, prod.AbortTransaction())
+
+	metrics := messageLog.Metrics()
+	assert.Equal(t, map[common.TopicPartition]common.PartitionMetrics{
+		{Topic: "test-topic", Partition: 0}: {Messages: 3, Markers: 1},
+		{Topic: "test-topic", Partition: 1}: {Messages: 2, Markers: 2},
+	}, metrics.Partitions)
+}
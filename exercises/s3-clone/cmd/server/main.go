@@ -42,16 +42,13 @@ func main() {
 		log.Println("Debug logging enabled")
 	}
 
-	// Initialize metadata service
-	metaSvc := metadata.NewInMemoryMetadata()
-
 	// Initialize storage based on type
 	var store storage.Storage
 
 	switch StorageType(*storageType) {
 	case StorageTypeMemory:
 		log.Println("Using in-memory storage")
-		store = storage.NewMemoryStorage(metaSvc)
+		store = storage.NewMemoryStorage(metadata.NewInMemoryMetadata())
 
 	case StorageTypeFilesystem:
 		// Ensure data directory exists
@@ -64,6 +61,12 @@ func main() {
 			log.Printf("Using filesystem storage at: %s", absDataDir)
 		}
 
+		// Keep the metadata with the data so that both survive restarts
+		metaSvc, err := metadata.NewFileMetadata(filepath.Join(absDataDir, "metadata.json"))
+		if err != nil {
+			log.Fatalf("Failed to load metadata: %v", err)
+		}
+
 		store, err = storage.NewFilesystemStorage(absDataDir, metaSvc)
 		if err != nil {
 			log.Fatalf("Failed to initialize filesystem storage: %v", err)
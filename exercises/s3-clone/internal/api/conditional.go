@@ -0,0 +1,81 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kumarlokesh/s3-clone/internal/types"
+)
+
+// etagMatches reports whether the comma-separated entity tags of an
+// If-Match or If-None-Match header include etag. The tag "*" matches any
+// object, and weak tags are compared like strong ones.
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		tag = strings.TrimPrefix(tag, "W/")
+		if strings.Trim(tag, `"`) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified reports whether the If-None-Match or If-Modified-Since header
+// of a GET or HEAD request shows that the client's copy of obj is current.
+// If-Modified-Since is ignored when If-None-Match is present.
+func notModified(r *http.Request, obj *types.Object) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return etagMatches(ifNoneMatch, obj.ETag)
+	}
+	if ifModifiedSince := r.Header.Get("If-Modified-Since"); ifModifiedSince != "" {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err != nil {
+			return false
+		}
+		// HTTP dates have a resolution of one second
+		return !obj.ModifiedAt.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// writeNotModified responds 304 Not Modified with the headers identifying
+// the client's copy of obj
+func writeNotModified(w http.ResponseWriter, obj *types.Object) {
+	w.Header().Set("Last-Modified", obj.ModifiedAt.UTC().Format(http.TimeFormat))
+	if obj.ETag != "" {
+		w.Header().Set("ETag", quoteETag(obj.ETag))
+	}
+	if obj.VersionId != "" {
+		w.Header().Set("X-Amz-Version-Id", obj.VersionId)
+	}
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// hasPutPreconditions reports whether a PUT request is conditional on the
+// current object
+func hasPutPreconditions(r *http.Request) bool {
+	return r.Header.Get("If-Match") != "" || r.Header.Get("If-None-Match") != ""
+}
+
+// putPreconditionsMet evaluates the If-Match and If-None-Match headers of a
+// PUT request against the current object, which is nil if there is none.
+// If-Match requires the object to exist with a matching ETag, and
+// If-None-Match requires it not to, so "If-None-Match: *" only creates.
+func putPreconditionsMet(r *http.Request, current *types.Object) bool {
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if current == nil || !etagMatches(ifMatch, current.ETag) {
+			return false
+		}
+	}
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if current != nil && etagMatches(ifNoneMatch, current.ETag) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/kumarlokesh/s3-clone/internal/types"
+)
+
+// parseRange parses a single-range HTTP Range header of the form
+// "bytes=start-end", "bytes=start-" or "bytes=-suffix" into opts. It
+// reports false if the header is absent or malformed, requests multiple
+// ranges or ends before it starts, in which case the whole object is
+// returned as HTTP allows.
+func parseRange(header string, opts *types.GetObjectOptions) bool {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return false
+	}
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return false
+	}
+
+	// Suffix range: the last N bytes
+	if first == "" {
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return false
+		}
+		if n == 0 {
+			// An empty suffix selects no bytes, so start past the end of
+			// any object to make the range unsatisfiable
+			opts.Range, opts.RangeStart, opts.RangeEnd = true, math.MaxInt64, -1
+			return true
+		}
+		opts.Range, opts.RangeStart, opts.RangeEnd = true, -n, -1
+		return true
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return false
+	}
+	end := int64(-1)
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return false
+		}
+	}
+	opts.Range, opts.RangeStart, opts.RangeEnd = true, start, end
+	return true
+}
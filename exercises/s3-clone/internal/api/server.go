@@ -2,12 +2,18 @@ package api
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,18 +24,20 @@ import (
 
 // Server represents the HTTP API server
 type Server struct {
-	storage storage.Storage
-	server  *http.Server
-	addr    string
-	cancel  context.CancelFunc
-	ctx     context.Context
+	storage   storage.Storage
+	multipart *storage.MultipartStore
+	server    *http.Server
+	addr      string
+	cancel    context.CancelFunc
+	ctx       context.Context
 }
 
 // NewServer creates a new API server
 func NewServer(addr string, store storage.Storage) *Server {
 	s := &Server{
-		storage: store,
-		addr:    addr,
+		storage:   store,
+		multipart: storage.NewMultipartStore(store, ""),
+		addr:      addr,
 	}
 
 	r := mux.NewRouter()
@@ -45,14 +53,27 @@ func NewServer(addr string, store storage.Storage) *Server {
 	// List all buckets
 	r.HandleFunc("/", s.listBuckets).Methods("GET")
 
+	// Bucket versioning operations
+	r.HandleFunc("/{bucket}", s.putBucketVersioning).Methods("PUT").Queries("versioning", "")
+	r.HandleFunc("/{bucket}", s.getBucketVersioning).Methods("GET").Queries("versioning", "")
+	r.HandleFunc("/{bucket}", s.listObjectVersions).Methods("GET").Queries("versions", "")
+
 	// Bucket operations
 	r.HandleFunc("/{bucket}", s.createBucket).Methods("PUT")
 	r.HandleFunc("/{bucket}", s.deleteBucket).Methods("DELETE")
 	r.HandleFunc("/{bucket}", s.listObjects).Methods("GET")
 
+	// Multipart upload operations
+	r.HandleFunc("/{bucket}/{key:.+}", s.createMultipartUpload).Methods("POST").Queries("uploads", "")
+	r.HandleFunc("/{bucket}/{key:.+}", s.uploadPart).Methods("PUT").Queries("partNumber", "{partNumber}", "uploadId", "{uploadId}")
+	r.HandleFunc("/{bucket}/{key:.+}", s.completeMultipartUpload).Methods("POST").Queries("uploadId", "{uploadId}")
+	r.HandleFunc("/{bucket}/{key:.+}", s.abortMultipartUpload).Methods("DELETE").Queries("uploadId", "{uploadId}")
+
 	// Object operations
+	r.HandleFunc("/{bucket}/{key:.+}", s.copyObject).Methods("PUT").Headers("X-Amz-Copy-Source", "")
 	r.HandleFunc("/{bucket}/{key:.+}", s.putObject).Methods("PUT")
 	r.HandleFunc("/{bucket}/{key:.+}", s.getObject).Methods("GET")
+	r.HandleFunc("/{bucket}/{key:.+}", s.headObject).Methods("HEAD")
 	r.HandleFunc("/{bucket}/{key:.+}", s.deleteObject).Methods("DELETE")
 
 	// Add a catch-all route for debugging
@@ -188,6 +209,15 @@ func (s *Server) respondError(w http.ResponseWriter, status int, err error) {
 	s.respond(w, status, map[string]string{"error": err.Error()})
 }
 
+// respondRangeNotSatisfiable sends a 416 error, with the Content-Range
+// header giving the object's size if it is known (non-negative)
+func (s *Server) respondRangeNotSatisfiable(w http.ResponseWriter, size int64, err error) {
+	if size >= 0 {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+	}
+	s.respondError(w, http.StatusRequestedRangeNotSatisfiable, err)
+}
+
 // HTTP Handlers
 // listBuckets handles GET / - List all buckets
 func (s *Server) listBuckets(w http.ResponseWriter, r *http.Request) {
@@ -222,9 +252,31 @@ func (s *Server) deleteBucket(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) listObjects(w http.ResponseWriter, r *http.Request) {
 	bucket := mux.Vars(r)["bucket"]
-	prefix := r.URL.Query().Get("prefix")
+	query := r.URL.Query()
+
+	opts := &types.ListObjectsOptions{
+		Prefix:    query.Get("prefix"),
+		Delimiter: query.Get("delimiter"),
+		Marker:    query.Get("marker"),
+	}
+	if token := query.Get("continuation-token"); token != "" {
+		marker, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, fmt.Errorf("invalid continuation token"))
+			return
+		}
+		opts.Marker = string(marker)
+	}
+	if maxKeys := query.Get("max-keys"); maxKeys != "" {
+		n, err := strconv.Atoi(maxKeys)
+		if err != nil || n < 0 {
+			s.respondError(w, http.StatusBadRequest, fmt.Errorf("invalid max-keys: %q", maxKeys))
+			return
+		}
+		opts.MaxKeys = min(n, storage.DefaultMaxKeys)
+	}
 
-	objects, err := s.storage.ListObjects(r.Context(), bucket, prefix)
+	listing, err := s.storage.ListObjects(r.Context(), bucket, opts)
 	if err != nil {
 		s.respondError(w, http.StatusInternalServerError, err)
 		return
@@ -232,15 +284,26 @@ func (s *Server) listObjects(w http.ResponseWriter, r *http.Request) {
 
 	// Convert to a simpler format for the response
 	var keys []string
-	for _, obj := range objects {
+	for _, obj := range listing.Objects {
 		keys = append(keys, obj.Key)
 	}
 
-	s.respond(w, http.StatusOK, map[string]interface{}{
-		"bucket":  bucket,
-		"prefix":  prefix,
-		"objects": keys,
-	})
+	response := map[string]interface{}{
+		"bucket":      bucket,
+		"prefix":      opts.Prefix,
+		"maxKeys":     listing.MaxKeys,
+		"isTruncated": listing.IsTruncated,
+		"objects":     keys,
+	}
+	if opts.Delimiter != "" {
+		response["delimiter"] = opts.Delimiter
+		response["commonPrefixes"] = listing.CommonPrefixes
+	}
+	if listing.IsTruncated {
+		response["nextMarker"] = listing.NextMarker
+		response["nextContinuationToken"] = base64.RawURLEncoding.EncodeToString([]byte(listing.NextMarker))
+	}
+	s.respond(w, http.StatusOK, response)
 }
 
 func (s *Server) putObject(w http.ResponseWriter, r *http.Request) {
@@ -248,9 +311,9 @@ func (s *Server) putObject(w http.ResponseWriter, r *http.Request) {
 	bucket := vars["bucket"]
 	key := vars["key"]
 
-	data, err := io.ReadAll(r.Body)
-	if err != nil {
-		s.respondError(w, http.StatusBadRequest, fmt.Errorf("failed to read request body: %v", err))
+	// The body is streamed to storage, which needs to know its size
+	if r.ContentLength < 0 {
+		s.respondError(w, http.StatusLengthRequired, fmt.Errorf("MissingContentLength: you must provide the Content-Length HTTP header"))
 		return
 	}
 
@@ -259,51 +322,285 @@ func (s *Server) putObject(w http.ResponseWriter, r *http.Request) {
 		Metadata:    make(map[string]string),
 	}
 
-	// Copy user-defined metadata
-	for k, v := range r.Header {
-		if strings.HasPrefix(k, "X-Amz-Meta-") {
-			metaKey := strings.TrimPrefix(k, "X-Amz-Meta-")
-			if len(v) > 0 {
-				opts.Metadata[metaKey] = v[0]
-			}
+	copyUserMetadata(r.Header, opts.Metadata)
+
+	// Check the preconditions before reading the body. They aren't
+	// rechecked when the object is stored, so a concurrent put can still
+	// slip in between.
+	if hasPutPreconditions(r) {
+		current, err := s.storage.HeadObject(r.Context(), bucket, key, nil)
+		if errors.Is(err, storage.ErrBucketNotFound) {
+			s.respondError(w, http.StatusNotFound, fmt.Errorf("NoSuchBucket: %w", err))
+			return
+		}
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			current, err = nil, nil
+		}
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if !putPreconditionsMet(r, current) {
+			s.respondError(w, http.StatusPreconditionFailed, fmt.Errorf("PreconditionFailed: at least one of the preconditions you specified did not hold"))
+			return
+		}
+	}
+
+	if contentMD5 := r.Header.Get("Content-MD5"); contentMD5 != "" {
+		digest, err := parseContentMD5(contentMD5)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err)
+			return
 		}
+		opts.ContentMD5 = digest
 	}
 
-	if err := s.storage.PutObject(r.Context(), bucket, key, data, opts); err != nil {
+	obj, err := s.storage.PutObject(r.Context(), bucket, key, r.Body, r.ContentLength, opts)
+	if errors.Is(err, storage.ErrBucketNotFound) {
+		s.respondError(w, http.StatusNotFound, fmt.Errorf("NoSuchBucket: %w", err))
+		return
+	}
+	if errors.Is(err, storage.ErrBadDigest) {
+		s.respondError(w, http.StatusBadRequest, fmt.Errorf("BadDigest: the Content-MD5 you specified did not match what was received"))
+		return
+	}
+	if errors.Is(err, storage.ErrIncompleteBody) {
+		s.respondError(w, http.StatusBadRequest, fmt.Errorf("IncompleteBody: %v", err))
+		return
+	}
+	if errors.Is(err, storage.ErrInvalidKey) {
+		s.respondError(w, http.StatusBadRequest, fmt.Errorf("InvalidKey: %v", err))
+		return
+	}
+	if err != nil {
 		s.respondError(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	w.Header().Set("ETag", quoteETag(obj.ETag))
 	s.respond(w, http.StatusOK, map[string]string{
 		"bucket": bucket,
 		"key":    key,
+		"etag":   obj.ETag,
 	})
 }
 
+// parseContentMD5 decodes a base64-encoded Content-MD5 header into a
+// hex-encoded MD5 digest, the form of an ETag
+func parseContentMD5(contentMD5 string) (string, error) {
+	digest, err := base64.StdEncoding.DecodeString(contentMD5)
+	if err != nil || len(digest) != md5.Size {
+		return "", fmt.Errorf("InvalidDigest: the Content-MD5 you specified is not valid")
+	}
+	return hex.EncodeToString(digest), nil
+}
+
+// quoteETag formats an ETag for the ETag response header
+func quoteETag(etag string) string {
+	return `"` + etag + `"`
+}
+
+// copyObject handles PUT /{bucket}/{key} with an X-Amz-Copy-Source header -
+// Copy an object server-side
+func (s *Server) copyObject(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	key := vars["key"]
+
+	srcBucket, srcKey, srcOpts, err := parseCopySource(r.Header.Get("X-Amz-Copy-Source"))
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	directive := strings.ToUpper(r.Header.Get("X-Amz-Metadata-Directive"))
+	if directive != "" && directive != "COPY" && directive != "REPLACE" {
+		s.respondError(w, http.StatusBadRequest, fmt.Errorf("InvalidArgument: unknown metadata directive %q", directive))
+		return
+	}
+	if srcBucket == bucket && srcKey == key && srcOpts.VersionId == "" && directive != "REPLACE" {
+		s.respondError(w, http.StatusBadRequest, fmt.Errorf("InvalidRequest: this copy request is illegal because it is trying to copy an object to itself without changing the object's metadata"))
+		return
+	}
+
+	src, body, err := s.storage.GetObject(r.Context(), srcBucket, srcKey, srcOpts)
+	if errors.Is(err, storage.ErrBucketNotFound) {
+		s.respondError(w, http.StatusNotFound, fmt.Errorf("NoSuchBucket: copy source bucket %s: %w", srcBucket, err))
+		return
+	}
+	if errors.Is(err, storage.ErrObjectNotFound) || errors.Is(err, storage.ErrVersionNotFound) {
+		s.respondError(w, http.StatusNotFound, fmt.Errorf("NoSuchKey: copy source %s/%s: %w", srcBucket, srcKey, err))
+		return
+	}
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer body.Close()
+
+	opts := &types.PutObjectOptions{
+		ContentType: src.ContentType,
+		Metadata:    make(map[string]string),
+	}
+	if directive == "REPLACE" {
+		opts.ContentType = r.Header.Get("Content-Type")
+		copyUserMetadata(r.Header, opts.Metadata)
+	} else {
+		for k, v := range src.Metadata {
+			opts.Metadata[k] = v
+		}
+	}
+
+	obj, err := s.storage.PutObject(r.Context(), bucket, key, body, src.Size, opts)
+	if errors.Is(err, storage.ErrBucketNotFound) {
+		s.respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if errors.Is(err, storage.ErrInvalidKey) {
+		s.respondError(w, http.StatusBadRequest, fmt.Errorf("InvalidKey: %v", err))
+		return
+	}
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("ETag", quoteETag(obj.ETag))
+	if src.VersionId != "" {
+		w.Header().Set("X-Amz-Copy-Source-Version-Id", src.VersionId)
+	}
+	if obj.VersionId != "" {
+		w.Header().Set("X-Amz-Version-Id", obj.VersionId)
+	}
+	s.respond(w, http.StatusOK, map[string]string{
+		"bucket":       bucket,
+		"key":          key,
+		"etag":         obj.ETag,
+		"lastModified": obj.ModifiedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// parseCopySource parses an X-Amz-Copy-Source header of the form
+// "/bucket/key", optionally URL-encoded and followed by "?versionId=ID",
+// into the source bucket, key and the options selecting its version
+func parseCopySource(header string) (string, string, *types.GetObjectOptions, error) {
+	source, query, _ := strings.Cut(header, "?")
+	opts := &types.GetObjectOptions{}
+	if query != "" {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("InvalidArgument: invalid copy source %q", header)
+		}
+		opts.VersionId = values.Get("versionId")
+	}
+
+	source, err := url.PathUnescape(source)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("InvalidArgument: invalid copy source %q", header)
+	}
+	bucket, key, ok := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+	if !ok || bucket == "" || key == "" {
+		return "", "", nil, fmt.Errorf("InvalidArgument: copy source must be of the form /bucket/key, got %q", header)
+	}
+	return bucket, key, opts, nil
+}
+
 func (s *Server) getObject(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	bucket := vars["bucket"]
 	key := vars["key"]
 
-	obj, err := s.storage.GetObject(r.Context(), bucket, key, &types.GetObjectOptions{})
+	opts := &types.GetObjectOptions{
+		VersionId: r.URL.Query().Get("versionId"),
+	}
+	ranged := parseRange(r.Header.Get("Range"), opts)
+
+	obj, body, err := s.storage.GetObject(r.Context(), bucket, key, opts)
+	if errors.Is(err, storage.ErrBucketNotFound) {
+		s.respondError(w, http.StatusNotFound, fmt.Errorf("NoSuchBucket: %w", err))
+		return
+	}
+	if errors.Is(err, storage.ErrObjectNotFound) || errors.Is(err, storage.ErrVersionNotFound) {
+		s.respondError(w, http.StatusNotFound, fmt.Errorf("NoSuchKey: %w", err))
+		return
+	}
+	if errors.Is(err, storage.ErrInvalidRange) {
+		size := int64(-1)
+		if obj, err := s.storage.HeadObject(r.Context(), bucket, key, opts); err == nil {
+			size = obj.Size
+		}
+		s.respondRangeNotSatisfiable(w, size, err)
+		return
+	}
 	if err != nil {
 		s.respondError(w, http.StatusInternalServerError, err)
 		return
 	}
-	if obj == nil {
-		s.respondError(w, http.StatusNotFound, fmt.Errorf("object not found"))
+	defer body.Close()
+
+	if notModified(r, obj) {
+		writeNotModified(w, obj)
 		return
 	}
 
+	start, end, err := storage.ResolveRange(opts, obj.Size)
+	if err != nil {
+		s.respondRangeNotSatisfiable(w, obj.Size, err)
+		return
+	}
+
+	status := http.StatusOK
+	if ranged {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, obj.Size))
+		status = http.StatusPartialContent
+	}
+
+	setObjectHeaders(w, obj, end-start+1)
+	w.WriteHeader(status)
+	_, _ = io.Copy(w, body)
+}
+
+// headObject handles HEAD /{bucket}/{key} - Get an object's headers without its content
+func (s *Server) headObject(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	opts := &types.GetObjectOptions{
+		VersionId: r.URL.Query().Get("versionId"),
+	}
+
+	obj, err := s.storage.HeadObject(r.Context(), vars["bucket"], vars["key"], opts)
+	if errors.Is(err, storage.ErrBucketNotFound) || errors.Is(err, storage.ErrObjectNotFound) || errors.Is(err, storage.ErrVersionNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if notModified(r, obj) {
+		writeNotModified(w, obj)
+		return
+	}
+
+	setObjectHeaders(w, obj, obj.Size)
+	w.WriteHeader(http.StatusOK)
+}
+
+// setObjectHeaders sets the response headers describing an object, with
+// the length of the content being returned
+func setObjectHeaders(w http.ResponseWriter, obj *types.Object, contentLength int64) {
 	w.Header().Set("Content-Type", obj.ContentType)
-	w.Header().Set("Content-Length", fmt.Sprintf("%d", obj.Size))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", contentLength))
 	w.Header().Set("Last-Modified", obj.ModifiedAt.UTC().Format(http.TimeFormat))
+	w.Header().Set("Accept-Ranges", "bytes")
+	if obj.ETag != "" {
+		w.Header().Set("ETag", quoteETag(obj.ETag))
+	}
+	if obj.VersionId != "" {
+		w.Header().Set("X-Amz-Version-Id", obj.VersionId)
+	}
 	for k, v := range obj.Metadata {
 		w.Header().Set("X-Amz-Meta-"+k, v)
 	}
-
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write(obj.Content)
 }
 
 func (s *Server) deleteObject(w http.ResponseWriter, r *http.Request) {
@@ -318,3 +615,211 @@ func (s *Server) deleteObject(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// copyUserMetadata copies the user-defined X-Amz-Meta-* headers into metadata
+func copyUserMetadata(header http.Header, metadata map[string]string) {
+	for k, v := range header {
+		if strings.HasPrefix(k, "X-Amz-Meta-") {
+			metaKey := strings.TrimPrefix(k, "X-Amz-Meta-")
+			if len(v) > 0 {
+				metadata[metaKey] = v[0]
+			}
+		}
+	}
+}
+
+// multipartStatus maps a multipart upload error to an HTTP status code
+func multipartStatus(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrUploadNotFound), errors.Is(err, storage.ErrBucketNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, storage.ErrInvalidPart):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// createMultipartUpload handles POST /{bucket}/{key}?uploads - Start a multipart upload
+func (s *Server) createMultipartUpload(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	key := vars["key"]
+
+	opts := &types.PutObjectOptions{
+		ContentType: r.Header.Get("Content-Type"),
+		Metadata:    make(map[string]string),
+	}
+	copyUserMetadata(r.Header, opts.Metadata)
+
+	uploadID, err := s.multipart.CreateUpload(r.Context(), bucket, key, opts)
+	if err != nil {
+		s.respondError(w, multipartStatus(err), err)
+		return
+	}
+
+	s.respond(w, http.StatusOK, map[string]string{
+		"bucket":   bucket,
+		"key":      key,
+		"uploadId": uploadID,
+	})
+}
+
+// uploadPart handles PUT /{bucket}/{key}?partNumber=N&uploadId=ID - Upload a part
+func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	key := vars["key"]
+
+	partNumber, err := strconv.Atoi(vars["partNumber"])
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, fmt.Errorf("invalid part number: %q", vars["partNumber"]))
+		return
+	}
+
+	if err := s.multipart.UploadPart(r.Context(), bucket, key, vars["uploadId"], partNumber, r.Body); err != nil {
+		s.respondError(w, multipartStatus(err), err)
+		return
+	}
+
+	s.respond(w, http.StatusOK, map[string]interface{}{
+		"bucket":     bucket,
+		"key":        key,
+		"partNumber": partNumber,
+	})
+}
+
+// completeMultipartUpload handles POST /{bucket}/{key}?uploadId=ID - Assemble the parts into the object
+func (s *Server) completeMultipartUpload(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	key := vars["key"]
+
+	if err := s.multipart.CompleteUpload(r.Context(), bucket, key, vars["uploadId"]); err != nil {
+		s.respondError(w, multipartStatus(err), err)
+		return
+	}
+
+	s.respond(w, http.StatusOK, map[string]string{
+		"bucket": bucket,
+		"key":    key,
+	})
+}
+
+// abortMultipartUpload handles DELETE /{bucket}/{key}?uploadId=ID - Discard a multipart upload
+func (s *Server) abortMultipartUpload(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if err := s.multipart.AbortUpload(r.Context(), vars["bucket"], vars["key"], vars["uploadId"]); err != nil {
+		s.respondError(w, multipartStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Versioning status values used by the versioning endpoints
+const (
+	versioningEnabled   = "Enabled"
+	versioningSuspended = "Suspended"
+)
+
+// versioningConfiguration is the request and response body of the bucket
+// versioning endpoints
+type versioningConfiguration struct {
+	Bucket string `json:"bucket,omitempty"`
+	Status string `json:"status"`
+}
+
+// putBucketVersioning handles PUT /{bucket}?versioning - Enable or suspend versioning
+func (s *Server) putBucketVersioning(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	var config versioningConfiguration
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		s.respondError(w, http.StatusBadRequest, fmt.Errorf("invalid versioning configuration: %v", err))
+		return
+	}
+	if config.Status != versioningEnabled && config.Status != versioningSuspended {
+		s.respondError(w, http.StatusBadRequest, fmt.Errorf("versioning status must be %q or %q", versioningEnabled, versioningSuspended))
+		return
+	}
+
+	err := s.storage.SetBucketVersioning(r.Context(), bucket, config.Status == versioningEnabled)
+	if errors.Is(err, storage.ErrBucketNotFound) {
+		s.respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	s.respond(w, http.StatusOK, versioningConfiguration{Bucket: bucket, Status: config.Status})
+}
+
+// getBucketVersioning handles GET /{bucket}?versioning - Get the versioning status
+func (s *Server) getBucketVersioning(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	enabled, err := s.storage.GetBucketVersioning(r.Context(), bucket)
+	if errors.Is(err, storage.ErrBucketNotFound) {
+		s.respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	status := versioningSuspended
+	if enabled {
+		status = versioningEnabled
+	}
+	s.respond(w, http.StatusOK, versioningConfiguration{Bucket: bucket, Status: status})
+}
+
+// objectVersion is an entry of a version listing
+type objectVersion struct {
+	Key            string    `json:"key"`
+	VersionId      string    `json:"versionId"`
+	IsLatest       bool      `json:"isLatest"`
+	IsDeleteMarker bool      `json:"isDeleteMarker,omitempty"`
+	Size           int64     `json:"size"`
+	LastModified   time.Time `json:"lastModified"`
+}
+
+// listObjectVersions handles GET /{bucket}?versions - List all object versions
+func (s *Server) listObjectVersions(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+	prefix := r.URL.Query().Get("prefix")
+
+	objects, err := s.storage.ListObjectVersions(r.Context(), bucket, prefix)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Versions are listed newest first for each key
+	versions := make([]objectVersion, 0, len(objects))
+	for i, obj := range objects {
+		versionId := obj.VersionId
+		if versionId == "" {
+			versionId = types.NullVersionId
+		}
+		versions = append(versions, objectVersion{
+			Key:            obj.Key,
+			VersionId:      versionId,
+			IsLatest:       i == 0 || objects[i-1].Key != obj.Key,
+			IsDeleteMarker: obj.IsDeleteMarker,
+			Size:           obj.Size,
+			LastModified:   obj.ModifiedAt,
+		})
+	}
+
+	s.respond(w, http.StatusOK, map[string]interface{}{
+		"bucket":   bucket,
+		"prefix":   prefix,
+		"versions": versions,
+	})
+}
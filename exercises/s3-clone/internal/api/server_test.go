@@ -3,12 +3,18 @@ package api_test
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/kumarlokesh/s3-clone/internal/api"
 	"github.com/kumarlokesh/s3-clone/internal/metadata"
@@ -79,9 +85,12 @@ func TestAPI(t *testing.T) {
 
 			assert.Equal(t, http.StatusOK, resp.StatusCode)
 
-			obj, err := store.GetObject(context.Background(), bucketName, objectKey, &types.GetObjectOptions{})
+			_, body, err := store.GetObject(context.Background(), bucketName, objectKey, &types.GetObjectOptions{})
 			require.NoError(t, err)
-			assert.Equal(t, objectData, obj.Content)
+			defer body.Close()
+			data, err := io.ReadAll(body)
+			require.NoError(t, err)
+			assert.Equal(t, objectData, data)
 		})
 
 		t.Run("Get object", func(t *testing.T) {
@@ -130,7 +139,7 @@ func TestAPI(t *testing.T) {
 
 			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
 
-			_, err = store.GetObject(context.Background(), bucketName, objectKey, &types.GetObjectOptions{})
+			_, _, err = store.GetObject(context.Background(), bucketName, objectKey, &types.GetObjectOptions{})
 			assert.Error(t, err)
 		})
 
@@ -154,3 +163,808 @@ func TestAPI(t *testing.T) {
 		})
 	})
 }
+
+// setupTestServer starts a test server backed by in-memory storage with a
+// single bucket
+func setupTestServer(t *testing.T, bucket string) (*httptest.Server, storage.Storage) {
+	t.Helper()
+
+	store := storage.NewMemoryStorage(metadata.NewInMemoryMetadata())
+	require.NoError(t, store.CreateBucket(context.Background(), bucket))
+
+	testServer := httptest.NewServer(api.NewServer(":0", store).Handler())
+	t.Cleanup(testServer.Close)
+	return testServer, store
+}
+
+func TestGetObjectRange(t *testing.T) {
+	testServer, store := setupTestServer(t, "test-bucket")
+	content := []byte("0123456789abcdef")
+	_, err := store.PutObject(context.Background(), "test-bucket", "test-object", bytes.NewReader(content), int64(len(content)), &types.PutObjectOptions{
+		ContentType: "text/plain",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		rangeHeader  string
+		wantStatus   int
+		wantBody     string
+		contentRange string
+	}{
+		{
+			name:         "Valid range",
+			rangeHeader:  "bytes=2-5",
+			wantStatus:   http.StatusPartialContent,
+			wantBody:     "2345",
+			contentRange: "bytes 2-5/16",
+		},
+		{
+			name:         "Open-ended range",
+			rangeHeader:  "bytes=10-",
+			wantStatus:   http.StatusPartialContent,
+			wantBody:     "abcdef",
+			contentRange: "bytes 10-15/16",
+		},
+		{
+			name:         "Suffix range",
+			rangeHeader:  "bytes=-3",
+			wantStatus:   http.StatusPartialContent,
+			wantBody:     "def",
+			contentRange: "bytes 13-15/16",
+		},
+		{
+			name:         "Range end beyond object",
+			rangeHeader:  "bytes=14-100",
+			wantStatus:   http.StatusPartialContent,
+			wantBody:     "ef",
+			contentRange: "bytes 14-15/16",
+		},
+		{
+			name:         "Unsatisfiable range",
+			rangeHeader:  "bytes=16-20",
+			wantStatus:   http.StatusRequestedRangeNotSatisfiable,
+			contentRange: "bytes */16",
+		},
+		{
+			name:         "Empty suffix range",
+			rangeHeader:  "bytes=-0",
+			wantStatus:   http.StatusRequestedRangeNotSatisfiable,
+			contentRange: "bytes */16",
+		},
+		{
+			name:        "Reversed range is ignored",
+			rangeHeader: "bytes=5-3",
+			wantStatus:  http.StatusOK,
+			wantBody:    string(content),
+		},
+		{
+			name:        "Malformed range is ignored",
+			rangeHeader: "bytes=abc",
+			wantStatus:  http.StatusOK,
+			wantBody:    string(content),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", testServer.URL+"/test-bucket/test-object", nil)
+			require.NoError(t, err)
+			req.Header.Set("Range", tt.rangeHeader)
+
+			resp, err := testServer.Client().Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			if tt.wantStatus == http.StatusRequestedRangeNotSatisfiable {
+				assert.Equal(t, tt.contentRange, resp.Header.Get("Content-Range"))
+				return
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBody, string(body))
+			assert.Equal(t, fmt.Sprintf("%d", len(tt.wantBody)), resp.Header.Get("Content-Length"))
+			assert.Equal(t, tt.contentRange, resp.Header.Get("Content-Range"))
+		})
+	}
+}
+
+func TestMultipartUpload(t *testing.T) {
+	testServer, store := setupTestServer(t, "test-bucket")
+	client := testServer.Client()
+	objectURL := testServer.URL + "/test-bucket/big-object"
+
+	do := func(method, url string, body []byte) *http.Response {
+		req, err := http.NewRequest(method, url, bytes.NewReader(body))
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	initiate := func() string {
+		resp := do("POST", objectURL+"?uploads", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var result struct {
+			UploadID string `json:"uploadId"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		require.NotEmpty(t, result.UploadID)
+		return result.UploadID
+	}
+
+	t.Run("Upload parts out of order and complete", func(t *testing.T) {
+		uploadID := initiate()
+
+		parts := map[int]string{3: "third part", 1: "first part, ", 2: "second part, "}
+		for _, partNumber := range []int{3, 1, 2} {
+			resp := do("PUT", fmt.Sprintf("%s?partNumber=%d&uploadId=%s", objectURL, partNumber, uploadID), []byte(parts[partNumber]))
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+
+		resp := do("POST", objectURL+"?uploadId="+uploadID, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = do("GET", objectURL, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "first part, second part, third part", string(data))
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		uploadID := initiate()
+
+		resp := do("PUT", fmt.Sprintf("%s?partNumber=1&uploadId=%s", testServer.URL+"/test-bucket/aborted", uploadID), []byte("data"))
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, "upload ID belongs to a different key")
+
+		resp = do("DELETE", objectURL+"?uploadId="+uploadID, nil)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp = do("POST", objectURL+"?uploadId="+uploadID, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		_, body, err := store.GetObject(context.Background(), "test-bucket", "big-object", &types.GetObjectOptions{})
+		require.NoError(t, err, "aborting must not affect the completed object")
+		body.Close()
+	})
+
+	t.Run("Invalid part number", func(t *testing.T) {
+		uploadID := initiate()
+
+		resp := do("PUT", fmt.Sprintf("%s?partNumber=abc&uploadId=%s", objectURL, uploadID), []byte("data"))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = do("PUT", fmt.Sprintf("%s?partNumber=0&uploadId=%s", objectURL, uploadID), []byte("data"))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = do("DELETE", objectURL+"?uploadId="+uploadID, nil)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+}
+
+func TestObjectVersioning(t *testing.T) {
+	testServer, _ := setupTestServer(t, "test-bucket")
+	client := testServer.Client()
+	bucketURL := testServer.URL + "/test-bucket"
+	objectURL := bucketURL + "/test-object"
+
+	do := func(method, url string, body []byte) *http.Response {
+		req, err := http.NewRequest(method, url, bytes.NewReader(body))
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	readBody := func(resp *http.Response) string {
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	type version struct {
+		Key            string `json:"key"`
+		VersionId      string `json:"versionId"`
+		IsLatest       bool   `json:"isLatest"`
+		IsDeleteMarker bool   `json:"isDeleteMarker"`
+	}
+	listVersions := func() []version {
+		resp := do("GET", bucketURL+"?versions", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var result struct {
+			Versions []version `json:"versions"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		return result.Versions
+	}
+
+	// An object stored before versioning is enabled keeps the null version
+	resp := do("PUT", objectURL, []byte("v0"))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = do("PUT", bucketURL+"?versioning", []byte(`{"status": "Enabled"}`))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = do("GET", bucketURL+"?versioning", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, readBody(resp), `"status":"Enabled"`)
+
+	t.Run("Put put get latest", func(t *testing.T) {
+		resp := do("PUT", objectURL, []byte("v1"))
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = do("PUT", objectURL, []byte("v2"))
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = do("GET", objectURL, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "v2", readBody(resp))
+		assert.NotEmpty(t, resp.Header.Get("X-Amz-Version-Id"))
+
+		versions := listVersions()
+		require.Len(t, versions, 3)
+		assert.True(t, versions[0].IsLatest)
+		assert.False(t, versions[1].IsLatest)
+		assert.Equal(t, "null", versions[2].VersionId)
+	})
+
+	t.Run("Get by version", func(t *testing.T) {
+		versions := listVersions()
+		require.Len(t, versions, 3)
+
+		for i, want := range []string{"v2", "v1", "v0"} {
+			resp := do("GET", objectURL+"?versionId="+versions[i].VersionId, nil)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, want, readBody(resp))
+		}
+
+		resp := do("GET", objectURL+"?versionId=missing", nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Delete marker", func(t *testing.T) {
+		previous := listVersions()
+
+		resp := do("DELETE", objectURL, nil)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp = do("GET", objectURL, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp = do("GET", bucketURL, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotContains(t, readBody(resp), "test-object")
+
+		versions := listVersions()
+		require.Len(t, versions, 4)
+		assert.True(t, versions[0].IsDeleteMarker)
+		assert.True(t, versions[0].IsLatest)
+
+		// The data of earlier versions is kept
+		resp = do("GET", objectURL+"?versionId="+previous[0].VersionId, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "v2", readBody(resp))
+
+		// A new put makes the object visible again
+		resp = do("PUT", objectURL, []byte("v3"))
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = do("GET", objectURL, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "v3", readBody(resp))
+	})
+
+	t.Run("Invalid configuration", func(t *testing.T) {
+		resp := do("PUT", bucketURL+"?versioning", []byte(`{"status": "On"}`))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = do("PUT", testServer.URL+"/missing-bucket?versioning", []byte(`{"status": "Enabled"}`))
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+// listResult is the response body of a list objects request
+type listResult struct {
+	Objects               []string `json:"objects"`
+	CommonPrefixes        []string `json:"commonPrefixes"`
+	IsTruncated           bool     `json:"isTruncated"`
+	NextMarker            string   `json:"nextMarker"`
+	NextContinuationToken string   `json:"nextContinuationToken"`
+}
+
+func listObjects(t *testing.T, url string) listResult {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result listResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	return result
+}
+
+func TestListObjectsPagination(t *testing.T) {
+	testServer, store := setupTestServer(t, "test-bucket")
+	ctx := context.Background()
+
+	var want []string
+	for i := 0; i < 25; i++ {
+		key := fmt.Sprintf("object-%02d", i)
+		_, err := store.PutObject(ctx, "test-bucket", key, strings.NewReader("data"), 4, &types.PutObjectOptions{})
+		require.NoError(t, err)
+		want = append(want, key)
+	}
+
+	t.Run("Marker", func(t *testing.T) {
+		var keys []string
+		marker := ""
+		for page := 0; ; page++ {
+			require.Less(t, page, 3, "expected three pages")
+
+			result := listObjects(t, fmt.Sprintf("%s/test-bucket?max-keys=10&marker=%s", testServer.URL, marker))
+			keys = append(keys, result.Objects...)
+			if !result.IsTruncated {
+				assert.Len(t, result.Objects, 5)
+				break
+			}
+			assert.Len(t, result.Objects, 10)
+			assert.Equal(t, result.Objects[len(result.Objects)-1], result.NextMarker)
+			marker = result.NextMarker
+		}
+		assert.Equal(t, want, keys)
+	})
+
+	t.Run("Continuation token", func(t *testing.T) {
+		var keys []string
+		token := ""
+		for page := 0; ; page++ {
+			require.Less(t, page, 3, "expected three pages")
+
+			result := listObjects(t, fmt.Sprintf("%s/test-bucket?max-keys=10&continuation-token=%s", testServer.URL, token))
+			keys = append(keys, result.Objects...)
+			if !result.IsTruncated {
+				break
+			}
+			token = result.NextContinuationToken
+		}
+		assert.Equal(t, want, keys)
+	})
+
+	t.Run("Invalid max-keys", func(t *testing.T) {
+		resp, err := http.Get(testServer.URL + "/test-bucket?max-keys=-1")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestListObjectsDelimiter(t *testing.T) {
+	testServer, store := setupTestServer(t, "test-bucket")
+	ctx := context.Background()
+
+	for _, key := range []string{
+		"docs/guide.md",
+		"photos/2023/a.jpg",
+		"photos/2023/b.jpg",
+		"photos/2024/c.jpg",
+		"photos/readme.txt",
+		"root.txt",
+	} {
+		_, err := store.PutObject(ctx, "test-bucket", key, strings.NewReader("data"), 4, &types.PutObjectOptions{})
+		require.NoError(t, err)
+	}
+
+	result := listObjects(t, testServer.URL+"/test-bucket?delimiter=/")
+	assert.Equal(t, []string{"root.txt"}, result.Objects)
+	assert.Equal(t, []string{"docs/", "photos/"}, result.CommonPrefixes)
+	assert.False(t, result.IsTruncated)
+
+	result = listObjects(t, testServer.URL+"/test-bucket?delimiter=/&prefix=photos/")
+	assert.Equal(t, []string{"photos/readme.txt"}, result.Objects)
+	assert.Equal(t, []string{"photos/2023/", "photos/2024/"}, result.CommonPrefixes)
+
+	// Common prefixes count towards max-keys and can be paged through
+	var entries []string
+	marker := ""
+	for page := 0; ; page++ {
+		require.Less(t, page, 3, "expected three pages")
+
+		result := listObjects(t, fmt.Sprintf("%s/test-bucket?delimiter=/&prefix=photos/&max-keys=1&marker=%s", testServer.URL, marker))
+		entries = append(entries, result.CommonPrefixes...)
+		entries = append(entries, result.Objects...)
+		if !result.IsTruncated {
+			break
+		}
+		marker = result.NextMarker
+	}
+	assert.Equal(t, []string{"photos/2023/", "photos/2024/", "photos/readme.txt"}, entries)
+}
+
+func TestObjectETag(t *testing.T) {
+	testServer, _ := setupTestServer(t, "test-bucket")
+	client := testServer.Client()
+	objectURL := testServer.URL + "/test-bucket/test-object"
+	content := []byte("etag test data")
+	sum := md5.Sum(content)
+	wantETag := `"` + hex.EncodeToString(sum[:]) + `"`
+
+	put := func(contentMD5 string) *http.Response {
+		req, err := http.NewRequest("PUT", objectURL, bytes.NewReader(content))
+		require.NoError(t, err)
+		if contentMD5 != "" {
+			req.Header.Set("Content-MD5", contentMD5)
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	t.Run("ETag is stable across GETs", func(t *testing.T) {
+		resp := put("")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, wantETag, resp.Header.Get("ETag"))
+
+		for i := 0; i < 2; i++ {
+			resp, err := http.Get(objectURL)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, wantETag, resp.Header.Get("ETag"))
+		}
+	})
+
+	t.Run("Matching Content-MD5 is accepted", func(t *testing.T) {
+		resp := put(base64.StdEncoding.EncodeToString(sum[:]))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Wrong Content-MD5 is rejected", func(t *testing.T) {
+		wrong := md5.Sum([]byte("other data"))
+		resp := put(base64.StdEncoding.EncodeToString(wrong[:]))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "BadDigest")
+
+		resp = put("not base64!")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestHeadObject(t *testing.T) {
+	testServer, _ := setupTestServer(t, "test-bucket")
+	client := testServer.Client()
+	objectURL := testServer.URL + "/test-bucket/test-object"
+	content := []byte("head test data")
+
+	req, err := http.NewRequest("PUT", objectURL, bytes.NewReader(content))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("X-Amz-Meta-Author", "tester")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	t.Run("Headers match GET without a body", func(t *testing.T) {
+		getResp, err := client.Get(objectURL)
+		require.NoError(t, err)
+		getResp.Body.Close()
+		require.Equal(t, http.StatusOK, getResp.StatusCode)
+
+		headResp, err := client.Head(objectURL)
+		require.NoError(t, err)
+		defer headResp.Body.Close()
+		assert.Equal(t, http.StatusOK, headResp.StatusCode)
+
+		for _, header := range []string{"Content-Type", "Content-Length", "Last-Modified", "ETag", "X-Amz-Meta-Author"} {
+			assert.NotEmpty(t, getResp.Header.Get(header), header)
+			assert.Equal(t, getResp.Header.Get(header), headResp.Header.Get(header), header)
+		}
+		assert.Equal(t, fmt.Sprintf("%d", len(content)), headResp.Header.Get("Content-Length"))
+
+		body, err := io.ReadAll(headResp.Body)
+		require.NoError(t, err)
+		assert.Empty(t, body)
+	})
+
+	t.Run("Missing object", func(t *testing.T) {
+		resp, err := client.Head(testServer.URL + "/test-bucket/missing-object")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestGetObjectNotFound(t *testing.T) {
+	backends := map[string]func(t *testing.T) storage.Storage{
+		"memory": func(t *testing.T) storage.Storage {
+			return storage.NewMemoryStorage(metadata.NewInMemoryMetadata())
+		},
+		"filesystem": func(t *testing.T) storage.Storage {
+			store, err := storage.NewFilesystemStorage(t.TempDir(), metadata.NewInMemoryMetadata())
+			require.NoError(t, err)
+			return store
+		},
+	}
+
+	for name, newStore := range backends {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			require.NoError(t, store.CreateBucket(context.Background(), "test-bucket"))
+			testServer := httptest.NewServer(api.NewServer(":0", store).Handler())
+			defer testServer.Close()
+
+			tests := []struct {
+				name    string
+				path    string
+				message string
+			}{
+				{"missing object", "/test-bucket/missing-object", "NoSuchKey"},
+				{"missing bucket", "/missing-bucket/test-object", "NoSuchBucket"},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					resp, err := http.Get(testServer.URL + tt.path)
+					require.NoError(t, err)
+					defer resp.Body.Close()
+					assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+					var body map[string]string
+					require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+					assert.Contains(t, body["error"], tt.message)
+
+					headResp, err := http.Head(testServer.URL + tt.path)
+					require.NoError(t, err)
+					headResp.Body.Close()
+					assert.Equal(t, http.StatusNotFound, headResp.StatusCode)
+				})
+			}
+		})
+	}
+}
+
+// patternReader generates n bytes of a repeating pattern without holding
+// them in memory
+type patternReader struct {
+	n, off int64
+}
+
+func (r *patternReader) Read(p []byte) (int, error) {
+	if r.off >= r.n {
+		return 0, io.EOF
+	}
+	p = p[:min(int64(len(p)), r.n-r.off)]
+	for i := range p {
+		p[i] = byte((r.off + int64(i)) % 251)
+	}
+	r.off += int64(len(p))
+	return len(p), nil
+}
+
+func TestLargeObjectStreaming(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large object test in short mode")
+	}
+	const size = 64 << 20
+
+	ctx := context.Background()
+	store, err := storage.NewFilesystemStorage(t.TempDir(), metadata.NewInMemoryMetadata())
+	require.NoError(t, err)
+	require.NoError(t, store.CreateBucket(ctx, "test-bucket"))
+
+	testServer := httptest.NewServer(api.NewServer(":0", store).Handler())
+	t.Cleanup(testServer.Close)
+	client := testServer.Client()
+	objectURL := testServer.URL + "/test-bucket/large-object"
+
+	want := md5.New()
+	_, err = io.Copy(want, &patternReader{n: size})
+	require.NoError(t, err)
+	wantETag := hex.EncodeToString(want.Sum(nil))
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	req, err := http.NewRequest("PUT", objectURL, &patternReader{n: size})
+	require.NoError(t, err)
+	req.ContentLength = size
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `"`+wantETag+`"`, resp.Header.Get("ETag"))
+
+	resp, err = client.Get(objectURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, fmt.Sprintf("%d", size), resp.Header.Get("Content-Length"))
+
+	got := md5.New()
+	n, err := io.Copy(got, resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, int64(size), n)
+	assert.Equal(t, wantETag, hex.EncodeToString(got.Sum(nil)))
+
+	// Buffering the object would allocate at least its size on each side
+	runtime.ReadMemStats(&after)
+	allocated := after.TotalAlloc - before.TotalAlloc
+	assert.Less(t, allocated, uint64(size/8), "allocated %d bytes streaming a %d byte object", allocated, size)
+}
+
+func TestConditionalRequests(t *testing.T) {
+	testServer, _ := setupTestServer(t, "test-bucket")
+	client := testServer.Client()
+	objectURL := testServer.URL + "/test-bucket/test-object"
+	content := []byte("conditional test data")
+
+	do := func(method, url string, body []byte, header map[string]string) *http.Response {
+		req, err := http.NewRequest(method, url, bytes.NewReader(body))
+		require.NoError(t, err)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := do("PUT", objectURL, content, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	lastModified, err := http.ParseTime(do("HEAD", objectURL, nil, nil).Header.Get("Last-Modified"))
+	require.NoError(t, err)
+
+	getTests := []struct {
+		name       string
+		header     map[string]string
+		wantStatus int
+	}{
+		{"Matching ETag", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+		{"Matching one of several ETags", map[string]string{"If-None-Match": `"other", ` + etag}, http.StatusNotModified},
+		{"Non-matching ETag", map[string]string{"If-None-Match": `"other"`}, http.StatusOK},
+		{"Modified since the past", map[string]string{"If-Modified-Since": lastModified.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+		{"Not modified since the future", map[string]string{"If-Modified-Since": lastModified.Add(time.Hour).Format(http.TimeFormat)}, http.StatusNotModified},
+		{"Not modified since last modification", map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)}, http.StatusNotModified},
+		{"If-None-Match takes precedence", map[string]string{
+			"If-None-Match":     `"other"`,
+			"If-Modified-Since": lastModified.Add(time.Hour).Format(http.TimeFormat),
+		}, http.StatusOK},
+	}
+
+	for _, tc := range getTests {
+		t.Run("GET "+tc.name, func(t *testing.T) {
+			resp := do("GET", objectURL, nil, tc.header)
+			assert.Equal(t, tc.wantStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			if tc.wantStatus == http.StatusNotModified {
+				assert.Empty(t, body)
+				assert.Equal(t, etag, resp.Header.Get("ETag"))
+			} else {
+				assert.Equal(t, content, body)
+			}
+
+			resp = do("HEAD", objectURL, nil, tc.header)
+			assert.Equal(t, tc.wantStatus, resp.StatusCode, "HEAD")
+		})
+	}
+
+	putTests := []struct {
+		name       string
+		key        string
+		header     map[string]string
+		wantStatus int
+	}{
+		{"If-Match with the current ETag", "test-object", map[string]string{"If-Match": etag}, http.StatusOK},
+		{"If-Match with a stale ETag", "test-object", map[string]string{"If-Match": `"stale"`}, http.StatusPreconditionFailed},
+		{"If-Match on a missing object", "new-object", map[string]string{"If-Match": "*"}, http.StatusPreconditionFailed},
+		{"If-None-Match * on an existing object", "test-object", map[string]string{"If-None-Match": "*"}, http.StatusPreconditionFailed},
+		{"If-None-Match with the current ETag", "test-object", map[string]string{"If-None-Match": etag}, http.StatusPreconditionFailed},
+		{"If-None-Match * on a missing object", "new-object", map[string]string{"If-None-Match": "*"}, http.StatusOK},
+	}
+
+	for _, tc := range putTests {
+		t.Run("PUT "+tc.name, func(t *testing.T) {
+			resp := do("PUT", testServer.URL+"/test-bucket/"+tc.key, content, tc.header)
+			assert.Equal(t, tc.wantStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestCopyObject(t *testing.T) {
+	testServer, store := setupTestServer(t, "test-bucket")
+	require.NoError(t, store.CreateBucket(context.Background(), "other-bucket"))
+	client := testServer.Client()
+	sourceURL := testServer.URL + "/test-bucket/source-object"
+	content := []byte("copy test data")
+
+	do := func(method, url string, body []byte, header map[string]string) *http.Response {
+		req, err := http.NewRequest(method, url, bytes.NewReader(body))
+		require.NoError(t, err)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := do("PUT", sourceURL, content, map[string]string{
+		"Content-Type":    "text/plain",
+		"X-Amz-Meta-Team": "storage",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+
+	t.Run("Copy with metadata", func(t *testing.T) {
+		destURL := testServer.URL + "/other-bucket/copied-object"
+		resp := do("PUT", destURL, nil, map[string]string{"X-Amz-Copy-Source": "/test-bucket/source-object"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, etag, resp.Header.Get("ETag"))
+
+		resp = do("GET", destURL, nil, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, content, body)
+		assert.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "storage", resp.Header.Get("X-Amz-Meta-Team"))
+	})
+
+	t.Run("Missing source", func(t *testing.T) {
+		resp := do("PUT", testServer.URL+"/test-bucket/copied-object", nil, map[string]string{"X-Amz-Copy-Source": "/test-bucket/missing-object"})
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp = do("PUT", testServer.URL+"/test-bucket/copied-object", nil, map[string]string{"X-Amz-Copy-Source": "no-key"})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Replace metadata", func(t *testing.T) {
+		destURL := testServer.URL + "/test-bucket/replaced-object"
+		resp := do("PUT", destURL, nil, map[string]string{
+			"X-Amz-Copy-Source":        "/test-bucket/source-object",
+			"X-Amz-Metadata-Directive": "REPLACE",
+			"Content-Type":             "application/octet-stream",
+			"X-Amz-Meta-Owner":         "copier",
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = do("HEAD", destURL, nil, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/octet-stream", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "copier", resp.Header.Get("X-Amz-Meta-Owner"))
+		assert.Empty(t, resp.Header.Get("X-Amz-Meta-Team"))
+		assert.Equal(t, fmt.Sprintf("%d", len(content)), resp.Header.Get("Content-Length"))
+	})
+
+	t.Run("Copy onto itself", func(t *testing.T) {
+		resp := do("PUT", sourceURL, nil, map[string]string{"X-Amz-Copy-Source": "/test-bucket/source-object"})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = do("PUT", sourceURL, nil, map[string]string{
+			"X-Amz-Copy-Source":        "/test-bucket/source-object",
+			"X-Amz-Metadata-Directive": "REPLACE",
+			"X-Amz-Meta-Team":          "platform",
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = do("GET", sourceURL, nil, nil)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, content, body)
+		assert.Equal(t, "platform", resp.Header.Get("X-Amz-Meta-Team"))
+	})
+}
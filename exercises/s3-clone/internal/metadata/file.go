@@ -0,0 +1,128 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kumarlokesh/s3-clone/internal/types"
+)
+
+// fileMetadata is a metadata service that keeps its state in memory and
+// saves a snapshot of it to a JSON file after every change, so that it
+// survives restarts. Each save rewrites the whole file, which is fine for
+// the number of objects this service is meant for.
+type fileMetadata struct {
+	*inMemoryMetadata
+	path string
+}
+
+// fileSnapshot is the content of a metadata file
+type fileSnapshot struct {
+	Buckets map[string]bool           `json:"buckets"`
+	Objects map[string][]types.Object `json:"objects"`
+}
+
+// NewFileMetadata creates a metadata service persisted to the file at
+// path, loading the metadata saved there if the file exists
+func NewFileMetadata(path string) (Service, error) {
+	m := &fileMetadata{
+		inMemoryMetadata: NewInMemoryMetadata().(*inMemoryMetadata),
+		path:             path,
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata file: %w", err)
+	}
+
+	var snapshot fileSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata file %s: %w", path, err)
+	}
+	if snapshot.Buckets != nil {
+		m.buckets = snapshot.Buckets
+	}
+	if snapshot.Objects != nil {
+		m.objects = snapshot.Objects
+	}
+	return m, nil
+}
+
+// save writes the metadata to a temporary file and moves it over the
+// metadata file, so that a crash never leaves a partially written file
+func (m *fileMetadata) save() error {
+	data, err := json.Marshal(fileSnapshot{Buckets: m.buckets, Objects: m.objects})
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return fmt.Errorf("failed to create metadata directory: %w", err)
+	}
+	tempPath := m.path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write metadata file: %w", err)
+	}
+	if err := os.Rename(tempPath, m.path); err != nil {
+		return fmt.Errorf("failed to replace metadata file: %w", err)
+	}
+	return nil
+}
+
+// PutObjectMetadata stores the metadata of a new object version and saves it
+func (m *fileMetadata) PutObjectMetadata(ctx context.Context, obj *types.Object) error {
+	if err := m.inMemoryMetadata.PutObjectMetadata(ctx, obj); err != nil {
+		return err
+	}
+	return m.save()
+}
+
+// DeleteObjectMetadata removes the unversioned copy of an object and saves
+// the change
+func (m *fileMetadata) DeleteObjectMetadata(ctx context.Context, bucket, key string) error {
+	if err := m.inMemoryMetadata.DeleteObjectMetadata(ctx, bucket, key); err != nil {
+		return err
+	}
+	return m.save()
+}
+
+// CreateBucketMetadata adds a bucket and saves it
+func (m *fileMetadata) CreateBucketMetadata(ctx context.Context, bucket string) error {
+	if err := m.inMemoryMetadata.CreateBucketMetadata(ctx, bucket); err != nil {
+		return err
+	}
+	return m.save()
+}
+
+// DeleteBucketMetadata removes a bucket and the metadata of its objects and
+// saves the change
+func (m *fileMetadata) DeleteBucketMetadata(ctx context.Context, bucket string) error {
+	if err := m.inMemoryMetadata.DeleteBucketMetadata(ctx, bucket); err != nil {
+		return err
+	}
+	return m.save()
+}
+
+// SetBucketVersioning enables or disables versioning for a bucket and saves
+// the change
+func (m *fileMetadata) SetBucketVersioning(ctx context.Context, bucket string, enabled bool) error {
+	if err := m.inMemoryMetadata.SetBucketVersioning(ctx, bucket, enabled); err != nil {
+		return err
+	}
+	return m.save()
+}
+
+// Ping checks that the metadata file's directory is accessible
+func (m *fileMetadata) Ping(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Dir(m.path)); err != nil {
+		return fmt.Errorf("failed to access metadata directory: %w", err)
+	}
+	return nil
+}
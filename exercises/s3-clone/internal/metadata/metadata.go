@@ -2,6 +2,10 @@ package metadata
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/kumarlokesh/s3-clone/internal/types"
 )
 
@@ -11,13 +15,19 @@ type Service interface {
 	PutObjectMetadata(ctx context.Context, obj *types.Object) error
 	GetObjectMetadata(ctx context.Context, bucket, key string) (*types.Object, error)
 	DeleteObjectMetadata(ctx context.Context, bucket, key string) error
-	ListObjectMetadata(ctx context.Context, bucket, prefix string) ([]types.Object, error)
+	ListObjectMetadata(ctx context.Context, bucket, prefix, startAfter string, maxKeys int) ([]types.Object, error)
+
+	// Object version operations
+	GetObjectVersionMetadata(ctx context.Context, bucket, key, versionId string) (*types.Object, error)
+	ListObjectVersions(ctx context.Context, bucket, prefix string) ([]types.Object, error)
 
 	// Bucket metadata operations
 	CreateBucketMetadata(ctx context.Context, bucket string) error
 	DeleteBucketMetadata(ctx context.Context, bucket string) error
 	ListBucketsMetadata(ctx context.Context) ([]string, error)
 	BucketExists(ctx context.Context, bucket string) (bool, error)
+	SetBucketVersioning(ctx context.Context, bucket string, enabled bool) error
+	BucketVersioning(ctx context.Context, bucket string) (bool, error)
 
 	// Health check
 	Ping(ctx context.Context) error
@@ -29,57 +39,128 @@ type Service interface {
 // like etcd, Redis, or a database
 
 type inMemoryMetadata struct {
-	buckets map[string]struct{}
-	objects map[string]types.Object // key: "bucket/key"
+	buckets map[string]bool           // bucket name to whether versioning is enabled
+	objects map[string][]types.Object // key: "bucket/key", versions oldest first
 }
 
 // NewInMemoryMetadata creates a new in-memory metadata service
 func NewInMemoryMetadata() Service {
 	return &inMemoryMetadata{
-		buckets: make(map[string]struct{}),
-		objects: make(map[string]types.Object),
+		buckets: make(map[string]bool),
+		objects: make(map[string][]types.Object),
 	}
 }
 
+// PutObjectMetadata stores the metadata of a new object version, which
+// becomes the latest version. An object without a version ID replaces the
+// existing unversioned copy of the object.
 func (m *inMemoryMetadata) PutObjectMetadata(ctx context.Context, obj *types.Object) error {
 	key := obj.Bucket + "/" + obj.Key
-	m.objects[key] = *obj
+	versions := m.objects[key]
+	if obj.VersionId == "" {
+		versions = removeVersion(versions, "")
+	}
+	m.objects[key] = append(versions, *obj)
 	return nil
 }
 
+// GetObjectMetadata returns the metadata of the latest version of an
+// object, or nil if the object does not exist or its latest version is a
+// delete marker
 func (m *inMemoryMetadata) GetObjectMetadata(ctx context.Context, bucket, key string) (*types.Object, error) {
-	obj, exists := m.objects[bucket+"/"+key]
-	if !exists {
+	versions := m.objects[bucket+"/"+key]
+	if len(versions) == 0 {
+		return nil, nil
+	}
+	obj := versions[len(versions)-1]
+	if obj.IsDeleteMarker {
 		return nil, nil
 	}
 	return &obj, nil
 }
 
+// DeleteObjectMetadata removes the unversioned copy of an object
 func (m *inMemoryMetadata) DeleteObjectMetadata(ctx context.Context, bucket, key string) error {
-	delete(m.objects, bucket+"/"+key)
+	key = bucket + "/" + key
+	versions := removeVersion(m.objects[key], "")
+	if len(versions) == 0 {
+		delete(m.objects, key)
+	} else {
+		m.objects[key] = versions
+	}
 	return nil
 }
 
-func (m *inMemoryMetadata) ListObjectMetadata(ctx context.Context, bucket, prefix string) ([]types.Object, error) {
+// ListObjectMetadata returns the latest version of the objects whose key
+// starts with prefix and sorts after startAfter, in key order. At most
+// maxKeys objects are returned, or all of them if maxKeys is 0. Objects
+// whose latest version is a delete marker are skipped.
+func (m *inMemoryMetadata) ListObjectMetadata(ctx context.Context, bucket, prefix, startAfter string, maxKeys int) ([]types.Object, error) {
 	var result []types.Object
-	for _, obj := range m.objects {
-		if obj.Bucket == bucket {
-			if prefix == "" || (len(obj.Key) >= len(prefix) && obj.Key[:len(prefix)] == prefix) {
-				result = append(result, obj)
-			}
+	for _, versions := range m.objects {
+		obj := versions[len(versions)-1]
+		if obj.Bucket == bucket && strings.HasPrefix(obj.Key, prefix) && obj.Key > startAfter && !obj.IsDeleteMarker {
+			result = append(result, obj)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	if maxKeys > 0 && len(result) > maxKeys {
+		result = result[:maxKeys]
+	}
+	return result, nil
+}
+
+// GetObjectVersionMetadata returns the metadata of a specific version of an
+// object, or nil if the version does not exist
+func (m *inMemoryMetadata) GetObjectVersionMetadata(ctx context.Context, bucket, key, versionId string) (*types.Object, error) {
+	for _, obj := range m.objects[bucket+"/"+key] {
+		if obj.VersionId == versionId {
+			return &obj, nil
+		}
+	}
+	return nil, nil
+}
+
+// ListObjectVersions returns all versions, including delete markers, of
+// the objects whose key starts with prefix, sorted by key and then from
+// newest to oldest
+func (m *inMemoryMetadata) ListObjectVersions(ctx context.Context, bucket, prefix string) ([]types.Object, error) {
+	var keys []string
+	for key, versions := range m.objects {
+		obj := versions[0]
+		if obj.Bucket == bucket && strings.HasPrefix(obj.Key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var result []types.Object
+	for _, key := range keys {
+		versions := m.objects[key]
+		for i := len(versions) - 1; i >= 0; i-- {
+			result = append(result, versions[i])
 		}
 	}
 	return result, nil
 }
 
 func (m *inMemoryMetadata) CreateBucketMetadata(ctx context.Context, bucket string) error {
-	m.buckets[bucket] = struct{}{}
+	m.buckets[bucket] = false
 	return nil
 }
 
+// DeleteBucketMetadata removes a bucket along with the metadata of any
+// object versions left in it
 func (m *inMemoryMetadata) DeleteBucketMetadata(ctx context.Context, bucket string) error {
 	delete(m.buckets, bucket)
-	// In a real implementation, we would also need to clean up objects
+	for key, versions := range m.objects {
+		if versions[0].Bucket == bucket {
+			delete(m.objects, key)
+		}
+	}
 	return nil
 }
 
@@ -96,6 +177,32 @@ func (m *inMemoryMetadata) BucketExists(ctx context.Context, bucket string) (boo
 	return exists, nil
 }
 
+// SetBucketVersioning enables or disables versioning for a bucket. Existing
+// versions are kept when versioning is disabled.
+func (m *inMemoryMetadata) SetBucketVersioning(ctx context.Context, bucket string, enabled bool) error {
+	if _, exists := m.buckets[bucket]; !exists {
+		return fmt.Errorf("bucket does not exist")
+	}
+	m.buckets[bucket] = enabled
+	return nil
+}
+
+// BucketVersioning reports whether versioning is enabled for a bucket
+func (m *inMemoryMetadata) BucketVersioning(ctx context.Context, bucket string) (bool, error) {
+	return m.buckets[bucket], nil
+}
+
 func (m *inMemoryMetadata) Ping(ctx context.Context) error {
 	return nil // Always healthy in-memory
 }
+
+// removeVersion returns versions without the version with the given ID
+func removeVersion(versions []types.Object, versionId string) []types.Object {
+	result := versions[:0:0]
+	for _, obj := range versions {
+		if obj.VersionId != versionId {
+			result = append(result, obj)
+		}
+	}
+	return result
+}
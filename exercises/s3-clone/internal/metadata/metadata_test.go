@@ -2,6 +2,8 @@ package metadata_test
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -57,16 +59,16 @@ func TestInMemoryMetadata(t *testing.T) {
 		assert.Equal(t, obj.Size, gotObj.Size)
 		assert.Equal(t, obj.Metadata, gotObj.Metadata)
 
-		objects, err := svc.ListObjectMetadata(ctx, "test-bucket", "")
+		objects, err := svc.ListObjectMetadata(ctx, "test-bucket", "", "", 0)
 		require.NoError(t, err)
 		require.Len(t, objects, 1)
 		assert.Equal(t, "test-object", objects[0].Key)
 
-		objects, err = svc.ListObjectMetadata(ctx, "test-bucket", "test-")
+		objects, err = svc.ListObjectMetadata(ctx, "test-bucket", "test-", "", 0)
 		require.NoError(t, err)
 		assert.Len(t, objects, 1)
 
-		objects, err = svc.ListObjectMetadata(ctx, "test-bucket", "non-matching")
+		objects, err = svc.ListObjectMetadata(ctx, "test-bucket", "non-matching", "", 0)
 		require.NoError(t, err)
 		assert.Empty(t, objects)
 
@@ -78,6 +80,61 @@ func TestInMemoryMetadata(t *testing.T) {
 		assert.Nil(t, gotObj)
 	})
 
+	t.Run("Sorted bounded listing", func(t *testing.T) {
+		for _, key := range []string{"list-c", "list-a", "list-d", "list-b"} {
+			require.NoError(t, svc.PutObjectMetadata(ctx, &types.Object{Key: key, Bucket: "test-bucket"}))
+		}
+
+		objects, err := svc.ListObjectMetadata(ctx, "test-bucket", "list-", "list-a", 2)
+		require.NoError(t, err)
+		require.Len(t, objects, 2)
+		assert.Equal(t, "list-b", objects[0].Key)
+		assert.Equal(t, "list-c", objects[1].Key)
+
+		for _, key := range []string{"list-a", "list-b", "list-c", "list-d"} {
+			require.NoError(t, svc.DeleteObjectMetadata(ctx, "test-bucket", key))
+		}
+	})
+
+	t.Run("Object versions", func(t *testing.T) {
+		require.NoError(t, svc.SetBucketVersioning(ctx, "test-bucket", true))
+		versioned, err := svc.BucketVersioning(ctx, "test-bucket")
+		require.NoError(t, err)
+		assert.True(t, versioned)
+
+		for _, versionId := range []string{"v1", "v2"} {
+			err := svc.PutObjectMetadata(ctx, &types.Object{Key: "versioned", Bucket: "test-bucket", VersionId: versionId})
+			require.NoError(t, err)
+		}
+
+		latest, err := svc.GetObjectMetadata(ctx, "test-bucket", "versioned")
+		require.NoError(t, err)
+		require.NotNil(t, latest)
+		assert.Equal(t, "v2", latest.VersionId)
+
+		v1, err := svc.GetObjectVersionMetadata(ctx, "test-bucket", "versioned", "v1")
+		require.NoError(t, err)
+		require.NotNil(t, v1)
+		assert.Equal(t, "v1", v1.VersionId)
+
+		err = svc.PutObjectMetadata(ctx, &types.Object{Key: "versioned", Bucket: "test-bucket", VersionId: "v3", IsDeleteMarker: true})
+		require.NoError(t, err)
+
+		latest, err = svc.GetObjectMetadata(ctx, "test-bucket", "versioned")
+		require.NoError(t, err)
+		assert.Nil(t, latest, "a delete marker hides the object")
+
+		objects, err := svc.ListObjectMetadata(ctx, "test-bucket", "versioned", "", 0)
+		require.NoError(t, err)
+		assert.Empty(t, objects)
+
+		versions, err := svc.ListObjectVersions(ctx, "test-bucket", "versioned")
+		require.NoError(t, err)
+		require.Len(t, versions, 3)
+		assert.Equal(t, "v3", versions[0].VersionId)
+		assert.Equal(t, "v1", versions[2].VersionId)
+	})
+
 	t.Run("Delete bucket", func(t *testing.T) {
 		err := svc.DeleteBucketMetadata(ctx, "test-bucket")
 		require.NoError(t, err)
@@ -96,3 +153,50 @@ func TestInMemoryMetadata(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestFileMetadata(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	ctx := context.Background()
+
+	svc, err := metadata.NewFileMetadata(path)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.CreateBucketMetadata(ctx, "kept"))
+	require.NoError(t, svc.CreateBucketMetadata(ctx, "deleted"))
+	require.NoError(t, svc.SetBucketVersioning(ctx, "kept", true))
+	require.NoError(t, svc.PutObjectMetadata(ctx, &types.Object{
+		Key:         "test-object",
+		Bucket:      "kept",
+		ContentType: "text/plain",
+		Metadata:    map[string]string{"key1": "value1"},
+		Size:        12,
+		ETag:        "etag",
+		VersionId:   "v1",
+	}))
+	require.NoError(t, svc.DeleteBucketMetadata(ctx, "deleted"))
+
+	reopened, err := metadata.NewFileMetadata(path)
+	require.NoError(t, err)
+
+	buckets, err := reopened.ListBucketsMetadata(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kept"}, buckets)
+
+	enabled, err := reopened.BucketVersioning(ctx, "kept")
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	obj, err := reopened.GetObjectMetadata(ctx, "kept", "test-object")
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", obj.ContentType)
+	assert.Equal(t, "value1", obj.Metadata["key1"])
+	assert.Equal(t, "v1", obj.VersionId)
+
+	t.Run("Corrupt file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "metadata.json")
+		require.NoError(t, os.WriteFile(path, []byte("not json"), 0644))
+
+		_, err := metadata.NewFileMetadata(path)
+		assert.Error(t, err)
+	})
+}
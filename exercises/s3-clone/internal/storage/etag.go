@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"hash"
+
+	"github.com/kumarlokesh/s3-clone/internal/types"
+)
+
+// ComputeETag returns the ETag of an object's content: the hex-encoded MD5
+// digest, as S3 uses for objects uploaded in a single request
+func ComputeETag(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// newETagHash returns a hash computing the ETag of content streamed into it
+func newETagHash() hash.Hash {
+	return md5.New()
+}
+
+// etagOf returns the ETag of the content written to a hash from newETagHash
+func etagOf(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// verifyDigest checks the ETag of received content against the digest
+// expected by opts, if any
+func verifyDigest(opts *types.PutObjectOptions, etag string) error {
+	if opts != nil && opts.ContentMD5 != "" && opts.ContentMD5 != etag {
+		return ErrBadDigest
+	}
+	return nil
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/kumarlokesh/s3-clone/internal/metadata"
 	"github.com/kumarlokesh/s3-clone/internal/types"
@@ -45,9 +49,62 @@ func (s *filesystemStorage) bucketPath(bucket string) string {
 // objectPath returns the filesystem path for an object
 func (s *filesystemStorage) objectPath(bucket, key string) string {
 	bucketPath := s.bucketPath(bucket)
-	// Replace path separators in the key to prevent directory traversal
-	safeKey := strings.ReplaceAll(key, string(filepath.Separator), "_")
-	return filepath.Join(bucketPath, safeKey)
+	return filepath.Join(bucketPath, encodeKey(key))
+}
+
+// versionsPath returns the directory holding the versions of a bucket's
+// objects, kept next to the bucket directory so that version IDs can't
+// collide with object keys
+func (s *filesystemStorage) versionsPath(bucket string) string {
+	return s.bucketPath(bucket) + ".versions"
+}
+
+// versionPath returns the filesystem path for an object version. An empty
+// version ID refers to the unversioned object.
+func (s *filesystemStorage) versionPath(bucket, key, versionId string) string {
+	if versionId == "" {
+		return s.objectPath(bucket, key)
+	}
+	return filepath.Join(s.versionsPath(bucket), encodeKey(key), versionId)
+}
+
+// tempPath returns the directory holding objects while they are being
+// written, before they are moved to their final path
+func (s *filesystemStorage) tempPath() string {
+	return filepath.Join(s.rootDir, "tmp")
+}
+
+// maxKeyNameLen is the longest file name encodeKey returns, the NAME_MAX
+// limit of common filesystems.
+const maxKeyNameLen = 255
+
+// encodeKey returns the file name an object key is stored under. Keys are
+// percent-encoded, so that every key maps to a different name and none
+// contains a path separator. Names that would be too long for the
+// filesystem are replaced by a hash of the key, prefixed with "#", which
+// percent-encoding always escapes. validateKey must have accepted the key.
+func encodeKey(key string) string {
+	name := url.PathEscape(key)
+	if len(name) <= maxKeyNameLen {
+		return name
+	}
+	h := sha256.Sum256([]byte(key))
+	return "#" + hex.EncodeToString(h[:])
+}
+
+// validateKey rejects keys that are empty or that have a "." or ".."
+// segment, since their file names would refer to the bucket directory or
+// its parent
+func validateKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("%w: key must not be empty", ErrInvalidKey)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return fmt.Errorf("%w: %q contains a %q segment", ErrInvalidKey, key, segment)
+		}
+	}
+	return nil
 }
 
 // CreateBucket creates a new bucket
@@ -76,7 +133,7 @@ func (s *filesystemStorage) DeleteBucket(ctx context.Context, name string) error
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	objects, err := s.metadata.ListObjectMetadata(ctx, name, "")
+	objects, err := s.metadata.ListObjectMetadata(ctx, name, "", "", 1)
 	if err != nil {
 		return fmt.Errorf("failed to list objects: %w", err)
 	}
@@ -89,6 +146,9 @@ func (s *filesystemStorage) DeleteBucket(ctx context.Context, name string) error
 	if err := os.RemoveAll(bucketPath); err != nil {
 		return fmt.Errorf("failed to remove bucket directory: %w", err)
 	}
+	if err := os.RemoveAll(s.versionsPath(name)); err != nil {
+		return fmt.Errorf("failed to remove bucket versions directory: %w", err)
+	}
 
 	return s.metadata.DeleteBucketMetadata(ctx, name)
 }
@@ -98,62 +158,169 @@ func (s *filesystemStorage) ListBuckets(ctx context.Context) ([]string, error) {
 	return s.metadata.ListBucketsMetadata(ctx)
 }
 
-// PutObject stores an object in the bucket
-func (s *filesystemStorage) PutObject(ctx context.Context, bucket, key string, data []byte, opts *types.PutObjectOptions) error {
+// PutObject stores an object in the bucket. The content is streamed to a
+// temporary file, which is moved into place once it has been fully written.
+func (s *filesystemStorage) PutObject(ctx context.Context, bucket, key string, r io.Reader, size int64, opts *types.PutObjectOptions) (*types.Object, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	// Write the content before taking the lock so that a slow upload
+	// doesn't block other requests
+	tempFile, etag, err := s.writeTemp(r, size)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tempFile) // fails harmlessly once the file has been moved
+
+	if err := verifyDigest(opts, etag); err != nil {
+		return nil, err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	exists, err := s.metadata.BucketExists(ctx, bucket)
 	if err != nil {
-		return fmt.Errorf("failed to check bucket existence: %w", err)
+		return nil, fmt.Errorf("failed to check bucket existence: %w", err)
 	}
 	if !exists {
-		return fmt.Errorf("bucket does not exist")
+		return nil, ErrBucketNotFound
 	}
 
-	objectPath := s.objectPath(bucket, key)
+	versionId, err := newVersionId(ctx, s.metadata, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	objectPath := s.versionPath(bucket, key, versionId)
 	if err := os.MkdirAll(filepath.Dir(objectPath), 0755); err != nil {
-		return fmt.Errorf("failed to create object directory: %w", err)
+		return nil, fmt.Errorf("failed to create object directory: %w", err)
 	}
 
-	if err := os.WriteFile(objectPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write object data: %w", err)
+	if err := os.Rename(tempFile, objectPath); err != nil {
+		return nil, fmt.Errorf("failed to write object data: %w", err)
 	}
+	now := time.Now()
 	obj := &types.Object{
 		Key:         key,
 		Bucket:      bucket,
-		Content:     data,
 		ContentType: opts.ContentType,
 		Metadata:    opts.Metadata,
+		Size:        size,
+		CreatedAt:   now,
+		ModifiedAt:  now,
+		ETag:        etag,
+		VersionId:   versionId,
 	}
 
-	return s.metadata.PutObjectMetadata(ctx, obj)
+	if err := s.metadata.PutObjectMetadata(ctx, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
 }
 
-// GetObject retrieves an object from the bucket
-func (s *filesystemStorage) GetObject(ctx context.Context, bucket, key string, opts *types.GetObjectOptions) (*types.Object, error) {
+// writeTemp writes size bytes read from r to a new temporary file, and
+// returns the file's path and the ETag of its content
+func (s *filesystemStorage) writeTemp(r io.Reader, size int64) (string, string, error) {
+	if err := os.MkdirAll(s.tempPath(), 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	f, err := os.CreateTemp(s.tempPath(), "object-")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	h := newETagHash()
+	n, copyErr := io.Copy(io.MultiWriter(f, h), io.LimitReader(r, size))
+	closeErr := f.Close()
+	if err := errors.Join(copyErr, closeErr); err != nil {
+		os.Remove(f.Name())
+		return "", "", fmt.Errorf("failed to write object data: %w", err)
+	}
+	if n < size {
+		os.Remove(f.Name())
+		return "", "", ErrIncompleteBody
+	}
+	return f.Name(), etagOf(h), nil
+}
+
+// GetObject retrieves an object from the bucket. The returned reader reads
+// the object's file directly, so the content is never held in memory.
+func (s *filesystemStorage) GetObject(ctx context.Context, bucket, key string, opts *types.GetObjectOptions) (*types.Object, io.ReadCloser, error) {
+	if err := validateKey(key); err != nil {
+		return nil, nil, err
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	obj, err := s.metadata.GetObjectMetadata(ctx, bucket, key)
+	obj, err := objectMetadata(ctx, s.metadata, bucket, key, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get object metadata: %w", err)
+		return nil, nil, err
+	}
+
+	// An open file keeps its content even if the object is overwritten or
+	// deleted after the lock is released
+	f, err := os.Open(s.versionPath(bucket, key, obj.VersionId))
+	if os.IsNotExist(err) {
+		return nil, nil, ErrObjectNotFound
 	}
-	objectPath := s.objectPath(bucket, key)
-	data, err := os.ReadFile(objectPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read object data: %w", err)
+		return nil, nil, fmt.Errorf("failed to open object data: %w", err)
 	}
 
-	obj.Content = data
-	return obj, nil
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("failed to stat object data: %w", err)
+	}
+
+	// Read only the requested bytes
+	start, end, err := ResolveRange(opts, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+
+	obj.Size = info.Size()
+	return obj, &fileSection{io.NewSectionReader(f, start, end-start+1), f}, nil
+}
+
+// fileSection reads a byte range of a file and closes the file when done
+type fileSection struct {
+	*io.SectionReader
+	file *os.File
+}
+
+func (s *fileSection) Close() error {
+	return s.file.Close()
+}
+
+// HeadObject returns an object's metadata without reading its content
+func (s *filesystemStorage) HeadObject(ctx context.Context, bucket, key string, opts *types.GetObjectOptions) (*types.Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return objectMetadata(ctx, s.metadata, bucket, key, opts)
 }
 
 // DeleteObject deletes an object from the bucket
 func (s *filesystemStorage) DeleteObject(ctx context.Context, bucket, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	versioned, err := s.metadata.BucketVersioning(ctx, bucket)
+	if err != nil {
+		return fmt.Errorf("failed to get bucket versioning: %w", err)
+	}
+	if versioned {
+		return putDeleteMarker(ctx, s.metadata, bucket, key)
+	}
+
 	objectPath := s.objectPath(bucket, key)
 	if err := os.Remove(objectPath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to delete object file: %w", err)
@@ -162,9 +329,33 @@ func (s *filesystemStorage) DeleteObject(ctx context.Context, bucket, key string
 	return s.metadata.DeleteObjectMetadata(ctx, bucket, key)
 }
 
-// ListObjects lists objects in a bucket with the given prefix
-func (s *filesystemStorage) ListObjects(ctx context.Context, bucket, prefix string) ([]types.Object, error) {
-	return s.metadata.ListObjectMetadata(ctx, bucket, prefix)
+// ListObjects lists a page of the objects in a bucket
+func (s *filesystemStorage) ListObjects(ctx context.Context, bucket string, opts *types.ListObjectsOptions) (*types.ObjectListing, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return listObjects(ctx, s.metadata, bucket, opts)
+}
+
+// SetBucketVersioning enables or disables versioning for a bucket
+func (s *filesystemStorage) SetBucketVersioning(ctx context.Context, bucket string, enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return setBucketVersioning(ctx, s.metadata, bucket, enabled)
+}
+
+// GetBucketVersioning reports whether versioning is enabled for a bucket
+func (s *filesystemStorage) GetBucketVersioning(ctx context.Context, bucket string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return getBucketVersioning(ctx, s.metadata, bucket)
+}
+
+// ListObjectVersions lists all versions of the objects in a bucket with the
+// given prefix, including delete markers
+func (s *filesystemStorage) ListObjectVersions(ctx context.Context, bucket, prefix string) ([]types.Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.metadata.ListObjectVersions(ctx, bucket, prefix)
 }
 
 // Ping checks if the storage backend is accessible
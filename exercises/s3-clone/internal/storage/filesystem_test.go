@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/kumarlokesh/s3-clone/internal/metadata"
@@ -59,30 +60,30 @@ func TestFilesystemStorage(t *testing.T) {
 		err := store.CreateBucket(ctx, bucket)
 		require.NoError(t, err)
 
-		err = store.PutObject(ctx, bucket, key, content, &types.PutObjectOptions{
+		err = putObject(ctx, store, bucket, key, content, &types.PutObjectOptions{
 			ContentType: "text/plain",
 			Metadata:    map[string]string{"key1": "value1"},
 		})
 		require.NoError(t, err)
 
-		obj, err := store.GetObject(ctx, bucket, key, &types.GetObjectOptions{})
+		obj, data, err := getObject(ctx, store, bucket, key, &types.GetObjectOptions{})
 		require.NoError(t, err)
-		assert.Equal(t, content, obj.Content)
+		assert.Equal(t, content, data)
 		assert.Equal(t, "text/plain", obj.ContentType)
 		assert.Equal(t, "value1", obj.Metadata["key1"])
 
 		files, err := filepath.Glob(filepath.Join(tempDir, "*", "*", "*", key))
 		assert.NoError(t, err)
 		assert.Len(t, files, 1)
-		objects, err := store.ListObjects(ctx, bucket, "")
+		listing, err := store.ListObjects(ctx, bucket, &types.ListObjectsOptions{})
 		require.NoError(t, err)
-		require.Len(t, objects, 1)
-		assert.Equal(t, key, objects[0].Key)
+		require.Len(t, listing.Objects, 1)
+		assert.Equal(t, key, listing.Objects[0].Key)
 
 		err = store.DeleteObject(ctx, bucket, key)
 		require.NoError(t, err)
 
-		_, err = store.GetObject(ctx, bucket, key, &types.GetObjectOptions{})
+		_, _, err = getObject(ctx, store, bucket, key, &types.GetObjectOptions{})
 		assert.Error(t, err)
 
 		files, err = filepath.Glob(filepath.Join(tempDir, "*", "*", "*", key))
@@ -90,6 +91,94 @@ func TestFilesystemStorage(t *testing.T) {
 		assert.Empty(t, files)
 	})
 
+	t.Run("Range reads", func(t *testing.T) {
+		store, _, cleanup := setupFilesystemStorage(t)
+		defer cleanup()
+
+		ctx := context.Background()
+		bucket := "test-bucket"
+		key := "test-object"
+		content := []byte("0123456789")
+
+		require.NoError(t, store.CreateBucket(ctx, bucket))
+		require.NoError(t, putObject(ctx, store, bucket, key, content, &types.PutObjectOptions{}))
+
+		obj, data, err := getObject(ctx, store, bucket, key, &types.GetObjectOptions{Range: true, RangeStart: 3, RangeEnd: 6})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("3456"), data)
+		assert.Equal(t, int64(len(content)), obj.Size)
+
+		obj, data, err = getObject(ctx, store, bucket, key, &types.GetObjectOptions{Range: true, RangeStart: 7, RangeEnd: -1})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("789"), data)
+
+		_, _, err = getObject(ctx, store, bucket, key, &types.GetObjectOptions{Range: true, RangeStart: 10, RangeEnd: -1})
+		assert.ErrorIs(t, err, storage.ErrInvalidRange)
+	})
+
+	t.Run("Versioning", func(t *testing.T) {
+		store, _, cleanup := setupFilesystemStorage(t)
+		defer cleanup()
+
+		ctx := context.Background()
+		bucket := "test-bucket"
+		key := "test-object"
+
+		require.NoError(t, store.CreateBucket(ctx, bucket))
+		require.NoError(t, store.SetBucketVersioning(ctx, bucket, true))
+
+		require.NoError(t, putObject(ctx, store, bucket, key, []byte("v1"), &types.PutObjectOptions{}))
+		require.NoError(t, putObject(ctx, store, bucket, key, []byte("v2"), &types.PutObjectOptions{}))
+
+		_, data, err := getObject(ctx, store, bucket, key, &types.GetObjectOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("v2"), data)
+
+		versions, err := store.ListObjectVersions(ctx, bucket, "")
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+
+		_, data, err = getObject(ctx, store, bucket, key, &types.GetObjectOptions{VersionId: versions[1].VersionId})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("v1"), data)
+
+		require.NoError(t, store.DeleteObject(ctx, bucket, key))
+		_, _, err = getObject(ctx, store, bucket, key, &types.GetObjectOptions{})
+		assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+
+		_, data, err = getObject(ctx, store, bucket, key, &types.GetObjectOptions{VersionId: versions[0].VersionId})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("v2"), data)
+
+		// Only delete markers and noncurrent versions remain, so the bucket can be deleted
+		require.NoError(t, store.DeleteBucket(ctx, bucket))
+	})
+
+	t.Run("Rejected content is not stored", func(t *testing.T) {
+		store, tempDir, cleanup := setupFilesystemStorage(t)
+		defer cleanup()
+
+		ctx := context.Background()
+		bucket := "test-bucket"
+		key := "test-object"
+		require.NoError(t, store.CreateBucket(ctx, bucket))
+
+		_, err := store.PutObject(ctx, bucket, key, strings.NewReader("short"), 10, &types.PutObjectOptions{})
+		assert.ErrorIs(t, err, storage.ErrIncompleteBody)
+
+		err = putObject(ctx, store, bucket, key, []byte("content"), &types.PutObjectOptions{
+			ContentMD5: storage.ComputeETag([]byte("other content")),
+		})
+		assert.ErrorIs(t, err, storage.ErrBadDigest)
+
+		_, _, err = getObject(ctx, store, bucket, key, &types.GetObjectOptions{})
+		assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+
+		files, err := filepath.Glob(filepath.Join(tempDir, "tmp", "*"))
+		require.NoError(t, err)
+		assert.Empty(t, files, "temporary files should be removed")
+	})
+
 	t.Run("Delete bucket", func(t *testing.T) {
 		store, _, cleanup := setupFilesystemStorage(t)
 		defer cleanup()
@@ -100,7 +189,7 @@ func TestFilesystemStorage(t *testing.T) {
 		err := store.CreateBucket(ctx, bucket)
 		require.NoError(t, err)
 
-		err = store.PutObject(ctx, bucket, "test-object", []byte("content"), &types.PutObjectOptions{})
+		err = putObject(ctx, store, bucket, "test-object", []byte("content"), &types.PutObjectOptions{})
 		require.NoError(t, err)
 
 		err = store.DeleteBucket(ctx, bucket)
@@ -118,6 +207,129 @@ func TestFilesystemStorage(t *testing.T) {
 		assert.Empty(t, buckets)
 	})
 
+	t.Run("Key encoding", func(t *testing.T) {
+		store, tempDir, cleanup := setupFilesystemStorage(t)
+		defer cleanup()
+
+		ctx := context.Background()
+		bucket := "test-bucket"
+		require.NoError(t, store.CreateBucket(ctx, bucket))
+
+		// Keys that differ only in separators must not share a file
+		keys := []string{"a/b", "a_b", "a%2Fb", "a/b/c"}
+		for _, key := range keys {
+			require.NoError(t, putObject(ctx, store, bucket, key, []byte("content of "+key), &types.PutObjectOptions{}))
+		}
+		for _, key := range keys {
+			_, data, err := getObject(ctx, store, bucket, key, &types.GetObjectOptions{})
+			require.NoError(t, err, key)
+			assert.Equal(t, "content of "+key, string(data))
+		}
+
+		// Every object is stored inside its bucket's directory
+		files, err := filepath.Glob(filepath.Join(tempDir, "*", "*", "*", "*"))
+		require.NoError(t, err)
+		assert.Len(t, files, len(keys))
+	})
+
+	t.Run("Long keys", func(t *testing.T) {
+		store, _, cleanup := setupFilesystemStorage(t)
+		defer cleanup()
+
+		ctx := context.Background()
+		bucket := "test-bucket"
+		require.NoError(t, store.CreateBucket(ctx, bucket))
+
+		// Both keys encode to names longer than the filesystem allows
+		key := strings.Repeat("k", 1024)
+		other := strings.Repeat("k", 1023) + "/"
+		require.NoError(t, putObject(ctx, store, bucket, key, []byte("long"), &types.PutObjectOptions{}))
+		require.NoError(t, putObject(ctx, store, bucket, other, []byte("other"), &types.PutObjectOptions{}))
+
+		_, data, err := getObject(ctx, store, bucket, key, &types.GetObjectOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("long"), data)
+		_, data, err = getObject(ctx, store, bucket, other, &types.GetObjectOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("other"), data)
+
+		require.NoError(t, store.DeleteObject(ctx, bucket, key))
+		_, _, err = getObject(ctx, store, bucket, key, &types.GetObjectOptions{})
+		assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+
+		// Versioned objects use the encoded name as a directory
+		require.NoError(t, store.SetBucketVersioning(ctx, bucket, true))
+		require.NoError(t, putObject(ctx, store, bucket, key, []byte("v1"), &types.PutObjectOptions{}))
+		require.NoError(t, putObject(ctx, store, bucket, key, []byte("v2"), &types.PutObjectOptions{}))
+
+		versions, err := store.ListObjectVersions(ctx, bucket, key)
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		_, data, err = getObject(ctx, store, bucket, key, &types.GetObjectOptions{VersionId: versions[1].VersionId})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("v1"), data)
+	})
+
+	t.Run("Invalid keys are rejected", func(t *testing.T) {
+		store, tempDir, cleanup := setupFilesystemStorage(t)
+		defer cleanup()
+
+		ctx := context.Background()
+		bucket := "test-bucket"
+		require.NoError(t, store.CreateBucket(ctx, bucket))
+
+		for _, key := range []string{"", "..", ".", "../escape", "a/../../escape", "a/./b"} {
+			err := putObject(ctx, store, bucket, key, []byte("content"), &types.PutObjectOptions{})
+			assert.ErrorIs(t, err, storage.ErrInvalidKey, key)
+
+			_, _, err = getObject(ctx, store, bucket, key, &types.GetObjectOptions{})
+			assert.ErrorIs(t, err, storage.ErrInvalidKey, key)
+
+			err = store.DeleteObject(ctx, bucket, key)
+			assert.ErrorIs(t, err, storage.ErrInvalidKey, key)
+		}
+
+		_, err := os.Stat(filepath.Join(filepath.Dir(tempDir), "escape"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Metadata survives a restart", func(t *testing.T) {
+		tempDir := t.TempDir()
+		metaPath := filepath.Join(tempDir, "metadata.json")
+		ctx := context.Background()
+		bucket := "test-bucket"
+		key := "test-object"
+		content := []byte("test content")
+
+		metaSvc, err := metadata.NewFileMetadata(metaPath)
+		require.NoError(t, err)
+		store, err := storage.NewFilesystemStorage(tempDir, metaSvc)
+		require.NoError(t, err)
+
+		require.NoError(t, store.CreateBucket(ctx, bucket))
+		err = putObject(ctx, store, bucket, key, content, &types.PutObjectOptions{
+			ContentType: "text/plain",
+			Metadata:    map[string]string{"key1": "value1"},
+		})
+		require.NoError(t, err)
+
+		// Reopen the storage as a restarted server would
+		metaSvc, err = metadata.NewFileMetadata(metaPath)
+		require.NoError(t, err)
+		store, err = storage.NewFilesystemStorage(tempDir, metaSvc)
+		require.NoError(t, err)
+
+		buckets, err := store.ListBuckets(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{bucket}, buckets)
+
+		obj, data, err := getObject(ctx, store, bucket, key, &types.GetObjectOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, content, data)
+		assert.Equal(t, "text/plain", obj.ContentType)
+		assert.Equal(t, "value1", obj.Metadata["key1"])
+	})
+
 	t.Run("Ping", func(t *testing.T) {
 		store, _, cleanup := setupFilesystemStorage(t)
 		defer cleanup()
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kumarlokesh/s3-clone/internal/metadata"
+	"github.com/kumarlokesh/s3-clone/internal/types"
+)
+
+// DefaultMaxKeys is the maximum number of keys listed when no limit is given
+const DefaultMaxKeys = 1000
+
+// listObjects lists a page of objects from the metadata service, grouping
+// keys into common prefixes when a delimiter is given. Objects are fetched
+// in batches, so large buckets are never read in full.
+func listObjects(ctx context.Context, meta metadata.Service, bucket string, opts *types.ListObjectsOptions) (*types.ObjectListing, error) {
+	if opts == nil {
+		opts = &types.ListObjectsOptions{}
+	}
+	maxKeys := opts.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+
+	listing := &types.ObjectListing{
+		Bucket:    bucket,
+		Prefix:    opts.Prefix,
+		Delimiter: opts.Delimiter,
+		MaxKeys:   maxKeys,
+	}
+
+	// Keys under the marker's common prefix were listed with it on a
+	// previous page
+	lastPrefix := opts.Marker
+	startAfter := opts.Marker
+	count := 0
+	for {
+		batch, err := meta.ListObjectMetadata(ctx, bucket, opts.Prefix, startAfter, maxKeys+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+
+		for _, obj := range batch {
+			startAfter = obj.Key
+
+			commonPrefix := commonPrefix(obj.Key, opts.Prefix, opts.Delimiter)
+			if commonPrefix != "" && commonPrefix == lastPrefix {
+				continue
+			}
+
+			if count == maxKeys {
+				listing.IsTruncated = true
+				return listing, nil
+			}
+			count++
+
+			if commonPrefix != "" {
+				listing.CommonPrefixes = append(listing.CommonPrefixes, commonPrefix)
+				listing.NextMarker = commonPrefix
+				lastPrefix = commonPrefix
+			} else {
+				listing.Objects = append(listing.Objects, obj)
+				listing.NextMarker = obj.Key
+			}
+		}
+
+		if len(batch) <= maxKeys {
+			// The next marker is only needed to continue a truncated listing
+			listing.NextMarker = ""
+			return listing, nil
+		}
+	}
+}
+
+// commonPrefix returns the part of key up to and including the first
+// delimiter after prefix, or "" if the key has no delimiter after prefix
+func commonPrefix(key, prefix, delimiter string) string {
+	if delimiter == "" {
+		return ""
+	}
+	idx := strings.Index(key[len(prefix):], delimiter)
+	if idx < 0 {
+		return ""
+	}
+	return key[:len(prefix)+idx+len(delimiter)]
+}
@@ -1,8 +1,11 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
@@ -13,36 +16,57 @@ import (
 // memoryStorage is an in-memory implementation of the Storage interface
 type memoryStorage struct {
 	mu       sync.RWMutex
-	objects  map[string][]byte // key: "bucket/key"
+	objects  map[objectVersion][]byte
 	metadata metadata.Service
 }
 
+// objectVersion identifies the data of an object version. The version ID
+// is empty for unversioned objects.
+type objectVersion struct {
+	bucket    string
+	key       string
+	versionId string
+}
+
 // NewMemoryStorage creates a new in-memory storage instance
 func NewMemoryStorage(meta metadata.Service) Storage {
 	return &memoryStorage{
-		objects:  make(map[string][]byte),
+		objects:  make(map[objectVersion][]byte),
 		metadata: meta,
 	}
 }
 
-func (s *memoryStorage) key(bucket, key string) string {
-	return bucket + "/" + key
-}
+func (s *memoryStorage) PutObject(ctx context.Context, bucket, key string, r io.Reader, size int64, opts *types.PutObjectOptions) (*types.Object, error) {
+	// Read the content before taking the lock so that a slow upload
+	// doesn't block other requests
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrIncompleteBody
+		}
+		return nil, fmt.Errorf("failed to read object data: %w", err)
+	}
+	etag := ComputeETag(data)
+	if err := verifyDigest(opts, etag); err != nil {
+		return nil, err
+	}
 
-func (s *memoryStorage) PutObject(ctx context.Context, bucket, key string, data []byte, opts *types.PutObjectOptions) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	exists, err := s.metadata.BucketExists(ctx, bucket)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if !exists {
-		return ErrBucketNotFound
+		return nil, ErrBucketNotFound
 	}
 
-	objKey := s.key(bucket, key)
-	s.objects[objKey] = data
+	versionId, err := newVersionId(ctx, s.metadata, bucket)
+	if err != nil {
+		return nil, err
+	}
+	s.objects[objectVersion{bucket, key, versionId}] = data
 
 	now := time.Now()
 	obj := &types.Object{
@@ -50,37 +74,48 @@ func (s *memoryStorage) PutObject(ctx context.Context, bucket, key string, data
 		Bucket:      bucket,
 		ContentType: opts.ContentType,
 		Metadata:    opts.Metadata,
-		Size:        int64(len(data)),
+		Size:        size,
 		CreatedAt:   now,
 		ModifiedAt:  now,
+		ETag:        etag,
+		VersionId:   versionId,
 	}
 
-	return s.metadata.PutObjectMetadata(ctx, obj)
+	if err := s.metadata.PutObjectMetadata(ctx, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
 }
 
-func (s *memoryStorage) GetObject(ctx context.Context, bucket, key string, opts *types.GetObjectOptions) (*types.Object, error) {
+func (s *memoryStorage) GetObject(ctx context.Context, bucket, key string, opts *types.GetObjectOptions) (*types.Object, io.ReadCloser, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	meta, err := s.metadata.GetObjectMetadata(ctx, bucket, key)
+	meta, err := objectMetadata(ctx, s.metadata, bucket, key, opts)
 	if err != nil {
-		return nil, err
-	}
-	if meta == nil {
-		return nil, ErrObjectNotFound
+		return nil, nil, err
 	}
 
-	data, exists := s.objects[s.key(bucket, key)]
+	data, exists := s.objects[objectVersion{bucket, key, meta.VersionId}]
 	if !exists {
-		return nil, ErrObjectNotFound
+		return nil, nil, ErrObjectNotFound
+	}
+
+	start, end, err := ResolveRange(opts, int64(len(data)))
+	if err != nil {
+		return nil, nil, err
 	}
 
-	content := make([]byte, len(data))
-	copy(content, data)
+	// Stored data is never modified, so it can be read after the lock is
+	// released
+	return meta, io.NopCloser(bytes.NewReader(data[start : end+1])), nil
+}
 
-	result := *meta
-	result.Content = content
-	return &result, nil
+// HeadObject returns an object's metadata without its content
+func (s *memoryStorage) HeadObject(ctx context.Context, bucket, key string, opts *types.GetObjectOptions) (*types.Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return objectMetadata(ctx, s.metadata, bucket, key, opts)
 }
 
 func (s *memoryStorage) DeleteObject(ctx context.Context, bucket, key string) error {
@@ -92,12 +127,22 @@ func (s *memoryStorage) DeleteObject(ctx context.Context, bucket, key string) er
 		return err
 	}
 
-	delete(s.objects, s.key(bucket, key))
+	versioned, err := s.metadata.BucketVersioning(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	if versioned {
+		return putDeleteMarker(ctx, s.metadata, bucket, key)
+	}
+
+	delete(s.objects, objectVersion{bucket, key, ""})
 	return s.metadata.DeleteObjectMetadata(ctx, bucket, key)
 }
 
-func (s *memoryStorage) ListObjects(ctx context.Context, bucket, prefix string) ([]types.Object, error) {
-	return s.metadata.ListObjectMetadata(ctx, bucket, prefix)
+func (s *memoryStorage) ListObjects(ctx context.Context, bucket string, opts *types.ListObjectsOptions) (*types.ObjectListing, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return listObjects(ctx, s.metadata, bucket, opts)
 }
 
 func (s *memoryStorage) CreateBucket(ctx context.Context, name string) error {
@@ -127,7 +172,7 @@ func (s *memoryStorage) DeleteBucket(ctx context.Context, name string) error {
 		return fmt.Errorf("bucket does not exist")
 	}
 
-	objects, err := s.metadata.ListObjectMetadata(ctx, name, "")
+	objects, err := s.metadata.ListObjectMetadata(ctx, name, "", "", 1)
 	if err != nil {
 		return fmt.Errorf("failed to list objects in bucket: %w", err)
 	}
@@ -135,9 +180,34 @@ func (s *memoryStorage) DeleteBucket(ctx context.Context, name string) error {
 		return fmt.Errorf("bucket is not empty")
 	}
 
+	// Drop the data of any noncurrent versions left in the bucket
+	for version := range s.objects {
+		if version.bucket == name {
+			delete(s.objects, version)
+		}
+	}
+
 	return s.metadata.DeleteBucketMetadata(ctx, name)
 }
 
+func (s *memoryStorage) SetBucketVersioning(ctx context.Context, bucket string, enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return setBucketVersioning(ctx, s.metadata, bucket, enabled)
+}
+
+func (s *memoryStorage) GetBucketVersioning(ctx context.Context, bucket string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return getBucketVersioning(ctx, s.metadata, bucket)
+}
+
+func (s *memoryStorage) ListObjectVersions(ctx context.Context, bucket, prefix string) ([]types.Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.metadata.ListObjectVersions(ctx, bucket, prefix)
+}
+
 func (s *memoryStorage) ListBuckets(ctx context.Context) ([]string, error) {
 	return s.metadata.ListBucketsMetadata(ctx)
 }
@@ -148,8 +218,15 @@ func (s *memoryStorage) Ping(ctx context.Context) error {
 
 // Common errors
 var (
-	ErrObjectNotFound = &Error{"object not found"}
-	ErrBucketNotFound = &Error{"bucket not found"}
+	ErrObjectNotFound  = &Error{"object not found"}
+	ErrBucketNotFound  = &Error{"bucket not found"}
+	ErrInvalidRange    = &Error{"requested range not satisfiable"}
+	ErrUploadNotFound  = &Error{"multipart upload not found"}
+	ErrInvalidPart     = &Error{"invalid part"}
+	ErrVersionNotFound = &Error{"object version not found"}
+	ErrBadDigest       = &Error{"content does not match the expected digest"}
+	ErrIncompleteBody  = &Error{"content is shorter than its declared size"}
+	ErrInvalidKey      = &Error{"invalid object key"}
 )
 
 // Error represents a storage error
@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/kumarlokesh/s3-clone/internal/types"
+)
+
+// MaxPartNumber is the highest part number accepted for a multipart upload
+const MaxPartNumber = 10000
+
+// MultipartStore tracks in-progress multipart uploads. Parts are written to
+// a temporary directory per upload until the upload is completed, when they
+// are assembled into an object in the underlying storage, or aborted.
+type MultipartStore struct {
+	mu      sync.Mutex
+	store   Storage
+	tempDir string
+	uploads map[string]*multipartUpload
+}
+
+// multipartUpload is an in-progress multipart upload
+type multipartUpload struct {
+	mu     sync.Mutex
+	bucket string
+	key    string
+	opts   *types.PutObjectOptions
+	dir    string
+	parts  map[int]int64 // part number to size
+}
+
+// NewMultipartStore creates a multipart store that assembles uploads into
+// store, keeping parts in temporary directories under tempDir. An empty
+// tempDir uses the system's default temporary directory.
+func NewMultipartStore(store Storage, tempDir string) *MultipartStore {
+	return &MultipartStore{
+		store:   store,
+		tempDir: tempDir,
+		uploads: make(map[string]*multipartUpload),
+	}
+}
+
+// CreateUpload starts a multipart upload of an object and returns its
+// upload ID. The options are applied to the object on completion.
+func (m *MultipartStore) CreateUpload(ctx context.Context, bucket, key string, opts *types.PutObjectOptions) (string, error) {
+	buckets, err := m.store.ListBuckets(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list buckets: %w", err)
+	}
+	if !slices.Contains(buckets, bucket) {
+		return "", ErrBucketNotFound
+	}
+
+	uploadID, err := newID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate upload ID: %w", err)
+	}
+	dir, err := os.MkdirTemp(m.tempDir, "s3-clone-upload-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.uploads[uploadID] = &multipartUpload{
+		bucket: bucket,
+		key:    key,
+		opts:   opts,
+		dir:    dir,
+		parts:  make(map[int]int64),
+	}
+	return uploadID, nil
+}
+
+// UploadPart stores a part of a multipart upload, streaming it from r to
+// disk. Uploading a part number again replaces the earlier part.
+func (m *MultipartStore) UploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int, r io.Reader) error {
+	if partNumber < 1 || partNumber > MaxPartNumber {
+		return ErrInvalidPart
+	}
+
+	upload, err := m.upload(bucket, key, uploadID)
+	if err != nil {
+		return err
+	}
+
+	upload.mu.Lock()
+	defer upload.mu.Unlock()
+	if upload.parts == nil {
+		return ErrUploadNotFound
+	}
+
+	f, err := os.Create(upload.partPath(partNumber))
+	if err != nil {
+		return fmt.Errorf("failed to write part %d: %w", partNumber, err)
+	}
+	n, copyErr := io.Copy(f, r)
+	if err := errors.Join(copyErr, f.Close()); err != nil {
+		// Forget the partially written part
+		delete(upload.parts, partNumber)
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to write part %d: %w", partNumber, err)
+	}
+	upload.parts[partNumber] = n
+	return nil
+}
+
+// CompleteUpload assembles the uploaded parts in part number order into
+// the object and removes the parts
+func (m *MultipartStore) CompleteUpload(ctx context.Context, bucket, key, uploadID string) error {
+	upload, err := m.upload(bucket, key, uploadID)
+	if err != nil {
+		return err
+	}
+
+	upload.mu.Lock()
+	defer upload.mu.Unlock()
+	if upload.parts == nil {
+		return ErrUploadNotFound
+	}
+	if len(upload.parts) == 0 {
+		return ErrInvalidPart
+	}
+
+	partNumbers := make([]int, 0, len(upload.parts))
+	var size int64
+	for partNumber, partSize := range upload.parts {
+		partNumbers = append(partNumbers, partNumber)
+		size += partSize
+	}
+	slices.Sort(partNumbers)
+
+	paths := make([]string, len(partNumbers))
+	for i, partNumber := range partNumbers {
+		paths[i] = upload.partPath(partNumber)
+	}
+	parts := &partReader{paths: paths}
+	defer parts.Close()
+
+	opts := upload.opts
+	if opts == nil {
+		opts = &types.PutObjectOptions{}
+	}
+	if _, err := m.store.PutObject(ctx, bucket, key, parts, size, opts); err != nil {
+		return err
+	}
+
+	return m.remove(uploadID, upload)
+}
+
+// AbortUpload discards a multipart upload and its parts
+func (m *MultipartStore) AbortUpload(ctx context.Context, bucket, key, uploadID string) error {
+	upload, err := m.upload(bucket, key, uploadID)
+	if err != nil {
+		return err
+	}
+
+	upload.mu.Lock()
+	defer upload.mu.Unlock()
+	if upload.parts == nil {
+		return ErrUploadNotFound
+	}
+	return m.remove(uploadID, upload)
+}
+
+// upload returns the in-progress upload with the given ID for an object
+func (m *MultipartStore) upload(bucket, key, uploadID string) (*multipartUpload, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	upload, ok := m.uploads[uploadID]
+	if !ok || upload.bucket != bucket || upload.key != key {
+		return nil, ErrUploadNotFound
+	}
+	return upload, nil
+}
+
+// remove forgets an upload and deletes its parts. The caller must hold the
+// upload's lock.
+func (m *MultipartStore) remove(uploadID string, upload *multipartUpload) error {
+	m.mu.Lock()
+	delete(m.uploads, uploadID)
+	m.mu.Unlock()
+
+	// Mark the upload as finished for callers already holding a reference
+	upload.parts = nil
+	if err := os.RemoveAll(upload.dir); err != nil {
+		return fmt.Errorf("failed to remove upload directory: %w", err)
+	}
+	return nil
+}
+
+// partPath returns the path of the file holding a part
+func (u *multipartUpload) partPath(partNumber int) string {
+	return filepath.Join(u.dir, fmt.Sprintf("part-%05d", partNumber))
+}
+
+// partReader reads the concatenated content of part files, opening one file
+// at a time
+type partReader struct {
+	paths []string
+	file  *os.File
+}
+
+func (r *partReader) Read(p []byte) (int, error) {
+	for {
+		if r.file == nil {
+			if len(r.paths) == 0 {
+				return 0, io.EOF
+			}
+			f, err := os.Open(r.paths[0])
+			if err != nil {
+				return 0, fmt.Errorf("failed to read part: %w", err)
+			}
+			r.file, r.paths = f, r.paths[1:]
+		}
+
+		n, err := r.file.Read(p)
+		if err == io.EOF {
+			r.file.Close()
+			r.file = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+// Close closes the part file being read, if any
+func (r *partReader) Close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// newID generates a random ID for uploads and object versions
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
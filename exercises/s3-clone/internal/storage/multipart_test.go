@@ -0,0 +1,93 @@
+package storage_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kumarlokesh/s3-clone/internal/metadata"
+	"github.com/kumarlokesh/s3-clone/internal/storage"
+	"github.com/kumarlokesh/s3-clone/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipartStore(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStorage(metadata.NewInMemoryMetadata())
+	require.NoError(t, store.CreateBucket(ctx, "test-bucket"))
+
+	t.Run("Complete assembles parts in order", func(t *testing.T) {
+		tempDir := t.TempDir()
+		multipart := storage.NewMultipartStore(store, tempDir)
+
+		uploadID, err := multipart.CreateUpload(ctx, "test-bucket", "big-object", &types.PutObjectOptions{
+			ContentType: "text/plain",
+		})
+		require.NoError(t, err)
+
+		// Upload the parts out of order
+		require.NoError(t, multipart.UploadPart(ctx, "test-bucket", "big-object", uploadID, 3, strings.NewReader("three")))
+		require.NoError(t, multipart.UploadPart(ctx, "test-bucket", "big-object", uploadID, 1, strings.NewReader("one-")))
+		require.NoError(t, multipart.UploadPart(ctx, "test-bucket", "big-object", uploadID, 2, strings.NewReader("two-")))
+
+		require.NoError(t, multipart.CompleteUpload(ctx, "test-bucket", "big-object", uploadID))
+
+		obj, data, err := getObject(ctx, store, "test-bucket", "big-object", &types.GetObjectOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "one-two-three", string(data))
+		assert.Equal(t, "text/plain", obj.ContentType)
+
+		entries, err := os.ReadDir(tempDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "parts should be removed after completion")
+
+		err = multipart.CompleteUpload(ctx, "test-bucket", "big-object", uploadID)
+		assert.ErrorIs(t, err, storage.ErrUploadNotFound)
+	})
+
+	t.Run("Abort removes parts", func(t *testing.T) {
+		tempDir := t.TempDir()
+		multipart := storage.NewMultipartStore(store, tempDir)
+
+		uploadID, err := multipart.CreateUpload(ctx, "test-bucket", "aborted-object", nil)
+		require.NoError(t, err)
+		require.NoError(t, multipart.UploadPart(ctx, "test-bucket", "aborted-object", uploadID, 1, strings.NewReader("part")))
+
+		entries, err := os.ReadDir(tempDir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+
+		require.NoError(t, multipart.AbortUpload(ctx, "test-bucket", "aborted-object", uploadID))
+
+		entries, err = os.ReadDir(tempDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "parts should be removed after abort")
+
+		err = multipart.UploadPart(ctx, "test-bucket", "aborted-object", uploadID, 2, strings.NewReader("late"))
+		assert.ErrorIs(t, err, storage.ErrUploadNotFound)
+
+		_, _, err = getObject(ctx, store, "test-bucket", "aborted-object", &types.GetObjectOptions{})
+		assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+	})
+
+	t.Run("Invalid uploads", func(t *testing.T) {
+		multipart := storage.NewMultipartStore(store, t.TempDir())
+
+		_, err := multipart.CreateUpload(ctx, "missing-bucket", "object", nil)
+		assert.ErrorIs(t, err, storage.ErrBucketNotFound)
+
+		uploadID, err := multipart.CreateUpload(ctx, "test-bucket", "object", nil)
+		require.NoError(t, err)
+
+		err = multipart.UploadPart(ctx, "test-bucket", "object", uploadID, 0, strings.NewReader("data"))
+		assert.ErrorIs(t, err, storage.ErrInvalidPart)
+
+		err = multipart.UploadPart(ctx, "test-bucket", "other-object", uploadID, 1, strings.NewReader("data"))
+		assert.ErrorIs(t, err, storage.ErrUploadNotFound)
+
+		err = multipart.CompleteUpload(ctx, "test-bucket", "object", uploadID)
+		assert.ErrorIs(t, err, storage.ErrInvalidPart, "completing without parts should fail")
+	})
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"github.com/kumarlokesh/s3-clone/internal/types"
+)
+
+// ResolveRange returns the inclusive byte offsets [start, end] selected by
+// opts in an object of the given size. Without a range the whole object is
+// selected. It returns ErrInvalidRange if the range is not satisfiable.
+func ResolveRange(opts *types.GetObjectOptions, size int64) (start, end int64, err error) {
+	if opts == nil || !opts.Range {
+		return 0, size - 1, nil
+	}
+
+	// A suffix range selects the last bytes of the object
+	if opts.RangeStart < 0 {
+		n := min(-opts.RangeStart, size)
+		if n == 0 {
+			return 0, 0, ErrInvalidRange
+		}
+		return size - n, size - 1, nil
+	}
+
+	start, end = opts.RangeStart, opts.RangeEnd
+	if start >= size {
+		return 0, 0, ErrInvalidRange
+	}
+	if end < 0 || end >= size {
+		end = size - 1
+	}
+	if end < start {
+		return 0, 0, ErrInvalidRange
+	}
+	return start, end, nil
+}
@@ -2,16 +2,27 @@ package storage
 
 import (
 	"context"
+	"io"
+
 	"github.com/kumarlokesh/s3-clone/internal/types"
 )
 
 // Storage defines the interface for object storage operations
 type Storage interface {
-	// Object operations
-	PutObject(ctx context.Context, bucket, key string, data []byte, opts *types.PutObjectOptions) error
-	GetObject(ctx context.Context, bucket, key string, opts *types.GetObjectOptions) (*types.Object, error)
+	// Object operations. PutObject stores size bytes read from r and
+	// returns the stored object's metadata. GetObject returns the object's
+	// metadata and a reader for its content, or the requested range of it,
+	// which the caller must close.
+	PutObject(ctx context.Context, bucket, key string, r io.Reader, size int64, opts *types.PutObjectOptions) (*types.Object, error)
+	GetObject(ctx context.Context, bucket, key string, opts *types.GetObjectOptions) (*types.Object, io.ReadCloser, error)
+	HeadObject(ctx context.Context, bucket, key string, opts *types.GetObjectOptions) (*types.Object, error)
 	DeleteObject(ctx context.Context, bucket, key string) error
-	ListObjects(ctx context.Context, bucket, prefix string) ([]types.Object, error)
+	ListObjects(ctx context.Context, bucket string, opts *types.ListObjectsOptions) (*types.ObjectListing, error)
+
+	// Versioning operations
+	SetBucketVersioning(ctx context.Context, bucket string, enabled bool) error
+	GetBucketVersioning(ctx context.Context, bucket string) (bool, error)
+	ListObjectVersions(ctx context.Context, bucket, prefix string) ([]types.Object, error)
 
 	// Bucket operations
 	CreateBucket(ctx context.Context, name string) error
@@ -1,7 +1,9 @@
 package storage_test
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"testing"
 
 	"github.com/kumarlokesh/s3-clone/internal/metadata"
@@ -11,6 +13,24 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// putObject stores data as an object
+func putObject(ctx context.Context, store storage.Storage, bucket, key string, data []byte, opts *types.PutObjectOptions) error {
+	_, err := store.PutObject(ctx, bucket, key, bytes.NewReader(data), int64(len(data)), opts)
+	return err
+}
+
+// getObject gets an object and reads its content
+func getObject(ctx context.Context, store storage.Storage, bucket, key string, opts *types.GetObjectOptions) (*types.Object, []byte, error) {
+	obj, body, err := store.GetObject(ctx, bucket, key, opts)
+	if err != nil {
+		return obj, nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	return obj, data, err
+}
+
 func TestMemoryStorage(t *testing.T) {
 	metaSvc := metadata.NewInMemoryMetadata()
 	store := storage.NewMemoryStorage(metaSvc)
@@ -35,10 +55,10 @@ func TestMemoryStorage(t *testing.T) {
 			Metadata:    map[string]string{"key": "value"},
 		}
 
-		err := store.PutObject(ctx, "test-bucket", "test-object", testData, opts)
+		err := putObject(ctx, store, "test-bucket", "test-object", testData, opts)
 		require.NoError(t, err)
 
-		obj, err := store.GetObject(ctx, "test-bucket", "test-object", &types.GetObjectOptions{})
+		obj, data, err := getObject(ctx, store, "test-bucket", "test-object", &types.GetObjectOptions{})
 		require.NoError(t, err)
 		require.NotNil(t, obj)
 		assert.Equal(t, "test-object", obj.Key)
@@ -46,17 +66,18 @@ func TestMemoryStorage(t *testing.T) {
 		assert.Equal(t, "text/plain", obj.ContentType)
 		assert.Equal(t, int64(len(testData)), obj.Size)
 		assert.Equal(t, "value", obj.Metadata["key"])
-		assert.Equal(t, testData, obj.Content)
+		assert.Equal(t, testData, data)
+		assert.Equal(t, "eb733a00c0c9d336e65691a37ab54293", obj.ETag, "ETag is the MD5 of the content")
 
-		objects, err := store.ListObjects(ctx, "test-bucket", "")
+		listing, err := store.ListObjects(ctx, "test-bucket", &types.ListObjectsOptions{})
 		require.NoError(t, err)
-		assert.Len(t, objects, 1)
-		assert.Equal(t, "test-object", objects[0].Key)
+		assert.Len(t, listing.Objects, 1)
+		assert.Equal(t, "test-object", listing.Objects[0].Key)
 
 		err = store.DeleteObject(ctx, "test-bucket", "test-object")
 		require.NoError(t, err)
 
-		obj, err = store.GetObject(ctx, "test-bucket", "test-object", &types.GetObjectOptions{})
+		obj, data, err = getObject(ctx, store, "test-bucket", "test-object", &types.GetObjectOptions{})
 		assert.ErrorIs(t, err, storage.ErrObjectNotFound)
 		assert.Nil(t, obj)
 	})
@@ -65,7 +86,7 @@ func TestMemoryStorage(t *testing.T) {
 		err := store.CreateBucket(ctx, "test-bucket-2")
 		require.NoError(t, err)
 
-		err = store.PutObject(ctx, "test-bucket-2", "test-object", []byte("data"), &types.PutObjectOptions{})
+		err = putObject(ctx, store, "test-bucket-2", "test-object", []byte("data"), &types.PutObjectOptions{})
 		require.NoError(t, err)
 
 		err = store.DeleteBucket(ctx, "test-bucket-2")
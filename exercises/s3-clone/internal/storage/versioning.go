@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kumarlokesh/s3-clone/internal/metadata"
+	"github.com/kumarlokesh/s3-clone/internal/types"
+)
+
+// Versioning helpers shared by the storage backends. When versioning is
+// enabled for a bucket, every put stores a new version with a generated
+// version ID and deletes add a delete marker instead of removing data.
+
+// newVersionId returns the version ID for a new object version, which is
+// empty if versioning is not enabled for the bucket
+func newVersionId(ctx context.Context, meta metadata.Service, bucket string) (string, error) {
+	versioned, err := meta.BucketVersioning(ctx, bucket)
+	if err != nil {
+		return "", fmt.Errorf("failed to get bucket versioning: %w", err)
+	}
+	if !versioned {
+		return "", nil
+	}
+
+	versionId, err := newID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate version ID: %w", err)
+	}
+	return versionId, nil
+}
+
+// objectMetadata returns the metadata of the object version selected by
+// opts: the latest version unless a version ID is given
+func objectMetadata(ctx context.Context, meta metadata.Service, bucket, key string, opts *types.GetObjectOptions) (*types.Object, error) {
+	if opts == nil || opts.VersionId == "" {
+		obj, err := meta.GetObjectMetadata(ctx, bucket, key)
+		if err != nil {
+			return nil, err
+		}
+		if obj == nil {
+			return nil, missingObject(ctx, meta, bucket, ErrObjectNotFound)
+		}
+		return obj, nil
+	}
+
+	versionId := opts.VersionId
+	if versionId == types.NullVersionId {
+		versionId = ""
+	}
+	obj, err := meta.GetObjectVersionMetadata(ctx, bucket, key, versionId)
+	if err != nil {
+		return nil, err
+	}
+	if obj == nil || obj.IsDeleteMarker {
+		return nil, missingObject(ctx, meta, bucket, ErrVersionNotFound)
+	}
+	return obj, nil
+}
+
+// missingObject returns the error for an object that wasn't found:
+// ErrBucketNotFound if its bucket doesn't exist either, or else notFound
+func missingObject(ctx context.Context, meta metadata.Service, bucket string, notFound error) error {
+	exists, err := meta.BucketExists(ctx, bucket)
+	if err != nil {
+		return fmt.Errorf("failed to check bucket existence: %w", err)
+	}
+	if !exists {
+		return ErrBucketNotFound
+	}
+	return notFound
+}
+
+// putDeleteMarker records that a versioned object was deleted
+func putDeleteMarker(ctx context.Context, meta metadata.Service, bucket, key string) error {
+	versionId, err := newID()
+	if err != nil {
+		return fmt.Errorf("failed to generate version ID: %w", err)
+	}
+
+	now := time.Now()
+	return meta.PutObjectMetadata(ctx, &types.Object{
+		Key:            key,
+		Bucket:         bucket,
+		CreatedAt:      now,
+		ModifiedAt:     now,
+		VersionId:      versionId,
+		IsDeleteMarker: true,
+	})
+}
+
+// setBucketVersioning enables or disables versioning for an existing bucket
+func setBucketVersioning(ctx context.Context, meta metadata.Service, bucket string, enabled bool) error {
+	exists, err := meta.BucketExists(ctx, bucket)
+	if err != nil {
+		return fmt.Errorf("failed to check bucket existence: %w", err)
+	}
+	if !exists {
+		return ErrBucketNotFound
+	}
+	return meta.SetBucketVersioning(ctx, bucket, enabled)
+}
+
+// getBucketVersioning reports whether versioning is enabled for an
+// existing bucket
+func getBucketVersioning(ctx context.Context, meta metadata.Service, bucket string) (bool, error) {
+	exists, err := meta.BucketExists(ctx, bucket)
+	if err != nil {
+		return false, fmt.Errorf("failed to check bucket existence: %w", err)
+	}
+	if !exists {
+		return false, ErrBucketNotFound
+	}
+	return meta.BucketVersioning(ctx, bucket)
+}
@@ -8,14 +8,28 @@ import (
 type Object struct {
 	Key         string            `json:"key"`
 	Bucket      string            `json:"bucket"`
-	Content     []byte            `json:"content,omitempty"`
 	ContentType string            `json:"content_type"`
 	Metadata    map[string]string `json:"metadata"`
 	Size        int64             `json:"size"`
 	CreatedAt   time.Time         `json:"created_at"`
 	ModifiedAt  time.Time         `json:"modified_at"`
+
+	// ETag is the hex-encoded MD5 digest of the object's content
+	ETag string `json:"etag,omitempty"`
+
+	// VersionId identifies the version of the object in a bucket with
+	// versioning enabled. It is empty for unversioned objects.
+	VersionId string `json:"version_id,omitempty"`
+
+	// IsDeleteMarker is set for the version recording that a versioned
+	// object was deleted
+	IsDeleteMarker bool `json:"is_delete_marker,omitempty"`
 }
 
+// NullVersionId is the version ID reported for objects stored while
+// versioning was not enabled
+const NullVersionId = "null"
+
 // Bucket represents a container for objects
 type Bucket struct {
 	Name      string    `json:"name"`
@@ -24,24 +38,65 @@ type Bucket struct {
 
 // ObjectListing represents a list of objects in a bucket
 type ObjectListing struct {
-	Bucket  string   `json:"bucket"`
-	Prefix  string   `json:"prefix"`
-	Objects []Object `json:"objects"`
+	Bucket    string   `json:"bucket"`
+	Prefix    string   `json:"prefix"`
+	Delimiter string   `json:"delimiter,omitempty"`
+	MaxKeys   int      `json:"max_keys"`
+	Objects   []Object `json:"objects"`
+
+	// CommonPrefixes holds the distinct key prefixes up to and including
+	// the first delimiter after Prefix, which are listed instead of the
+	// objects under them
+	CommonPrefixes []string `json:"common_prefixes,omitempty"`
+
+	// IsTruncated is set if there are more results, which can be listed
+	// by passing NextMarker as the marker of the next request
+	IsTruncated bool   `json:"is_truncated"`
+	NextMarker  string `json:"next_marker,omitempty"`
 }
 
 // PutObjectOptions contains optional parameters for PutObject
 type PutObjectOptions struct {
 	ContentType string
 	Metadata    map[string]string
+
+	// ContentMD5 is the expected hex-encoded MD5 digest of the content. The
+	// object is not stored if the received content doesn't match it.
+	ContentMD5 string
 }
 
 // GetObjectOptions contains optional parameters for GetObject
 type GetObjectOptions struct {
-	// Future: Add range, versioning, etc.
+	// VersionId selects a specific version of the object instead of the
+	// latest one
+	VersionId string
+
+	// Range selects a byte range of the object instead of its whole
+	// content, as requested by an HTTP Range header
+	Range bool
+
+	// RangeStart is the offset of the first byte to return. A negative
+	// value selects the last -RangeStart bytes of the object.
+	RangeStart int64
+
+	// RangeEnd is the offset of the last byte to return, inclusive. A
+	// negative value reads to the end of the object.
+	RangeEnd int64
 }
 
 // ListObjectsOptions contains optional parameters for listing objects
 type ListObjectsOptions struct {
+	// Prefix limits the listing to keys that begin with it
 	Prefix string
-	// Future: Add delimiter, max keys, etc.
+
+	// Delimiter groups keys that contain it after the prefix into common
+	// prefixes, like folders
+	Delimiter string
+
+	// MaxKeys is the maximum number of objects and common prefixes to
+	// return. Zero uses the default of 1000.
+	MaxKeys int
+
+	// Marker is the key or common prefix after which to start listing
+	Marker string
 }
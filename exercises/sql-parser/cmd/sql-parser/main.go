@@ -27,22 +27,125 @@ func main() {
 	}
 
 	printStatement(stmt)
+
+	fmt.Printf("\nCanonical SQL: %s\n", stmt)
 }
 
 // printStatement prints the parsed statement in a readable format
 func printStatement(node ast.Node) {
 	switch stmt := node.(type) {
 	case *ast.SelectStmt:
-		fmt.Println("SELECT")
+		if stmt.Distinct {
+			fmt.Println("SELECT DISTINCT")
+		} else {
+			fmt.Println("SELECT")
+		}
 		fmt.Println("  Fields:")
 		for _, field := range stmt.Fields {
-			if field.Name == "*" {
+			switch {
+			case field.Name == "*" && field.Table != "":
+				fmt.Printf("    %s.* (all columns of %s)\n", field.Table, field.Table)
+			case field.Name == "*":
 				fmt.Println("    * (all columns)")
-			} else {
+			case field.Name == "":
+				if field.Alias != "" {
+					fmt.Printf("    Expression AS %s:\n", field.Alias)
+				} else {
+					fmt.Println("    Expression:")
+				}
+				printExpression(field.Expr, "      ")
+			case field.Alias != "":
+				fmt.Printf("    %s AS %s\n", field.Name, field.Alias)
+			default:
 				fmt.Printf("    %s\n", field.Name)
 			}
 		}
 
+		fmt.Println("  From:")
+		for _, table := range stmt.From {
+			printTableRef(table, "    ")
+		}
+
+		for _, join := range stmt.Joins {
+			fmt.Printf("  %s Join:\n", join.Type)
+			printTableRef(join.Table, "    ")
+			fmt.Println("    On:")
+			printExpression(join.On, "      ")
+		}
+
+		if stmt.Where != nil {
+			fmt.Println("  Where:")
+			printExpression(stmt.Where, "    ")
+		}
+
+		if len(stmt.GroupBy) > 0 {
+			fmt.Println("  Group By:")
+			for _, g := range stmt.GroupBy {
+				printExpression(g, "    ")
+			}
+		}
+
+		if stmt.Having != nil {
+			fmt.Println("  Having:")
+			printExpression(stmt.Having, "    ")
+		}
+
+		if len(stmt.OrderBy) > 0 {
+			fmt.Println("  Order By:")
+			for _, o := range stmt.OrderBy {
+				direction := "ASC"
+				if o.Desc {
+					direction = "DESC"
+				}
+				fmt.Printf("    %s:\n", direction)
+				printExpression(o.Expr, "      ")
+			}
+		}
+
+		if stmt.Limit != nil {
+			fmt.Printf("  Limit: %d\n", *stmt.Limit)
+		}
+
+		if stmt.Offset != nil {
+			fmt.Printf("  Offset: %d\n", *stmt.Offset)
+		}
+
+	case *ast.InsertStmt:
+		fmt.Println("INSERT")
+		fmt.Printf("  Into: %s\n", stmt.TableName)
+
+		if len(stmt.Columns) > 0 {
+			fmt.Println("  Columns:")
+			for _, col := range stmt.Columns {
+				fmt.Printf("    %s\n", col)
+			}
+		}
+
+		fmt.Println("  Values:")
+		for i, row := range stmt.Rows {
+			fmt.Printf("    Row %d:\n", i+1)
+			for _, value := range row {
+				printExpression(value, "      ")
+			}
+		}
+
+	case *ast.UpdateStmt:
+		fmt.Println("UPDATE")
+		fmt.Printf("  Table: %s\n", stmt.TableName)
+
+		fmt.Println("  Set:")
+		for _, a := range stmt.Assignments {
+			fmt.Printf("    %s:\n", a.Column)
+			printExpression(a.Value, "      ")
+		}
+
+		if stmt.Where != nil {
+			fmt.Println("  Where:")
+			printExpression(stmt.Where, "    ")
+		}
+
+	case *ast.DeleteStmt:
+		fmt.Println("DELETE")
 		fmt.Printf("  From: %s\n", stmt.TableName)
 
 		if stmt.Where != nil {
@@ -55,6 +158,15 @@ func printStatement(node ast.Node) {
 	}
 }
 
+// printTableRef prints a table reference and its alias, if any
+func printTableRef(table ast.TableRef, indent string) {
+	if table.Alias != "" {
+		fmt.Printf("%s%s AS %s\n", indent, table.Name, table.Alias)
+	} else {
+		fmt.Printf("%s%s\n", indent, table.Name)
+	}
+}
+
 // printExpression recursively prints an expression
 func printExpression(expr ast.Expr, indent string) {
 	switch e := expr.(type) {
@@ -64,10 +176,56 @@ func printExpression(expr ast.Expr, indent string) {
 		printExpression(e.Left, indent+"    ")
 		fmt.Printf("%s  Right:\n", indent)
 		printExpression(e.Right, indent+"    ")
+	case *ast.InExpr:
+		if e.Negated {
+			fmt.Printf("%sNot In:\n", indent)
+		} else {
+			fmt.Printf("%sIn:\n", indent)
+		}
+		printExpression(e.Expr, indent+"  ")
+		fmt.Printf("%s  List:\n", indent)
+		for _, item := range e.List {
+			printExpression(item, indent+"    ")
+		}
+	case *ast.BetweenExpr:
+		if e.Negated {
+			fmt.Printf("%sNot Between:\n", indent)
+		} else {
+			fmt.Printf("%sBetween:\n", indent)
+		}
+		printExpression(e.Expr, indent+"  ")
+		fmt.Printf("%s  Low:\n", indent)
+		printExpression(e.Low, indent+"    ")
+		fmt.Printf("%s  High:\n", indent)
+		printExpression(e.High, indent+"    ")
+	case *ast.IsNullExpr:
+		if e.Negated {
+			fmt.Printf("%sIs Not Null:\n", indent)
+		} else {
+			fmt.Printf("%sIs Null:\n", indent)
+		}
+		printExpression(e.Expr, indent+"  ")
+	case *ast.NullLit:
+		fmt.Printf("%sNull\n", indent)
+	case *ast.FuncCall:
+		fmt.Printf("%sFunction: %s\n", indent, e.Name)
+		if e.Star {
+			fmt.Printf("%s  *\n", indent)
+		}
+		for _, arg := range e.Args {
+			printExpression(arg, indent+"  ")
+		}
 	case *ast.ColRef:
 		fmt.Printf("%sColumn: %s\n", indent, e.Name)
+	case *ast.QualifiedRef:
+		fmt.Printf("%sColumn: %s (table %s)\n", indent, e.Column, e.Table)
+	case *ast.UnaryExpr:
+		fmt.Printf("%sUnary Expression: %s\n", indent, e.Op)
+		printExpression(e.Operand, indent+"  ")
 	case *ast.NumberLit:
 		fmt.Printf("%sNumber: %d\n", indent, e.Value)
+	case *ast.FloatLit:
+		fmt.Printf("%sNumber: %g\n", indent, e.Value)
 	case *ast.StringLit:
 		fmt.Printf("%sString: '%s'\n", indent, e.Value)
 	case *ast.BoolLit:
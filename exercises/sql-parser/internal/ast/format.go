@@ -0,0 +1,332 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Binding strengths used to decide where parentheses are needed when
+// rendering expressions. They mirror the precedences used by the parser.
+const (
+	precOr      = iota + 1 // OR
+	precAnd                // AND
+	precNot                // NOT X
+	precCompare            // =, <, LIKE, IN, BETWEEN, IS, ...
+	precSum                // +, -
+	precProduct            // *, /
+	precPrefix             // -X
+	precAtom               // literals, column references, function calls
+)
+
+// binaryPrecedences maps binary operators to their binding strength.
+var binaryPrecedences = map[string]int{
+	"AND": precAnd,
+	"OR":  precOr,
+	"+":   precSum,
+	"-":   precSum,
+	"*":   precProduct,
+	"/":   precProduct,
+}
+
+// precedence returns the binding strength of an expression's outermost
+// operator.
+func precedence(e Expr) int {
+	switch e := e.(type) {
+	case *BinaryExpr:
+		if p, ok := binaryPrecedences[e.Op]; ok {
+			return p
+		}
+		return precCompare
+	case *InExpr, *BetweenExpr, *IsNullExpr:
+		return precCompare
+	case *UnaryExpr:
+		if e.Op == "NOT" {
+			return precNot
+		}
+		return precPrefix
+	default:
+		return precAtom
+	}
+}
+
+// group renders e, parenthesized if it binds less tightly than min.
+func group(e Expr, min int) string {
+	if precedence(e) < min {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+// groupLeft renders the left operand of an infix operator binding at min.
+// A BETWEEN on the left of anything but AND and OR is parenthesized: the
+// parser would group it the same way, but x BETWEEN 1 AND 2 = TRUE reads as
+// if the comparison belonged to the upper bound.
+func groupLeft(e Expr, min int) string {
+	if _, ok := e.(*BetweenExpr); ok && min > precAnd {
+		return "(" + e.String() + ")"
+	}
+	return group(e, min)
+}
+
+// String renders the statement as SQL.
+func (s *SelectStmt) String() string {
+	var b strings.Builder
+
+	fields := make([]string, len(s.Fields))
+	for i, f := range s.Fields {
+		fields[i] = f.String()
+	}
+	b.WriteString("SELECT ")
+	if s.Distinct {
+		b.WriteString("DISTINCT ")
+	}
+	b.WriteString(strings.Join(fields, ", "))
+
+	if len(s.From) > 0 {
+		tables := make([]string, len(s.From))
+		for i, t := range s.From {
+			tables[i] = t.String()
+		}
+		b.WriteString(" FROM " + strings.Join(tables, ", "))
+	} else {
+		b.WriteString(" FROM " + s.TableName)
+	}
+
+	for _, j := range s.Joins {
+		b.WriteString(" " + j.String())
+	}
+
+	if s.Where != nil {
+		b.WriteString(" WHERE " + s.Where.String())
+	}
+
+	if len(s.GroupBy) > 0 {
+		b.WriteString(" GROUP BY " + joinExprs(s.GroupBy))
+	}
+
+	if s.Having != nil {
+		b.WriteString(" HAVING " + s.Having.String())
+	}
+
+	if len(s.OrderBy) > 0 {
+		terms := make([]string, len(s.OrderBy))
+		for i, o := range s.OrderBy {
+			terms[i] = o.String()
+		}
+		b.WriteString(" ORDER BY " + strings.Join(terms, ", "))
+	}
+
+	if s.Limit != nil {
+		fmt.Fprintf(&b, " LIMIT %d", *s.Limit)
+	}
+
+	if s.Offset != nil {
+		fmt.Fprintf(&b, " OFFSET %d", *s.Offset)
+	}
+
+	return b.String()
+}
+
+// String renders the statement as SQL.
+func (s *InsertStmt) String() string {
+	var b strings.Builder
+
+	b.WriteString("INSERT INTO " + s.TableName)
+	if len(s.Columns) > 0 {
+		b.WriteString(" (" + strings.Join(s.Columns, ", ") + ")")
+	}
+
+	rows := make([]string, len(s.Rows))
+	for i, row := range s.Rows {
+		rows[i] = "(" + joinExprs(row) + ")"
+	}
+	b.WriteString(" VALUES " + strings.Join(rows, ", "))
+
+	return b.String()
+}
+
+// String renders the statement as SQL.
+func (s *UpdateStmt) String() string {
+	assignments := make([]string, len(s.Assignments))
+	for i, a := range s.Assignments {
+		assignments[i] = a.String()
+	}
+
+	sql := "UPDATE " + s.TableName + " SET " + strings.Join(assignments, ", ")
+	if s.Where != nil {
+		sql += " WHERE " + s.Where.String()
+	}
+	return sql
+}
+
+// String renders the statement as SQL.
+func (s *DeleteStmt) String() string {
+	sql := "DELETE FROM " + s.TableName
+	if s.Where != nil {
+		sql += " WHERE " + s.Where.String()
+	}
+	return sql
+}
+
+// String renders the field as it appears in a SELECT list.
+func (f *Field) String() string {
+	sql := f.Name
+	if f.Expr != nil {
+		sql = f.Expr.String()
+	} else if f.Table != "" {
+		sql = f.Table + ".*"
+	}
+	if f.Alias != "" {
+		sql += " AS " + f.Alias
+	}
+	return sql
+}
+
+// String renders the table reference as it appears in a FROM or JOIN clause.
+func (t TableRef) String() string {
+	if t.Alias != "" {
+		return t.Name + " AS " + t.Alias
+	}
+	return t.Name
+}
+
+// String renders the JOIN clause as SQL.
+func (j JoinClause) String() string {
+	return fmt.Sprintf("%s JOIN %s ON %s", j.Type, j.Table, j.On)
+}
+
+// String renders the ORDER BY term as SQL. Ascending order is the default
+// and is left implicit.
+func (o OrderByClause) String() string {
+	if o.Desc {
+		return o.Expr.String() + " DESC"
+	}
+	return o.Expr.String()
+}
+
+// String renders the assignment as it appears in a SET clause.
+func (a Assignment) String() string {
+	return a.Column + " = " + a.Value.String()
+}
+
+// String renders the expression as SQL. Operands are parenthesized where
+// the parser would otherwise group them differently; operators of equal
+// precedence associate to the left.
+func (b *BinaryExpr) String() string {
+	p := precedence(b)
+	if p == precOr || p == precAnd {
+		// AND binds more tightly than OR, but mixing them is always made
+		// explicit so the rendering doesn't rely on it
+		return conditionOperand(b.Left, b.Op, p) + " " + b.Op + " " + conditionOperand(b.Right, b.Op, p+1)
+	}
+	return groupLeft(b.Left, p) + " " + b.Op + " " + group(b.Right, p+1)
+}
+
+// conditionOperand renders an operand of an AND or OR binding at min,
+// parenthesizing conditions that use the other operator.
+func conditionOperand(e Expr, op string, min int) string {
+	if b, ok := e.(*BinaryExpr); ok && (b.Op == "AND" || b.Op == "OR") && b.Op != op {
+		return "(" + b.String() + ")"
+	}
+	return group(e, min)
+}
+
+// String renders the expression as SQL.
+func (u *UnaryExpr) String() string {
+	if u.Op == "NOT" {
+		return "NOT " + group(u.Operand, precNot)
+	}
+	operand := group(u.Operand, precPrefix)
+	if inner, ok := u.Operand.(*UnaryExpr); ok && inner.Op != "NOT" {
+		// Avoid "--x", which would read as a comment
+		operand = "(" + operand + ")"
+	}
+	return u.Op + operand
+}
+
+// String renders the expression as SQL.
+func (i *InExpr) String() string {
+	op := " IN "
+	if i.Negated {
+		op = " NOT IN "
+	}
+	return groupLeft(i.Expr, precCompare) + op + "(" + joinExprs(i.List) + ")"
+}
+
+// String renders the expression as SQL.
+func (b *BetweenExpr) String() string {
+	op := " BETWEEN "
+	if b.Negated {
+		op = " NOT BETWEEN "
+	}
+	return groupLeft(b.Expr, precCompare) + op + group(b.Low, precSum) + " AND " + group(b.High, precSum)
+}
+
+// String renders the expression as SQL.
+func (i *IsNullExpr) String() string {
+	if i.Negated {
+		return groupLeft(i.Expr, precCompare) + " IS NOT NULL"
+	}
+	return groupLeft(i.Expr, precCompare) + " IS NULL"
+}
+
+// String renders the expression as SQL.
+func (f *FuncCall) String() string {
+	if f.Star {
+		return f.Name + "(*)"
+	}
+	return f.Name + "(" + joinExprs(f.Args) + ")"
+}
+
+// String renders the expression as SQL.
+func (c *ColRef) String() string {
+	return c.Name
+}
+
+// String renders the expression as SQL.
+func (q *QualifiedRef) String() string {
+	return q.Table + "." + q.Column
+}
+
+// String renders the expression as SQL.
+func (n *NumberLit) String() string {
+	return strconv.FormatInt(n.Value, 10)
+}
+
+// String renders the expression as SQL. Whole numbers keep a fractional
+// part so that they parse back as floats.
+func (f *FloatLit) String() string {
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return s
+}
+
+// String renders the expression as SQL, escaping embedded quotes.
+func (s *StringLit) String() string {
+	return "'" + strings.ReplaceAll(s.Value, "'", "''") + "'"
+}
+
+// String renders the expression as SQL.
+func (b *BoolLit) String() string {
+	if b.Value {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// String renders the expression as SQL.
+func (n *NullLit) String() string {
+	return "NULL"
+}
+
+// joinExprs renders a comma-separated list of expressions.
+func joinExprs(exprs []Expr) string {
+	parts := make([]string, len(exprs))
+	for i, e := range exprs {
+		parts[i] = e.String()
+	}
+	return strings.Join(parts, ", ")
+}
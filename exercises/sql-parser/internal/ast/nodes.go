@@ -5,6 +5,8 @@ type Node interface {
 	// node is an unexported method to ensure only types in this package
 	// can be AST nodes.
 	node()
+	// String renders the node as SQL that parses back to an equivalent tree.
+	String() string
 }
 
 // Statement represents a SQL statement.
@@ -15,12 +17,28 @@ type Statement interface {
 
 // SelectStmt represents a SELECT SQL statement.
 type SelectStmt struct {
+	// Distinct is true for SELECT DISTINCT, which removes duplicate rows.
+	Distinct bool
 	// Fields is the list of columns being selected.
 	Fields []*Field
-	// TableName is the name of the table to select from.
+	// TableName is the name of the first table to select from.
 	TableName string
+	// From is the list of tables in the FROM clause.
+	From []TableRef
+	// Joins is the list of JOIN clauses, in order.
+	Joins []JoinClause
 	// Where is the WHERE clause expression, if any.
 	Where Expr
+	// GroupBy is the list of GROUP BY expressions, if any.
+	GroupBy []Expr
+	// Having is the HAVING clause expression, if any.
+	Having Expr
+	// OrderBy is the list of ORDER BY terms, if any.
+	OrderBy []OrderByClause
+	// Limit is the maximum number of rows to return, if specified.
+	Limit *int64
+	// Offset is the number of rows to skip, if specified.
+	Offset *int64
 }
 
 // node implements the Node interface.
@@ -29,10 +47,108 @@ func (s *SelectStmt) node() {}
 // stmt implements the Statement interface.
 func (s *SelectStmt) stmt() {}
 
+// TableRef represents a table in a FROM or JOIN clause.
+type TableRef struct {
+	// Name is the name of the table.
+	Name string
+	// Alias is the alias given to the table, if any.
+	Alias string
+}
+
+// JoinType is the kind of a JOIN.
+type JoinType string
+
+const (
+	// InnerJoin is an INNER JOIN, or a bare JOIN.
+	InnerJoin JoinType = "INNER"
+	// LeftJoin is a LEFT [OUTER] JOIN.
+	LeftJoin JoinType = "LEFT"
+)
+
+// JoinClause represents a JOIN clause in a SELECT statement.
+type JoinClause struct {
+	// Type is the kind of join.
+	Type JoinType
+	// Table is the joined table.
+	Table TableRef
+	// On is the join condition.
+	On Expr
+}
+
+// OrderByClause represents a single term of an ORDER BY clause.
+type OrderByClause struct {
+	// Expr is the expression to sort by.
+	Expr Expr
+	// Desc is true for descending order. The default is ascending.
+	Desc bool
+}
+
+// InsertStmt represents an INSERT SQL statement.
+type InsertStmt struct {
+	// TableName is the name of the table to insert into.
+	TableName string
+	// Columns is the list of target columns, if specified.
+	Columns []string
+	// Rows is the list of value tuples to insert.
+	Rows [][]Expr
+}
+
+// node implements the Node interface.
+func (s *InsertStmt) node() {}
+
+// stmt implements the Statement interface.
+func (s *InsertStmt) stmt() {}
+
+// UpdateStmt represents an UPDATE SQL statement.
+type UpdateStmt struct {
+	// TableName is the name of the table to update.
+	TableName string
+	// Assignments is the list of column assignments in the SET clause.
+	Assignments []Assignment
+	// Where is the WHERE clause expression, if any.
+	Where Expr
+}
+
+// node implements the Node interface.
+func (s *UpdateStmt) node() {}
+
+// stmt implements the Statement interface.
+func (s *UpdateStmt) stmt() {}
+
+// Assignment represents a single column = value pair in an UPDATE statement.
+type Assignment struct {
+	// Column is the name of the column being assigned.
+	Column string
+	// Value is the expression assigned to the column.
+	Value Expr
+}
+
+// DeleteStmt represents a DELETE SQL statement.
+type DeleteStmt struct {
+	// TableName is the name of the table to delete from.
+	TableName string
+	// Where is the WHERE clause expression, if any.
+	Where Expr
+}
+
+// node implements the Node interface.
+func (s *DeleteStmt) node() {}
+
+// stmt implements the Statement interface.
+func (s *DeleteStmt) stmt() {}
+
 // Field represents a selected field in a SELECT statement.
 type Field struct {
-	// Name is the name of the field.
+	// Name is the name of the field. It is set for column references, as
+	// table.column for qualified ones, and to "*" for wildcards, and empty
+	// for other expressions.
 	Name string
+	// Table is the table qualifying a wildcard (e.g., t.*), if any.
+	Table string
+	// Expr is the selected expression. It is nil for wildcards.
+	Expr Expr
+	// Alias is the output name given with AS, if any.
+	Alias string
 }
 
 // Expr represents an expression in SQL.
@@ -45,7 +161,7 @@ type Expr interface {
 type BinaryExpr struct {
 	// Left is the left-hand side of the expression.
 	Left Expr
-	// Op is the operator (e.g., "=", "!=", ">", "<", etc.).
+	// Op is the operator (e.g., "=", "!=", ">", "<", "LIKE", etc.).
 	Op string
 	// Right is the right-hand side of the expression.
 	Right Expr
@@ -54,7 +170,70 @@ type BinaryExpr struct {
 func (b *BinaryExpr) node() {}
 func (b *BinaryExpr) expr() {}
 
-// ColRef represents a column reference (e.g., users.id).
+// UnaryExpr represents a prefix expression (e.g., -1 or NOT active).
+type UnaryExpr struct {
+	// Op is the operator ("-" or "NOT").
+	Op string
+	// Operand is the expression the operator applies to.
+	Operand Expr
+}
+
+func (u *UnaryExpr) node() {}
+func (u *UnaryExpr) expr() {}
+
+// InExpr represents an IN or NOT IN predicate (e.g., id IN (1, 2, 3)).
+type InExpr struct {
+	// Expr is the expression being tested.
+	Expr Expr
+	// List is the list of values to test against.
+	List []Expr
+	// Negated is true for NOT IN.
+	Negated bool
+}
+
+func (i *InExpr) node() {}
+func (i *InExpr) expr() {}
+
+// BetweenExpr represents a BETWEEN predicate (e.g., age BETWEEN 18 AND 65).
+type BetweenExpr struct {
+	// Expr is the expression being tested.
+	Expr Expr
+	// Low is the inclusive lower bound.
+	Low Expr
+	// High is the inclusive upper bound.
+	High Expr
+	// Negated is true for NOT BETWEEN.
+	Negated bool
+}
+
+func (b *BetweenExpr) node() {}
+func (b *BetweenExpr) expr() {}
+
+// IsNullExpr represents an IS NULL or IS NOT NULL predicate.
+type IsNullExpr struct {
+	// Expr is the expression being tested.
+	Expr Expr
+	// Negated is true for IS NOT NULL.
+	Negated bool
+}
+
+func (i *IsNullExpr) node() {}
+func (i *IsNullExpr) expr() {}
+
+// FuncCall represents a function call (e.g., COUNT(*) or MAX(age)).
+type FuncCall struct {
+	// Name is the name of the function.
+	Name string
+	// Args is the list of arguments.
+	Args []Expr
+	// Star is true when the sole argument is * (e.g., COUNT(*)).
+	Star bool
+}
+
+func (f *FuncCall) node() {}
+func (f *FuncCall) expr() {}
+
+// ColRef represents a column reference (e.g., id).
 type ColRef struct {
 	// Name is the name of the column.
 	Name string
@@ -63,6 +242,18 @@ type ColRef struct {
 func (c *ColRef) node() {}
 func (c *ColRef) expr() {}
 
+// QualifiedRef represents a column reference qualified by a table name or
+// alias (e.g., users.id).
+type QualifiedRef struct {
+	// Table is the name or alias of the table.
+	Table string
+	// Column is the name of the column.
+	Column string
+}
+
+func (q *QualifiedRef) node() {}
+func (q *QualifiedRef) expr() {}
+
 // NumberLit represents a numeric literal (e.g., 42).
 type NumberLit struct {
 	// Value is the numeric value.
@@ -72,6 +263,15 @@ type NumberLit struct {
 func (n *NumberLit) node() {}
 func (n *NumberLit) expr() {}
 
+// FloatLit represents a floating-point literal (e.g., 3.14 or 2.5e-3).
+type FloatLit struct {
+	// Value is the numeric value.
+	Value float64
+}
+
+func (f *FloatLit) node() {}
+func (f *FloatLit) expr() {}
+
 // StringLit represents a string literal (e.g., 'hello').
 type StringLit struct {
 	// Value is the string value, without surrounding quotes.
@@ -89,3 +289,9 @@ type BoolLit struct {
 
 func (b *BoolLit) node() {}
 func (b *BoolLit) expr() {}
+
+// NullLit represents the NULL literal.
+type NullLit struct{}
+
+func (n *NullLit) node() {}
+func (n *NullLit) expr() {}
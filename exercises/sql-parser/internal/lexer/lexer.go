@@ -31,7 +31,9 @@ func New(input string) *Lexer {
 
 // NextToken returns the next token from the input.
 func (l *Lexer) NextToken() Token {
-	l.skipWhitespace()
+	if start, ok := l.skipWhitespace(); !ok {
+		return Token{Type: ILLEGAL, Literal: "/*", Pos: start}
+	}
 
 	// Capture the starting position of the token before any character is read
 	// This ensures we get the correct position for the token
@@ -172,9 +174,38 @@ func (l *Lexer) NextToken() Token {
 	}
 }
 
-func (l *Lexer) skipWhitespace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
-		l.readChar()
+// skipWhitespace skips whitespace, "-- ..." line comments and "/* ... */"
+// block comments. It returns false, along with the position of the opening
+// "/*", if a block comment is not terminated before the end of input.
+func (l *Lexer) skipWhitespace() (Position, bool) {
+	for {
+		switch {
+		case l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r':
+			l.readChar()
+		case l.ch == '-' && l.peekChar() == '-':
+			// Line comment: skip to the end of the line
+			for l.ch != '\n' && l.ch != 0 {
+				l.readChar()
+			}
+		case l.ch == '/' && l.peekChar() == '*':
+			start := Position{Line: l.pos.Line, Column: l.pos.Column}
+			if start.Column == 0 {
+				start.Column = 1
+			}
+
+			l.readChar() // consume '/'
+			l.readChar() // consume '*'
+			for !(l.ch == '*' && l.peekChar() == '/') {
+				if l.ch == 0 {
+					return start, false
+				}
+				l.readChar()
+			}
+			l.readChar() // consume '*'
+			l.readChar() // consume '/'
+		default:
+			return Position{}, true
+		}
 	}
 }
 
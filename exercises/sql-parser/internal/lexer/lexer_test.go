@@ -93,6 +93,92 @@ func TestNextToken(t *testing.T) {
 	}
 }
 
+func TestComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []Token
+	}{
+		{
+			name:  "line comment",
+			input: "SELECT id -- get the id\nFROM users",
+			expected: []Token{
+				{Type: SELECT, Literal: "SELECT", Pos: Position{Line: 1, Column: 1}},
+				{Type: IDENT, Literal: "id", Pos: Position{Line: 1, Column: 8}},
+				{Type: FROM, Literal: "FROM", Pos: Position{Line: 2, Column: 1}},
+				{Type: IDENT, Literal: "users", Pos: Position{Line: 2, Column: 6}},
+				{Type: EOF, Literal: "", Pos: Position{Line: 2, Column: 11}},
+			},
+		},
+		{
+			name:  "block comment spanning lines",
+			input: "SELECT /* all\ncolumns */ * FROM t",
+			expected: []Token{
+				{Type: SELECT, Literal: "SELECT", Pos: Position{Line: 1, Column: 1}},
+				{Type: ASTERISK, Literal: "*", Pos: Position{Line: 2, Column: 12}},
+				{Type: FROM, Literal: "FROM", Pos: Position{Line: 2, Column: 14}},
+				{Type: IDENT, Literal: "t", Pos: Position{Line: 2, Column: 19}},
+				{Type: EOF, Literal: "", Pos: Position{Line: 2, Column: 20}},
+			},
+		},
+		{
+			name:  "double dash starts a comment",
+			input: "a--b",
+			expected: []Token{
+				{Type: IDENT, Literal: "a", Pos: Position{Line: 1, Column: 1}},
+				{Type: EOF, Literal: "", Pos: Position{Line: 1, Column: 5}},
+			},
+		},
+		{
+			name:  "separated minus signs and division",
+			input: "a - -b / c",
+			expected: []Token{
+				{Type: IDENT, Literal: "a", Pos: Position{Line: 1, Column: 1}},
+				{Type: MINUS, Literal: "-", Pos: Position{Line: 1, Column: 3}},
+				{Type: MINUS, Literal: "-", Pos: Position{Line: 1, Column: 5}},
+				{Type: IDENT, Literal: "b", Pos: Position{Line: 1, Column: 6}},
+				{Type: SLASH, Literal: "/", Pos: Position{Line: 1, Column: 8}},
+				{Type: IDENT, Literal: "c", Pos: Position{Line: 1, Column: 10}},
+				{Type: EOF, Literal: "", Pos: Position{Line: 1, Column: 11}},
+			},
+		},
+		{
+			name:  "unterminated block comment",
+			input: "SELECT *\n  /* never closed",
+			expected: []Token{
+				{Type: SELECT, Literal: "SELECT", Pos: Position{Line: 1, Column: 1}},
+				{Type: ASTERISK, Literal: "*", Pos: Position{Line: 1, Column: 8}},
+				{Type: ILLEGAL, Literal: "/*", Pos: Position{Line: 2, Column: 3}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(tt.input)
+
+			for i, expectedToken := range tt.expected {
+				tok := l.NextToken()
+
+				if tok.Type != expectedToken.Type {
+					t.Fatalf("tests[%d] - tokentype wrong. expected=%v, got=%v (literal=%q)",
+						i, expectedToken.Type, tok.Type, tok.Literal)
+				}
+
+				if tok.Literal != expectedToken.Literal {
+					t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+						i, expectedToken.Literal, tok.Literal)
+				}
+
+				if tok.Pos != expectedToken.Pos {
+					t.Fatalf("tests[%d] - position wrong. expected=%+v, got=%+v",
+						i, expectedToken.Pos, tok.Pos)
+				}
+			}
+		})
+	}
+}
+
 func TestTokenize(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -109,6 +195,11 @@ func TestTokenize(t *testing.T) {
 			input:   "SELECT @ FROM users",
 			wantErr: true,
 		},
+		{
+			name:    "unterminated block comment",
+			input:   "SELECT * FROM users /* oops",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
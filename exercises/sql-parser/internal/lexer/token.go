@@ -1,6 +1,9 @@
 package lexer
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 // TokenType represents the type of a token.
 type TokenType int
@@ -44,18 +47,105 @@ const (
 	TRUE
 	FALSE
 	NULL
+	INSERT
+	INTO
+	VALUES
+	UPDATE
+	SET
+	DELETE
+	ORDER
+	BY
+	ASC
+	DESC
+	LIMIT
+	OFFSET
+	IN
+	LIKE
+	BETWEEN
+	IS
+	AS
+	GROUP
+	HAVING
+	JOIN
+	INNER
+	LEFT
+	OUTER
+	ON
+	DISTINCT
 )
 
 var keywords = map[string]TokenType{
-	"SELECT": SELECT,
-	"FROM":   FROM,
-	"WHERE":  WHERE,
-	"AND":    AND,
-	"OR":     OR,
-	"NOT":    NOT,
-	"TRUE":   TRUE,
-	"FALSE":  FALSE,
-	"NULL":   NULL,
+	"SELECT":   SELECT,
+	"FROM":     FROM,
+	"WHERE":    WHERE,
+	"AND":      AND,
+	"OR":       OR,
+	"NOT":      NOT,
+	"TRUE":     TRUE,
+	"FALSE":    FALSE,
+	"NULL":     NULL,
+	"INSERT":   INSERT,
+	"INTO":     INTO,
+	"VALUES":   VALUES,
+	"UPDATE":   UPDATE,
+	"SET":      SET,
+	"DELETE":   DELETE,
+	"ORDER":    ORDER,
+	"BY":       BY,
+	"ASC":      ASC,
+	"DESC":     DESC,
+	"LIMIT":    LIMIT,
+	"OFFSET":   OFFSET,
+	"IN":       IN,
+	"LIKE":     LIKE,
+	"BETWEEN":  BETWEEN,
+	"IS":       IS,
+	"AS":       AS,
+	"GROUP":    GROUP,
+	"HAVING":   HAVING,
+	"JOIN":     JOIN,
+	"INNER":    INNER,
+	"LEFT":     LEFT,
+	"OUTER":    OUTER,
+	"ON":       ON,
+	"DISTINCT": DISTINCT,
+}
+
+// tokenNames holds display names for non-keyword tokens.
+var tokenNames = map[TokenType]string{
+	ILLEGAL:   "ILLEGAL",
+	EOF:       "EOF",
+	WS:        "WS",
+	IDENT:     "IDENT",
+	NUMBER:    "NUMBER",
+	STRING:    "STRING",
+	EQ:        "=",
+	NEQ:       "!=",
+	LT:        "<",
+	GT:        ">",
+	LTE:       "<=",
+	GTE:       ">=",
+	PLUS:      "+",
+	MINUS:     "-",
+	ASTERISK:  "*",
+	SLASH:     "/",
+	COMMA:     ",",
+	SEMICOLON: ";",
+	LPAREN:    "(",
+	RPAREN:    ")",
+}
+
+// String returns a readable name for the token type, used in error messages.
+func (t TokenType) String() string {
+	if name, ok := tokenNames[t]; ok {
+		return name
+	}
+	for name, tok := range keywords {
+		if tok == t {
+			return name
+		}
+	}
+	return fmt.Sprintf("TokenType(%d)", int(t))
 }
 
 // Token represents a token or text string returned from the scanner.
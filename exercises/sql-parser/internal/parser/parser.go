@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/kumarlokesh/sql-parser/internal/ast"
@@ -14,12 +15,26 @@ type Parser struct {
 
 	currentToken lexer.Token
 	peekToken    lexer.Token
-	errors       []string
+	errors       []ParseError
+	recovery     bool
 
 	prefixParseFns map[lexer.TokenType]prefixParseFn
 	infixParseFns  map[lexer.TokenType]infixParseFn
 }
 
+// ParseError is a syntax error found at a position in the input.
+type ParseError struct {
+	// Pos is the position of the offending token.
+	Pos lexer.Position
+	// Msg describes the error.
+	Msg string
+}
+
+// Error implements the error interface.
+func (e ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Pos.Line, e.Pos.Column, e.Msg)
+}
+
 type (
 	prefixParseFn func() (ast.Expr, error)
 	infixParseFn  func(ast.Expr) (ast.Expr, error)
@@ -29,7 +44,7 @@ type (
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
 		l:              l,
-		errors:         []string{},
+		errors:         []ParseError{},
 		prefixParseFns: make(map[lexer.TokenType]prefixParseFn),
 		infixParseFns:  make(map[lexer.TokenType]infixParseFn),
 	}
@@ -40,6 +55,10 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(lexer.STRING, p.parseStringLiteral)
 	p.registerPrefix(lexer.TRUE, p.parseBoolean)
 	p.registerPrefix(lexer.FALSE, p.parseBoolean)
+	p.registerPrefix(lexer.NULL, p.parseNull)
+	p.registerPrefix(lexer.LPAREN, p.parseGroupedExpression)
+	p.registerPrefix(lexer.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(lexer.NOT, p.parseNotExpression)
 
 	// Register infix functions with their precedence
 	p.registerInfix(lexer.EQ, p.parseInfixExpression)
@@ -54,6 +73,12 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(lexer.SLASH, p.parseInfixExpression)
 	p.registerInfix(lexer.AND, p.parseInfixExpression)
 	p.registerInfix(lexer.OR, p.parseInfixExpression)
+	p.registerInfix(lexer.IN, p.parseInExpression)
+	p.registerInfix(lexer.LIKE, p.parseInfixExpression)
+	p.registerInfix(lexer.BETWEEN, p.parseBetweenExpression)
+	p.registerInfix(lexer.IS, p.parseIsExpression)
+	p.registerInfix(lexer.LPAREN, p.parseCallExpression)
+	p.registerInfix(lexer.NOT, p.parseNotInfixExpression)
 
 	// Read two tokens, so currentToken and peekToken are both set
 	p.nextToken()
@@ -68,21 +93,159 @@ func (p *Parser) nextToken() {
 	p.peekToken = p.l.NextToken()
 }
 
-// Parse parses the input and returns the AST.
+// SetRecovery enables or disables error recovery. With recovery enabled,
+// Parse skips to the next statement boundary (semicolon) after a syntax error
+// and keeps parsing, so every error in the input is reported.
+func (p *Parser) SetRecovery(enabled bool) {
+	p.recovery = enabled
+}
+
+// Errors returns the syntax errors reported so far, in input order.
+func (p *Parser) Errors() []ParseError {
+	return p.errors
+}
+
+// Parse parses the input and returns the AST. If any errors were found, the
+// returned error combines all of them; Errors returns the detailed list.
+// With recovery enabled, the first successfully parsed statement is returned.
 func (p *Parser) Parse() (ast.Statement, error) {
-	if p.currentToken.Type == lexer.SELECT {
+	if !p.recovery {
+		stmt, err := p.parseStatementRecording()
+		if err != nil {
+			return nil, p.combinedError()
+		}
+		return stmt, nil
+	}
+
+	var first ast.Statement
+	for !p.currentTokenIs(lexer.EOF) {
+		if p.currentTokenIs(lexer.SEMICOLON) {
+			p.nextToken() // skip empty statements
+			continue
+		}
+
+		stmt, err := p.parseStatementRecording()
+		if err == nil && first == nil {
+			first = stmt
+		}
+
+		p.skipToNextStatement()
+	}
+
+	if len(p.errors) > 0 {
+		return first, p.combinedError()
+	}
+	return first, nil
+}
+
+// ParseProgram parses a sequence of statements separated by semicolons,
+// up to the end of the input. Empty statements are skipped, and the last
+// statement needs no terminating semicolon. Without recovery, parsing stops
+// at the first error; with it, every error is reported and the statements
+// that parsed are returned along with the combined error.
+func (p *Parser) ParseProgram() ([]ast.Statement, error) {
+	var stmts []ast.Statement
+	for !p.currentTokenIs(lexer.EOF) {
+		if p.currentTokenIs(lexer.SEMICOLON) {
+			p.nextToken() // skip empty statements
+			continue
+		}
+
+		stmt, err := p.parseStatementRecording()
+		if err != nil && !p.recovery {
+			return nil, p.combinedError()
+		}
+		if err == nil {
+			stmts = append(stmts, stmt)
+		}
+
+		p.skipToNextStatement()
+	}
+
+	if len(p.errors) > 0 {
+		return stmts, p.combinedError()
+	}
+	return stmts, nil
+}
+
+// parseStatement parses a single statement starting at the current token.
+func (p *Parser) parseStatement() (ast.Statement, error) {
+	switch p.currentToken.Type {
+	case lexer.SELECT:
 		return p.parseSelectStatement()
+	case lexer.INSERT:
+		return p.parseInsertStatement()
+	case lexer.UPDATE:
+		return p.parseUpdateStatement()
+	case lexer.DELETE:
+		return p.parseDeleteStatement()
 	}
 
-	return nil, fmt.Errorf("expected SELECT, got token type %d", p.currentToken.Type)
+	return nil, fmt.Errorf("expected SELECT, INSERT, UPDATE or DELETE, got %v", p.currentToken.Type)
+}
+
+// parseStatementRecording parses a single statement, checks that it ends at
+// a statement boundary, and records its error, if any. Errors already
+// recorded by expectPeek are not recorded twice.
+func (p *Parser) parseStatementRecording() (ast.Statement, error) {
+	n := len(p.errors)
+	stmt, err := p.parseStatement()
+	if err != nil {
+		if len(p.errors) == n {
+			p.errors = append(p.errors, ParseError{Pos: p.currentToken.Pos, Msg: err.Error()})
+		}
+		return nil, err
+	}
+
+	if !p.currentTokenIs(lexer.SEMICOLON) && !p.peekTokenIs(lexer.SEMICOLON) && !p.peekTokenIs(lexer.EOF) {
+		err := ParseError{
+			Pos: p.peekToken.Pos,
+			Msg: fmt.Sprintf("unexpected token %v after end of statement", p.peekToken.Type),
+		}
+		p.errors = append(p.errors, err)
+		return nil, err
+	}
+
+	return stmt, nil
+}
+
+// skipToNextStatement advances past the next semicolon, or to EOF if there
+// is none.
+func (p *Parser) skipToNextStatement() {
+	for !p.currentTokenIs(lexer.SEMICOLON) && !p.currentTokenIs(lexer.EOF) {
+		p.nextToken()
+	}
+	if p.currentTokenIs(lexer.SEMICOLON) {
+		p.nextToken()
+	}
+}
+
+// combinedError returns a single error describing every recorded error.
+func (p *Parser) combinedError() error {
+	switch len(p.errors) {
+	case 0:
+		return nil
+	case 1:
+		return p.errors[0]
+	}
+
+	msgs := make([]string, len(p.errors))
+	for i, e := range p.errors {
+		msgs[i] = e.Error()
+	}
+	return fmt.Errorf("%d parse errors: %s", len(p.errors), strings.Join(msgs, "; "))
 }
 
 // parseSelectStatement parses a SELECT SQL statement.
 func (p *Parser) parseSelectStatement() (*ast.SelectStmt, error) {
 	stmt := &ast.SelectStmt{}
 
-	// We've already seen the SELECT token, so we can proceed to parse fields
-	// Parse fields
+	// We've already seen the SELECT token; DISTINCT may come before the fields
+	if p.peekTokenIs(lexer.DISTINCT) {
+		p.nextToken() // consume DISTINCT
+		stmt.Distinct = true
+	}
+
 	fields, err := p.parseSelectFields()
 	if err != nil {
 		return nil, fmt.Errorf("error parsing fields: %v", err)
@@ -92,28 +255,257 @@ func (p *Parser) parseSelectStatement() (*ast.SelectStmt, error) {
 	if !p.expectPeek(lexer.FROM) {
 		return nil, fmt.Errorf("expected FROM, got token type %d", p.peekToken.Type)
 	}
+
+	// Parse the comma-separated table list
+	for {
+		table, err := p.parseTableRef()
+		if err != nil {
+			return nil, err
+		}
+		stmt.From = append(stmt.From, table)
+
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken() // consume comma
+	}
+	stmt.TableName = stmt.From[0].Name
+
+	joins, err := p.parseJoinClauses()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Joins = joins
+
+	where, err := p.parseWhereClause()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Where = where
+
+	if err := p.parseGroupByClause(stmt); err != nil {
+		return nil, err
+	}
+
+	orderBy, err := p.parseOrderByClause()
+	if err != nil {
+		return nil, err
+	}
+	stmt.OrderBy = orderBy
+
+	if p.peekTokenIs(lexer.LIMIT) {
+		p.nextToken() // consume LIMIT
+		limit, err := p.parseRowCount("LIMIT")
+		if err != nil {
+			return nil, err
+		}
+		stmt.Limit = limit
+	}
+
+	if p.peekTokenIs(lexer.OFFSET) {
+		p.nextToken() // consume OFFSET
+		offset, err := p.parseRowCount("OFFSET")
+		if err != nil {
+			return nil, err
+		}
+		stmt.Offset = offset
+	}
+
+	return stmt, nil
+}
+
+// parseTableRef parses a table name followed by an optional alias, either
+// "AS alias" or a bare "alias".
+func (p *Parser) parseTableRef() (ast.TableRef, error) {
 	if !p.expectPeek(lexer.IDENT) {
-		return nil, fmt.Errorf("expected table name, got token type %d", p.peekToken.Type)
+		return ast.TableRef{}, fmt.Errorf("expected table name, got token type %d", p.peekToken.Type)
 	}
-	stmt.TableName = p.currentToken.Literal
-	if p.peekTokenIs(lexer.WHERE) {
-		p.nextToken() // consume WHERE
+	table := ast.TableRef{Name: p.currentToken.Literal}
+
+	if p.peekTokenIs(lexer.AS) {
+		p.nextToken() // consume AS
+		if !p.expectPeek(lexer.IDENT) {
+			return ast.TableRef{}, fmt.Errorf("expected alias after AS, got token type %d", p.peekToken.Type)
+		}
+		table.Alias = p.currentToken.Literal
+	} else if p.peekTokenIs(lexer.IDENT) {
+		p.nextToken()
+		table.Alias = p.currentToken.Literal
+	}
+
+	return table, nil
+}
+
+// parseJoinClauses parses any JOIN clauses following the table list.
+// A bare JOIN is an inner join.
+func (p *Parser) parseJoinClauses() ([]ast.JoinClause, error) {
+	var joins []ast.JoinClause
+	for {
+		join := ast.JoinClause{Type: ast.InnerJoin}
+
+		switch {
+		case p.peekTokenIs(lexer.JOIN):
+			p.nextToken()
+		case p.peekTokenIs(lexer.INNER):
+			p.nextToken()
+			if !p.expectPeek(lexer.JOIN) {
+				return nil, fmt.Errorf("expected JOIN after INNER, got token type %d", p.peekToken.Type)
+			}
+		case p.peekTokenIs(lexer.LEFT):
+			p.nextToken()
+			join.Type = ast.LeftJoin
+			if p.peekTokenIs(lexer.OUTER) {
+				p.nextToken()
+			}
+			if !p.expectPeek(lexer.JOIN) {
+				return nil, fmt.Errorf("expected JOIN after LEFT, got token type %d", p.peekToken.Type)
+			}
+		default:
+			return joins, nil
+		}
+
+		table, err := p.parseTableRef()
+		if err != nil {
+			return nil, err
+		}
+		join.Table = table
+
+		if !p.expectPeek(lexer.ON) {
+			return nil, fmt.Errorf("expected ON after joined table %s, got token type %d", table.Name, p.peekToken.Type)
+		}
+		p.nextToken() // move to the start of the join condition
 
-		// Ensure we're at the start of an expression
-		if !p.currentTokenIs(lexer.IDENT) && !p.currentTokenIs(lexer.NUMBER) &&
-			!p.currentTokenIs(lexer.STRING) && !p.currentTokenIs(lexer.TRUE) &&
-			!p.currentTokenIs(lexer.FALSE) && !p.currentTokenIs(lexer.LPAREN) {
-			p.nextToken() // advance to the next token if we're not at an expression start
+		on, err := p.parseExpression(LOWEST)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing ON clause: %v", err)
 		}
+		join.On = on
+
+		joins = append(joins, join)
+	}
+}
+
+// parseWhereClause parses an optional WHERE clause following the current
+// token. It returns nil if there is no WHERE clause.
+func (p *Parser) parseWhereClause() (ast.Expr, error) {
+	if !p.peekTokenIs(lexer.WHERE) {
+		return nil, nil
+	}
+	p.nextToken() // consume WHERE
+
+	// Ensure we're at the start of an expression
+	if !p.currentTokenIs(lexer.IDENT) && !p.currentTokenIs(lexer.NUMBER) &&
+		!p.currentTokenIs(lexer.STRING) && !p.currentTokenIs(lexer.TRUE) &&
+		!p.currentTokenIs(lexer.FALSE) && !p.currentTokenIs(lexer.LPAREN) {
+		p.nextToken() // advance to the next token if we're not at an expression start
+	}
+
+	expr, err := p.parseExpression(LOWEST)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing WHERE clause: %v", err)
+	}
+	return expr, nil
+}
+
+// parseGroupByClause parses the optional GROUP BY and HAVING clauses
+// following the current token into stmt. HAVING may appear without GROUP BY,
+// but not before it.
+func (p *Parser) parseGroupByClause(stmt *ast.SelectStmt) error {
+	if p.peekTokenIs(lexer.GROUP) {
+		p.nextToken() // consume GROUP
+
+		if !p.expectPeek(lexer.BY) {
+			return fmt.Errorf("expected BY after GROUP, got token type %d", p.peekToken.Type)
+		}
+
+		for {
+			p.nextToken() // move to the start of the grouping expression
+			expr, err := p.parseExpression(LOWEST)
+			if err != nil {
+				return fmt.Errorf("error parsing GROUP BY clause: %v", err)
+			}
+			stmt.GroupBy = append(stmt.GroupBy, expr)
+
+			if !p.peekTokenIs(lexer.COMMA) {
+				break
+			}
+			p.nextToken() // consume comma
+		}
+	}
+
+	if p.peekTokenIs(lexer.HAVING) {
+		p.nextToken() // consume HAVING
+		p.nextToken() // move to the start of the predicate
 
 		expr, err := p.parseExpression(LOWEST)
 		if err != nil {
-			return nil, fmt.Errorf("error parsing WHERE clause: %v", err)
+			return fmt.Errorf("error parsing HAVING clause: %v", err)
+		}
+		stmt.Having = expr
+
+		if p.peekTokenIs(lexer.GROUP) {
+			err := ParseError{Pos: p.peekToken.Pos, Msg: "GROUP BY must come before HAVING"}
+			p.errors = append(p.errors, err)
+			return err
 		}
-		stmt.Where = expr
 	}
 
-	return stmt, nil
+	return nil
+}
+
+// parseOrderByClause parses an optional ORDER BY clause following the
+// current token. Terms without ASC or DESC sort in ascending order.
+func (p *Parser) parseOrderByClause() ([]ast.OrderByClause, error) {
+	if !p.peekTokenIs(lexer.ORDER) {
+		return nil, nil
+	}
+	p.nextToken() // consume ORDER
+
+	if !p.expectPeek(lexer.BY) {
+		return nil, fmt.Errorf("expected BY after ORDER, got token type %d", p.peekToken.Type)
+	}
+
+	var clauses []ast.OrderByClause
+	for {
+		p.nextToken() // move to the start of the sort expression
+		expr, err := p.parseExpression(LOWEST)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing ORDER BY clause: %v", err)
+		}
+
+		clause := ast.OrderByClause{Expr: expr}
+		if p.peekTokenIs(lexer.ASC) {
+			p.nextToken()
+		} else if p.peekTokenIs(lexer.DESC) {
+			p.nextToken()
+			clause.Desc = true
+		}
+		clauses = append(clauses, clause)
+
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken() // consume comma
+	}
+
+	return clauses, nil
+}
+
+// parseRowCount parses the non-negative integer following a LIMIT or
+// OFFSET keyword.
+func (p *Parser) parseRowCount(keyword string) (*int64, error) {
+	if p.peekTokenIs(lexer.MINUS) {
+		return nil, fmt.Errorf("%s must not be negative", keyword)
+	}
+	if !p.expectPeek(lexer.NUMBER) {
+		return nil, fmt.Errorf("expected number after %s, got token type %d", keyword, p.peekToken.Type)
+	}
+
+	n, err := strconv.ParseInt(p.currentToken.Literal, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s value %q as integer: %v", keyword, p.currentToken.Literal, err)
+	}
+	return &n, nil
 }
 
 // parseSelectFields parses the list of fields in a SELECT statement.
@@ -126,25 +518,226 @@ func (p *Parser) parseSelectFields() ([]*ast.Field, error) {
 	}
 
 	// Parse field list
+	for {
+		p.nextToken() // move to the start of the field expression
+
+		// The lexer reads "t." of "t.*" as an identifier
+		if p.currentTokenIs(lexer.IDENT) && strings.HasSuffix(p.currentToken.Literal, ".") && p.peekTokenIs(lexer.ASTERISK) {
+			table := strings.TrimSuffix(p.currentToken.Literal, ".")
+			p.nextToken() // consume *
+			fields = append(fields, &ast.Field{Name: "*", Table: table})
+
+			if !p.peekTokenIs(lexer.COMMA) {
+				break
+			}
+			p.nextToken() // consume comma
+			continue
+		}
+
+		expr, err := p.parseExpression(LOWEST)
+		if err != nil {
+			return nil, err
+		}
+
+		// Column references keep their name for convenience
+		field := &ast.Field{Expr: expr}
+		switch col := expr.(type) {
+		case *ast.ColRef:
+			field.Name = col.Name
+		case *ast.QualifiedRef:
+			field.Name = col.String()
+		}
+
+		// Parse an optional alias, either "AS alias" or a bare "alias"
+		if p.peekTokenIs(lexer.AS) {
+			p.nextToken() // consume AS
+			if !p.expectPeek(lexer.IDENT) {
+				return nil, fmt.Errorf("expected alias after AS, got token type %d", p.peekToken.Type)
+			}
+			field.Alias = p.currentToken.Literal
+		} else if p.peekTokenIs(lexer.IDENT) {
+			p.nextToken()
+			field.Alias = p.currentToken.Literal
+		}
+
+		fields = append(fields, field)
+
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken() // consume comma
+	}
+
+	return fields, nil
+}
+
+// parseInsertStatement parses an INSERT SQL statement.
+func (p *Parser) parseInsertStatement() (*ast.InsertStmt, error) {
+	stmt := &ast.InsertStmt{}
+
+	if !p.expectPeek(lexer.INTO) {
+		return nil, fmt.Errorf("expected INTO, got token type %d", p.peekToken.Type)
+	}
+	if !p.expectPeek(lexer.IDENT) {
+		return nil, fmt.Errorf("expected table name, got token type %d", p.peekToken.Type)
+	}
+	stmt.TableName = p.currentToken.Literal
+
+	// Parse the optional column list
+	if p.peekTokenIs(lexer.LPAREN) {
+		p.nextToken() // consume (
+		for {
+			if !p.expectPeek(lexer.IDENT) {
+				return nil, fmt.Errorf("expected column name, got token type %d", p.peekToken.Type)
+			}
+			stmt.Columns = append(stmt.Columns, p.currentToken.Literal)
+
+			if !p.peekTokenIs(lexer.COMMA) {
+				break
+			}
+			p.nextToken() // consume comma
+		}
+		if !p.expectPeek(lexer.RPAREN) {
+			return nil, fmt.Errorf("expected ), got token type %d", p.peekToken.Type)
+		}
+	}
+
+	if !p.expectPeek(lexer.VALUES) {
+		return nil, fmt.Errorf("expected VALUES, got token type %d", p.peekToken.Type)
+	}
+
+	// Parse one or more value tuples
+	for {
+		row, err := p.parseValueList()
+		if err != nil {
+			return nil, fmt.Errorf("error parsing row %d: %v", len(stmt.Rows)+1, err)
+		}
+
+		// Every row must match the column list, or the first row if none was given
+		expected := len(stmt.Columns)
+		if expected == 0 && len(stmt.Rows) > 0 {
+			expected = len(stmt.Rows[0])
+		}
+		if expected > 0 && len(row) != expected {
+			return nil, fmt.Errorf("row %d has %d values, expected %d", len(stmt.Rows)+1, len(row), expected)
+		}
+		stmt.Rows = append(stmt.Rows, row)
+
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken() // consume comma
+	}
+
+	return stmt, nil
+}
+
+// parseUpdateStatement parses an UPDATE SQL statement.
+func (p *Parser) parseUpdateStatement() (*ast.UpdateStmt, error) {
+	stmt := &ast.UpdateStmt{}
+
+	if !p.expectPeek(lexer.IDENT) {
+		return nil, fmt.Errorf("expected table name, got token type %d", p.peekToken.Type)
+	}
+	stmt.TableName = p.currentToken.Literal
+
+	if !p.expectPeek(lexer.SET) {
+		return nil, fmt.Errorf("expected SET, got token type %d", p.peekToken.Type)
+	}
+
+	// Parse comma-separated column = value assignments
 	for {
 		if !p.expectPeek(lexer.IDENT) {
-			return nil, fmt.Errorf("expected identifier, got token type %d", p.peekToken.Type)
+			return nil, fmt.Errorf("expected column name, got token type %d", p.peekToken.Type)
 		}
+		column := p.currentToken.Literal
 
-		fields = append(fields, &ast.Field{Name: p.currentToken.Literal})
+		if !p.expectPeek(lexer.EQ) {
+			return nil, fmt.Errorf("expected =, got token type %d", p.peekToken.Type)
+		}
+		p.nextToken() // move to the start of the value expression
+
+		value, err := p.parseExpression(LOWEST)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing value for %s: %v", column, err)
+		}
+		stmt.Assignments = append(stmt.Assignments, ast.Assignment{Column: column, Value: value})
 
 		if !p.peekTokenIs(lexer.COMMA) {
 			break
 		}
 		p.nextToken() // consume comma
+	}
+
+	where, err := p.parseWhereClause()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Where = where
+
+	if p.peekTokenIs(lexer.SEMICOLON) {
+		p.nextToken() // consume the optional trailing semicolon
+	}
+
+	return stmt, nil
+}
 
-		// If the next token is not an identifier or asterisk, we have a syntax error
-		if !p.peekTokenIs(lexer.IDENT) && !p.peekTokenIs(lexer.ASTERISK) {
-			return nil, fmt.Errorf("expected identifier or *, got token type %d", p.peekToken.Type)
+// parseDeleteStatement parses a DELETE SQL statement.
+func (p *Parser) parseDeleteStatement() (*ast.DeleteStmt, error) {
+	stmt := &ast.DeleteStmt{}
+
+	if !p.expectPeek(lexer.FROM) {
+		return nil, fmt.Errorf("expected FROM, got token type %d", p.peekToken.Type)
+	}
+
+	if !p.expectPeek(lexer.IDENT) {
+		return nil, fmt.Errorf("expected table name, got token type %d", p.peekToken.Type)
+	}
+	stmt.TableName = p.currentToken.Literal
+
+	where, err := p.parseWhereClause()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Where = where
+
+	if p.peekTokenIs(lexer.SEMICOLON) {
+		p.nextToken() // consume the optional trailing semicolon
+	}
+
+	return stmt, nil
+}
+
+// parseValueList parses a parenthesized, comma-separated list of expressions.
+func (p *Parser) parseValueList() ([]ast.Expr, error) {
+	if !p.expectPeek(lexer.LPAREN) {
+		return nil, fmt.Errorf("expected (, got token type %d", p.peekToken.Type)
+	}
+
+	if p.peekTokenIs(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected at least one value in list")
+	}
+
+	var values []ast.Expr
+	for {
+		p.nextToken() // move to the start of the expression
+		expr, err := p.parseExpression(LOWEST)
+		if err != nil {
+			return nil, err
 		}
+		values = append(values, expr)
+
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken() // consume comma
 	}
 
-	return fields, nil
+	if !p.expectPeek(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ), got token type %d", p.peekToken.Type)
+	}
+
+	return values, nil
 }
 
 // parseExpression parses an expression with the given precedence.
@@ -178,9 +771,10 @@ func (p *Parser) parseExpression(precedence int) (ast.Expr, error) {
 
 // parseInfixExpression parses an infix expression.
 func (p *Parser) parseInfixExpression(left ast.Expr) (ast.Expr, error) {
+	// Keyword operators such as AND or LIKE are normalized to upper case
 	expression := &ast.BinaryExpr{
 		Left:  left,
-		Op:    p.currentToken.Literal,
+		Op:    strings.ToUpper(p.currentToken.Literal),
 		Right: nil,
 	}
 
@@ -200,18 +794,219 @@ func (p *Parser) parseInfixExpression(left ast.Expr) (ast.Expr, error) {
 	return expression, nil
 }
 
-// parseIdentifier parses an identifier expression.
+// parsePrefixExpression parses a prefix operator applied to the expression
+// that follows it.
+func (p *Parser) parsePrefixExpression() (ast.Expr, error) {
+	expression := &ast.UnaryExpr{Op: p.currentToken.Literal}
+
+	p.nextToken() // move to the operand
+
+	operand, err := p.parseExpression(PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	expression.Operand = operand
+
+	return expression, nil
+}
+
+// parseNotExpression parses a NOT that starts an expression, which negates
+// the condition after it (e.g., NOT active). As in standard SQL, NOT binds
+// more loosely than comparisons and more tightly than AND and OR, so
+// NOT a = 1 AND b negates only a = 1.
+func (p *Parser) parseNotExpression() (ast.Expr, error) {
+	expression := &ast.UnaryExpr{Op: "NOT"}
+
+	p.nextToken() // move to the operand
+
+	operand, err := p.parseExpression(NEGATION)
+	if err != nil {
+		return nil, err
+	}
+	expression.Operand = operand
+
+	return expression, nil
+}
+
+// parseGroupedExpression parses a parenthesized expression. The inner
+// expression is parsed at the lowest precedence, so grouping overrides the
+// default binding of the surrounding operators.
+func (p *Parser) parseGroupedExpression() (ast.Expr, error) {
+	p.nextToken() // consume (
+
+	expr, err := p.parseExpression(LOWEST)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.expectPeek(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ), got token type %d", p.peekToken.Type)
+	}
+
+	return expr, nil
+}
+
+// parseInExpression parses an IN predicate whose left-hand side has already
+// been parsed.
+func (p *Parser) parseInExpression(left ast.Expr) (ast.Expr, error) {
+	list, err := p.parseValueList()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing IN list: %v", err)
+	}
+	return &ast.InExpr{Expr: left, List: list}, nil
+}
+
+// parseBetweenExpression parses a BETWEEN predicate whose left-hand side has
+// already been parsed. The bounds are parsed at EQUALS precedence so that
+// the AND separating them is not taken as a logical conjunction, and a
+// comparison after the upper bound applies to the whole predicate.
+func (p *Parser) parseBetweenExpression(left ast.Expr) (ast.Expr, error) {
+	expression := &ast.BetweenExpr{Expr: left}
+
+	p.nextToken() // move to the lower bound
+	low, err := p.parseExpression(EQUALS)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing BETWEEN lower bound: %v", err)
+	}
+	expression.Low = low
+
+	if !p.expectPeek(lexer.AND) {
+		return nil, fmt.Errorf("expected AND in BETWEEN, got token type %d", p.peekToken.Type)
+	}
+
+	p.nextToken() // move to the upper bound
+	high, err := p.parseExpression(EQUALS)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing BETWEEN upper bound: %v", err)
+	}
+	expression.High = high
+
+	return expression, nil
+}
+
+// parseCallExpression parses the argument list of a function call whose
+// name has already been parsed. COUNT(*) style calls set Star instead of
+// taking arguments.
+func (p *Parser) parseCallExpression(function ast.Expr) (ast.Expr, error) {
+	var call *ast.FuncCall
+	switch name := function.(type) {
+	case *ast.ColRef:
+		call = &ast.FuncCall{Name: name.Name}
+	case *ast.QualifiedRef:
+		call = &ast.FuncCall{Name: name.String()}
+	default:
+		return nil, fmt.Errorf("unexpected ( after expression")
+	}
+
+	if p.peekTokenIs(lexer.ASTERISK) {
+		p.nextToken()
+		call.Star = true
+	} else if !p.peekTokenIs(lexer.RPAREN) {
+		for {
+			p.nextToken() // move to the start of the argument
+			arg, err := p.parseExpression(LOWEST)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing arguments to %s: %v", call.Name, err)
+			}
+			call.Args = append(call.Args, arg)
+
+			if !p.peekTokenIs(lexer.COMMA) {
+				break
+			}
+			p.nextToken() // consume comma
+		}
+	}
+
+	if !p.expectPeek(lexer.RPAREN) {
+		return nil, fmt.Errorf("expected ) after arguments to %s, got token type %d", call.Name, p.peekToken.Type)
+	}
+
+	return call, nil
+}
+
+// parseIsExpression parses an IS NULL or IS NOT NULL predicate whose
+// left-hand side has already been parsed.
+func (p *Parser) parseIsExpression(left ast.Expr) (ast.Expr, error) {
+	expression := &ast.IsNullExpr{Expr: left}
+
+	if p.peekTokenIs(lexer.NOT) {
+		p.nextToken()
+		expression.Negated = true
+	}
+
+	if !p.expectPeek(lexer.NULL) {
+		return nil, fmt.Errorf("expected NULL after IS, got token type %d", p.peekToken.Type)
+	}
+
+	return expression, nil
+}
+
+// parseNotInfixExpression parses a NOT that follows an expression, which
+// negates the predicate after it (e.g., status NOT IN ('a', 'b')).
+func (p *Parser) parseNotInfixExpression(left ast.Expr) (ast.Expr, error) {
+	switch p.peekToken.Type {
+	case lexer.IN:
+		p.nextToken()
+		expr, err := p.parseInExpression(left)
+		if err != nil {
+			return nil, err
+		}
+		expr.(*ast.InExpr).Negated = true
+		return expr, nil
+	case lexer.BETWEEN:
+		p.nextToken()
+		expr, err := p.parseBetweenExpression(left)
+		if err != nil {
+			return nil, err
+		}
+		expr.(*ast.BetweenExpr).Negated = true
+		return expr, nil
+	case lexer.LIKE:
+		p.nextToken()
+		expr, err := p.parseInfixExpression(left)
+		if err != nil {
+			return nil, err
+		}
+		expr.(*ast.BinaryExpr).Op = "NOT LIKE"
+		return expr, nil
+	}
+
+	err := p.peekError(lexer.IN, lexer.BETWEEN, lexer.LIKE)
+	p.errors = append(p.errors, ParseError{Pos: p.peekToken.Pos, Msg: err.Error()})
+	return nil, fmt.Errorf("expected IN, BETWEEN or LIKE after NOT, got token type %d", p.peekToken.Type)
+}
+
+// parseIdentifier parses an identifier expression. Dotted identifiers are
+// split at the last dot into a table-qualified column reference.
 func (p *Parser) parseIdentifier() (ast.Expr, error) {
-	return &ast.ColRef{Name: p.currentToken.Literal}, nil
+	lit := p.currentToken.Literal
+	i := strings.LastIndex(lit, ".")
+	if i < 0 {
+		return &ast.ColRef{Name: lit}, nil
+	}
+
+	table, column := lit[:i], lit[i+1:]
+	if column == "" || strings.HasSuffix(table, ".") || strings.Contains(table, "..") {
+		return nil, fmt.Errorf("invalid qualified name %q", lit)
+	}
+	return &ast.QualifiedRef{Table: table, Column: column}, nil
 }
 
-// parseNumberLiteral parses a number literal.
+// parseNumberLiteral parses a number literal. Literals with a decimal point
+// or exponent become a FloatLit, all others a NumberLit.
 func (p *Parser) parseNumberLiteral() (ast.Expr, error) {
-	// Parse the string into an int64
-	var val int64
-	_, err := fmt.Sscanf(p.currentToken.Literal, "%d", &val)
+	lit := p.currentToken.Literal
+	if strings.ContainsAny(lit, ".eE") {
+		val, err := strconv.ParseFloat(lit, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %q as float: %v", lit, err)
+		}
+		return &ast.FloatLit{Value: val}, nil
+	}
+
+	val, err := strconv.ParseInt(lit, 10, 64)
 	if err != nil {
-		return nil, fmt.Errorf("could not parse %q as integer: %v", p.currentToken.Literal, err)
+		return nil, fmt.Errorf("could not parse %q as integer: %v", lit, err)
 	}
 	return &ast.NumberLit{Value: val}, nil
 }
@@ -231,6 +1026,11 @@ func (p *Parser) parseBoolean() (ast.Expr, error) {
 	return &ast.BoolLit{Value: p.currentToken.Type == lexer.TRUE}, nil
 }
 
+// parseNull parses the NULL literal.
+func (p *Parser) parseNull() (ast.Expr, error) {
+	return &ast.NullLit{}, nil
+}
+
 // registerPrefix registers a prefix parser function.
 func (p *Parser) registerPrefix(tokenType lexer.TokenType, fn prefixParseFn) {
 	p.prefixParseFns[tokenType] = fn
@@ -258,10 +1058,10 @@ func (p *Parser) expectPeek(t lexer.TokenType) bool {
 		p.nextToken()
 		return true
 	}
-	// Store the error in the parser's errors slice
+	// Record the error at the position of the unexpected token
 	err := p.peekError(t)
 	if err != nil {
-		p.errors = append(p.errors, err.Error())
+		p.errors = append(p.errors, ParseError{Pos: p.peekToken.Pos, Msg: err.Error()})
 	}
 	return false
 }
@@ -287,12 +1087,14 @@ func (p *Parser) peekError(expected ...lexer.TokenType) error {
 const (
 	_ int = iota
 	LOWEST
-	CONDITION // AND, OR
-	EQUALS    // =, !=, <, >, <=, >=
-	SUM       // +, -
-	PRODUCT   // *, /
-	PREFIX    // -X or !X
-	CALL      // myFunction(X)
+	DISJUNCTION // OR
+	CONJUNCTION // AND
+	NEGATION    // NOT X
+	EQUALS      // =, !=, <, >, <=, >=
+	SUM         // +, -
+	PRODUCT     // *, /
+	PREFIX      // -X or !X
+	CALL        // myFunction(X)
 )
 
 var precedences = map[lexer.TokenType]int{
@@ -302,8 +1104,14 @@ var precedences = map[lexer.TokenType]int{
 	lexer.GT:       EQUALS,
 	lexer.LTE:      EQUALS,
 	lexer.GTE:      EQUALS,
-	lexer.AND:      CONDITION,
-	lexer.OR:       CONDITION,
+	lexer.IN:       EQUALS,
+	lexer.NOT:      EQUALS,
+	lexer.LIKE:     EQUALS,
+	lexer.BETWEEN:  EQUALS,
+	lexer.IS:       EQUALS,
+	lexer.LPAREN:   CALL,
+	lexer.AND:      CONJUNCTION,
+	lexer.OR:       DISJUNCTION,
 	lexer.PLUS:     SUM,
 	lexer.MINUS:    SUM,
 	lexer.SLASH:    PRODUCT,
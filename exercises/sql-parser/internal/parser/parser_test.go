@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/kumarlokesh/sql-parser/internal/ast"
@@ -13,10 +14,40 @@ func debugPrintAST(expr ast.Expr, indent string) string {
 	case *ast.BinaryExpr:
 		return fmt.Sprintf("%sBinaryExpr{\n%s  Op: %q,\n%s  Left: %s,\n%s  Right: %s\n%s}",
 			indent, indent, e.Op, indent, debugPrintAST(e.Left, indent+"  "), indent, debugPrintAST(e.Right, indent+"  "), indent)
+	case *ast.InExpr:
+		list := ""
+		for _, item := range e.List {
+			list += "\n" + debugPrintAST(item, indent+"    ") + ","
+		}
+		return fmt.Sprintf("%sInExpr{\n%s  Negated: %v,\n%s  Expr: %s,\n%s  List: [%s\n%s  ]\n%s}",
+			indent, indent, e.Negated, indent, debugPrintAST(e.Expr, indent+"  "), indent, list, indent, indent)
+	case *ast.BetweenExpr:
+		return fmt.Sprintf("%sBetweenExpr{\n%s  Negated: %v,\n%s  Expr: %s,\n%s  Low: %s,\n%s  High: %s\n%s}",
+			indent, indent, e.Negated, indent, debugPrintAST(e.Expr, indent+"  "),
+			indent, debugPrintAST(e.Low, indent+"  "), indent, debugPrintAST(e.High, indent+"  "), indent)
+	case *ast.IsNullExpr:
+		return fmt.Sprintf("%sIsNullExpr{\n%s  Negated: %v,\n%s  Expr: %s\n%s}",
+			indent, indent, e.Negated, indent, debugPrintAST(e.Expr, indent+"  "), indent)
+	case *ast.NullLit:
+		return fmt.Sprintf("%sNullLit{}", indent)
+	case *ast.FuncCall:
+		args := ""
+		for _, arg := range e.Args {
+			args += "\n" + debugPrintAST(arg, indent+"    ") + ","
+		}
+		return fmt.Sprintf("%sFuncCall{\n%s  Name: %q,\n%s  Star: %v,\n%s  Args: [%s\n%s  ]\n%s}",
+			indent, indent, e.Name, indent, e.Star, indent, args, indent, indent)
 	case *ast.ColRef:
 		return fmt.Sprintf("%sColRef{Name: %q}", indent, e.Name)
+	case *ast.QualifiedRef:
+		return fmt.Sprintf("%sQualifiedRef{Table: %q, Column: %q}", indent, e.Table, e.Column)
+	case *ast.UnaryExpr:
+		return fmt.Sprintf("%sUnaryExpr{\n%s  Op: %q,\n%s  Operand: %s\n%s}",
+			indent, indent, e.Op, indent, debugPrintAST(e.Operand, indent+"  "), indent)
 	case *ast.NumberLit:
 		return fmt.Sprintf("%sNumberLit{Value: %d}", indent, e.Value)
+	case *ast.FloatLit:
+		return fmt.Sprintf("%sFloatLit{Value: %g}", indent, e.Value)
 	case *ast.StringLit:
 		return fmt.Sprintf("%sStringLit{Value: %q}", indent, e.Value)
 	case *ast.BoolLit:
@@ -81,6 +112,46 @@ func TestSelectStatement(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:  "select distinct",
+			input: "SELECT DISTINCT dept FROM employees",
+			want: &ast.SelectStmt{
+				Distinct:  true,
+				Fields:    []*ast.Field{{Name: "dept"}},
+				TableName: "employees",
+			},
+			wantErr: false,
+		},
+		{
+			name:  "select distinct wildcard",
+			input: "select distinct * from employees",
+			want: &ast.SelectStmt{
+				Distinct:  true,
+				Fields:    []*ast.Field{{Name: "*"}},
+				TableName: "employees",
+			},
+			wantErr: false,
+		},
+		{
+			name:  "select distinct multiple fields",
+			input: "SELECT DISTINCT a, b FROM t",
+			want: &ast.SelectStmt{
+				Distinct:  true,
+				Fields:    []*ast.Field{{Name: "a"}, {Name: "b"}},
+				TableName: "t",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "distinct after the first field",
+			input:   "SELECT a, DISTINCT b FROM t",
+			wantErr: true,
+		},
+		{
+			name:    "distinct without fields",
+			input:   "SELECT DISTINCT FROM t",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -101,6 +172,10 @@ func TestSelectStatement(t *testing.T) {
 				t.Fatalf("Parse() = %T, want *ast.SelectStmt", got)
 			}
 
+			if stmt.Distinct != tt.want.Distinct {
+				t.Errorf("distinct = %v, want %v", stmt.Distinct, tt.want.Distinct)
+			}
+
 			if len(stmt.Fields) != len(tt.want.Fields) {
 				t.Fatalf("got %d fields, want %d", len(stmt.Fields), len(tt.want.Fields))
 			}
@@ -130,22 +205,1868 @@ func TestSelectStatement(t *testing.T) {
 	}
 }
 
-func compareExpr(a, b ast.Expr) bool {
-	switch a := a.(type) {
-	case *ast.BinaryExpr:
-		b, ok := b.(*ast.BinaryExpr)
-		if !ok {
-			return false
-		}
-		return compareExpr(a.Left, b.Left) && a.Op == b.Op && compareExpr(a.Right, b.Right)
-	case *ast.ColRef:
-		b, ok := b.(*ast.ColRef)
-		if !ok {
-			return false
-		}
-		return a.Name == b.Name
-	case *ast.NumberLit:
-		b, ok := b.(*ast.NumberLit)
+func TestFieldAliases(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []*ast.Field
+		wantErr bool
+	}{
+		{
+			name:    "explicit alias",
+			input:   "SELECT id AS uid FROM users",
+			want:    []*ast.Field{{Name: "id", Alias: "uid"}},
+			wantErr: false,
+		},
+		{
+			name:    "implicit alias",
+			input:   "SELECT id uid FROM users",
+			want:    []*ast.Field{{Name: "id", Alias: "uid"}},
+			wantErr: false,
+		},
+		{
+			name:  "mixed aliased and unaliased fields",
+			input: "SELECT id AS user_id, name, email contact FROM users",
+			want: []*ast.Field{
+				{Name: "id", Alias: "user_id"},
+				{Name: "name"},
+				{Name: "email", Alias: "contact"},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "missing alias after AS",
+			input:   "SELECT id AS FROM users",
+			wantErr: true,
+		},
+		{
+			name:    "aliased wildcard",
+			input:   "SELECT * AS everything FROM users",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := New(l)
+			got, err := p.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			stmt, ok := got.(*ast.SelectStmt)
+			if !ok {
+				t.Fatalf("Parse() = %T, want *ast.SelectStmt", got)
+			}
+
+			if len(stmt.Fields) != len(tt.want) {
+				t.Fatalf("got %d fields, want %d", len(stmt.Fields), len(tt.want))
+			}
+			for i, f := range stmt.Fields {
+				if f.Name != tt.want[i].Name || f.Alias != tt.want[i].Alias {
+					t.Errorf("field[%d] = %+v, want %+v", i, *f, *tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestQualifiedReferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []*ast.Field
+		wantErr bool
+	}{
+		{
+			name:  "qualified columns",
+			input: "SELECT u.id, u.name FROM users u",
+			want: []*ast.Field{
+				{Name: "u.id", Expr: &ast.QualifiedRef{Table: "u", Column: "id"}},
+				{Name: "u.name", Expr: &ast.QualifiedRef{Table: "u", Column: "name"}},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "table wildcard",
+			input:   "SELECT t.* FROM t",
+			want:    []*ast.Field{{Name: "*", Table: "t"}},
+			wantErr: false,
+		},
+		{
+			name:  "table wildcards mixed with columns",
+			input: "SELECT u.*, o.total AS amount, id FROM users u JOIN orders o ON u.id = o.user_id",
+			want: []*ast.Field{
+				{Name: "*", Table: "u"},
+				{Name: "o.total", Expr: &ast.QualifiedRef{Table: "o", Column: "total"}, Alias: "amount"},
+				{Name: "id", Expr: &ast.ColRef{Name: "id"}},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "schema-qualified table",
+			input: "SELECT public.users.id FROM public.users",
+			want: []*ast.Field{
+				{Name: "public.users.id", Expr: &ast.QualifiedRef{Table: "public.users", Column: "id"}},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "missing column after dot",
+			input:   "SELECT u. FROM users u",
+			wantErr: true,
+		},
+		{
+			name:    "empty table name",
+			input:   "SELECT a..b FROM t",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(lexer.New(tt.input)).Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			stmt, ok := got.(*ast.SelectStmt)
+			if !ok {
+				t.Fatalf("Parse() = %T, want *ast.SelectStmt", got)
+			}
+
+			if !reflect.DeepEqual(stmt.Fields, tt.want) {
+				for i, f := range stmt.Fields {
+					t.Logf("field[%d] = %+v", i, *f)
+				}
+				t.Errorf("fields do not match the expected table/column split")
+			}
+		})
+	}
+}
+
+func TestFunctionCalls(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantField []ast.Expr
+		wantWhere ast.Expr
+		wantErr   bool
+	}{
+		{
+			name:      "count star",
+			input:     "SELECT COUNT(*) FROM users",
+			wantField: []ast.Expr{&ast.FuncCall{Name: "COUNT", Star: true}},
+			wantErr:   false,
+		},
+		{
+			name:  "aggregates with column arguments",
+			input: "SELECT SUM(amount), MAX(age) AS oldest FROM orders",
+			wantField: []ast.Expr{
+				&ast.FuncCall{Name: "SUM", Args: []ast.Expr{&ast.ColRef{Name: "amount"}}},
+				&ast.FuncCall{Name: "MAX", Args: []ast.Expr{&ast.ColRef{Name: "age"}}},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "nested calls with multiple arguments",
+			input: "SELECT ROUND(AVG(x), 2) FROM t",
+			wantField: []ast.Expr{
+				&ast.FuncCall{Name: "ROUND", Args: []ast.Expr{
+					&ast.FuncCall{Name: "AVG", Args: []ast.Expr{&ast.ColRef{Name: "x"}}},
+					&ast.NumberLit{Value: 2},
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "function in field list and where clause",
+			input: "SELECT id, LOWER(name) FROM users WHERE LENGTH(name) > 3",
+			wantField: []ast.Expr{
+				&ast.ColRef{Name: "id"},
+				&ast.FuncCall{Name: "LOWER", Args: []ast.Expr{&ast.ColRef{Name: "name"}}},
+			},
+			wantWhere: &ast.BinaryExpr{
+				Left:  &ast.FuncCall{Name: "LENGTH", Args: []ast.Expr{&ast.ColRef{Name: "name"}}},
+				Op:    ">",
+				Right: &ast.NumberLit{Value: 3},
+			},
+			wantErr: false,
+		},
+		{
+			name:      "no arguments",
+			input:     "SELECT NOW() FROM t",
+			wantField: []ast.Expr{&ast.FuncCall{Name: "NOW"}},
+			wantErr:   false,
+		},
+		{
+			name:    "unclosed argument list",
+			input:   "SELECT COUNT(* FROM users",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := New(l)
+			got, err := p.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			stmt, ok := got.(*ast.SelectStmt)
+			if !ok {
+				t.Fatalf("Parse() = %T, want *ast.SelectStmt", got)
+			}
+
+			if len(stmt.Fields) != len(tt.wantField) {
+				t.Fatalf("got %d fields, want %d", len(stmt.Fields), len(tt.wantField))
+			}
+			for i, f := range stmt.Fields {
+				if !compareExpr(f.Expr, tt.wantField[i]) {
+					t.Errorf("field[%d] mismatch\ngot: %s\nwant: %s", i,
+						debugPrintAST(f.Expr, "  "), debugPrintAST(tt.wantField[i], "  "))
+				}
+			}
+
+			if tt.wantWhere != nil && !compareExpr(stmt.Where, tt.wantWhere) {
+				t.Errorf("where clause mismatch\ngot: %s\nwant: %s",
+					debugPrintAST(stmt.Where, "  "),
+					debugPrintAST(tt.wantWhere, "  "))
+			}
+		})
+	}
+}
+
+func TestGroupedExpressions(t *testing.T) {
+	eq := func(col string, val int64) ast.Expr {
+		return &ast.BinaryExpr{Left: &ast.ColRef{Name: col}, Op: "=", Right: &ast.NumberLit{Value: val}}
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    ast.Expr
+		wantErr bool
+	}{
+		{
+			name:  "grouped OR before AND",
+			input: "SELECT * FROM t WHERE (a = 1 OR b = 2) AND c = 3",
+			want: &ast.BinaryExpr{
+				Left:  &ast.BinaryExpr{Left: eq("a", 1), Op: "OR", Right: eq("b", 2)},
+				Op:    "AND",
+				Right: eq("c", 3),
+			},
+			wantErr: false,
+		},
+		{
+			name:  "grouped AND after OR",
+			input: "SELECT * FROM t WHERE a = 1 OR (b = 2 AND c = 3)",
+			want: &ast.BinaryExpr{
+				Left:  eq("a", 1),
+				Op:    "OR",
+				Right: &ast.BinaryExpr{Left: eq("b", 2), Op: "AND", Right: eq("c", 3)},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "AND binds tighter than OR",
+			input: "SELECT * FROM t WHERE a = 1 OR b = 2 AND c = 3",
+			want: &ast.BinaryExpr{
+				Left:  eq("a", 1),
+				Op:    "OR",
+				Right: &ast.BinaryExpr{Left: eq("b", 2), Op: "AND", Right: eq("c", 3)},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "AND before OR",
+			input: "SELECT * FROM t WHERE a = 1 AND b = 2 OR c = 3 AND d = 4",
+			want: &ast.BinaryExpr{
+				Left:  &ast.BinaryExpr{Left: eq("a", 1), Op: "AND", Right: eq("b", 2)},
+				Op:    "OR",
+				Right: &ast.BinaryExpr{Left: eq("c", 3), Op: "AND", Right: eq("d", 4)},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "grouped arithmetic",
+			input: "SELECT * FROM t WHERE (a + 1) * 2 > 10",
+			want: &ast.BinaryExpr{
+				Left: &ast.BinaryExpr{
+					Left:  &ast.BinaryExpr{Left: &ast.ColRef{Name: "a"}, Op: "+", Right: &ast.NumberLit{Value: 1}},
+					Op:    "*",
+					Right: &ast.NumberLit{Value: 2},
+				},
+				Op:    ">",
+				Right: &ast.NumberLit{Value: 10},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "unclosed paren",
+			input:   "SELECT * FROM t WHERE (a = 1 OR b = 2",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := New(l)
+			got, err := p.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			stmt, ok := got.(*ast.SelectStmt)
+			if !ok {
+				t.Fatalf("Parse() = %T, want *ast.SelectStmt", got)
+			}
+
+			if !compareExpr(stmt.Where, tt.want) {
+				t.Errorf("where clause mismatch\ngot: %s\nwant: %s",
+					debugPrintAST(stmt.Where, "  "),
+					debugPrintAST(tt.want, "  "))
+			}
+		})
+	}
+}
+
+func TestJoins(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantFrom  []ast.TableRef
+		wantJoins []ast.JoinClause
+		wantErr   bool
+	}{
+		{
+			name:     "inner join with aliases",
+			input:    "SELECT u.name, o.total FROM users u JOIN orders o ON u.id = o.user_id",
+			wantFrom: []ast.TableRef{{Name: "users", Alias: "u"}},
+			wantJoins: []ast.JoinClause{
+				{
+					Type:  ast.InnerJoin,
+					Table: ast.TableRef{Name: "orders", Alias: "o"},
+					On: &ast.BinaryExpr{
+						Left:  &ast.QualifiedRef{Table: "u", Column: "id"},
+						Op:    "=",
+						Right: &ast.QualifiedRef{Table: "o", Column: "user_id"},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:     "left join followed by inner join and where",
+			input:    "SELECT * FROM users AS u LEFT OUTER JOIN orders ON u.id = orders.user_id INNER JOIN items i ON i.order_id = orders.id WHERE u.active = true",
+			wantFrom: []ast.TableRef{{Name: "users", Alias: "u"}},
+			wantJoins: []ast.JoinClause{
+				{
+					Type:  ast.LeftJoin,
+					Table: ast.TableRef{Name: "orders"},
+					On: &ast.BinaryExpr{
+						Left:  &ast.QualifiedRef{Table: "u", Column: "id"},
+						Op:    "=",
+						Right: &ast.QualifiedRef{Table: "orders", Column: "user_id"},
+					},
+				},
+				{
+					Type:  ast.InnerJoin,
+					Table: ast.TableRef{Name: "items", Alias: "i"},
+					On: &ast.BinaryExpr{
+						Left:  &ast.QualifiedRef{Table: "i", Column: "order_id"},
+						Op:    "=",
+						Right: &ast.QualifiedRef{Table: "orders", Column: "id"},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:     "comma-separated tables",
+			input:    "SELECT * FROM users, orders o",
+			wantFrom: []ast.TableRef{{Name: "users"}, {Name: "orders", Alias: "o"}},
+			wantErr:  false,
+		},
+		{
+			name:    "missing ON clause",
+			input:   "SELECT * FROM users u JOIN orders o WHERE u.id = 1",
+			wantErr: true,
+		},
+		{
+			name:    "LEFT without JOIN",
+			input:   "SELECT * FROM users LEFT orders ON users.id = orders.user_id",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := New(l)
+			got, err := p.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			stmt, ok := got.(*ast.SelectStmt)
+			if !ok {
+				t.Fatalf("Parse() = %T, want *ast.SelectStmt", got)
+			}
+
+			if len(stmt.From) != len(tt.wantFrom) {
+				t.Fatalf("got %d tables, want %d", len(stmt.From), len(tt.wantFrom))
+			}
+			for i, table := range stmt.From {
+				if table != tt.wantFrom[i] {
+					t.Errorf("from[%d] = %+v, want %+v", i, table, tt.wantFrom[i])
+				}
+			}
+			if stmt.TableName != tt.wantFrom[0].Name {
+				t.Errorf("table name = %q, want %q", stmt.TableName, tt.wantFrom[0].Name)
+			}
+
+			if len(stmt.Joins) != len(tt.wantJoins) {
+				t.Fatalf("got %d joins, want %d", len(stmt.Joins), len(tt.wantJoins))
+			}
+			for i, join := range stmt.Joins {
+				want := tt.wantJoins[i]
+				if join.Type != want.Type || join.Table != want.Table {
+					t.Errorf("join[%d] = %s %+v, want %s %+v", i, join.Type, join.Table, want.Type, want.Table)
+				}
+				if !compareExpr(join.On, want.On) {
+					t.Errorf("join[%d] condition mismatch\ngot: %s\nwant: %s", i,
+						debugPrintAST(join.On, "  "), debugPrintAST(want.On, "  "))
+				}
+			}
+		})
+	}
+}
+
+func TestGroupByHaving(t *testing.T) {
+	countGT5 := &ast.BinaryExpr{
+		Left:  &ast.FuncCall{Name: "COUNT", Star: true},
+		Op:    ">",
+		Right: &ast.NumberLit{Value: 5},
+	}
+
+	tests := []struct {
+		name        string
+		input       string
+		wantGroupBy []ast.Expr
+		wantHaving  ast.Expr
+		wantErr     bool
+	}{
+		{
+			name:        "group by",
+			input:       "SELECT dept, COUNT(*) FROM employees GROUP BY dept",
+			wantGroupBy: []ast.Expr{&ast.ColRef{Name: "dept"}},
+			wantErr:     false,
+		},
+		{
+			name:        "group by with having",
+			input:       "SELECT dept FROM employees GROUP BY dept HAVING COUNT(*) > 5",
+			wantGroupBy: []ast.Expr{&ast.ColRef{Name: "dept"}},
+			wantHaving:  countGT5,
+			wantErr:     false,
+		},
+		{
+			name:  "multiple columns between where and order by",
+			input: "SELECT dept, title FROM employees WHERE active = true GROUP BY dept, title ORDER BY dept",
+			wantGroupBy: []ast.Expr{
+				&ast.ColRef{Name: "dept"},
+				&ast.ColRef{Name: "title"},
+			},
+			wantErr: false,
+		},
+		{
+			name:       "having without group by",
+			input:      "SELECT COUNT(*) FROM employees HAVING COUNT(*) > 5",
+			wantHaving: countGT5,
+			wantErr:    false,
+		},
+		{
+			name:    "having before group by",
+			input:   "SELECT dept FROM employees HAVING COUNT(*) > 5 GROUP BY dept",
+			wantErr: true,
+		},
+		{
+			name:    "group by after order by",
+			input:   "SELECT dept FROM employees ORDER BY dept GROUP BY dept",
+			wantErr: true,
+		},
+		{
+			name:    "missing BY",
+			input:   "SELECT dept FROM employees GROUP dept",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := New(l)
+			got, err := p.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			stmt, ok := got.(*ast.SelectStmt)
+			if !ok {
+				t.Fatalf("Parse() = %T, want *ast.SelectStmt", got)
+			}
+
+			if len(stmt.GroupBy) != len(tt.wantGroupBy) {
+				t.Fatalf("got %d GROUP BY expressions, want %d", len(stmt.GroupBy), len(tt.wantGroupBy))
+			}
+			for i, g := range stmt.GroupBy {
+				if !compareExpr(g, tt.wantGroupBy[i]) {
+					t.Errorf("group by[%d] mismatch\ngot: %s\nwant: %s", i,
+						debugPrintAST(g, "  "), debugPrintAST(tt.wantGroupBy[i], "  "))
+				}
+			}
+
+			if tt.wantHaving != nil {
+				if stmt.Having == nil {
+					t.Error("expected having clause, got none")
+				} else if !compareExpr(stmt.Having, tt.wantHaving) {
+					t.Errorf("having clause mismatch\ngot: %s\nwant: %s",
+						debugPrintAST(stmt.Having, "  "),
+						debugPrintAST(tt.wantHaving, "  "))
+				}
+			} else if stmt.Having != nil {
+				t.Errorf("unexpected having clause: %s", debugPrintAST(stmt.Having, "  "))
+			}
+		})
+	}
+}
+
+func TestOrderByClause(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []ast.OrderByClause
+		wantErr bool
+	}{
+		{
+			name:  "single column defaults to ascending",
+			input: "SELECT * FROM users ORDER BY age",
+			want: []ast.OrderByClause{
+				{Expr: &ast.ColRef{Name: "age"}, Desc: false},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "multiple columns with directions",
+			input: "SELECT * FROM users ORDER BY age DESC, name ASC",
+			want: []ast.OrderByClause{
+				{Expr: &ast.ColRef{Name: "age"}, Desc: true},
+				{Expr: &ast.ColRef{Name: "name"}, Desc: false},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "after where clause",
+			input: "SELECT id FROM users WHERE active = true ORDER BY id DESC",
+			want: []ast.OrderByClause{
+				{Expr: &ast.ColRef{Name: "id"}, Desc: true},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "missing BY",
+			input:   "SELECT * FROM users ORDER name",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := New(l)
+			got, err := p.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			stmt, ok := got.(*ast.SelectStmt)
+			if !ok {
+				t.Fatalf("Parse() = %T, want *ast.SelectStmt", got)
+			}
+
+			if len(stmt.OrderBy) != len(tt.want) {
+				t.Fatalf("got %d ORDER BY terms, want %d", len(stmt.OrderBy), len(tt.want))
+			}
+			for i, o := range stmt.OrderBy {
+				if !compareExpr(o.Expr, tt.want[i].Expr) {
+					t.Errorf("order by[%d] expr mismatch\ngot: %s\nwant: %s", i,
+						debugPrintAST(o.Expr, "  "), debugPrintAST(tt.want[i].Expr, "  "))
+				}
+				if o.Desc != tt.want[i].Desc {
+					t.Errorf("order by[%d] desc = %v, want %v", i, o.Desc, tt.want[i].Desc)
+				}
+			}
+		})
+	}
+}
+
+func TestLimitOffset(t *testing.T) {
+	int64Ptr := func(n int64) *int64 { return &n }
+
+	tests := []struct {
+		name       string
+		input      string
+		wantLimit  *int64
+		wantOffset *int64
+		wantErr    bool
+	}{
+		{
+			name:      "limit only",
+			input:     "SELECT * FROM users LIMIT 5",
+			wantLimit: int64Ptr(5),
+			wantErr:   false,
+		},
+		{
+			name:       "limit and offset",
+			input:      "SELECT * FROM users LIMIT 5 OFFSET 10",
+			wantLimit:  int64Ptr(5),
+			wantOffset: int64Ptr(10),
+			wantErr:    false,
+		},
+		{
+			name:       "offset without limit",
+			input:      "SELECT * FROM users OFFSET 10",
+			wantOffset: int64Ptr(10),
+			wantErr:    false,
+		},
+		{
+			name:       "after order by",
+			input:      "SELECT * FROM users ORDER BY id DESC LIMIT 1 OFFSET 0",
+			wantLimit:  int64Ptr(1),
+			wantOffset: int64Ptr(0),
+			wantErr:    false,
+		},
+		{
+			name:    "negative limit",
+			input:   "SELECT * FROM users LIMIT -1",
+			wantErr: true,
+		},
+		{
+			name:    "non-numeric limit",
+			input:   "SELECT * FROM users LIMIT ten",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := New(l)
+			got, err := p.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			stmt, ok := got.(*ast.SelectStmt)
+			if !ok {
+				t.Fatalf("Parse() = %T, want *ast.SelectStmt", got)
+			}
+
+			checkRowCount(t, "limit", stmt.Limit, tt.wantLimit)
+			checkRowCount(t, "offset", stmt.Offset, tt.wantOffset)
+		})
+	}
+}
+
+func checkRowCount(t *testing.T, name string, got, want *int64) {
+	t.Helper()
+	switch {
+	case want == nil && got != nil:
+		t.Errorf("%s = %d, want none", name, *got)
+	case want != nil && got == nil:
+		t.Errorf("%s = none, want %d", name, *want)
+	case want != nil && *got != *want:
+		t.Errorf("%s = %d, want %d", name, *got, *want)
+	}
+}
+
+func TestNumericLiterals(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    ast.Expr
+		wantErr bool
+	}{
+		{
+			name:    "integer",
+			input:   "SELECT * FROM t WHERE x = 42",
+			want:    &ast.NumberLit{Value: 42},
+			wantErr: false,
+		},
+		{
+			name:    "decimal",
+			input:   "SELECT * FROM t WHERE x = 3.14",
+			want:    &ast.FloatLit{Value: 3.14},
+			wantErr: false,
+		},
+		{
+			name:    "negative integer",
+			input:   "SELECT * FROM t WHERE x = -1",
+			want:    &ast.UnaryExpr{Op: "-", Operand: &ast.NumberLit{Value: 1}},
+			wantErr: false,
+		},
+		{
+			name:    "exponent",
+			input:   "SELECT * FROM t WHERE x = 1e10",
+			want:    &ast.FloatLit{Value: 1e10},
+			wantErr: false,
+		},
+		{
+			name:    "decimal with negative exponent",
+			input:   "SELECT * FROM t WHERE x = 2.5e-3",
+			want:    &ast.FloatLit{Value: 2.5e-3},
+			wantErr: false,
+		},
+		{
+			name:    "negative decimal",
+			input:   "SELECT * FROM t WHERE x = -9.99",
+			want:    &ast.UnaryExpr{Op: "-", Operand: &ast.FloatLit{Value: 9.99}},
+			wantErr: false,
+		},
+		{
+			name:    "integer out of range",
+			input:   "SELECT * FROM t WHERE x = 99999999999999999999",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := New(l)
+			got, err := p.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			stmt, ok := got.(*ast.SelectStmt)
+			if !ok {
+				t.Fatalf("Parse() = %T, want *ast.SelectStmt", got)
+			}
+
+			want := &ast.BinaryExpr{Left: &ast.ColRef{Name: "x"}, Op: "=", Right: tt.want}
+			if !compareExpr(stmt.Where, want) {
+				t.Errorf("where clause mismatch\ngot: %s\nwant: %s",
+					debugPrintAST(stmt.Where, "  "),
+					debugPrintAST(want, "  "))
+			}
+		})
+	}
+}
+
+func TestUnaryMinusPrecedence(t *testing.T) {
+	l := lexer.New("SELECT * FROM products WHERE price - -1 > 9.99")
+	p := New(l)
+	got, err := p.Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := &ast.BinaryExpr{
+		Left: &ast.BinaryExpr{
+			Left:  &ast.ColRef{Name: "price"},
+			Op:    "-",
+			Right: &ast.UnaryExpr{Op: "-", Operand: &ast.NumberLit{Value: 1}},
+		},
+		Op:    ">",
+		Right: &ast.FloatLit{Value: 9.99},
+	}
+	stmt := got.(*ast.SelectStmt)
+	if !compareExpr(stmt.Where, want) {
+		t.Errorf("where clause mismatch\ngot: %s\nwant: %s",
+			debugPrintAST(stmt.Where, "  "),
+			debugPrintAST(want, "  "))
+	}
+}
+
+func TestInExpressions(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    ast.Expr
+		wantErr bool
+	}{
+		{
+			name:  "in list",
+			input: "SELECT * FROM t WHERE id IN (1, 2, 3)",
+			want: &ast.InExpr{
+				Expr: &ast.ColRef{Name: "id"},
+				List: []ast.Expr{&ast.NumberLit{Value: 1}, &ast.NumberLit{Value: 2}, &ast.NumberLit{Value: 3}},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "not in list",
+			input: "SELECT * FROM t WHERE status NOT IN ('a', 'b')",
+			want: &ast.InExpr{
+				Expr:    &ast.ColRef{Name: "status"},
+				List:    []ast.Expr{&ast.StringLit{Value: "a"}, &ast.StringLit{Value: "b"}},
+				Negated: true,
+			},
+			wantErr: false,
+		},
+		{
+			name:  "nested in AND and OR",
+			input: "SELECT * FROM t WHERE id IN (1) AND status NOT IN ('x') OR admin = true",
+			want: &ast.BinaryExpr{
+				Left: &ast.BinaryExpr{
+					Left: &ast.InExpr{
+						Expr: &ast.ColRef{Name: "id"},
+						List: []ast.Expr{&ast.NumberLit{Value: 1}},
+					},
+					Op: "AND",
+					Right: &ast.InExpr{
+						Expr:    &ast.ColRef{Name: "status"},
+						List:    []ast.Expr{&ast.StringLit{Value: "x"}},
+						Negated: true,
+					},
+				},
+				Op: "OR",
+				Right: &ast.BinaryExpr{
+					Left:  &ast.ColRef{Name: "admin"},
+					Op:    "=",
+					Right: &ast.BoolLit{Value: true},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "empty list",
+			input:   "SELECT * FROM t WHERE id IN ()",
+			wantErr: true,
+		},
+		{
+			name:    "missing list",
+			input:   "SELECT * FROM t WHERE id IN 1",
+			wantErr: true,
+		},
+		{
+			name:    "NOT without IN",
+			input:   "SELECT * FROM t WHERE id NOT 1",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := New(l)
+			got, err := p.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			stmt, ok := got.(*ast.SelectStmt)
+			if !ok {
+				t.Fatalf("Parse() = %T, want *ast.SelectStmt", got)
+			}
+
+			if !compareExpr(stmt.Where, tt.want) {
+				t.Errorf("where clause mismatch\ngot: %s\nwant: %s",
+					debugPrintAST(stmt.Where, "  "),
+					debugPrintAST(tt.want, "  "))
+			}
+		})
+	}
+}
+
+func TestLikeAndBetween(t *testing.T) {
+	age := &ast.ColRef{Name: "age"}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    ast.Expr
+		wantErr bool
+	}{
+		{
+			name:    "like",
+			input:   "SELECT * FROM t WHERE name LIKE 'A%'",
+			want:    &ast.BinaryExpr{Left: &ast.ColRef{Name: "name"}, Op: "LIKE", Right: &ast.StringLit{Value: "A%"}},
+			wantErr: false,
+		},
+		{
+			name:    "not like",
+			input:   "SELECT * FROM t WHERE name not like '%x'",
+			want:    &ast.BinaryExpr{Left: &ast.ColRef{Name: "name"}, Op: "NOT LIKE", Right: &ast.StringLit{Value: "%x"}},
+			wantErr: false,
+		},
+		{
+			name:  "between",
+			input: "SELECT * FROM t WHERE age BETWEEN 18 AND 65",
+			want: &ast.BetweenExpr{
+				Expr: age,
+				Low:  &ast.NumberLit{Value: 18},
+				High: &ast.NumberLit{Value: 65},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "between followed by AND",
+			input: "SELECT * FROM t WHERE age BETWEEN 18 AND 65 AND active = true",
+			want: &ast.BinaryExpr{
+				Left: &ast.BetweenExpr{
+					Expr: age,
+					Low:  &ast.NumberLit{Value: 18},
+					High: &ast.NumberLit{Value: 65},
+				},
+				Op:    "AND",
+				Right: &ast.BinaryExpr{Left: &ast.ColRef{Name: "active"}, Op: "=", Right: &ast.BoolLit{Value: true}},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "between after OR with arithmetic bounds",
+			input: "SELECT * FROM t WHERE admin = true OR age NOT BETWEEN 10 + 8 AND 65",
+			want: &ast.BinaryExpr{
+				Left: &ast.BinaryExpr{Left: &ast.ColRef{Name: "admin"}, Op: "=", Right: &ast.BoolLit{Value: true}},
+				Op:   "OR",
+				Right: &ast.BetweenExpr{
+					Expr:    age,
+					Low:     &ast.BinaryExpr{Left: &ast.NumberLit{Value: 10}, Op: "+", Right: &ast.NumberLit{Value: 8}},
+					High:    &ast.NumberLit{Value: 65},
+					Negated: true,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "comparison after between",
+			input: "SELECT * FROM t WHERE age BETWEEN 18 AND 65 = TRUE",
+			want: &ast.BinaryExpr{
+				Left: &ast.BetweenExpr{
+					Expr: age,
+					Low:  &ast.NumberLit{Value: 18},
+					High: &ast.NumberLit{Value: 65},
+				},
+				Op:    "=",
+				Right: &ast.BoolLit{Value: true},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "between without AND",
+			input:   "SELECT * FROM t WHERE age BETWEEN 18 OR 65",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := New(l)
+			got, err := p.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			stmt, ok := got.(*ast.SelectStmt)
+			if !ok {
+				t.Fatalf("Parse() = %T, want *ast.SelectStmt", got)
+			}
+
+			if !compareExpr(stmt.Where, tt.want) {
+				t.Errorf("where clause mismatch\ngot: %s\nwant: %s",
+					debugPrintAST(stmt.Where, "  "),
+					debugPrintAST(tt.want, "  "))
+			}
+		})
+	}
+}
+
+func TestIsNullExpressions(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    ast.Expr
+		wantErr bool
+	}{
+		{
+			name:    "is null",
+			input:   "SELECT * FROM t WHERE deleted_at IS NULL",
+			want:    &ast.IsNullExpr{Expr: &ast.ColRef{Name: "deleted_at"}},
+			wantErr: false,
+		},
+		{
+			name:    "is not null",
+			input:   "SELECT * FROM t WHERE name IS NOT NULL",
+			want:    &ast.IsNullExpr{Expr: &ast.ColRef{Name: "name"}, Negated: true},
+			wantErr: false,
+		},
+		{
+			name:  "composes with AND",
+			input: "SELECT * FROM t WHERE x IS NULL AND y = 1",
+			want: &ast.BinaryExpr{
+				Left:  &ast.IsNullExpr{Expr: &ast.ColRef{Name: "x"}},
+				Op:    "AND",
+				Right: &ast.BinaryExpr{Left: &ast.ColRef{Name: "y"}, Op: "=", Right: &ast.NumberLit{Value: 1}},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "null literal",
+			input:   "SELECT * FROM t WHERE x = NULL",
+			want:    &ast.BinaryExpr{Left: &ast.ColRef{Name: "x"}, Op: "=", Right: &ast.NullLit{}},
+			wantErr: false,
+		},
+		{
+			name:    "is followed by a number",
+			input:   "SELECT * FROM t WHERE x IS 5",
+			wantErr: true,
+		},
+		{
+			name:    "is not followed by a number",
+			input:   "SELECT * FROM t WHERE x IS NOT 5",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := New(l)
+			got, err := p.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			stmt, ok := got.(*ast.SelectStmt)
+			if !ok {
+				t.Fatalf("Parse() = %T, want *ast.SelectStmt", got)
+			}
+
+			if !compareExpr(stmt.Where, tt.want) {
+				t.Errorf("where clause mismatch\ngot: %s\nwant: %s",
+					debugPrintAST(stmt.Where, "  "),
+					debugPrintAST(tt.want, "  "))
+			}
+		})
+	}
+}
+
+func TestNotExpressions(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    ast.Expr
+		wantErr bool
+	}{
+		{
+			name:    "not column",
+			input:   "SELECT * FROM users WHERE NOT active",
+			want:    &ast.UnaryExpr{Op: "NOT", Operand: &ast.ColRef{Name: "active"}},
+			wantErr: false,
+		},
+		{
+			name:  "not grouped condition",
+			input: "SELECT * FROM t WHERE NOT (x = 1 OR y = 2)",
+			want: &ast.UnaryExpr{Op: "NOT", Operand: &ast.BinaryExpr{
+				Left:  &ast.BinaryExpr{Left: &ast.ColRef{Name: "x"}, Op: "=", Right: &ast.NumberLit{Value: 1}},
+				Op:    "OR",
+				Right: &ast.BinaryExpr{Left: &ast.ColRef{Name: "y"}, Op: "=", Right: &ast.NumberLit{Value: 2}},
+			}},
+			wantErr: false,
+		},
+		{
+			name:  "binds tighter than AND",
+			input: "SELECT * FROM t WHERE NOT a AND b",
+			want: &ast.BinaryExpr{
+				Left:  &ast.UnaryExpr{Op: "NOT", Operand: &ast.ColRef{Name: "a"}},
+				Op:    "AND",
+				Right: &ast.ColRef{Name: "b"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "right operand of OR",
+			input: "SELECT * FROM t WHERE a OR NOT b",
+			want: &ast.BinaryExpr{
+				Left:  &ast.ColRef{Name: "a"},
+				Op:    "OR",
+				Right: &ast.UnaryExpr{Op: "NOT", Operand: &ast.ColRef{Name: "b"}},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "mixed with AND and OR",
+			input: "SELECT * FROM t WHERE NOT a OR b AND NOT c",
+			want: &ast.BinaryExpr{
+				Left: &ast.UnaryExpr{Op: "NOT", Operand: &ast.ColRef{Name: "a"}},
+				Op:   "OR",
+				Right: &ast.BinaryExpr{
+					Left:  &ast.ColRef{Name: "b"},
+					Op:    "AND",
+					Right: &ast.UnaryExpr{Op: "NOT", Operand: &ast.ColRef{Name: "c"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "AND of negations before OR",
+			input: "SELECT * FROM t WHERE NOT a AND NOT b OR c",
+			want: &ast.BinaryExpr{
+				Left: &ast.BinaryExpr{
+					Left:  &ast.UnaryExpr{Op: "NOT", Operand: &ast.ColRef{Name: "a"}},
+					Op:    "AND",
+					Right: &ast.UnaryExpr{Op: "NOT", Operand: &ast.ColRef{Name: "b"}},
+				},
+				Op:    "OR",
+				Right: &ast.ColRef{Name: "c"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "binds looser than comparisons",
+			input: "SELECT * FROM t WHERE NOT x = 1",
+			want: &ast.UnaryExpr{Op: "NOT", Operand: &ast.BinaryExpr{
+				Left: &ast.ColRef{Name: "x"}, Op: "=", Right: &ast.NumberLit{Value: 1},
+			}},
+			wantErr: false,
+		},
+		{
+			name:  "composes with NOT IN",
+			input: "SELECT * FROM t WHERE NOT x NOT IN (1)",
+			want: &ast.UnaryExpr{Op: "NOT", Operand: &ast.InExpr{
+				Expr: &ast.ColRef{Name: "x"}, List: []ast.Expr{&ast.NumberLit{Value: 1}}, Negated: true,
+			}},
+			wantErr: false,
+		},
+		{
+			name:    "double negation",
+			input:   "SELECT * FROM t WHERE NOT NOT a",
+			want:    &ast.UnaryExpr{Op: "NOT", Operand: &ast.UnaryExpr{Op: "NOT", Operand: &ast.ColRef{Name: "a"}}},
+			wantErr: false,
+		},
+		{
+			name:    "missing operand",
+			input:   "SELECT * FROM t WHERE NOT",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := New(l)
+			got, err := p.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			stmt, ok := got.(*ast.SelectStmt)
+			if !ok {
+				t.Fatalf("Parse() = %T, want *ast.SelectStmt", got)
+			}
+
+			if !compareExpr(stmt.Where, tt.want) {
+				t.Errorf("where clause mismatch\ngot: %s\nwant: %s",
+					debugPrintAST(stmt.Where, "  "),
+					debugPrintAST(tt.want, "  "))
+			}
+		})
+	}
+}
+
+func TestInsertStatement(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    *ast.InsertStmt
+		wantErr bool
+	}{
+		{
+			name:  "single row",
+			input: "INSERT INTO users (id, name) VALUES (1, 'alice')",
+			want: &ast.InsertStmt{
+				TableName: "users",
+				Columns:   []string{"id", "name"},
+				Rows: [][]ast.Expr{
+					{&ast.NumberLit{Value: 1}, &ast.StringLit{Value: "alice"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "multiple rows",
+			input: "INSERT INTO users (id, name) VALUES (1, 'alice'), (2, 'bob')",
+			want: &ast.InsertStmt{
+				TableName: "users",
+				Columns:   []string{"id", "name"},
+				Rows: [][]ast.Expr{
+					{&ast.NumberLit{Value: 1}, &ast.StringLit{Value: "alice"}},
+					{&ast.NumberLit{Value: 2}, &ast.StringLit{Value: "bob"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "without column list",
+			input: "INSERT INTO flags VALUES (true)",
+			want: &ast.InsertStmt{
+				TableName: "flags",
+				Rows: [][]ast.Expr{
+					{&ast.BoolLit{Value: true}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "column count mismatch",
+			input:   "INSERT INTO users (id, name) VALUES (1)",
+			wantErr: true,
+		},
+		{
+			name:    "row length mismatch",
+			input:   "INSERT INTO users VALUES (1, 'alice'), (2)",
+			wantErr: true,
+		},
+		{
+			name:    "missing VALUES",
+			input:   "INSERT INTO users (id) (1)",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := New(l)
+			got, err := p.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			stmt, ok := got.(*ast.InsertStmt)
+			if !ok {
+				t.Fatalf("Parse() = %T, want *ast.InsertStmt", got)
+			}
+
+			if stmt.TableName != tt.want.TableName {
+				t.Errorf("table name = %q, want %q", stmt.TableName, tt.want.TableName)
+			}
+
+			if len(stmt.Columns) != len(tt.want.Columns) {
+				t.Fatalf("got %d columns, want %d", len(stmt.Columns), len(tt.want.Columns))
+			}
+			for i, c := range stmt.Columns {
+				if c != tt.want.Columns[i] {
+					t.Errorf("column[%d] = %q, want %q", i, c, tt.want.Columns[i])
+				}
+			}
+
+			if len(stmt.Rows) != len(tt.want.Rows) {
+				t.Fatalf("got %d rows, want %d", len(stmt.Rows), len(tt.want.Rows))
+			}
+			for i, row := range stmt.Rows {
+				if len(row) != len(tt.want.Rows[i]) {
+					t.Fatalf("row[%d] has %d values, want %d", i, len(row), len(tt.want.Rows[i]))
+				}
+				for j, v := range row {
+					if !compareExpr(v, tt.want.Rows[i][j]) {
+						t.Errorf("row[%d][%d] mismatch\ngot: %s\nwant: %s", i, j,
+							debugPrintAST(v, "  "), debugPrintAST(tt.want.Rows[i][j], "  "))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateStatement(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    *ast.UpdateStmt
+		wantErr bool
+	}{
+		{
+			name:  "update with where clause",
+			input: "UPDATE users SET name = 'alice' WHERE id = 1",
+			want: &ast.UpdateStmt{
+				TableName: "users",
+				Assignments: []ast.Assignment{
+					{Column: "name", Value: &ast.StringLit{Value: "alice"}},
+				},
+				Where: &ast.BinaryExpr{
+					Left:  &ast.ColRef{Name: "id"},
+					Op:    "=",
+					Right: &ast.NumberLit{Value: 1},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "multiple assignments with expressions",
+			input: "UPDATE accounts SET balance = balance + 10, active = true WHERE id = 7",
+			want: &ast.UpdateStmt{
+				TableName: "accounts",
+				Assignments: []ast.Assignment{
+					{
+						Column: "balance",
+						Value: &ast.BinaryExpr{
+							Left:  &ast.ColRef{Name: "balance"},
+							Op:    "+",
+							Right: &ast.NumberLit{Value: 10},
+						},
+					},
+					{Column: "active", Value: &ast.BoolLit{Value: true}},
+				},
+				Where: &ast.BinaryExpr{
+					Left:  &ast.ColRef{Name: "id"},
+					Op:    "=",
+					Right: &ast.NumberLit{Value: 7},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "full-table update with trailing semicolon",
+			input: "UPDATE users SET active = false;",
+			want: &ast.UpdateStmt{
+				TableName: "users",
+				Assignments: []ast.Assignment{
+					{Column: "active", Value: &ast.BoolLit{Value: false}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "missing SET",
+			input:   "UPDATE users name = 'alice'",
+			wantErr: true,
+		},
+		{
+			name:    "missing assignment value",
+			input:   "UPDATE users SET name = WHERE id = 1",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := New(l)
+			got, err := p.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			stmt, ok := got.(*ast.UpdateStmt)
+			if !ok {
+				t.Fatalf("Parse() = %T, want *ast.UpdateStmt", got)
+			}
+
+			if stmt.TableName != tt.want.TableName {
+				t.Errorf("table name = %q, want %q", stmt.TableName, tt.want.TableName)
+			}
+
+			if len(stmt.Assignments) != len(tt.want.Assignments) {
+				t.Fatalf("got %d assignments, want %d", len(stmt.Assignments), len(tt.want.Assignments))
+			}
+			for i, a := range stmt.Assignments {
+				want := tt.want.Assignments[i]
+				if a.Column != want.Column {
+					t.Errorf("assignment[%d] column = %q, want %q", i, a.Column, want.Column)
+				}
+				if !compareExpr(a.Value, want.Value) {
+					t.Errorf("assignment[%d] value mismatch\ngot: %s\nwant: %s", i,
+						debugPrintAST(a.Value, "  "), debugPrintAST(want.Value, "  "))
+				}
+			}
+
+			if tt.want.Where != nil {
+				if stmt.Where == nil {
+					t.Error("expected where clause, got none")
+				} else if !compareExpr(stmt.Where, tt.want.Where) {
+					t.Errorf("where clause mismatch\ngot: %s\nwant: %s",
+						debugPrintAST(stmt.Where, "  "),
+						debugPrintAST(tt.want.Where, "  "))
+				}
+			} else if stmt.Where != nil {
+				t.Errorf("unexpected where clause: %s", debugPrintAST(stmt.Where, "  "))
+			}
+		})
+	}
+}
+
+func TestDeleteStatement(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    *ast.DeleteStmt
+		wantErr bool
+	}{
+		{
+			name:  "delete all rows",
+			input: "DELETE FROM users",
+			want: &ast.DeleteStmt{
+				TableName: "users",
+			},
+			wantErr: false,
+		},
+		{
+			name:  "delete with where clause",
+			input: "DELETE FROM users WHERE id = 5",
+			want: &ast.DeleteStmt{
+				TableName: "users",
+				Where: &ast.BinaryExpr{
+					Left:  &ast.ColRef{Name: "id"},
+					Op:    "=",
+					Right: &ast.NumberLit{Value: 5},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "missing table name",
+			input:   "DELETE FROM WHERE id = 5",
+			wantErr: true,
+		},
+		{
+			name:    "missing FROM",
+			input:   "DELETE users",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := New(l)
+			got, err := p.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			stmt, ok := got.(*ast.DeleteStmt)
+			if !ok {
+				t.Fatalf("Parse() = %T, want *ast.DeleteStmt", got)
+			}
+
+			if stmt.TableName != tt.want.TableName {
+				t.Errorf("table name = %q, want %q", stmt.TableName, tt.want.TableName)
+			}
+
+			if tt.want.Where != nil {
+				if stmt.Where == nil {
+					t.Error("expected where clause, got none")
+				} else if !compareExpr(stmt.Where, tt.want.Where) {
+					t.Errorf("where clause mismatch\ngot: %s\nwant: %s",
+						debugPrintAST(stmt.Where, "  "),
+						debugPrintAST(tt.want.Where, "  "))
+				}
+			} else if stmt.Where != nil {
+				t.Errorf("unexpected where clause: %s", debugPrintAST(stmt.Where, "  "))
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		recovery bool
+		want     []lexer.Position
+		wantStmt bool
+	}{
+		{
+			name:     "stops at first error without recovery",
+			input:    "SELECT FROM users; DELETE users",
+			recovery: false,
+			want:     []lexer.Position{{Line: 1, Column: 8}},
+		},
+		{
+			name:     "reports every statement's error with recovery",
+			input:    "SELECT FROM users; DELETE users",
+			recovery: true,
+			want:     []lexer.Position{{Line: 1, Column: 8}, {Line: 1, Column: 27}},
+		},
+		{
+			name:     "returns first valid statement with recovery",
+			input:    "UPDATE users name = 1;\nSELECT * FROM users;\nINSERT INTO users VALUES (1",
+			recovery: true,
+			want:     []lexer.Position{{Line: 1, Column: 14}, {Line: 3, Column: 28}},
+			wantStmt: true,
+		},
+		{
+			name:     "trailing tokens after statement",
+			input:    "SELECT * FROM users u v; SELECT * FROM",
+			recovery: true,
+			want:     []lexer.Position{{Line: 1, Column: 23}, {Line: 1, Column: 39}},
+			wantStmt: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := New(l)
+			p.SetRecovery(tt.recovery)
+
+			stmt, err := p.Parse()
+			if err == nil {
+				t.Fatal("Parse() error = nil, want error")
+			}
+			if (stmt != nil) != tt.wantStmt {
+				t.Errorf("Parse() statement = %v, want statement %v", stmt, tt.wantStmt)
+			}
+
+			errs := p.Errors()
+			if len(errs) != len(tt.want) {
+				t.Fatalf("got %d errors, want %d: %v", len(errs), len(tt.want), errs)
+			}
+			for i, e := range errs {
+				if e.Pos != tt.want[i] {
+					t.Errorf("error[%d] position = %+v, want %+v (%s)", i, e.Pos, tt.want[i], e.Msg)
+				}
+				if e.Msg == "" {
+					t.Errorf("error[%d] has empty message", i)
+				}
+			}
+		})
+	}
+}
+
+func TestParseProgram(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		recovery  bool
+		want      []string
+		wantErrAt []lexer.Position
+	}{
+		{
+			name:  "two selects",
+			input: "SELECT id FROM users; SELECT name FROM orders",
+			want:  []string{"SELECT id FROM users", "SELECT name FROM orders"},
+		},
+		{
+			name:  "trailing semicolon",
+			input: "SELECT id FROM users;\nDELETE FROM users WHERE id = 1;",
+			want:  []string{"SELECT id FROM users", "DELETE FROM users WHERE id = 1"},
+		},
+		{
+			name:  "empty statements",
+			input: ";; UPDATE users SET a = 1;; ; INSERT INTO t VALUES (1);",
+			want:  []string{"UPDATE users SET a = 1", "INSERT INTO t VALUES (1)"},
+		},
+		{
+			name:  "empty input",
+			input: "  -- nothing here\n",
+			want:  nil,
+		},
+		{
+			name:      "error in second statement",
+			input:     "SELECT id FROM users; SELECT FROM orders; SELECT * FROM t",
+			wantErrAt: []lexer.Position{{Line: 1, Column: 30}},
+		},
+		{
+			name:      "error in second statement with recovery",
+			input:     "SELECT id FROM users; SELECT FROM orders; SELECT * FROM t",
+			recovery:  true,
+			want:      []string{"SELECT id FROM users", "SELECT * FROM t"},
+			wantErrAt: []lexer.Position{{Line: 1, Column: 30}},
+		},
+		{
+			name:      "missing separator",
+			input:     "SELECT id FROM users SELECT name FROM orders",
+			wantErrAt: []lexer.Position{{Line: 1, Column: 22}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			p.SetRecovery(tt.recovery)
+
+			stmts, err := p.ParseProgram()
+			if (err != nil) != (len(tt.wantErrAt) > 0) {
+				t.Fatalf("ParseProgram() error = %v, want error %v", err, len(tt.wantErrAt) > 0)
+			}
+
+			got := make([]string, len(stmts))
+			for i, stmt := range stmts {
+				got[i] = stmt.String()
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d statements %q, want %d %q", len(got), got, len(tt.want), tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("statement[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+
+			errs := p.Errors()
+			if len(errs) != len(tt.wantErrAt) {
+				t.Fatalf("got %d errors, want %d: %v", len(errs), len(tt.wantErrAt), errs)
+			}
+			for i, e := range errs {
+				if e.Pos != tt.wantErrAt[i] {
+					t.Errorf("error[%d] position = %+v, want %+v (%s)", i, e.Pos, tt.wantErrAt[i], e.Msg)
+				}
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			input: "SELECT id, name FROM users WHERE age > 18",
+			want:  "SELECT id, name FROM users WHERE age > 18",
+		},
+		{
+			input: "select * from users where name = 'John''s' and active = true",
+			want:  "SELECT * FROM users WHERE name = 'John''s' AND active = TRUE",
+		},
+		{
+			input: "SELECT * FROM t WHERE (a = 1 OR b = 2) AND c = 3",
+			want:  "SELECT * FROM t WHERE (a = 1 OR b = 2) AND c = 3",
+		},
+		{
+			input: "SELECT * FROM t WHERE a = 1 OR (b = 2 AND c = 3)",
+			want:  "SELECT * FROM t WHERE a = 1 OR (b = 2 AND c = 3)",
+		},
+		{
+			input: "SELECT * FROM t WHERE a = 1 OR b = 2 AND c = 3",
+			want:  "SELECT * FROM t WHERE a = 1 OR (b = 2 AND c = 3)",
+		},
+		{
+			input: "SELECT * FROM t WHERE (a + 1) * 2 > 10 - (3 - 1)",
+			want:  "SELECT * FROM t WHERE (a + 1) * 2 > 10 - (3 - 1)",
+		},
+		{
+			input: "SELECT * FROM t WHERE not (a = 1 OR b = 2) AND NOT c",
+			want:  "SELECT * FROM t WHERE NOT (a = 1 OR b = 2) AND NOT c",
+		},
+		{
+			input: "SELECT * FROM t WHERE NOT a = 1 OR b AND NOT c",
+			want:  "SELECT * FROM t WHERE NOT a = 1 OR (b AND NOT c)",
+		},
+		{
+			input: "SELECT * FROM t WHERE (NOT a) = b OR -(NOT c) > 0",
+			want:  "SELECT * FROM t WHERE (NOT a) = b OR -(NOT c) > 0",
+		},
+		{
+			input: "SELECT * FROM t WHERE x = -(-1) AND y > 2.0 AND z < 1e10",
+			want:  "SELECT * FROM t WHERE x = -(-1) AND y > 2.0 AND z < 1e+10",
+		},
+		{
+			input: "SELECT COUNT(*) AS n, dept d FROM employees e LEFT JOIN depts ON e.dept = depts.id " +
+				"WHERE e.age BETWEEN 18 AND 65 AND e.id NOT IN (1, 2) AND e.email IS NOT NULL " +
+				"GROUP BY dept HAVING COUNT(*) > 5 ORDER BY n DESC, dept LIMIT 10 OFFSET 20",
+			want: "SELECT COUNT(*) AS n, dept AS d FROM employees AS e LEFT JOIN depts ON e.dept = depts.id " +
+				"WHERE e.age BETWEEN 18 AND 65 AND e.id NOT IN (1, 2) AND e.email IS NOT NULL " +
+				"GROUP BY dept HAVING COUNT(*) > 5 ORDER BY n DESC, dept LIMIT 10 OFFSET 20",
+		},
+		{
+			input: "SELECT * FROM t WHERE name NOT LIKE 'a%' OR (x BETWEEN 1 AND 2) = TRUE",
+			want:  "SELECT * FROM t WHERE name NOT LIKE 'a%' OR (x BETWEEN 1 AND 2) = TRUE",
+		},
+		{
+			input: "SELECT * FROM t WHERE x BETWEEN 1 AND 2 = TRUE",
+			want:  "SELECT * FROM t WHERE (x BETWEEN 1 AND 2) = TRUE",
+		},
+		{
+			input: "INSERT INTO users (id, name) VALUES (1, 'alice'), (2, NULL)",
+			want:  "INSERT INTO users (id, name) VALUES (1, 'alice'), (2, NULL)",
+		},
+		{
+			input: "UPDATE accounts SET balance = balance + 10, active = false WHERE id = 7;",
+			want:  "UPDATE accounts SET balance = balance + 10, active = FALSE WHERE id = 7",
+		},
+		{
+			input: "DELETE FROM users WHERE id IN (5)",
+			want:  "DELETE FROM users WHERE id IN (5)",
+		},
+		{
+			input: "select distinct dept, COUNT(*) from employees group by dept",
+			want:  "SELECT DISTINCT dept, COUNT(*) FROM employees GROUP BY dept",
+		},
+		{
+			input: "SELECT u.*, o.total FROM users u JOIN orders o ON u.id = o.user_id",
+			want:  "SELECT u.*, o.total FROM users AS u INNER JOIN orders AS o ON u.id = o.user_id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			first, err := New(lexer.New(tt.input)).Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			printed := first.String()
+			if printed != tt.want {
+				t.Errorf("String() = %q, want %q", printed, tt.want)
+			}
+
+			second, err := New(lexer.New(printed)).Parse()
+			if err != nil {
+				t.Fatalf("Parse() of printed SQL %q error = %v", printed, err)
+			}
+			if !reflect.DeepEqual(first, second) {
+				t.Errorf("round trip changed the AST\nprinted: %s\nreprinted: %s", printed, second.String())
+			}
+		})
+	}
+}
+
+func compareExpr(a, b ast.Expr) bool {
+	switch a := a.(type) {
+	case *ast.BinaryExpr:
+		b, ok := b.(*ast.BinaryExpr)
+		if !ok {
+			return false
+		}
+		return compareExpr(a.Left, b.Left) && a.Op == b.Op && compareExpr(a.Right, b.Right)
+	case *ast.InExpr:
+		b, ok := b.(*ast.InExpr)
+		if !ok || a.Negated != b.Negated || len(a.List) != len(b.List) {
+			return false
+		}
+		for i := range a.List {
+			if !compareExpr(a.List[i], b.List[i]) {
+				return false
+			}
+		}
+		return compareExpr(a.Expr, b.Expr)
+	case *ast.BetweenExpr:
+		b, ok := b.(*ast.BetweenExpr)
+		if !ok {
+			return false
+		}
+		return a.Negated == b.Negated && compareExpr(a.Expr, b.Expr) &&
+			compareExpr(a.Low, b.Low) && compareExpr(a.High, b.High)
+	case *ast.IsNullExpr:
+		b, ok := b.(*ast.IsNullExpr)
+		if !ok {
+			return false
+		}
+		return a.Negated == b.Negated && compareExpr(a.Expr, b.Expr)
+	case *ast.NullLit:
+		_, ok := b.(*ast.NullLit)
+		return ok
+	case *ast.FuncCall:
+		b, ok := b.(*ast.FuncCall)
+		if !ok || a.Name != b.Name || a.Star != b.Star || len(a.Args) != len(b.Args) {
+			return false
+		}
+		for i := range a.Args {
+			if !compareExpr(a.Args[i], b.Args[i]) {
+				return false
+			}
+		}
+		return true
+	case *ast.ColRef:
+		b, ok := b.(*ast.ColRef)
+		if !ok {
+			return false
+		}
+		return a.Name == b.Name
+	case *ast.QualifiedRef:
+		b, ok := b.(*ast.QualifiedRef)
+		if !ok {
+			return false
+		}
+		return a.Table == b.Table && a.Column == b.Column
+	case *ast.UnaryExpr:
+		b, ok := b.(*ast.UnaryExpr)
+		if !ok {
+			return false
+		}
+		return a.Op == b.Op && compareExpr(a.Operand, b.Operand)
+	case *ast.NumberLit:
+		b, ok := b.(*ast.NumberLit)
+		if !ok {
+			return false
+		}
+		return a.Value == b.Value
+	case *ast.FloatLit:
+		b, ok := b.(*ast.FloatLit)
 		if !ok {
 			return false
 		}
@@ -62,6 +62,21 @@ var commands = []Command{
 		Description: "Write a key-value pair in a transaction",
 		Run:         runTxWrite,
 	},
+	{
+		Name:        "export",
+		Description: "Export all records as JSON lines",
+		Run:         runExport,
+	},
+	{
+		Name:        "import",
+		Description: "Import records exported as JSON lines into an empty WAL",
+		Run:         runImport,
+	},
+	{
+		Name:        "verify",
+		Description: "Check every record's framing and checksum",
+		Run:         runVerify,
+	},
 }
 
 func main() {
@@ -199,22 +214,33 @@ func runRead(config *wal.Config, txMgr *txManager, args []string) error {
 }
 
 func runBeginTx(config *wal.Config, txMgr *txManager, args []string) error {
-	// Begin a new transaction
-	txID, err := txMgr.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+	// Only one transaction can be active across invocations
+	if txID, active, err := txMgr.GetActiveTx(); err != nil {
+		return fmt.Errorf("failed to get active transaction: %w", err)
+	} else if active {
+		return fmt.Errorf("transaction %d is already active", txID)
 	}
 
-	// Open WAL to verify the transaction can be started
+	// Open WAL
 	w, err := wal.Open(config)
 	if err != nil {
-		// If we can't open the WAL, clean up the transaction
-		if err := txMgr.End(txID, false); err != nil {
-			return fmt.Errorf("failed to end transaction: %w", err)
-		}
 		return fmt.Errorf("failed to open WAL: %w", err)
 	}
-	w.Close()
+	defer w.Close()
+
+	// Begin the transaction in the WAL so its ID is allocated and logged
+	txID, err := w.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// Remember the transaction for subsequent invocations
+	if err := txMgr.Begin(txID); err != nil {
+		if abortErr := w.Abort(txID); abortErr != nil {
+			return fmt.Errorf("failed to abort transaction %d: %w", txID, abortErr)
+		}
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
 
 	fmt.Printf("Started new transaction %d\n", txID)
 	return nil
@@ -238,6 +264,18 @@ func runCommit(config *wal.Config, txMgr *txManager, args []string) error {
 	}
 	defer w.Close()
 
+	// Replay the transaction's writes, which earlier invocations could
+	// only persist with the transaction state
+	records, err := txMgr.ActiveRecords()
+	if err != nil {
+		return fmt.Errorf("failed to read transaction records: %w", err)
+	}
+	for _, r := range records {
+		if _, err := w.Write(txID, []byte(r.Key), []byte(r.Value)); err != nil {
+			return fmt.Errorf("failed to write to WAL: %w", err)
+		}
+	}
+
 	// Commit the transaction
 	if err := w.Commit(txID); err != nil {
 		return fmt.Errorf("failed to commit transaction %d: %w", txID, err)
@@ -248,7 +286,7 @@ func runCommit(config *wal.Config, txMgr *txManager, args []string) error {
 		return fmt.Errorf("failed to end transaction %d: %w", txID, err)
 	}
 
-	fmt.Printf("Committed transaction %d\n", txID)
+	fmt.Printf("Committed transaction %d (%d records)\n", txID, len(records))
 	return nil
 }
 
@@ -307,6 +345,58 @@ func runTxWrite(config *wal.Config, txMgr *txManager, args []string) error {
 		return fmt.Errorf("both key and value must be specified")
 	}
 
+	// The WAL only writes a transaction's records when it commits, so
+	// persist the write with the transaction state until then
+	if err := txMgr.Record(txID, *key, *value); err != nil {
+		return fmt.Errorf("failed to record write: %w", err)
+	}
+
+	fmt.Printf("Buffered record: TxID=%d, key=%s, value=%s\n", txID, *key, *value)
+	return nil
+}
+
+func runVerify(config *wal.Config, txMgr *txManager, args []string) error {
+	// Verify without opening the WAL, since recovery fails on a damaged log
+	report, err := wal.VerifyDir(config.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to verify WAL: %w", err)
+	}
+
+	fmt.Println("Segment              | Good  | Bad   | First bad offset")
+	fmt.Println("---------------------|-------|-------|-----------------")
+	for _, s := range report.Segments {
+		firstBad := "-"
+		if s.BadRecords > 0 {
+			firstBad = fmt.Sprintf("%d (%s)", s.FirstBadOffset, s.Error)
+		}
+		fmt.Printf("%020d | %-5d | %-5d | %s\n", s.SegmentID, s.GoodRecords, s.BadRecords, firstBad)
+	}
+	fmt.Printf("\n%d segments, %d good records, %d bad records\n",
+		len(report.Segments), report.GoodRecords, report.BadRecords)
+
+	if !report.OK() {
+		return fmt.Errorf("WAL is corrupted: %d bad records", report.BadRecords)
+	}
+	return nil
+}
+
+func runExport(config *wal.Config, txMgr *txManager, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	output := fs.String("o", "", "File to write to (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
 	// Open WAL
 	w, err := wal.Open(config)
 	if err != nil {
@@ -314,17 +404,47 @@ func runTxWrite(config *wal.Config, txMgr *txManager, args []string) error {
 	}
 	defer w.Close()
 
-	// Write the record in the transaction
-	lsn, err := w.Write(txID, []byte(*key), []byte(*value))
+	if err := w.Export(out); err != nil {
+		return fmt.Errorf("failed to export WAL: %w", err)
+	}
+	if out != os.Stdout {
+		return out.Close()
+	}
+	return nil
+}
+
+func runImport(config *wal.Config, txMgr *txManager, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	input := fs.String("i", "", "File to read from (default stdin)")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	in := os.Stdin
+	if *input != "" {
+		f, err := os.Open(*input)
+		if err != nil {
+			return fmt.Errorf("failed to open input file: %w", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	// Open WAL
+	w, err := wal.Open(config)
 	if err != nil {
-		return fmt.Errorf("failed to write to WAL: %w", err)
+		return fmt.Errorf("failed to open WAL: %w", err)
 	}
+	defer w.Close()
 
-	// Ensure the write is flushed to disk
-	if err := w.Sync(); err != nil {
-		return fmt.Errorf("failed to sync WAL: %w", err)
+	if err := w.Import(in); err != nil {
+		return fmt.Errorf("failed to import WAL: %w", err)
 	}
 
-	fmt.Printf("Wrote record: LSN=%d, TxID=%d, key=%s, value=%s\n", lsn, txID, *key, *value)
+	stats, err := w.Stats()
+	if err != nil {
+		return fmt.Errorf("failed to read WAL stats: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Imported records up to LSN %d\n", stats.LastLSN)
 	return nil
 }
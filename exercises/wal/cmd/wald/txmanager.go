@@ -8,7 +8,9 @@ import (
 	"sync"
 )
 
-// txManager implements the TxManager interface
+// txManager implements the TxManager interface.
+// Each wald invocation is a separate process, so the active transaction is
+// persisted to a state file in the WAL directory and re-read on every call.
 type txManager struct {
 	mu          sync.Mutex
 	txStateFile string
@@ -23,45 +25,75 @@ func NewTxManager(dir string) TxManager {
 
 // TxManager defines the interface for transaction management
 type TxManager interface {
-	Begin() (uint64, error)
+	Begin(txID uint64) error
+	Record(txID uint64, key, value string) error
 	End(txID uint64, commit bool) error
 	GetActiveTx() (uint64, bool, error)
+	ActiveRecords() ([]txRecord, error)
 }
 
 // txState represents the state of a transaction
 type txState struct {
-	Active bool   `json:"active"`
-	TxID   uint64 `json:"tx_id"`
+	Active  bool       `json:"active"`
+	TxID    uint64     `json:"tx_id"`
+	Records []txRecord `json:"records,omitempty"`
 }
 
-// Begin starts a new transaction
-func (m *txManager) Begin() (uint64, error) {
+// txRecord is a write made within the active transaction. The WAL buffers a
+// transaction's writes in memory until it commits, so they are kept here to
+// be replayed into the WAL by the invocation that commits.
+type txRecord struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Begin marks txID, as assigned by the WAL, as the active transaction
+func (m *txManager) Begin(txID uint64) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	// Read current state
 	state, err := m.readState()
 	if err != nil {
-		return 0, fmt.Errorf("failed to read transaction state: %w", err)
+		return fmt.Errorf("failed to read transaction state: %w", err)
 	}
 
 	if state.Active {
-		return 0, fmt.Errorf("transaction %d is already active", state.TxID)
+		return fmt.Errorf("transaction %d is already active", state.TxID)
 	}
 
-	// Generate new transaction ID
-	state.TxID++
-	state.Active = true
-
 	// Save new state
+	state = &txState{Active: true, TxID: txID}
 	if err := m.writeState(state); err != nil {
-		return 0, fmt.Errorf("failed to write transaction state: %w", err)
+		return fmt.Errorf("failed to write transaction state: %w", err)
 	}
 
-	return state.TxID, nil
+	return nil
 }
 
-// End ends the current transaction
+// Record adds a write to the active transaction's persisted records
+func (m *txManager) Record(txID uint64, key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, err := m.readState()
+	if err != nil {
+		return fmt.Errorf("failed to read transaction state: %w", err)
+	}
+
+	if !state.Active || state.TxID != txID {
+		return fmt.Errorf("no active transaction with ID %d", txID)
+	}
+
+	state.Records = append(state.Records, txRecord{Key: key, Value: value})
+	if err := m.writeState(state); err != nil {
+		return fmt.Errorf("failed to write transaction state: %w", err)
+	}
+
+	return nil
+}
+
+// End ends the current transaction and clears the persisted state
 func (m *txManager) End(txID uint64, commit bool) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -76,15 +108,12 @@ func (m *txManager) End(txID uint64, commit bool) error {
 		return fmt.Errorf("no active transaction with ID %d", txID)
 	}
 
-	// Update state
-	state.Active = false
-	if !commit {
-		// On abort, don't increment the transaction ID
-		state.TxID--
+	// The outcome is recorded in the WAL itself, so nothing needs to be kept
+	if err := os.Remove(m.txStateFile); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear transaction state: %w", err)
 	}
 
-	// Save state
-	return m.writeState(state)
+	return nil
 }
 
 // GetActiveTx returns the currently active transaction ID, if any
@@ -104,6 +133,23 @@ func (m *txManager) GetActiveTx() (uint64, bool, error) {
 	return state.TxID, true, nil
 }
 
+// ActiveRecords returns the writes made so far in the active transaction
+func (m *txManager) ActiveRecords() ([]txRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, err := m.readState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transaction state: %w", err)
+	}
+
+	if !state.Active {
+		return nil, nil
+	}
+
+	return state.Records, nil
+}
+
 // readState reads the transaction state from disk
 func (m *txManager) readState() (*txState, error) {
 	state := &txState{Active: false, TxID: 0}
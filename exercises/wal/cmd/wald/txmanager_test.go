@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kumarlokesh/sysd/exercises/wal/internal/wal"
+)
+
+func TestTxManager_PersistsAcrossInstances(t *testing.T) {
+	dir, err := os.MkdirTemp("", "wald-txmanager-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Begin in one instance
+	if err := NewTxManager(dir).Begin(7); err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+
+	// Record a write in a second instance
+	m := NewTxManager(dir)
+	txID, active, err := m.GetActiveTx()
+	if err != nil {
+		t.Fatalf("Failed to get active transaction: %v", err)
+	}
+	if !active || txID != 7 {
+		t.Fatalf("Expected active transaction 7, got %d (active=%v)", txID, active)
+	}
+	if err := m.Record(txID, "key1", "value1"); err != nil {
+		t.Fatalf("Failed to record write: %v", err)
+	}
+
+	// Commit in a third instance
+	m = NewTxManager(dir)
+	records, err := m.ActiveRecords()
+	if err != nil {
+		t.Fatalf("Failed to get active records: %v", err)
+	}
+	if len(records) != 1 || records[0] != (txRecord{Key: "key1", Value: "value1"}) {
+		t.Fatalf("Unexpected records: %+v", records)
+	}
+	if err := m.End(7, true); err != nil {
+		t.Fatalf("Failed to end transaction: %v", err)
+	}
+
+	if _, active, err := NewTxManager(dir).GetActiveTx(); err != nil || active {
+		t.Fatalf("Expected no active transaction after commit, got active=%v err=%v", active, err)
+	}
+}
+
+func TestCommands_TransactionAcrossInvocations(t *testing.T) {
+	dir, err := os.MkdirTemp("", "wald-commands-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := &wal.Config{
+		Dir:           dir,
+		SegmentSize:   1024 * 1024,
+		SyncMode:      wal.SyncAlways,
+		BufferSize:    4096,
+		FlushInterval: time.Second,
+	}
+
+	// Each command gets a fresh manager, as it would in a separate process
+	steps := []struct {
+		run  func(*wal.Config, *txManager, []string) error
+		args []string
+	}{
+		{runBeginTx, nil},
+		{runTxWrite, []string{"-key", "key1", "-value", "value1"}},
+		{runTxWrite, []string{"-key", "key2", "-value", "value2"}},
+		{runCommit, nil},
+	}
+	for i, step := range steps {
+		if err := step.run(config, NewTxManager(dir).(*txManager), step.args); err != nil {
+			t.Fatalf("Step %d failed: %v", i, err)
+		}
+	}
+
+	w, err := wal.Open(config)
+	if err != nil {
+		t.Fatalf("Failed to open WAL: %v", err)
+	}
+	defer w.Close()
+
+	records, err := w.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read records: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 committed records, got %d", len(records))
+	}
+	for i, key := range []string{"key1", "key2"} {
+		if string(records[i].Key) != key {
+			t.Errorf("Record %d: expected key %q, got %q", i, key, records[i].Key)
+		}
+	}
+}
@@ -1,3 +1,5 @@
 module github.com/kumarlokesh/sysd/exercises/wal
 
 go 1.24.3
+
+require github.com/golang/snappy v1.0.0
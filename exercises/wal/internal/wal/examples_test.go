@@ -82,7 +82,10 @@ func testTransactionExample(t *testing.T) {
 	defer w.Close()
 
 	// Example 2: Transaction with multiple writes
-	txID := w.Begin()
+	txID, err := w.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
 	t.Logf("Started transaction: %d", txID)
 
 	// Write multiple records in the transaction
@@ -103,7 +106,10 @@ func testTransactionExample(t *testing.T) {
 	t.Logf("Committed transaction %d", txID)
 
 	// Example 3: Aborted transaction
-	txID = w.Begin()
+	txID, err = w.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
 	t.Logf("Started transaction: %d (will be aborted)", txID)
 
 	// Write a record that will be aborted
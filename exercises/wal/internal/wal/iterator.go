@@ -0,0 +1,110 @@
+package wal
+
+import (
+	"fmt"
+	"io"
+)
+
+// RecordIterator streams committed records from the WAL in log order.
+//
+// Records are never materialized in bulk: the iterator makes one pass over
+// the log to learn which transactions committed, keeping only a map of
+// transaction IDs to their outcome, and then a second pass that decodes and
+// yields one record at a time. Memory use is therefore proportional to the
+// number of distinct transactions in the log rather than to its size.
+//
+// Writes belonging to transactions that commit after the iterator is created
+// are not returned.
+type RecordIterator struct {
+	reader    *LogReader
+	committed map[uint64]bool // Transaction ID -> committed (true) or aborted (false)
+}
+
+// Iterator returns a RecordIterator positioned at the start of the log.
+// The caller must Close the iterator when done.
+func (w *WAL) Iterator() (*RecordIterator, error) {
+	return w.ReadFrom(0)
+}
+
+// ReadFrom returns a RecordIterator positioned at the first record whose LSN
+// is >= lsn, for resuming consumption of the log from a known position.
+// Only the log from lsn onward is scanned: a transaction's commit or abort
+// record always follows its writes, so its outcome is known for every write
+// the iterator can return. The caller must Close the iterator when done.
+func (w *WAL) ReadFrom(lsn uint64) (*RecordIterator, error) {
+	reader, err := NewLogReader(w.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log reader: %w", err)
+	}
+
+	it := &RecordIterator{
+		reader:    reader,
+		committed: make(map[uint64]bool),
+	}
+
+	if err := reader.SeekToLSN(lsn); err != nil {
+		_ = reader.Close()
+		return nil, fmt.Errorf("failed to seek to LSN %d: %w", lsn, err)
+	}
+
+	// First pass: track transaction commit/abort status
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = reader.Close()
+			return nil, fmt.Errorf("failed to read record: %w", err)
+		}
+
+		switch record.Type {
+		case RecordTypeTxnCommit:
+			it.committed[record.TxID] = true
+		case RecordTypeTxnRollback:
+			it.committed[record.TxID] = false
+		}
+	}
+
+	if err := reader.SeekToLSN(lsn); err != nil {
+		_ = reader.Close()
+		return nil, fmt.Errorf("failed to seek to LSN %d: %w", lsn, err)
+	}
+
+	return it, nil
+}
+
+// Next returns the next committed record, or io.EOF when the log is exhausted.
+func (it *RecordIterator) Next() (*Record, error) {
+	for {
+		record, err := it.reader.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		if it.visible(record) {
+			return record, nil
+		}
+	}
+}
+
+// visible reports whether a record belongs in the committed view of the log.
+func (it *RecordIterator) visible(record *Record) bool {
+	switch record.Type {
+	case RecordTypeWrite, RecordTypeDelete:
+		// Include non-transactional records (txID=0) or records from committed
+		// transactions
+		return record.TxID == 0 || it.committed[record.TxID]
+	case RecordTypeTxnBegin, RecordTypeTxnCommit, RecordTypeTxnRollback, RecordTypeCheckpoint:
+		// Skip transaction control and checkpoint records
+		return false
+	default:
+		// Include any other record types with txID=0 (non-transactional)
+		return record.TxID == 0
+	}
+}
+
+// Close releases the iterator's open segment file.
+func (it *RecordIterator) Close() error {
+	return it.reader.Close()
+}
@@ -1,6 +1,8 @@
 package wal
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -21,16 +23,20 @@ var (
 
 // LogReader reads records from the WAL.
 type LogReader struct {
-	dir      string   // Directory containing WAL segments
-	segments []string // Sorted list of segment files
-	current  int      // Current segment index
-	file     *os.File // Current segment file
-	offset   int64    // Current offset in the segment
+	dir      string    // Directory containing WAL segments
+	segments []string  // Sorted list of segment files
+	current  int       // Current segment index
+	file     *os.File  // Current segment file
+	src      io.Reader // Record data of the current segment, decompressed if needed
+	offset   int64     // Current offset in the segment's record data
+	size     int64     // Last known size of the current segment
+	// Whether the current segment is compressed
+	compressed bool
+	pending    *Record // Record read ahead by SeekToLSN, returned by the next call to Next
 }
 
-// NewLogReader creates a new LogReader for the given directory.
-func NewLogReader(dir string) (*LogReader, error) {
-	// List all segment files
+// listSegments returns the segment files in dir sorted by segment ID.
+func listSegments(dir string) ([]string, error) {
 	files, err := filepath.Glob(filepath.Join(dir, "*.wal"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list segment files: %w", err)
@@ -38,26 +44,82 @@ func NewLogReader(dir string) (*LogReader, error) {
 
 	// Sort segments by ID (filename without extension)
 	sort.Slice(files, func(i, j int) bool {
-		iID, _ := strconv.ParseUint(strings.TrimSuffix(filepath.Base(files[i]), ".wal"), 10, 64)
-		jID, _ := strconv.ParseUint(strings.TrimSuffix(filepath.Base(files[j]), ".wal"), 10, 64)
-		return iID < jID
+		return segmentIDFromPath(files[i]) < segmentIDFromPath(files[j])
 	})
 
+	return files, nil
+}
+
+// segmentIDFromPath extracts the segment ID from a segment file path.
+func segmentIDFromPath(path string) uint64 {
+	id, _ := strconv.ParseUint(strings.TrimSuffix(filepath.Base(path), ".wal"), 10, 64)
+	return id
+}
+
+// NewLogReader creates a new LogReader for the given directory.
+func NewLogReader(dir string) (*LogReader, error) {
+	files, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &LogReader{
+		dir:      dir,
+		segments: files,
+	}
+
 	if len(files) == 0 {
-		return &LogReader{dir: dir}, nil
+		return r, nil
 	}
 
 	// Open the first segment
-	file, err := os.Open(files[0])
+	if err := r.openSegment(0); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// openSegment opens the segment at index i for reading. Compressed segments
+// are recognized by their header and read through a decompressing stream.
+func (r *LogReader) openSegment(i int) error {
+	path := r.segments[i]
+	file, err := os.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open segment %s: %w", files[0], err)
+		return fmt.Errorf("failed to open segment %s: %w", path, err)
 	}
 
-	return &LogReader{
-		dir:      dir,
-		segments: files,
-		file:     file,
-	}, nil
+	header := make([]byte, segmentHeaderSize)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		_ = file.Close()
+		return fmt.Errorf("failed to read segment %s: %w", path, err)
+	}
+
+	codec, ok, err := decodeSegmentHeader(header[:n])
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("invalid segment %s: %w", path, err)
+	}
+
+	r.file = file
+	r.offset = 0
+	r.size = 0
+	r.compressed = ok
+
+	if ok {
+		r.src = &blockReader{r: bufio.NewReader(file), codec: codec}
+		return nil
+	}
+
+	// Uncompressed segment: rewind past the bytes peeked for a header
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		_ = file.Close()
+		r.file = nil
+		return fmt.Errorf("failed to seek segment %s: %w", path, err)
+	}
+	r.src = file
+	return nil
 }
 
 // Read reads the next record from the WAL.
@@ -74,28 +136,29 @@ func (r *LogReader) Reset() error {
 
 // Next reads the next record from the WAL.
 func (r *LogReader) Next() (*Record, error) {
+	if r.pending != nil {
+		record := r.pending
+		r.pending = nil
+		return record, nil
+	}
+
 	// If we have no file open, try to open the next segment
 	if r.file == nil {
 		if r.current >= len(r.segments) {
 			return nil, io.EOF
 		}
 
-		file, err := os.Open(r.segments[r.current])
-		if err != nil {
-			return nil, fmt.Errorf("failed to open segment %s: %w", r.segments[r.current], err)
+		if err := r.openSegment(r.current); err != nil {
+			return nil, err
 		}
-
-		r.file = file
-		r.offset = 0
 	}
 
 	// Read the header
 	header := make([]byte, HeaderSize)
-	n, err := io.ReadFull(r.file, header)
+	n, err := io.ReadFull(r.src, header)
 	if err == io.EOF || err == io.ErrUnexpectedEOF {
 		// End of current segment, try next one
-		_ = r.file.Close()
-		r.file = nil
+		_ = r.Close()
 		r.current++
 		return r.Next()
 	}
@@ -107,53 +170,154 @@ func (r *LogReader) Next() (*Record, error) {
 	}
 
 	// Parse the header to get key and value lengths
-	keyLen := binary.BigEndian.Uint16(header[18:20])
-	valueLen := binary.BigEndian.Uint16(header[20:22])
-	recordSize := int64(HeaderSize + int(keyLen) + int(valueLen))
+	keyLen := binary.BigEndian.Uint32(header[18:22])
+	valueLen := binary.BigEndian.Uint32(header[22:26])
+	recordSize := int64(HeaderSize) + int64(keyLen) + int64(valueLen)
+
+	if r.compressed {
+		// The decompressed size of the segment is unknown up front, so grow
+		// the buffer as data arrives rather than trusting the header's lengths
+		data := bytes.NewBuffer(make([]byte, 0, min(recordSize, maxBlockSize)))
+		data.Write(header)
+		if _, err := io.CopyN(data, r.src, recordSize-HeaderSize); err != nil {
+			return nil, fmt.Errorf("failed to read record data: %w", err)
+		}
+		return r.decode(data.Bytes())
+	}
+
+	// Reject lengths that run past the end of the segment before allocating,
+	// since a corrupted header can claim a record of several gigabytes
+	if r.offset+recordSize > r.size {
+		info, err := r.file.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat segment: %w", err)
+		}
+		r.size = info.Size()
+		if r.offset+recordSize > r.size {
+			return nil, fmt.Errorf("%w: record at offset %d extends past end of segment", ErrCorruptLog, r.offset)
+		}
+	}
 
 	// Read the entire record
 	buf := make([]byte, recordSize)
 	copy(buf, header)
 
-	if _, err := io.ReadFull(r.file, buf[HeaderSize:]); err != nil {
+	if _, err := io.ReadFull(r.src, buf[HeaderSize:]); err != nil {
 		return nil, fmt.Errorf("failed to read record data: %w", err)
 	}
 
-	// Decode the record
+	return r.decode(buf)
+}
+
+// decode decodes a complete record read from the current segment.
+func (r *LogReader) decode(buf []byte) (*Record, error) {
 	record := &Record{}
 	if err := record.Decode(buf); err != nil {
 		return nil, fmt.Errorf("failed to decode record: %w", err)
 	}
 
-	r.offset += recordSize
+	r.offset += int64(len(buf))
 	return record, nil
 }
 
 // Close closes the LogReader and any open segment files.
 func (r *LogReader) Close() error {
 	if r.file != nil {
-		return r.file.Close()
+		err := r.file.Close()
+		r.file = nil
+		r.src = nil
+		return err
 	}
 	return nil
 }
 
 // SeekToStart resets the reader to the beginning of the first segment.
+// The segment list is refreshed so that rotated-in or truncated segments
+// are picked up.
 func (r *LogReader) SeekToStart() error {
 	if err := r.Close(); err != nil {
 		return err
 	}
 
+	files, err := listSegments(r.dir)
+	if err != nil {
+		return err
+	}
+
+	r.segments = files
 	r.current = 0
+	r.pending = nil
 	r.file = nil
 	r.offset = 0
+	r.size = 0
 
 	if len(r.segments) > 0 {
-		file, err := os.Open(r.segments[0])
+		return r.openSegment(0)
+	}
+
+	return nil
+}
+
+// SeekToLSN positions the reader so that the next call to Next returns the
+// first record whose LSN is >= lsn. Scanning starts from the latest segment
+// whose first record is <= lsn, so earlier segments are not re-read.
+func (r *LogReader) SeekToLSN(lsn uint64) error {
+	if err := r.SeekToStart(); err != nil {
+		return err
+	}
+
+	start := 0
+	for i, path := range r.segments {
+		first, ok, err := firstLSN(path)
 		if err != nil {
-			return fmt.Errorf("failed to open segment %s: %w", r.segments[0], err)
+			return err
 		}
-		r.file = file
+		if !ok {
+			// Empty segment
+			continue
+		}
+		if first > lsn {
+			break
+		}
+		start = i
 	}
 
-	return nil
+	if err := r.Close(); err != nil {
+		return err
+	}
+	r.current = start
+	r.offset = 0
+	r.size = 0
+
+	// Skip records until the target LSN
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if record.LSN >= lsn {
+			r.pending = record
+			return nil
+		}
+	}
+}
+
+// firstLSN returns the LSN of the first record in a segment file. It reports
+// false if the segment does not contain a complete record.
+func firstLSN(path string) (uint64, bool, error) {
+	reader := &LogReader{segments: []string{path}}
+	defer reader.Close()
+
+	record, err := reader.Next()
+	if err == io.EOF {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return record.LSN, true, nil
 }
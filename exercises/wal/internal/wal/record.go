@@ -3,8 +3,18 @@ package wal
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"hash/crc32"
 	"io"
+	"math"
+)
+
+var (
+	// ErrRecordTooLarge is returned when a key or value does not fit in the
+	// record's length fields.
+	ErrRecordTooLarge = errors.New("record key or value too large")
+	// ErrChecksumMismatch is returned when a record's checksum does not match its contents.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
 )
 
 // RecordType represents the type of a log record.
@@ -25,26 +35,72 @@ const (
 	RecordTypeTxnCommit
 	// RecordTypeTxnRollback marks the unsuccessful end of a transaction
 	RecordTypeTxnRollback
+	// RecordTypeDelete represents a key deletion (tombstone).
+	RecordTypeDelete
 )
 
+// recordTypeNames are the names of the record types, as used by String.
+var recordTypeNames = map[RecordType]string{
+	RecordTypeWrite:       "write",
+	RecordTypeCommit:      "commit",
+	RecordTypeAbort:       "abort",
+	RecordTypeCheckpoint:  "checkpoint",
+	RecordTypeTxnBegin:    "txn-begin",
+	RecordTypeTxnCommit:   "txn-commit",
+	RecordTypeTxnRollback: "txn-rollback",
+	RecordTypeDelete:      "delete",
+}
+
+// String returns the name of the record type.
+func (t RecordType) String() string {
+	if name, ok := recordTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("RecordType(%d)", byte(t))
+}
+
+// parseRecordType returns the record type with the given name.
+func parseRecordType(name string) (RecordType, error) {
+	for t, n := range recordTypeNames {
+		if n == name {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown record type %q", name)
+}
+
 const (
 	// HeaderSize is the size of the record header in bytes.
-	// LSN (8) + TxID (8) + Type (1) + Flags (1) + KeyLen (2) + ValueLen (2) + Checksum (4) = 26 bytes
-	HeaderSize = 26
+	// LSN (8) + TxID (8) + Type (1) + Flags (1) + KeyLen (4) + ValueLen (4) + Checksum (4) = 30 bytes
+	HeaderSize = 30
+	// MaxFieldSize is the largest key or value, in bytes, a record can hold.
+	MaxFieldSize = math.MaxUint32
+	// checksumOffset is the position of the checksum field within the header.
+	checksumOffset = HeaderSize - 4
+)
+
+const (
 	// LSNSize is the size of the Log Sequence Number in bytes.
 	LSNSize = 8
 	// TxIDSize is the size of the Transaction ID in bytes.
 	TxIDSize = 8
 )
 
+// Record flags, stored in the header's Flags byte.
+const (
+	// FlagHeaderChecksum marks a record whose checksum covers the header as
+	// well as the key and value. Every record must have it.
+	FlagHeaderChecksum byte = 1 << iota
+)
+
 // Header represents the header of a log record.
 type Header struct {
 	LSN      uint64     // Log Sequence Number (8 bytes)
 	TxID     uint64     // Transaction ID (8 bytes)
 	Type     RecordType // Record type (1 byte)
 	Flags    byte       // Flags (1 byte)
-	KeyLen   uint16     // Length of the key (2 bytes)
-	ValueLen uint16     // Length of the value (2 bytes)
+	KeyLen   uint32     // Length of the key (4 bytes)
+	ValueLen uint32     // Length of the value (4 bytes)
 	Checksum uint32     // CRC32 checksum (4 bytes)
 }
 
@@ -57,6 +113,10 @@ type Record struct {
 
 // Encode encodes the record into a byte slice.
 func (r *Record) Encode() ([]byte, error) {
+	if uint64(len(r.Key)) > MaxFieldSize || uint64(len(r.Value)) > MaxFieldSize {
+		return nil, fmt.Errorf("%w: key=%d bytes, value=%d bytes", ErrRecordTooLarge, len(r.Key), len(r.Value))
+	}
+
 	// Calculate total size
 	totalSize := HeaderSize + len(r.Key) + len(r.Value)
 	buf := make([]byte, totalSize)
@@ -69,12 +129,12 @@ func (r *Record) Encode() ([]byte, error) {
 	offset += 8
 	buf[offset] = byte(r.Type)
 	offset++
-	buf[offset] = r.Flags
+	buf[offset] = r.Flags | FlagHeaderChecksum
 	offset++
-	binary.BigEndian.PutUint16(buf[offset:], uint16(len(r.Key)))
-	offset += 2
-	binary.BigEndian.PutUint16(buf[offset:], uint16(len(r.Value)))
-	offset += 2
+	binary.BigEndian.PutUint32(buf[offset:], uint32(len(r.Key)))
+	offset += 4
+	binary.BigEndian.PutUint32(buf[offset:], uint32(len(r.Value)))
+	offset += 4
 	// Leave space for checksum (4 bytes)
 	checksumPos := offset
 	offset += 4
@@ -84,8 +144,8 @@ func (r *Record) Encode() ([]byte, error) {
 	offset += len(r.Key)
 	copy(buf[offset:], r.Value)
 
-	// Calculate and write checksum (over everything after the header)
-	r.Checksum = crc32.ChecksumIEEE(buf[HeaderSize:])
+	// Calculate and write checksum last, over everything except the checksum field
+	r.Checksum = recordChecksum(buf)
 	binary.BigEndian.PutUint32(buf[checksumPos:], r.Checksum)
 
 	return buf, nil
@@ -107,10 +167,10 @@ func (r *Record) Decode(data []byte) error {
 	offset++
 	r.Flags = data[offset]
 	offset++
-	keyLen := binary.BigEndian.Uint16(data[offset:])
-	offset += 2
-	valueLen := binary.BigEndian.Uint16(data[offset:])
-	offset += 2
+	keyLen := binary.BigEndian.Uint32(data[offset:])
+	offset += 4
+	valueLen := binary.BigEndian.Uint32(data[offset:])
+	offset += 4
 	checksum := binary.BigEndian.Uint32(data[offset:])
 
 	// Verify data length
@@ -119,10 +179,10 @@ func (r *Record) Decode(data []byte) error {
 		return io.ErrUnexpectedEOF
 	}
 
-	// Verify checksum
-	actualChecksum := crc32.ChecksumIEEE(data[HeaderSize:expectedLen])
-	if actualChecksum != checksum {
-		return errors.New("checksum mismatch")
+	// Verify checksum. Every record sets the flag, so a record without it
+	// has a corrupt Flags byte.
+	if r.Flags&FlagHeaderChecksum == 0 || recordChecksum(data[:expectedLen]) != checksum {
+		return ErrChecksumMismatch
 	}
 
 	// Copy key and value
@@ -138,6 +198,13 @@ func (r *Record) Decode(data []byte) error {
 	return nil
 }
 
+// recordChecksum computes the CRC32 of an encoded record, covering the header
+// up to the checksum field followed by the key and value.
+func recordChecksum(data []byte) uint32 {
+	crc := crc32.ChecksumIEEE(data[:checksumOffset])
+	return crc32.Update(crc, crc32.IEEETable, data[HeaderSize:])
+}
+
 // NewWriteRecord creates a new write record.
 func NewWriteRecord(lsn, txID uint64, key, value []byte) *Record {
 	return &Record{
@@ -145,14 +212,27 @@ func NewWriteRecord(lsn, txID uint64, key, value []byte) *Record {
 			LSN:      lsn,
 			TxID:     txID,
 			Type:     RecordTypeWrite,
-			KeyLen:   uint16(len(key)),
-			ValueLen: uint16(len(value)),
+			KeyLen:   uint32(len(key)),
+			ValueLen: uint32(len(value)),
 		},
 		Key:   key,
 		Value: value,
 	}
 }
 
+// NewDeleteRecord creates a new delete (tombstone) record.
+func NewDeleteRecord(lsn, txID uint64, key []byte) *Record {
+	return &Record{
+		Header: Header{
+			LSN:    lsn,
+			TxID:   txID,
+			Type:   RecordTypeDelete,
+			KeyLen: uint32(len(key)),
+		},
+		Key: key,
+	}
+}
+
 // NewCommitRecord creates a new commit record.
 func NewCommitRecord(lsn, txID uint64) *Record {
 	return &Record{
@@ -187,9 +267,10 @@ func RollbackTxnRecord(txID, lsn uint64) *Record {
 }
 
 // BeginTxnRecord creates a new transaction begin record
-func BeginTxnRecord(txID uint64) *Record {
+func BeginTxnRecord(txID, lsn uint64) *Record {
 	return &Record{
 		Header: Header{
+			LSN:  lsn,
 			TxID: txID,
 			Type: RecordTypeTxnBegin,
 		},
@@ -208,11 +289,17 @@ func NewAbortRecord(lsn, txID uint64) *Record {
 }
 
 // NewCheckpointRecord creates a new checkpoint record.
-func NewCheckpointRecord(lsn uint64) *Record {
+// The highest transaction ID issued so far is stored in the value so that
+// recovery does not reuse IDs whose records were truncated away.
+func NewCheckpointRecord(lsn, lastTxID uint64) *Record {
+	value := make([]byte, TxIDSize)
+	binary.BigEndian.PutUint64(value, lastTxID)
 	return &Record{
 		Header: Header{
-			LSN:  lsn,
-			Type: RecordTypeCheckpoint,
+			LSN:      lsn,
+			Type:     RecordTypeCheckpoint,
+			ValueLen: TxIDSize,
 		},
+		Value: value,
 	}
 }
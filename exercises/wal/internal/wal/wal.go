@@ -1,9 +1,12 @@
 package wal
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,9 +16,62 @@ import (
 type Config struct {
 	Dir           string        // Directory to store WAL segments
 	SegmentSize   int64         // Maximum size of each segment file in bytes
-	Sync          bool          // Whether to sync writes to disk
+	SyncMode      SyncMode      // When writes are fsynced; defaults from Sync if unset
 	BufferSize    int           // Size of the write buffer in bytes
 	FlushInterval time.Duration // Interval for background flushes
+	GroupCommit   bool          // Whether concurrent non-transactional writes share one fsync
+	Compression   Compression   // Codec for new segments: "none" (default), "gzip" or "snappy"
+
+	// OnFlush, if set, is called with the highest LSN known to be on stable
+	// storage each time that advances. It runs with the writer locked, so it
+	// must not block or call back into the WAL.
+	OnFlush func(lsn uint64)
+
+	// Sync is whether to sync writes to disk.
+	//
+	// Deprecated: Use SyncMode. When SyncMode is unset, true maps to
+	// SyncAlways and false to SyncNever.
+	Sync bool
+}
+
+// SyncMode controls when buffered writes are fsynced to stable storage.
+type SyncMode int
+
+const (
+	// SyncNever never fsyncs; durability is left to the operating system.
+	// Buffered data is still written to the segment on every flush.
+	SyncNever SyncMode = iota + 1
+	// SyncInterval fsyncs on every tick of the background flusher, so at
+	// most FlushInterval worth of writes can be lost on a crash.
+	SyncInterval
+	// SyncAlways fsyncs after every write.
+	SyncAlways
+)
+
+// String returns the name of the sync mode.
+func (m SyncMode) String() string {
+	switch m {
+	case SyncNever:
+		return "never"
+	case SyncInterval:
+		return "interval"
+	case SyncAlways:
+		return "always"
+	default:
+		return fmt.Sprintf("SyncMode(%d)", int(m))
+	}
+}
+
+// syncMode returns the effective sync mode, falling back to the deprecated
+// Sync flag when SyncMode is unset.
+func (c *Config) syncMode() SyncMode {
+	if c.SyncMode != 0 {
+		return c.SyncMode
+	}
+	if c.Sync {
+		return SyncAlways
+	}
+	return SyncNever
 }
 
 // WAL represents a write-ahead log.
@@ -31,6 +87,33 @@ type WAL struct {
 	txns     map[uint64]*Transaction
 	txnsMu   sync.RWMutex
 	nextTxID uint64 // Next transaction ID
+
+	recovery RecoveryReport // Outcome of the recovery pass run by Open
+}
+
+// RecoveryReport summarizes the transactions found while recovering the log.
+type RecoveryReport struct {
+	Committed int          // Number of transactions that committed
+	Aborted   int          // Number of transactions that aborted
+	InDoubt   []InDoubtTxn // Transactions that began but never committed or aborted
+}
+
+// InDoubtTxn describes a transaction left incomplete in the log, typically
+// because the process crashed before it committed or aborted.
+type InDoubtTxn struct {
+	TxID     uint64 // Transaction ID
+	FirstLSN uint64 // LSN of the transaction's first record
+	LastLSN  uint64 // LSN of the transaction's last record
+}
+
+// WALStats is a point-in-time view of the WAL's state for monitoring.
+type WALStats struct {
+	SegmentID          uint64 // ID of the segment being written
+	SegmentCount       int    // Number of segment files
+	DiskBytes          int64  // Total size of the segment files
+	LastLSN            uint64 // Last LSN assigned to a record
+	ActiveTransactions int    // Number of transactions that are active
+	BufferedBytes      int    // Bytes written but not yet flushed to a segment
 }
 
 // TransactionState represents the state of a transaction
@@ -49,13 +132,19 @@ const (
 
 // Transaction represents an active transaction
 type Transaction struct {
-	ID        uint64
-	LSN       uint64
-	State     TransactionState
-	Records   []*Record
-	StartedAt time.Time
+	ID         uint64
+	LSN        uint64
+	State      TransactionState
+	Records    []*Record     // Writes buffered until commit, without LSNs yet
+	Savepoints []SavepointID // Savepoints that can still be rolled back to, oldest first
+	StartedAt  time.Time
 }
 
+// SavepointID identifies a savepoint within a transaction. It is the number
+// of records the transaction had buffered when the savepoint was created, so
+// rolling back to it discards the records buffered since.
+type SavepointID uint64
+
 // Open opens or creates a WAL in the given directory.
 func Open(config *Config) (*WAL, error) {
 	if err := os.MkdirAll(config.Dir, 0755); err != nil {
@@ -91,17 +180,70 @@ func Open(config *Config) (*WAL, error) {
 }
 
 // Begin starts a new transaction and returns its ID.
-func (w *WAL) Begin() uint64 {
+// A begin record is appended to the log so that recovery can reconstruct
+// transactions that started but never wrote any records.
+func (w *WAL) Begin() (uint64, error) {
+	// Hold the append lock so the begin record reaches the log in LSN order
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
 	w.txnsMu.Lock()
 	defer w.txnsMu.Unlock()
 
 	txID := atomic.AddUint64(&w.lastTxID, 1)
+	lsn := w.generateLSN()
+
+	// Write begin record
+	if _, err := w.writer.Write(BeginTxnRecord(txID, lsn)); err != nil {
+		return 0, fmt.Errorf("failed to write begin record: %w", err)
+	}
+
 	w.txns[txID] = &Transaction{
 		ID:        txID,
+		LSN:       lsn,
 		State:     TransactionActive,
 		StartedAt: time.Now(),
 	}
-	return txID
+	return txID, nil
+}
+
+// ActiveTransactions returns the IDs of all transactions that are currently
+// active, including those recovered from the log, in ascending order.
+func (w *WAL) ActiveTransactions() []uint64 {
+	w.txnsMu.RLock()
+	defer w.txnsMu.RUnlock()
+
+	ids := make([]uint64, 0, len(w.txns))
+	for txID, tx := range w.txns {
+		if tx.State == TransactionActive {
+			ids = append(ids, txID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Stats returns the WAL's current statistics. Writers are blocked while
+// they are collected, so the values are consistent with each other.
+func (w *WAL) Stats() (WALStats, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.txnsMu.RLock()
+	defer w.txnsMu.RUnlock()
+
+	stats, err := w.writer.stats()
+	if err != nil {
+		return WALStats{}, err
+	}
+
+	stats.LastLSN = atomic.LoadUint64(&w.lastLSN)
+	for _, tx := range w.txns {
+		if tx.State == TransactionActive {
+			stats.ActiveTransactions++
+		}
+	}
+	return stats, nil
 }
 
 // recover recovers the WAL state by reading all records and rebuilding in-memory state.
@@ -120,6 +262,8 @@ func (w *WAL) recover() error {
 
 	// Track active transactions and their records
 	transactions := make(map[uint64]*Transaction)
+	lastLSNs := make(map[uint64]uint64) // Transaction ID -> LSN of its latest record
+	report := RecoveryReport{}
 
 	// First pass: process all records to rebuild transaction state
 	for {
@@ -136,18 +280,22 @@ func (w *WAL) recover() error {
 			atomic.StoreUint64(&w.lastLSN, record.LSN)
 		}
 
+		if record.TxID > maxTxID {
+			maxTxID = record.TxID
+		}
+		if record.TxID > 0 {
+			lastLSNs[record.TxID] = record.LSN
+		}
+
 		switch record.Type {
 		case RecordTypeTxnBegin:
-			// Start a new transaction
-			tx := &Transaction{
+			// Begin records are the source of truth for a transaction's existence
+			transactions[record.TxID] = &Transaction{
 				ID:        record.TxID,
+				LSN:       record.LSN,
 				State:     TransactionActive,
 				StartedAt: time.Now(),
 			}
-			transactions[record.TxID] = tx
-			if record.TxID > maxTxID {
-				maxTxID = record.TxID
-			}
 
 		case RecordTypeTxnCommit:
 			// Mark transaction as committed
@@ -155,6 +303,8 @@ func (w *WAL) recover() error {
 				tx.State = TransactionCommitted
 				delete(transactions, record.TxID)
 			}
+			delete(lastLSNs, record.TxID)
+			report.Committed++
 
 		case RecordTypeTxnRollback:
 			// Mark transaction as aborted
@@ -162,20 +312,28 @@ func (w *WAL) recover() error {
 				tx.State = TransactionAborted
 				delete(transactions, record.TxID)
 			}
+			delete(lastLSNs, record.TxID)
+			report.Aborted++
+
+		case RecordTypeCheckpoint:
+			// Checkpoints carry the highest transaction ID issued before them
+			if len(record.Value) == TxIDSize {
+				if txID := binary.BigEndian.Uint64(record.Value); txID > maxTxID {
+					maxTxID = txID
+				}
+			}
 
-		case RecordTypeWrite:
-			// For write records, ensure the transaction exists if txID > 0
+		case RecordTypeWrite, RecordTypeDelete:
+			// Logs written before begin records existed only reveal a
+			// transaction through its writes, so fall back to inferring it
 			if record.TxID > 0 {
 				if _, exists := transactions[record.TxID]; !exists {
-					tx := &Transaction{
+					transactions[record.TxID] = &Transaction{
 						ID:        record.TxID,
+						LSN:       record.LSN,
 						State:     TransactionActive,
 						StartedAt: time.Now(),
 					}
-					transactions[record.TxID] = tx
-					if record.TxID > maxTxID {
-						maxTxID = record.TxID
-					}
 				}
 			}
 		}
@@ -184,14 +342,25 @@ func (w *WAL) recover() error {
 	// Set the next transaction ID to one more than the highest we've seen
 	if maxTxID > 0 {
 		w.nextTxID = maxTxID + 1
+		atomic.StoreUint64(&w.lastTxID, maxTxID)
 	}
 
-	// Copy active transactions to the WAL's transaction map
+	// Copy active transactions to the WAL's transaction map and report them
+	// as in doubt
 	for txID, tx := range transactions {
 		if tx.State == TransactionActive {
 			w.txns[txID] = tx
+			report.InDoubt = append(report.InDoubt, InDoubtTxn{
+				TxID:     txID,
+				FirstLSN: tx.LSN,
+				LastLSN:  lastLSNs[txID],
+			})
 		}
 	}
+	sort.Slice(report.InDoubt, func(i, j int) bool {
+		return report.InDoubt[i].TxID < report.InDoubt[j].TxID
+	})
+	w.recovery = report
 
 	// Reset the reader again for normal operation
 	if err := w.reader.SeekToStart(); err != nil {
@@ -201,6 +370,15 @@ func (w *WAL) recover() error {
 	return nil
 }
 
+// RecoveryReport returns a summary of the transactions found when the WAL
+// was opened, including any left in doubt by a crash. In-doubt transactions
+// remain active and can still be committed or aborted.
+func (w *WAL) RecoveryReport() RecoveryReport {
+	report := w.recovery
+	report.InDoubt = append([]InDoubtTxn(nil), w.recovery.InDoubt...)
+	return report
+}
+
 // generateLSN generates a new Log Sequence Number.
 func (w *WAL) generateLSN() uint64 {
 	return atomic.AddUint64(&w.lastLSN, 1)
@@ -208,32 +386,92 @@ func (w *WAL) generateLSN() uint64 {
 
 // Write writes a new record to the WAL within the specified transaction.
 // If txID is 0, the write is non-transactional and will be immediately committed.
-// If txID > 0, the write is part of an existing transaction that must be committed separately.
+// If txID > 0, the write is buffered in the transaction and only reaches the
+// log when it commits, so it is isolated from other transactions and an
+// aborted transaction leaves no data in the log. Its LSN is assigned on
+// commit, so Write returns 0 for it.
 func (w *WAL) Write(txID uint64, key, value []byte) (uint64, error) {
+	return w.append(NewWriteRecord(0, txID, key, value))
+}
+
+// Delete writes a tombstone for key within the specified transaction.
+// Transaction semantics are the same as for Write.
+func (w *WAL) Delete(txID uint64, key []byte) (uint64, error) {
+	return w.append(NewDeleteRecord(0, txID, key))
+}
+
+// append assigns the next LSN to a non-transactional data record and makes
+// it durable, or buffers a transactional record in its transaction.
+func (w *WAL) append(record *Record) (uint64, error) {
+	if record.TxID != 0 {
+		return w.buffer(record)
+	}
+	if w.config.GroupCommit {
+		return w.appendGroupCommit(record)
+	}
+
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
 	lsn := w.generateLSN()
-	record := NewWriteRecord(lsn, txID, key, value)
+	record.LSN = lsn
 
-	// If this is a non-transactional write (txID=0), we need to ensure it's durable
-	if txID == 0 {
-		// For non-transactional writes, we write and flush immediately
-		if _, err := w.writer.Write(record); err != nil {
-			return 0, err
-		}
-		if err := w.writer.Flush(); err != nil {
-			return 0, err
-		}
-		return lsn, nil
+	// Non-transactional writes are written and flushed immediately
+	if _, err := w.writer.Write(record); err != nil {
+		return 0, err
+	}
+	if err := w.writer.Flush(); err != nil {
+		return 0, err
 	}
+	return lsn, nil
+}
 
-	// For transactional writes, just write to the log
-	// The transaction must be committed separately
-	return w.writer.Write(record)
+// buffer adds a record to its transaction's buffered records. The key and
+// value are copied, since the caller may reuse them before the commit.
+func (w *WAL) buffer(record *Record) (uint64, error) {
+	w.txnsMu.Lock()
+	defer w.txnsMu.Unlock()
+
+	tx, exists := w.txns[record.TxID]
+	if !exists || tx.State != TransactionActive {
+		return 0, fmt.Errorf("invalid or inactive transaction")
+	}
+
+	record.Key = bytes.Clone(record.Key)
+	record.Value = bytes.Clone(record.Value)
+	tx.Records = append(tx.Records, record)
+	return 0, nil
 }
 
-// Commit commits a transaction.
+// appendGroupCommit writes a non-transactional record and blocks until it
+// has been synced as part of a group commit. w.mu is held only while the
+// record is queued, so that concurrent writers can join the same batch.
+func (w *WAL) appendGroupCommit(record *Record) (uint64, error) {
+	w.mu.Lock()
+	lsn := w.generateLSN()
+	record.LSN = lsn
+
+	// Queue under the writer lock so records reach the log in LSN order
+	w.writer.mu.Lock()
+	defer w.writer.mu.Unlock()
+	batch, err := w.writer.enqueue(record)
+	w.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := w.writer.awaitDurable(batch); err != nil {
+		return 0, fmt.Errorf("group commit failed: %w", err)
+	}
+
+	return lsn, nil
+}
+
+// Commit commits a transaction, writing its buffered records followed by a
+// commit record. The records get consecutive LSNs at this point, so the log
+// stays in LSN order however transactions interleave. If the records can't
+// be written, some of them may already be in the log, so the transaction
+// can't be retried: it is aborted instead and the error is returned.
 func (w *WAL) Commit(txID uint64) error {
 	w.txnsMu.Lock()
 	tx, exists := w.txns[txID]
@@ -246,8 +484,45 @@ func (w *WAL) Commit(txID uint64) error {
 	tx.State = TransactionCommitting
 	w.txnsMu.Unlock()
 
+	// Hold the append lock so no other record is assigned an LSN between
+	// the transaction's records
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := w.writeCommit(tx)
+
+	w.txnsMu.Lock()
+	defer w.txnsMu.Unlock()
+	delete(w.txns, txID)
+	tx.Records = nil
+
+	if err != nil {
+		// Write an abort record so that readers and recovery ignore any of
+		// the transaction's records that reached the log
+		tx.State = TransactionAborted
+		if abortErr := w.writeAbort(txID); abortErr != nil {
+			return fmt.Errorf("%w; transaction %d could not be aborted: %v", err, txID, abortErr)
+		}
+		return fmt.Errorf("%w; transaction %d was aborted", err, txID)
+	}
+
+	// Mark transaction as committed
+	tx.State = TransactionCommitted
+	return nil
+}
+
+// writeCommit writes a transaction's buffered records and its commit record
+// and flushes them. Caller must hold w.mu.
+func (w *WAL) writeCommit(tx *Transaction) error {
+	for _, record := range tx.Records {
+		record.LSN = w.generateLSN()
+		if _, err := w.writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write transaction record: %w", err)
+		}
+	}
+
 	// Write commit record
-	commitRecord := CommitTxnRecord(txID, w.generateLSN())
+	commitRecord := CommitTxnRecord(tx.ID, w.generateLSN())
 	if _, err := w.writer.Write(commitRecord); err != nil {
 		return fmt.Errorf("failed to write commit record: %w", err)
 	}
@@ -256,18 +531,25 @@ func (w *WAL) Commit(txID uint64) error {
 	if err := w.writer.Flush(); err != nil {
 		return fmt.Errorf("failed to flush commit: %w", err)
 	}
-
-	// Mark transaction as committed
-	w.txnsMu.Lock()
-	defer w.txnsMu.Unlock()
-	tx.State = TransactionCommitted
-	delete(w.txns, txID)
-
 	return nil
 }
 
-// Abort aborts a transaction.
+// writeAbort writes and flushes an abort record for a transaction whose
+// commit failed. Caller must hold w.mu.
+func (w *WAL) writeAbort(txID uint64) error {
+	if _, err := w.writer.Write(RollbackTxnRecord(txID, w.generateLSN())); err != nil {
+		return err
+	}
+	return w.writer.Flush()
+}
+
+// Abort aborts a transaction, discarding its buffered records. Only an abort
+// record is written, so recovery knows the transaction finished.
 func (w *WAL) Abort(txID uint64) error {
+	// Hold the append lock so the abort record reaches the log in LSN order
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
 	w.txnsMu.Lock()
 	defer w.txnsMu.Unlock()
 
@@ -284,65 +566,189 @@ func (w *WAL) Abort(txID uint64) error {
 
 	// Mark transaction as aborted and clean up
 	tx.State = TransactionAborted
+	tx.Records = nil
 	delete(w.txns, txID)
 
 	return nil
 }
 
+// Savepoint marks the current position in a transaction so that later
+// writes can be undone with RollbackTo without aborting the transaction.
+// Savepoints only exist in memory, since the records they undo haven't
+// been written to the log.
+func (w *WAL) Savepoint(txID uint64) (SavepointID, error) {
+	w.txnsMu.Lock()
+	defer w.txnsMu.Unlock()
+
+	tx, exists := w.txns[txID]
+	if !exists || tx.State != TransactionActive {
+		return 0, fmt.Errorf("invalid or inactive transaction")
+	}
+
+	sp := SavepointID(len(tx.Records))
+	tx.Savepoints = append(tx.Savepoints, sp)
+	return sp, nil
+}
+
+// RollbackTo discards every record the transaction buffered after savepoint
+// sp, keeping earlier records. The transaction stays active, sp remains
+// valid, and savepoints created after sp are released.
+func (w *WAL) RollbackTo(txID uint64, sp SavepointID) error {
+	w.txnsMu.Lock()
+	defer w.txnsMu.Unlock()
+
+	tx, exists := w.txns[txID]
+	if !exists || tx.State != TransactionActive {
+		return fmt.Errorf("invalid or inactive transaction")
+	}
+
+	idx := -1
+	for i, s := range tx.Savepoints {
+		if s == sp {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return fmt.Errorf("unknown savepoint %d for transaction %d", sp, txID)
+	}
+
+	// Discard the records buffered since sp and release later savepoints
+	tx.Records = tx.Records[:sp]
+	tx.Savepoints = tx.Savepoints[:idx+1]
+	return nil
+}
+
 // ReadAll reads all committed records from the WAL.
+// It collects the output of Iterator into a slice, so memory use grows with
+// the size of the log; prefer Iterator for large logs.
 func (w *WAL) ReadAll() ([]*Record, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	if err := w.reader.SeekToStart(); err != nil {
-		return nil, fmt.Errorf("failed to reset reader: %w", err)
+	it, err := w.Iterator()
+	if err != nil {
+		return nil, err
 	}
+	defer it.Close()
 
-	var (
-		records      []*Record
-		transactions = make(map[uint64]bool) // Tracks transaction commit/abort status
-	)
-
-	// First pass: collect all records and track transaction status
-	var allRecords []*Record
+	var records []*Record
 	for {
-		record, err := w.reader.Next()
+		record, err := it.Next()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to read record: %w", err)
 		}
+		records = append(records, record)
+	}
 
-		allRecords = append(allRecords, record)
+	return records, nil
+}
 
-		switch record.Type {
-		case RecordTypeTxnCommit:
-			transactions[record.TxID] = true // Mark as committed
-		case RecordTypeTxnRollback:
-			transactions[record.TxID] = false // Mark as aborted
-		}
+// Snapshot replays the committed log and returns the latest value for each
+// key. Keys whose most recent committed record is a tombstone are omitted.
+// Buffered records are flushed first, and writers are blocked while it runs.
+func (w *WAL) Snapshot() (map[string][]byte, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.writer.Flush(); err != nil {
+		return nil, err
+	}
+
+	it, err := w.Iterator()
+	if err != nil {
+		return nil, err
 	}
+	defer it.Close()
+
+	snapshot := make(map[string][]byte)
+	for {
+		record, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read record: %w", err)
+		}
 
-	// Second pass: include only records from committed transactions or non-transactional records (txID=0)
-	for _, record := range allRecords {
 		switch record.Type {
 		case RecordTypeWrite:
-			// Include non-transactional records (txID=0) or records from committed transactions
-			if record.TxID == 0 || transactions[record.TxID] {
-				records = append(records, record)
-			}
-		case RecordTypeTxnBegin, RecordTypeTxnCommit, RecordTypeTxnRollback:
-			// Skip transaction control records in the final output
-		default:
-			// Include any other record types with txID=0 (non-transactional)
-			if record.TxID == 0 {
-				records = append(records, record)
-			}
+			snapshot[string(record.Key)] = record.Value
+		case RecordTypeDelete:
+			delete(snapshot, string(record.Key))
 		}
 	}
 
-	return records, nil
+	return snapshot, nil
+}
+
+// Checkpoint writes a checkpoint record and truncates the log by deleting
+// segment files whose records all have an LSN below upToLSN and belong to
+// transactions that have already committed or aborted. Segments are removed
+// oldest first, stopping at the first one that must be kept, and the active
+// segment is never removed. Writers are blocked while truncation runs.
+func (w *WAL) Checkpoint(upToLSN uint64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.txnsMu.Lock()
+	defer w.txnsMu.Unlock()
+
+	// Write checkpoint record
+	record := NewCheckpointRecord(w.generateLSN(), atomic.LoadUint64(&w.lastTxID))
+	if _, err := w.writer.Write(record); err != nil {
+		return fmt.Errorf("failed to write checkpoint record: %w", err)
+	}
+	if err := w.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush checkpoint: %w", err)
+	}
+
+	// Release the reader's open segment before removing files
+	if err := w.reader.Close(); err != nil {
+		return fmt.Errorf("failed to close reader: %w", err)
+	}
+
+	_, err := w.writer.RemoveSegments(func(path string) (bool, error) {
+		return w.segmentReclaimable(path, upToLSN)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to truncate log: %w", err)
+	}
+
+	// Refresh the reader's segment list so deleted files are not reopened
+	if err := w.reader.SeekToStart(); err != nil {
+		return fmt.Errorf("failed to reset reader after checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// segmentReclaimable reports whether every record in the segment at path
+// has an LSN below upToLSN and belongs to a finished transaction.
+// Caller must hold w.txnsMu.
+func (w *WAL) segmentReclaimable(path string, upToLSN uint64) (bool, error) {
+	reader := &LogReader{dir: w.dir, segments: []string{path}}
+	defer reader.Close()
+
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		if record.LSN >= upToLSN {
+			return false, nil
+		}
+		if _, inFlight := w.txns[record.TxID]; record.TxID != 0 && inFlight {
+			return false, nil
+		}
+	}
 }
 
 // Close closes the WAL and releases any resources.
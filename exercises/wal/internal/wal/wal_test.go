@@ -2,9 +2,17 @@ package wal
 
 import (
 	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestWAL_Minimal(t *testing.T) {
@@ -191,7 +199,10 @@ func TestWAL_Transactions(t *testing.T) {
 
 	// Test 1: Simple transaction with single write
 	t.Run("SingleWriteTransaction", func(t *testing.T) {
-		txID := wal.Begin()
+		txID, err := wal.Begin()
+		if err != nil {
+			t.Fatalf("Failed to begin transaction: %v", err)
+		}
 		t.Logf("Started transaction: %d", txID)
 
 		// Write a record in the transaction
@@ -226,7 +237,10 @@ func TestWAL_Transactions(t *testing.T) {
 
 	// Test 2: Transaction with multiple writes
 	t.Run("MultiWriteTransaction", func(t *testing.T) {
-		txID := wal.Begin()
+		txID, err := wal.Begin()
+		if err != nil {
+			t.Fatalf("Failed to begin transaction: %v", err)
+		}
 		t.Logf("Started transaction: %d", txID)
 
 		// Write multiple records in the transaction
@@ -264,13 +278,16 @@ func TestWAL_Transactions(t *testing.T) {
 
 	// Test 3: Aborted transaction
 	t.Run("AbortedTransaction", func(t *testing.T) {
-		txID := wal.Begin()
+		txID, err := wal.Begin()
+		if err != nil {
+			t.Fatalf("Failed to begin transaction: %v", err)
+		}
 		t.Logf("Started transaction: %d (will abort)", txID)
 
 		// Write a record that will be aborted
 		key := []byte("aborted-key")
 		value := []byte("aborted-value")
-		_, err := wal.Write(txID, key, value)
+		_, err = wal.Write(txID, key, value)
 		if err != nil {
 			t.Fatalf("Failed to write to WAL: %v", err)
 		}
@@ -392,3 +409,1615 @@ func TestWAL_Recovery(t *testing.T) {
 	}
 
 }
+
+func TestWAL_BeginWithoutWritesSurvivesReopen(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "wal-begin-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	config := &Config{
+		Dir:         tempDir,
+		Sync:        true,
+		SegmentSize: 1024 * 1024, // 1MB segments
+	}
+
+	wal, err := Open(config)
+	if err != nil {
+		t.Fatalf("Failed to open WAL: %v", err)
+	}
+
+	txID, err := wal.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+
+	// Simulate a crash: close without committing or aborting
+	if err := wal.Close(); err != nil {
+		t.Fatalf("Failed to close WAL: %v", err)
+	}
+
+	wal, err = Open(config)
+	if err != nil {
+		t.Fatalf("Failed to reopen WAL: %v", err)
+	}
+	defer wal.Close()
+
+	active := wal.ActiveTransactions()
+	if len(active) != 1 || active[0] != txID {
+		t.Fatalf("Expected active transactions [%d], got %v", txID, active)
+	}
+
+	// New transactions must not reuse the recovered ID
+	nextID, err := wal.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	if nextID <= txID {
+		t.Errorf("Expected transaction ID greater than %d, got %d", txID, nextID)
+	}
+
+	if err := wal.Abort(txID); err != nil {
+		t.Fatalf("Failed to abort recovered transaction: %v", err)
+	}
+}
+
+func TestWAL_LargeValue(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "wal-large-value-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	config := &Config{
+		Dir:         tempDir,
+		Sync:        true,
+		SegmentSize: 1024 * 1024, // 1MB segments
+	}
+
+	wal, err := Open(config)
+	if err != nil {
+		t.Fatalf("Failed to open WAL: %v", err)
+	}
+
+	key := []byte("large-key")
+	value := make([]byte, 200*1024) // 200KB, well past the old 64KB limit
+	for i := range value {
+		value[i] = byte(i % 251)
+	}
+
+	if _, err := wal.Write(0, key, value); err != nil {
+		t.Fatalf("Failed to write to WAL: %v", err)
+	}
+	if err := wal.Close(); err != nil {
+		t.Fatalf("Failed to close WAL: %v", err)
+	}
+
+	wal, err = Open(config)
+	if err != nil {
+		t.Fatalf("Failed to reopen WAL: %v", err)
+	}
+	defer wal.Close()
+
+	records, err := wal.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read from WAL: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if !bytes.Equal(records[0].Key, key) {
+		t.Errorf("Expected key %s, got %s", key, records[0].Key)
+	}
+	if !bytes.Equal(records[0].Value, value) {
+		t.Errorf("Value mismatch: expected %d bytes, got %d bytes", len(value), len(records[0].Value))
+	}
+}
+
+func TestWAL_Checkpoint(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "wal-checkpoint-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Small segments so that the writes below span many files
+	config := &Config{
+		Dir:         tempDir,
+		Sync:        true,
+		SegmentSize: 512,
+	}
+
+	wal, err := Open(config)
+	if err != nil {
+		t.Fatalf("Failed to open WAL: %v", err)
+	}
+
+	// A transaction that stays open pins the segment holding its records
+	pinnedTx, err := wal.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	if _, err := wal.Write(pinnedTx, []byte("pinned-key"), []byte("pinned-value")); err != nil {
+		t.Fatalf("Failed to write to WAL: %v", err)
+	}
+
+	value := make([]byte, 100)
+	lsns := make([]uint64, 0, 20)
+	for i := 0; i < 20; i++ {
+		lsn, err := wal.Write(0, []byte{'k', byte('A' + i)}, value)
+		if err != nil {
+			t.Fatalf("Failed to write to WAL: %v", err)
+		}
+		lsns = append(lsns, lsn)
+	}
+
+	countSegments := func() int {
+		files, err := filepath.Glob(filepath.Join(tempDir, "*.wal"))
+		if err != nil {
+			t.Fatalf("Failed to list segment files: %v", err)
+		}
+		return len(files)
+	}
+
+	t.Run("PinnedByActiveTransaction", func(t *testing.T) {
+		before := countSegments()
+		if err := wal.Checkpoint(lsns[15]); err != nil {
+			t.Fatalf("Checkpoint failed: %v", err)
+		}
+		if after := countSegments(); after != before {
+			t.Errorf("Expected no segments removed while a transaction is active, had %d, now %d", before, after)
+		}
+	})
+
+	t.Run("TruncatesMidStream", func(t *testing.T) {
+		if err := wal.Commit(pinnedTx); err != nil {
+			t.Fatalf("Failed to commit transaction: %v", err)
+		}
+
+		before := countSegments()
+		if err := wal.Checkpoint(lsns[15]); err != nil {
+			t.Fatalf("Checkpoint failed: %v", err)
+		}
+		if after := countSegments(); after >= before {
+			t.Fatalf("Expected segments to be removed, had %d, now %d", before, after)
+		}
+
+		records, err := wal.ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to read from WAL after checkpoint: %v", err)
+		}
+		if len(records) == 0 || len(records) >= 21 {
+			t.Fatalf("Expected a truncated record set, got %d records", len(records))
+		}
+
+		// Everything at or after the checkpoint LSN must survive
+		for _, lsn := range lsns[15:] {
+			found := false
+			for _, r := range records {
+				if r.LSN == lsn {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("Record with LSN %d was removed by checkpoint", lsn)
+			}
+		}
+	})
+
+	lastLSN, err := wal.Write(0, []byte("after-checkpoint"), []byte("value"))
+	if err != nil {
+		t.Fatalf("Failed to write to WAL: %v", err)
+	}
+	want, err := wal.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read from WAL: %v", err)
+	}
+	if err := wal.Close(); err != nil {
+		t.Fatalf("Failed to close WAL: %v", err)
+	}
+
+	t.Run("RecoveryAfterCheckpoint", func(t *testing.T) {
+		wal, err := Open(config)
+		if err != nil {
+			t.Fatalf("Failed to reopen WAL: %v", err)
+		}
+		defer wal.Close()
+
+		records, err := wal.ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to read from WAL: %v", err)
+		}
+		if len(records) != len(want) {
+			t.Fatalf("Expected %d records after recovery, got %d", len(want), len(records))
+		}
+
+		lsn, err := wal.Write(0, []byte("after-recovery"), []byte("value"))
+		if err != nil {
+			t.Fatalf("Failed to write to WAL: %v", err)
+		}
+		if lsn <= lastLSN {
+			t.Errorf("Expected LSN greater than %d after recovery, got %d", lastLSN, lsn)
+		}
+
+		txID, err := wal.Begin()
+		if err != nil {
+			t.Fatalf("Failed to begin transaction: %v", err)
+		}
+		if txID <= pinnedTx {
+			t.Errorf("Expected transaction ID greater than %d after recovery, got %d", pinnedTx, txID)
+		}
+	})
+}
+
+// openBenchWAL opens a WAL in a temporary directory pre-populated with n
+// committed records, half of them written inside transactions.
+func openBenchWAL(b *testing.B, n int) *WAL {
+	b.Helper()
+
+	config := &Config{
+		Dir:         b.TempDir(),
+		SegmentSize: 16 * 1024 * 1024, // 16MB segments
+	}
+
+	wal, err := Open(config)
+	if err != nil {
+		b.Fatalf("Failed to open WAL: %v", err)
+	}
+	b.Cleanup(func() { wal.Close() })
+
+	value := make([]byte, 128)
+	for i := 0; i < n/2; i++ {
+		if _, err := wal.Write(0, []byte("key"), value); err != nil {
+			b.Fatalf("Failed to write to WAL: %v", err)
+		}
+
+		txID, err := wal.Begin()
+		if err != nil {
+			b.Fatalf("Failed to begin transaction: %v", err)
+		}
+		if _, err := wal.Write(txID, []byte("tx-key"), value); err != nil {
+			b.Fatalf("Failed to write to WAL: %v", err)
+		}
+		if err := wal.Commit(txID); err != nil {
+			b.Fatalf("Failed to commit transaction: %v", err)
+		}
+	}
+
+	return wal
+}
+
+func BenchmarkReadAll(b *testing.B) {
+	wal := openBenchWAL(b, 10000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := wal.ReadAll(); err != nil {
+			b.Fatalf("ReadAll failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkIterator(b *testing.B) {
+	wal := openBenchWAL(b, 10000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		it, err := wal.Iterator()
+		if err != nil {
+			b.Fatalf("Iterator failed: %v", err)
+		}
+		for {
+			if _, err := it.Next(); err == io.EOF {
+				break
+			} else if err != nil {
+				b.Fatalf("Next failed: %v", err)
+			}
+		}
+		it.Close()
+	}
+}
+
+func TestWAL_ReadFrom(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "wal-readfrom-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Small segments so the records span many files
+	config := &Config{
+		Dir:         tempDir,
+		SegmentSize: 4 * 1024,
+	}
+
+	wal, err := Open(config)
+	if err != nil {
+		t.Fatalf("Failed to open WAL: %v", err)
+	}
+	defer wal.Close()
+
+	lsns := make([]uint64, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		lsn, err := wal.Write(0, []byte(fmt.Sprintf("key-%d", i)), []byte(fmt.Sprintf("value-%d", i)))
+		if err != nil {
+			t.Fatalf("Failed to write to WAL: %v", err)
+		}
+		lsns = append(lsns, lsn)
+	}
+
+	files, err := filepath.Glob(filepath.Join(tempDir, "*.wal"))
+	if err != nil {
+		t.Fatalf("Failed to list segment files: %v", err)
+	}
+	if len(files) < 3 {
+		t.Fatalf("Expected records to span several segments, got %d", len(files))
+	}
+
+	collect := func(lsn uint64) []*Record {
+		it, err := wal.ReadFrom(lsn)
+		if err != nil {
+			t.Fatalf("ReadFrom(%d) failed: %v", lsn, err)
+		}
+		defer it.Close()
+
+		var records []*Record
+		for {
+			record, err := it.Next()
+			if err == io.EOF {
+				return records
+			}
+			if err != nil {
+				t.Fatalf("Next failed: %v", err)
+			}
+			records = append(records, record)
+		}
+	}
+
+	t.Run("Middle", func(t *testing.T) {
+		start := 517
+		records := collect(lsns[start])
+		if len(records) != len(lsns)-start {
+			t.Fatalf("Expected %d records, got %d", len(lsns)-start, len(records))
+		}
+		for i, r := range records {
+			if r.LSN != lsns[start+i] {
+				t.Fatalf("Record %d: expected LSN %d, got %d", i, lsns[start+i], r.LSN)
+			}
+			if want := fmt.Sprintf("key-%d", start+i); string(r.Key) != want {
+				t.Fatalf("Record %d: expected key %s, got %s", i, want, r.Key)
+			}
+		}
+	})
+
+	t.Run("PastEnd", func(t *testing.T) {
+		if records := collect(lsns[len(lsns)-1] + 1); len(records) != 0 {
+			t.Errorf("Expected no records past the end of the log, got %d", len(records))
+		}
+	})
+}
+
+func TestWAL_GroupCommit(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "wal-group-commit-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	config := &Config{
+		Dir:         tempDir,
+		Sync:        true,
+		SegmentSize: 64 * 1024, // 64KB segments so rotation happens mid-run
+		GroupCommit: true,
+	}
+
+	wal, err := Open(config)
+	if err != nil {
+		t.Fatalf("Failed to open WAL: %v", err)
+	}
+
+	const writers, perWriter = 16, 100
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < perWriter; j++ {
+				key := []byte(fmt.Sprintf("writer-%d-key-%d", i, j))
+				if _, err := wal.Write(0, key, []byte("value")); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Concurrent write failed: %v", err)
+	}
+
+	if err := wal.Close(); err != nil {
+		t.Fatalf("Failed to close WAL: %v", err)
+	}
+
+	wal, err = Open(config)
+	if err != nil {
+		t.Fatalf("Failed to reopen WAL: %v", err)
+	}
+	defer wal.Close()
+
+	records, err := wal.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read from WAL: %v", err)
+	}
+	if len(records) != writers*perWriter {
+		t.Fatalf("Expected %d records, got %d", writers*perWriter, len(records))
+	}
+
+	// Records must reach the log in LSN order
+	for i := 1; i < len(records); i++ {
+		if records[i].LSN <= records[i-1].LSN {
+			t.Fatalf("Records out of order: LSN %d follows LSN %d", records[i].LSN, records[i-1].LSN)
+		}
+	}
+}
+
+func BenchmarkConcurrentWrites(b *testing.B) {
+	const writers = 16
+
+	for _, groupCommit := range []bool{false, true} {
+		b.Run(fmt.Sprintf("GroupCommit=%t", groupCommit), func(b *testing.B) {
+			config := &Config{
+				Dir:         b.TempDir(),
+				Sync:        true,
+				GroupCommit: groupCommit,
+			}
+
+			wal, err := Open(config)
+			if err != nil {
+				b.Fatalf("Failed to open WAL: %v", err)
+			}
+			defer wal.Close()
+
+			key, value := []byte("key"), make([]byte, 128)
+
+			b.ResetTimer()
+			var wg sync.WaitGroup
+			for i := 0; i < writers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					for j := i; j < b.N; j += writers {
+						if _, err := wal.Write(0, key, value); err != nil {
+							b.Errorf("Write failed: %v", err)
+							return
+						}
+					}
+				}(i)
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func TestWAL_Snapshot(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "wal-snapshot-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	config := &Config{
+		Dir:         tempDir,
+		Sync:        true,
+		SegmentSize: 1024 * 1024, // 1MB segments
+	}
+
+	wal, err := Open(config)
+	if err != nil {
+		t.Fatalf("Failed to open WAL: %v", err)
+	}
+	defer wal.Close()
+
+	mustWrite := func(txID uint64, key, value string) {
+		if _, err := wal.Write(txID, []byte(key), []byte(value)); err != nil {
+			t.Fatalf("Failed to write %s: %v", key, err)
+		}
+	}
+	mustDelete := func(txID uint64, key string) {
+		if _, err := wal.Delete(txID, []byte(key)); err != nil {
+			t.Fatalf("Failed to delete %s: %v", key, err)
+		}
+	}
+	mustBegin := func() uint64 {
+		txID, err := wal.Begin()
+		if err != nil {
+			t.Fatalf("Failed to begin transaction: %v", err)
+		}
+		return txID
+	}
+
+	// Overwrite then read
+	mustWrite(0, "overwritten", "v1")
+	mustWrite(0, "overwritten", "v2")
+
+	// Delete then read
+	mustWrite(0, "deleted", "v1")
+	mustDelete(0, "deleted")
+
+	// Delete then re-create
+	mustWrite(0, "recreated", "v1")
+	mustDelete(0, "recreated")
+	mustWrite(0, "recreated", "v2")
+
+	// Delete inside a committed transaction
+	mustWrite(0, "committed-delete", "v1")
+	txID := mustBegin()
+	mustDelete(txID, "committed-delete")
+	if err := wal.Commit(txID); err != nil {
+		t.Fatalf("Failed to commit transaction: %v", err)
+	}
+
+	// Delete inside an aborted transaction
+	mustWrite(0, "aborted-delete", "v1")
+	txID = mustBegin()
+	mustDelete(txID, "aborted-delete")
+	if err := wal.Abort(txID); err != nil {
+		t.Fatalf("Failed to abort transaction: %v", err)
+	}
+
+	snapshot, err := wal.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	want := map[string]string{
+		"overwritten":    "v2",
+		"recreated":      "v2",
+		"aborted-delete": "v1",
+	}
+	if len(snapshot) != len(want) {
+		t.Errorf("Expected %d keys, got %d: %v", len(want), len(snapshot), snapshot)
+	}
+	for key, value := range want {
+		if got, ok := snapshot[key]; !ok || string(got) != value {
+			t.Errorf("Key %s: expected %q, got %q (present=%t)", key, value, got, ok)
+		}
+	}
+	for _, key := range []string{"deleted", "committed-delete"} {
+		if _, ok := snapshot[key]; ok {
+			t.Errorf("Expected key %s to be deleted", key)
+		}
+	}
+}
+
+func TestRecord_ChecksumCoversHeader(t *testing.T) {
+	record := NewWriteRecord(42, 7, []byte("key"), []byte("value"))
+	data, err := record.Encode()
+	if err != nil {
+		t.Fatalf("Failed to encode record: %v", err)
+	}
+	if record.Flags != 0 {
+		t.Errorf("Encode changed the record's flags to %#x", record.Flags)
+	}
+
+	var decoded Record
+	if err := decoded.Decode(data); err != nil {
+		t.Fatalf("Failed to decode intact record: %v", err)
+	}
+	if decoded.Flags != FlagHeaderChecksum {
+		t.Errorf("Expected decoded flags %#x, got %#x", FlagHeaderChecksum, decoded.Flags)
+	}
+	if decoded.LSN != 42 || decoded.TxID != 7 {
+		t.Fatalf("Decoded header mismatch: LSN=%d, TxID=%d", decoded.LSN, decoded.TxID)
+	}
+
+	// Flip a byte in the TxID field (bytes 8-15)
+	corrupted := append([]byte(nil), data...)
+	corrupted[LSNSize+TxIDSize-1] ^= 0xFF
+
+	if err := decoded.Decode(corrupted); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("Expected ErrChecksumMismatch for corrupted TxID, got %v", err)
+	}
+}
+
+func TestRecord_RequiresHeaderChecksumFlag(t *testing.T) {
+	record := NewWriteRecord(1, 0, []byte("key"), []byte("value"))
+	data, err := record.Encode()
+	if err != nil {
+		t.Fatalf("Failed to encode record: %v", err)
+	}
+
+	// Clear the flag, as a flipped bit would, and fix up the checksum so
+	// only the missing flag is wrong
+	data[LSNSize+TxIDSize+1] = 0
+	binary.BigEndian.PutUint32(data[checksumOffset:], recordChecksum(data))
+
+	var decoded Record
+	if err := decoded.Decode(data); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("Expected ErrChecksumMismatch for a record without the flag, got %v", err)
+	}
+}
+
+func TestWAL_RecoveryReport(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "wal-recovery-report-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	config := &Config{
+		Dir:         tempDir,
+		Sync:        true,
+		SegmentSize: 1024 * 1024, // 1MB segments
+	}
+
+	wal, err := Open(config)
+	if err != nil {
+		t.Fatalf("Failed to open WAL: %v", err)
+	}
+
+	runTx := func(finish func(uint64) error) uint64 {
+		txID, err := wal.Begin()
+		if err != nil {
+			t.Fatalf("Failed to begin transaction: %v", err)
+		}
+		if _, err := wal.Write(txID, []byte("key"), []byte("value")); err != nil {
+			t.Fatalf("Failed to write to WAL: %v", err)
+		}
+		if finish != nil {
+			if err := finish(txID); err != nil {
+				t.Fatalf("Failed to finish transaction %d: %v", txID, err)
+			}
+		}
+		return txID
+	}
+
+	runTx(wal.Commit)
+	runTx(wal.Commit)
+	runTx(wal.Abort)
+
+	// The in-doubt transaction's writes are buffered, so only its begin
+	// record reaches the log
+	inDoubt := runTx(nil)
+	if _, err := wal.Write(inDoubt, []byte("key2"), []byte("value2")); err != nil {
+		t.Fatalf("Failed to write to WAL: %v", err)
+	}
+	stats, err := wal.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	lastLSN := stats.LastLSN
+
+	// Simulate a crash: close without finishing the last transaction
+	if err := wal.Close(); err != nil {
+		t.Fatalf("Failed to close WAL: %v", err)
+	}
+
+	wal, err = Open(config)
+	if err != nil {
+		t.Fatalf("Failed to reopen WAL: %v", err)
+	}
+	defer wal.Close()
+
+	report := wal.RecoveryReport()
+	if report.Committed != 2 {
+		t.Errorf("Expected 2 committed transactions, got %d", report.Committed)
+	}
+	if report.Aborted != 1 {
+		t.Errorf("Expected 1 aborted transaction, got %d", report.Aborted)
+	}
+	if len(report.InDoubt) != 1 {
+		t.Fatalf("Expected 1 in-doubt transaction, got %d", len(report.InDoubt))
+	}
+
+	txn := report.InDoubt[0]
+	if txn.TxID != inDoubt {
+		t.Errorf("Expected in-doubt transaction %d, got %d", inDoubt, txn.TxID)
+	}
+	if txn.FirstLSN != txn.LastLSN {
+		t.Errorf("Expected FirstLSN == LastLSN, got %d and %d", txn.FirstLSN, txn.LastLSN)
+	}
+	if txn.LastLSN != lastLSN {
+		t.Errorf("Expected LastLSN %d, got %d", lastLSN, txn.LastLSN)
+	}
+}
+
+func TestWAL_Savepoints(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "wal-savepoint-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	config := &Config{
+		Dir:         tempDir,
+		Sync:        true,
+		SegmentSize: 1024 * 1024, // 1MB segments
+	}
+
+	wal, err := Open(config)
+	if err != nil {
+		t.Fatalf("Failed to open WAL: %v", err)
+	}
+	defer wal.Close()
+
+	txID, err := wal.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	if _, err := wal.Write(txID, []byte("A"), []byte("a")); err != nil {
+		t.Fatalf("Failed to write A: %v", err)
+	}
+
+	sp1, err := wal.Savepoint(txID)
+	if err != nil {
+		t.Fatalf("Failed to create savepoint: %v", err)
+	}
+	if _, err := wal.Write(txID, []byte("B"), []byte("b")); err != nil {
+		t.Fatalf("Failed to write B: %v", err)
+	}
+
+	sp2, err := wal.Savepoint(txID)
+	if err != nil {
+		t.Fatalf("Failed to create savepoint: %v", err)
+	}
+	if _, err := wal.Delete(txID, []byte("A")); err != nil {
+		t.Fatalf("Failed to delete A: %v", err)
+	}
+
+	if err := wal.RollbackTo(txID, sp1); err != nil {
+		t.Fatalf("Failed to roll back to savepoint: %v", err)
+	}
+
+	// Rolling back to sp1 releases the later savepoint
+	if err := wal.RollbackTo(txID, sp2); err == nil {
+		t.Error("Expected error rolling back to a released savepoint")
+	}
+
+	// Writes after the rollback are kept
+	if _, err := wal.Write(txID, []byte("C"), []byte("c")); err != nil {
+		t.Fatalf("Failed to write C: %v", err)
+	}
+
+	if err := wal.Commit(txID); err != nil {
+		t.Fatalf("Failed to commit transaction: %v", err)
+	}
+
+	records, err := wal.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read from WAL: %v", err)
+	}
+	var keys []string
+	for _, r := range records {
+		keys = append(keys, string(r.Key))
+	}
+	if len(keys) != 2 || keys[0] != "A" || keys[1] != "C" {
+		t.Errorf("Expected records [A C], got %v", keys)
+	}
+
+	snapshot, err := wal.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if string(snapshot["A"]) != "a" || string(snapshot["C"]) != "c" || len(snapshot) != 2 {
+		t.Errorf("Expected snapshot {A:a C:c}, got %v", snapshot)
+	}
+}
+
+func TestWAL_SyncModes(t *testing.T) {
+	tests := []struct {
+		name string
+		mode SyncMode
+		// durableAfterWrite is whether a write is reported durable as soon as it returns
+		durableAfterWrite bool
+		// durableOnTick is whether the background flusher makes writes durable
+		durableOnTick bool
+	}{
+		{"Never", SyncNever, false, false},
+		{"Interval", SyncInterval, false, true},
+		{"Always", SyncAlways, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var durable atomic.Uint64
+			config := &Config{
+				Dir:           t.TempDir(),
+				SyncMode:      tt.mode,
+				FlushInterval: 10 * time.Millisecond,
+				OnFlush:       func(lsn uint64) { durable.Store(lsn) },
+			}
+
+			wal, err := Open(config)
+			if err != nil {
+				t.Fatalf("Failed to open WAL: %v", err)
+			}
+			defer wal.Close()
+
+			// waitDurable polls for the durable LSN to reach lsn
+			waitDurable := func(lsn uint64) bool {
+				deadline := time.Now().Add(time.Second)
+				for time.Now().Before(deadline) {
+					if durable.Load() >= lsn {
+						return true
+					}
+					time.Sleep(5 * time.Millisecond)
+				}
+				return false
+			}
+
+			lsn, err := wal.Write(0, []byte("key1"), []byte("value1"))
+			if err != nil {
+				t.Fatalf("Failed to write to WAL: %v", err)
+			}
+			if got := durable.Load() >= lsn; got != tt.durableAfterWrite {
+				t.Errorf("Durable immediately after write = %t, want %t", got, tt.durableAfterWrite)
+			}
+			if got := waitDurable(lsn); got != tt.durableOnTick {
+				t.Errorf("Durable after flush interval = %t, want %t", got, tt.durableOnTick)
+			}
+
+			// Kill the flusher: with no more ticks, only SyncAlways keeps
+			// new writes out of the data-loss window
+			wal.writer.flushTicker.Stop()
+			before := durable.Load()
+
+			lsn, err = wal.Write(0, []byte("key2"), []byte("value2"))
+			if err != nil {
+				t.Fatalf("Failed to write to WAL: %v", err)
+			}
+			time.Sleep(5 * config.FlushInterval)
+
+			switch got := durable.Load(); tt.mode {
+			case SyncAlways:
+				if got != lsn {
+					t.Errorf("Expected durable LSN %d with flusher stopped, got %d", lsn, got)
+				}
+			default:
+				if got != before {
+					t.Errorf("Expected durable LSN to stay at %d with flusher stopped, got %d", before, got)
+				}
+			}
+		})
+	}
+}
+
+func TestConfig_SyncModeFromDeprecatedSync(t *testing.T) {
+	if got := (&Config{Sync: true}).syncMode(); got != SyncAlways {
+		t.Errorf("Sync=true: expected %v, got %v", SyncAlways, got)
+	}
+	if got := (&Config{}).syncMode(); got != SyncNever {
+		t.Errorf("Sync=false: expected %v, got %v", SyncNever, got)
+	}
+	if got := (&Config{Sync: true, SyncMode: SyncInterval}).syncMode(); got != SyncInterval {
+		t.Errorf("SyncMode set: expected %v, got %v", SyncInterval, got)
+	}
+}
+
+func TestWAL_Compression(t *testing.T) {
+	// Highly repetitive values compress well
+	value := bytes.Repeat([]byte("compressible-value-"), 50)
+
+	// writeRecords writes n records to a fresh WAL and returns its directory
+	// along with the total size of its segment files
+	writeRecords := func(t *testing.T, compression Compression, n int) (string, int64) {
+		dir := t.TempDir()
+		wal, err := Open(&Config{
+			Dir:         dir,
+			SegmentSize: 64 * 1024, // Small segments so rotation is exercised
+			Compression: compression,
+		})
+		if err != nil {
+			t.Fatalf("Failed to open WAL: %v", err)
+		}
+
+		for i := 0; i < n; i++ {
+			if _, err := wal.Write(0, []byte(fmt.Sprintf("key-%d", i)), value); err != nil {
+				t.Fatalf("Failed to write to WAL: %v", err)
+			}
+		}
+		if err := wal.Close(); err != nil {
+			t.Fatalf("Failed to close WAL: %v", err)
+		}
+
+		files, err := filepath.Glob(filepath.Join(dir, "*.wal"))
+		if err != nil {
+			t.Fatalf("Failed to list segment files: %v", err)
+		}
+		var size int64
+		for _, f := range files {
+			info, err := os.Stat(f)
+			if err != nil {
+				t.Fatalf("Failed to stat segment: %v", err)
+			}
+			size += info.Size()
+		}
+		return dir, size
+	}
+
+	const n = 500
+	_, uncompressedSize := writeRecords(t, CompressionNone, n)
+
+	for _, compression := range []Compression{CompressionGzip, CompressionSnappy} {
+		t.Run(string(compression), func(t *testing.T) {
+			dir, size := writeRecords(t, compression, n)
+			if size*2 > uncompressedSize {
+				t.Errorf("Expected %s to at least halve the log size, got %d bytes vs %d uncompressed",
+					compression, size, uncompressedSize)
+			}
+
+			// Reopen without compression: existing segments are still
+			// decompressed, and new segments are written uncompressed
+			wal, err := Open(&Config{Dir: dir})
+			if err != nil {
+				t.Fatalf("Failed to reopen WAL: %v", err)
+			}
+			defer wal.Close()
+
+			if _, err := wal.Write(0, []byte("plain"), []byte("value")); err != nil {
+				t.Fatalf("Failed to write to WAL: %v", err)
+			}
+
+			records, err := wal.ReadAll()
+			if err != nil {
+				t.Fatalf("Failed to read from WAL: %v", err)
+			}
+			if len(records) != n+1 {
+				t.Fatalf("Expected %d records, got %d", n+1, len(records))
+			}
+			for i, r := range records[:n] {
+				if want := fmt.Sprintf("key-%d", i); string(r.Key) != want || !bytes.Equal(r.Value, value) {
+					t.Fatalf("Record %d mismatch: key=%s", i, r.Key)
+				}
+			}
+			if string(records[n].Key) != "plain" {
+				t.Errorf("Expected last record key plain, got %s", records[n].Key)
+			}
+
+			// Seeking into compressed segments uses their first record
+			it, err := wal.ReadFrom(records[n/2].LSN)
+			if err != nil {
+				t.Fatalf("ReadFrom failed: %v", err)
+			}
+			defer it.Close()
+			record, err := it.Next()
+			if err != nil {
+				t.Fatalf("Next failed: %v", err)
+			}
+			if record.LSN != records[n/2].LSN {
+				t.Errorf("Expected LSN %d, got %d", records[n/2].LSN, record.LSN)
+			}
+		})
+	}
+
+	t.Run("Oversized snappy block", func(t *testing.T) {
+		// A snappy block starts with its decoded length as a varint; claim
+		// far more than the block header allows
+		block := binary.AppendUvarint(nil, 1<<30)
+		block = append(block, 0)
+		if _, err := decompressBlock(CompressionSnappy, block, maxBlockSize); !errors.Is(err, ErrCorruptLog) {
+			t.Errorf("Expected ErrCorruptLog, got %v", err)
+		}
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		if _, err := Open(&Config{Dir: t.TempDir(), Compression: "lz4"}); err == nil {
+			t.Error("Expected error for unsupported compression")
+		}
+	})
+}
+
+func TestWAL_Stats(t *testing.T) {
+	dir := t.TempDir()
+	wal, err := Open(&Config{
+		Dir:           dir,
+		SegmentSize:   256, // Small segments so a few records rotate
+		SyncMode:      SyncNever,
+		FlushInterval: time.Hour, // Keep the flusher out of the buffer
+	})
+	if err != nil {
+		t.Fatalf("Failed to open WAL: %v", err)
+	}
+	defer wal.Close()
+
+	stats, err := wal.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.SegmentID != 1 || stats.SegmentCount != 1 || stats.DiskBytes != 0 || stats.LastLSN != 0 {
+		t.Errorf("Unexpected stats for an empty WAL: %+v", stats)
+	}
+
+	// Write until the segment rotates
+	var lastLSN uint64
+	for i := 0; stats.SegmentID == 1; i++ {
+		if lastLSN, err = wal.Write(0, []byte(fmt.Sprintf("key-%d", i)), []byte("value")); err != nil {
+			t.Fatalf("Failed to write to WAL: %v", err)
+		}
+		if stats, err = wal.Stats(); err != nil {
+			t.Fatalf("Stats failed: %v", err)
+		}
+	}
+	if stats.SegmentID != 2 || stats.SegmentCount != 2 {
+		t.Errorf("Expected to be writing segment 2 of 2, got segment %d of %d", stats.SegmentID, stats.SegmentCount)
+	}
+	if stats.LastLSN != lastLSN {
+		t.Errorf("Expected last LSN %d, got %d", lastLSN, stats.LastLSN)
+	}
+
+	// The begin record stays in the writer's buffer until it is flushed,
+	// and the transaction's write isn't assigned an LSN until it commits
+	txID, err := wal.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	lastLSN++
+	if _, err = wal.Write(txID, []byte("tx-key"), []byte("tx-value")); err != nil {
+		t.Fatalf("Failed to write to WAL: %v", err)
+	}
+
+	stats, err = wal.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.ActiveTransactions != 1 {
+		t.Errorf("Expected 1 active transaction, got %d", stats.ActiveTransactions)
+	}
+	if stats.LastLSN != lastLSN {
+		t.Errorf("Expected last LSN %d, got %d", lastLSN, stats.LastLSN)
+	}
+	if stats.BufferedBytes == 0 {
+		t.Error("Expected the begin record to be buffered")
+	}
+
+	var diskBytes int64
+	files, err := filepath.Glob(filepath.Join(dir, "*.wal"))
+	if err != nil {
+		t.Fatalf("Failed to list segment files: %v", err)
+	}
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			t.Fatalf("Failed to stat segment: %v", err)
+		}
+		diskBytes += info.Size()
+	}
+	if stats.DiskBytes != diskBytes {
+		t.Errorf("Expected %d bytes on disk, got %d", diskBytes, stats.DiskBytes)
+	}
+
+	if err := wal.Commit(txID); err != nil {
+		t.Fatalf("Failed to commit transaction: %v", err)
+	}
+	stats, err = wal.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.ActiveTransactions != 0 || stats.BufferedBytes != 0 {
+		t.Errorf("Expected no active transactions or buffered bytes after commit, got %+v", stats)
+	}
+}
+
+func TestWAL_Verify(t *testing.T) {
+	dir := t.TempDir()
+	wal, err := Open(&Config{
+		Dir:         dir,
+		SegmentSize: 512, // Small segments so the records span several
+	})
+	if err != nil {
+		t.Fatalf("Failed to open WAL: %v", err)
+	}
+	defer wal.Close()
+
+	const n = 30
+	for i := 0; i < n; i++ {
+		if _, err := wal.Write(0, []byte(fmt.Sprintf("key-%02d", i)), []byte("value")); err != nil {
+			t.Fatalf("Failed to write to WAL: %v", err)
+		}
+	}
+
+	report, err := wal.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !report.OK() || report.GoodRecords != n {
+		t.Fatalf("Expected %d good records and none bad, got %+v", n, report)
+	}
+	if len(report.Segments) < 3 {
+		t.Fatalf("Expected records to span at least 3 segments, got %d", len(report.Segments))
+	}
+	for _, s := range report.Segments {
+		if s.FirstBadOffset != -1 {
+			t.Errorf("Expected segment %d to be intact, got first bad offset %d", s.SegmentID, s.FirstBadOffset)
+		}
+	}
+
+	// Flip a byte in the key of the second record of the second segment
+	damaged := report.Segments[1]
+	recordSize := int64(HeaderSize + len("key-00") + len("value"))
+	data, err := os.ReadFile(damaged.Path)
+	if err != nil {
+		t.Fatalf("Failed to read segment: %v", err)
+	}
+	data[recordSize+HeaderSize] ^= 0xff
+	if err := os.WriteFile(damaged.Path, data, 0644); err != nil {
+		t.Fatalf("Failed to write segment: %v", err)
+	}
+
+	report, err = wal.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if report.OK() || report.BadRecords != 1 || report.GoodRecords != n-1 {
+		t.Fatalf("Expected 1 bad and %d good records, got %d bad and %d good",
+			n-1, report.BadRecords, report.GoodRecords)
+	}
+	for _, s := range report.Segments {
+		if s.SegmentID != damaged.SegmentID {
+			if s.BadRecords != 0 {
+				t.Errorf("Expected segment %d to be intact, got %d bad records", s.SegmentID, s.BadRecords)
+			}
+			continue
+		}
+		if s.FirstBadOffset != recordSize {
+			t.Errorf("Expected first bad offset %d, got %d", recordSize, s.FirstBadOffset)
+		}
+		if s.GoodRecords != damaged.GoodRecords-1 {
+			t.Errorf("Expected the records after the damaged one to be checked, got %d good of %d",
+				s.GoodRecords, damaged.GoodRecords)
+		}
+		if !strings.Contains(s.Error, ErrChecksumMismatch.Error()) {
+			t.Errorf("Expected a checksum mismatch, got %q", s.Error)
+		}
+	}
+
+	// Truncating a segment mid-record breaks its framing
+	truncated := report.Segments[0]
+	info, err := os.Stat(truncated.Path)
+	if err != nil {
+		t.Fatalf("Failed to stat segment: %v", err)
+	}
+	if err := os.Truncate(truncated.Path, info.Size()-3); err != nil {
+		t.Fatalf("Failed to truncate segment: %v", err)
+	}
+
+	report, err = VerifyDir(dir)
+	if err != nil {
+		t.Fatalf("VerifyDir failed: %v", err)
+	}
+	if report.BadRecords != 2 {
+		t.Errorf("Expected 2 bad records, got %d", report.BadRecords)
+	}
+	for _, s := range report.Segments {
+		if s.SegmentID == truncated.SegmentID && s.FirstBadOffset != info.Size()-recordSize {
+			t.Errorf("Expected truncated record at offset %d, got %d", info.Size()-recordSize, s.FirstBadOffset)
+		}
+	}
+}
+
+func TestWAL_ExportImport(t *testing.T) {
+	src, err := Open(&Config{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to open WAL: %v", err)
+	}
+	defer src.Close()
+
+	if _, err := src.Write(0, []byte("plain"), []byte("value")); err != nil {
+		t.Fatalf("Failed to write to WAL: %v", err)
+	}
+	if _, err := src.Write(0, []byte{0xff, 0x00, 0xfe}, []byte{0x80, 0x81}); err != nil {
+		t.Fatalf("Failed to write binary record: %v", err)
+	}
+	if _, err := src.Delete(0, []byte("plain")); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+
+	committed, err := src.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	if _, err := src.Write(committed, []byte("tx-key"), []byte("tx-value")); err != nil {
+		t.Fatalf("Failed to write to WAL: %v", err)
+	}
+	sp, err := src.Savepoint(committed)
+	if err != nil {
+		t.Fatalf("Failed to create savepoint: %v", err)
+	}
+	if _, err := src.Write(committed, []byte("undone"), []byte("value")); err != nil {
+		t.Fatalf("Failed to write to WAL: %v", err)
+	}
+	if err := src.RollbackTo(committed, sp); err != nil {
+		t.Fatalf("Failed to roll back: %v", err)
+	}
+	if err := src.Commit(committed); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	aborted, err := src.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	if _, err := src.Write(aborted, []byte("aborted"), []byte("value")); err != nil {
+		t.Fatalf("Failed to write to WAL: %v", err)
+	}
+	if err := src.Abort(aborted); err != nil {
+		t.Fatalf("Failed to abort: %v", err)
+	}
+
+	inDoubt, err := src.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+
+	var exported bytes.Buffer
+	if err := src.Export(&exported); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if !strings.Contains(exported.String(), `"type":"txn-commit"`) {
+		t.Errorf("Expected type names in export, got:\n%s", exported.String())
+	}
+	if !strings.Contains(exported.String(), `"key_base64":"/wD+"`) {
+		t.Errorf("Expected binary key to be base64 encoded, got:\n%s", exported.String())
+	}
+
+	dst, err := Open(&Config{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to open WAL: %v", err)
+	}
+	defer dst.Close()
+	if err := dst.Import(bytes.NewReader(exported.Bytes())); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	want, err := src.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read source WAL: %v", err)
+	}
+	got, err := dst.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read imported WAL: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d records, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].LSN != want[i].LSN || got[i].TxID != want[i].TxID || got[i].Type != want[i].Type ||
+			!bytes.Equal(got[i].Key, want[i].Key) || !bytes.Equal(got[i].Value, want[i].Value) {
+			t.Errorf("Record %d mismatch: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// Transaction state and counters are recovered from the imported records
+	if active := dst.ActiveTransactions(); len(active) != 1 || active[0] != inDoubt {
+		t.Errorf("Expected transaction %d to be active, got %v", inDoubt, active)
+	}
+	txID, err := dst.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	if txID != inDoubt+1 {
+		t.Errorf("Expected next transaction ID %d, got %d", inDoubt+1, txID)
+	}
+
+	// Importing again would reuse LSNs
+	if err := dst.Import(bytes.NewReader(exported.Bytes())); !errors.Is(err, ErrNotEmpty) {
+		t.Errorf("Expected ErrNotEmpty importing into a non-empty WAL, got %v", err)
+	}
+}
+
+func TestWAL_TransactionBuffering(t *testing.T) {
+	dir := t.TempDir()
+	wal, err := Open(&Config{Dir: dir, SyncMode: SyncAlways})
+	if err != nil {
+		t.Fatalf("Failed to open WAL: %v", err)
+	}
+
+	// segmentBytes returns the contents of every segment file
+	segmentBytes := func() []byte {
+		files, err := filepath.Glob(filepath.Join(dir, "*.wal"))
+		if err != nil {
+			t.Fatalf("Failed to list segment files: %v", err)
+		}
+		var data []byte
+		for _, f := range files {
+			b, err := os.ReadFile(f)
+			if err != nil {
+				t.Fatalf("Failed to read segment: %v", err)
+			}
+			data = append(data, b...)
+		}
+		return data
+	}
+
+	// Interleave the writes of two transactions with a non-transactional one
+	committed, err := wal.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	aborted, err := wal.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+
+	key := []byte("committed-key-1")
+	if _, err := wal.Write(committed, key, []byte("committed-value-1")); err != nil {
+		t.Fatalf("Failed to write to WAL: %v", err)
+	}
+	// The caller may reuse its buffers once Write returns
+	copy(key, "overwritten-key")
+	if _, err := wal.Write(aborted, []byte("aborted-key-1"), []byte("aborted-value-1")); err != nil {
+		t.Fatalf("Failed to write to WAL: %v", err)
+	}
+	if _, err := wal.Write(0, []byte("plain-key"), []byte("plain-value")); err != nil {
+		t.Fatalf("Failed to write to WAL: %v", err)
+	}
+	if _, err := wal.Write(aborted, []byte("aborted-key-2"), []byte("aborted-value-2")); err != nil {
+		t.Fatalf("Failed to write to WAL: %v", err)
+	}
+	if _, err := wal.Write(committed, []byte("committed-key-2"), []byte("committed-value-2")); err != nil {
+		t.Fatalf("Failed to write to WAL: %v", err)
+	}
+
+	// Nothing transactional reaches the log before commit
+	if data := segmentBytes(); bytes.Contains(data, []byte("committed-key")) || bytes.Contains(data, []byte("aborted-key")) {
+		t.Fatal("Found uncommitted transaction data in the segment files")
+	}
+
+	if err := wal.Abort(aborted); err != nil {
+		t.Fatalf("Failed to abort transaction: %v", err)
+	}
+	if err := wal.Commit(committed); err != nil {
+		t.Fatalf("Failed to commit transaction: %v", err)
+	}
+	if _, err := wal.Write(aborted, []byte("late"), []byte("value")); err == nil {
+		t.Error("Expected error writing to an aborted transaction")
+	}
+	if err := wal.Close(); err != nil {
+		t.Fatalf("Failed to close WAL: %v", err)
+	}
+
+	data := segmentBytes()
+	for _, aborted := range []string{"aborted-key", "aborted-value"} {
+		if bytes.Contains(data, []byte(aborted)) {
+			t.Errorf("Found %q from the aborted transaction in the segment files", aborted)
+		}
+	}
+	if !bytes.Contains(data, []byte("committed-value-2")) {
+		t.Error("Expected the committed transaction's data in the segment files")
+	}
+
+	// The committed records are contiguous and in LSN order, after the
+	// non-transactional write that happened before the commit
+	reader, err := NewLogReader(dir)
+	if err != nil {
+		t.Fatalf("Failed to create log reader: %v", err)
+	}
+	defer reader.Close()
+
+	var got []string
+	var lastLSN uint64
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read record: %v", err)
+		}
+		if record.LSN <= lastLSN {
+			t.Errorf("Record LSN %d does not follow %d", record.LSN, lastLSN)
+		}
+		lastLSN = record.LSN
+		got = append(got, fmt.Sprintf("%s:%d:%s", record.Type, record.TxID, record.Key))
+	}
+	want := []string{
+		fmt.Sprintf("txn-begin:%d:", committed),
+		fmt.Sprintf("txn-begin:%d:", aborted),
+		"write:0:plain-key",
+		fmt.Sprintf("txn-rollback:%d:", aborted),
+		fmt.Sprintf("write:%d:committed-key-1", committed),
+		fmt.Sprintf("write:%d:committed-key-2", committed),
+		fmt.Sprintf("txn-commit:%d:", committed),
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Unexpected log contents:\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestWAL_ConcurrentTransactionsStayInLSNOrder(t *testing.T) {
+	dir := t.TempDir()
+	wal, err := Open(&Config{Dir: dir, SyncMode: SyncAlways})
+	if err != nil {
+		t.Fatalf("Failed to open WAL: %v", err)
+	}
+
+	// Begin, Commit and Abort race each other across goroutines
+	const workers, perWorker = 8, 50
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				txID, err := wal.Begin()
+				if err != nil {
+					errs <- err
+					return
+				}
+				key := []byte(fmt.Sprintf("worker-%d-key-%d", i, j))
+				if _, err := wal.Write(txID, key, []byte("value")); err != nil {
+					errs <- err
+					return
+				}
+				if j%2 == 0 {
+					err = wal.Commit(txID)
+				} else {
+					err = wal.Abort(txID)
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Concurrent transaction failed: %v", err)
+	}
+	if err := wal.Close(); err != nil {
+		t.Fatalf("Failed to close WAL: %v", err)
+	}
+
+	reader, err := NewLogReader(dir)
+	if err != nil {
+		t.Fatalf("Failed to create log reader: %v", err)
+	}
+	defer reader.Close()
+
+	var count int
+	var lastLSN uint64
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read record: %v", err)
+		}
+		if record.LSN <= lastLSN {
+			t.Fatalf("Records out of order: LSN %d follows LSN %d", record.LSN, lastLSN)
+		}
+		lastLSN = record.LSN
+		count++
+	}
+
+	// Each transaction writes a begin record and a commit or abort record,
+	// and committed ones also write their data record
+	if want := workers*perWorker*2 + workers*perWorker/2; count != want {
+		t.Errorf("Expected %d records, got %d", want, count)
+	}
+}
+
+func TestWAL_FailedCommitAbortsTransaction(t *testing.T) {
+	dir := t.TempDir()
+	wal, err := Open(&Config{Dir: dir, SyncMode: SyncAlways})
+	if err != nil {
+		t.Fatalf("Failed to open WAL: %v", err)
+	}
+
+	txID, err := wal.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	if _, err := wal.Write(txID, []byte("key"), []byte("value")); err != nil {
+		t.Fatalf("Failed to write to WAL: %v", err)
+	}
+
+	// Make writes to the segment fail while the transaction commits
+	segment := wal.writer.file
+	readOnly, err := os.Open(segment.Name())
+	if err != nil {
+		t.Fatalf("Failed to open segment: %v", err)
+	}
+	wal.writer.mu.Lock()
+	wal.writer.file = readOnly
+	wal.writer.mu.Unlock()
+
+	if err := wal.Commit(txID); err == nil {
+		t.Fatal("Expected commit to fail")
+	}
+
+	wal.writer.mu.Lock()
+	wal.writer.file = segment
+	wal.writer.mu.Unlock()
+	readOnly.Close()
+
+	// The transaction is finished rather than stuck committing
+	if active := wal.ActiveTransactions(); len(active) != 0 {
+		t.Errorf("Expected no active transactions, got %v", active)
+	}
+	if err := wal.Commit(txID); err == nil {
+		t.Error("Expected error retrying a failed commit")
+	}
+	if err := wal.Abort(txID); err == nil {
+		t.Error("Expected error aborting a failed commit")
+	}
+
+	// Once the buffered records reach the log, the abort record hides them
+	if err := wal.Close(); err != nil {
+		t.Fatalf("Failed to close WAL: %v", err)
+	}
+	wal, err = Open(&Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("Failed to reopen WAL: %v", err)
+	}
+	defer wal.Close()
+
+	records, err := wal.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read from WAL: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("Expected no committed records, got %d", len(records))
+	}
+	if report := wal.RecoveryReport(); len(report.InDoubt) != 0 {
+		t.Errorf("Expected no in-doubt transactions, got %v", report.InDoubt)
+	}
+}
+
+func TestWAL_SnapshotDuringWrites(t *testing.T) {
+	wal, err := Open(&Config{Dir: t.TempDir(), SyncMode: SyncNever})
+	if err != nil {
+		t.Fatalf("Failed to open WAL: %v", err)
+	}
+	defer wal.Close()
+
+	const writes = 500
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < writes; i++ {
+			key := []byte(fmt.Sprintf("key-%d", i))
+			if _, err := wal.Write(0, key, []byte("value")); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	// Every snapshot sees a consistent prefix of the writes
+	last := 0
+	for finished := false; !finished; {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("Failed to write to WAL: %v", err)
+			}
+			finished = true
+		default:
+		}
+
+		snapshot, err := wal.Snapshot()
+		if err != nil {
+			t.Fatalf("Failed to take snapshot: %v", err)
+		}
+		if len(snapshot) < last {
+			t.Fatalf("Snapshot shrank from %d to %d keys", last, len(snapshot))
+		}
+		last = len(snapshot)
+	}
+
+	if last != writes {
+		t.Errorf("Expected %d keys in the final snapshot, got %d", writes, last)
+	}
+}
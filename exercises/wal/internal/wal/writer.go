@@ -35,11 +35,32 @@ type LogWriter struct {
 	segmentSize int64          // Maximum size of each segment file
 	buf         *bytes.Buffer  // In-memory buffer for batching writes
 	bufMu       sync.Mutex     // Protects the buffer
-	sync        bool           // Whether to sync after each write
+	syncMode    SyncMode       // When buffered writes are fsynced
+	compression Compression    // Codec used to compress segment data
+	onFlush     func(uint64)   // Called with the highest LSN made durable
 	closed      bool           // Whether the writer is closed
 	flushTicker *time.Ticker   // Ticker for periodic flushes
 	stopCh      chan struct{}  // Channel to stop background flusher
 	wg          sync.WaitGroup // Wait group for background flusher
+
+	// Group commit state, guarded by mu
+	groupCommit bool          // Whether buffered writes are committed in batches
+	commitCond  *sync.Cond    // Signalled when a batch finishes committing
+	committing  bool          // Whether a batch is being written outside mu
+	pending     *commitBatch  // Batch that records appended to buf will join
+	spare       *bytes.Buffer // Buffer swapped in while a batch is being written
+
+	// Durability tracking, guarded by mu
+	bufLSN     uint64 // Highest LSN appended to the buffer
+	writtenLSN uint64 // Highest LSN written to a segment file
+	syncedLSN  uint64 // Highest LSN fsynced to stable storage
+	dirty      bool   // Whether the current segment has writes not yet fsynced
+}
+
+// commitBatch tracks a group of records that are written and synced together.
+type commitBatch struct {
+	done bool  // Whether the batch has been written and synced
+	err  error // Error from writing or syncing the batch
 }
 
 // NewLogWriter creates a new LogWriter.
@@ -68,14 +89,25 @@ func NewLogWriter(dir string, config *Config) (*LogWriter, error) {
 		segmentSize = DefaultSegmentSize
 	}
 
+	compression, err := parseCompression(config.Compression)
+	if err != nil {
+		return nil, err
+	}
+
 	w := &LogWriter{
 		dir:         dir,
-		sync:        config.Sync,
+		syncMode:    config.syncMode(),
+		compression: compression,
+		onFlush:     config.OnFlush,
 		segmentSize: segmentSize,
 		buf:         bytes.NewBuffer(make([]byte, 0, bufferSize)),
 		stopCh:      make(chan struct{}),
 		flushTicker: time.NewTicker(flushInterval),
+		groupCommit: config.GroupCommit,
+		pending:     &commitBatch{},
+		spare:       bytes.NewBuffer(make([]byte, 0, bufferSize)),
 	}
+	w.commitCond = sync.NewCond(&w.mu)
 
 	w.wg.Add(1)
 	go w.backgroundFlusher()
@@ -102,27 +134,130 @@ func (w *LogWriter) Write(record *Record) (uint64, error) {
 		return 0, err
 	}
 
+	if err := w.append(record.LSN, data); err != nil {
+		return 0, err
+	}
+
+	if w.syncMode == SyncAlways {
+		if err := w.flushBuffer(); err != nil {
+			return 0, fmt.Errorf("failed to flush buffer: %w", err)
+		}
+	}
+
+	return record.LSN, nil
+}
+
+// enqueue appends a record to the write buffer and returns the batch it will
+// be committed with. Caller must hold w.mu.
+func (w *LogWriter) enqueue(record *Record) (*commitBatch, error) {
+	if w.closed {
+		return nil, ErrWALClosed
+	}
+
+	data, err := record.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.append(record.LSN, data); err != nil {
+		return nil, err
+	}
+
+	return w.pending, nil
+}
+
+// awaitDurable blocks until batch has been written and synced.
+//
+// Waiting writers take turns acting as the single flusher: whichever finds no
+// commit in progress writes out everything buffered so far, including records
+// from writers that queued up behind it, and issues one fsync for all of them.
+// Caller must hold w.mu.
+func (w *LogWriter) awaitDurable(batch *commitBatch) error {
+	for !batch.done {
+		if w.committing {
+			w.commitCond.Wait()
+			continue
+		}
+		w.commitPending()
+	}
+
+	return batch.err
+}
+
+// commitPending writes and syncs the buffered records as one batch. mu is
+// released during the I/O so that other writers can queue up for the next
+// batch. Caller must hold w.mu and ensure no other commit is in progress.
+func (w *LogWriter) commitPending() {
+	if w.buf.Len() == 0 {
+		return
+	}
+
+	batch := w.pending
+	data := w.buf
+	file := w.file
+	lsn := w.bufLSN
+
+	w.pending = &commitBatch{}
+	w.buf, w.spare = w.spare, w.buf
+	w.committing = true
+	w.mu.Unlock()
+
+	var n int
+	out, err := w.frame(data.Bytes())
+	if err == nil {
+		n, err = file.Write(out)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+
+	w.mu.Lock()
+	data.Reset()
+	w.offset += int64(n)
+	if n > 0 {
+		w.writtenLSN = max(w.writtenLSN, lsn)
+	}
+	if err == nil {
+		// The fsync also covers anything written to the segment earlier
+		w.dirty = false
+		w.markDurable(w.writtenLSN)
+	} else if n > 0 {
+		w.dirty = true
+	}
+	w.committing = false
+	batch.done = true
+	batch.err = err
+	w.commitCond.Broadcast()
+}
+
+// append adds the encoded data of the record with the given LSN to the
+// buffer, rotating the segment first if the data would not fit.
+// Caller must hold w.mu.
+func (w *LogWriter) append(lsn uint64, data []byte) error {
 	// Check if we need to rotate the segment
 	if w.offset+int64(len(data)) > w.segmentSize {
 		if err := w.rotateSegment(); err != nil {
-			return 0, fmt.Errorf("failed to rotate segment: %w", err)
+			return fmt.Errorf("failed to rotate segment: %w", err)
 		}
 	}
 
 	w.bufMu.Lock()
-	_, err = w.buf.Write(data)
+	_, err := w.buf.Write(data)
 	w.bufMu.Unlock()
 	if err != nil {
-		return 0, fmt.Errorf("failed to write to buffer: %w", err)
+		return fmt.Errorf("failed to write to buffer: %w", err)
 	}
 
-	if w.sync {
-		if err := w.flushBuffer(); err != nil {
-			return 0, fmt.Errorf("failed to flush buffer: %w", err)
-		}
-	}
+	w.bufLSN = max(w.bufLSN, lsn)
+	return nil
+}
 
-	return record.LSN, nil
+// waitForCommit blocks until any in-progress group commit has finished.
+// Caller must hold w.mu.
+func (w *LogWriter) waitForCommit() {
+	for w.committing {
+		w.commitCond.Wait()
+	}
 }
 
 // Flush writes any buffered data to the underlying writer.
@@ -139,26 +274,98 @@ func (w *LogWriter) Flush() error {
 // flushBuffer writes the buffered data to disk.
 // Caller must hold w.mu
 func (w *LogWriter) flushBuffer() error {
+	w.waitForCommit()
+
 	if w.buf.Len() == 0 {
 		return nil
 	}
 
-	n, err := w.file.Write(w.buf.Bytes())
+	if w.groupCommit {
+		// Complete the pending batch so its waiters see the data as durable
+		batch := w.pending
+		w.commitPending()
+		return batch.err
+	}
+
+	out, err := w.frame(w.buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	n, err := w.file.Write(out)
 	if err != nil {
 		return err
 	}
 
 	w.offset += int64(n)
+	w.writtenLSN = max(w.writtenLSN, w.bufLSN)
+	w.dirty = true
 
 	w.buf.Reset()
 
-	if w.sync {
-		return w.file.Sync()
+	if w.syncMode == SyncAlways {
+		return w.syncFile()
 	}
 
 	return nil
 }
 
+// frame returns buffered record data in the form it is written to a segment,
+// compressed into framed blocks when compression is enabled.
+func (w *LogWriter) frame(data []byte) ([]byte, error) {
+	if w.compression == CompressionNone {
+		return data, nil
+	}
+	return compressBlocks(w.compression, data)
+}
+
+// writeSegmentHeader writes the header identifying a compressed segment's
+// codec to a newly created segment. Uncompressed segments have no header.
+// Caller must hold w.mu.
+func (w *LogWriter) writeSegmentHeader() error {
+	if w.compression == CompressionNone || w.offset > 0 {
+		return nil
+	}
+
+	n, err := w.file.Write(encodeSegmentHeader(w.compression))
+	w.offset += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write segment header: %w", err)
+	}
+
+	w.dirty = true
+	return nil
+}
+
+// syncFile fsyncs the current segment if it has writes that are not yet
+// durable. Caller must hold w.mu.
+func (w *LogWriter) syncFile() error {
+	if !w.dirty {
+		return nil
+	}
+
+	if err := w.file.Sync(); err != nil {
+		return err
+	}
+
+	w.dirty = false
+	w.markDurable(w.writtenLSN)
+	return nil
+}
+
+// markDurable records that every record up to lsn is on stable storage and
+// notifies the OnFlush callback. Caller must hold w.mu.
+func (w *LogWriter) markDurable(lsn uint64) {
+	if lsn <= w.syncedLSN {
+		return
+	}
+
+	w.syncedLSN = lsn
+	if w.onFlush != nil {
+		w.onFlush(lsn)
+	}
+}
+
 // backgroundFlusher periodically flushes the buffer to disk.
 func (w *LogWriter) backgroundFlusher() {
 	defer w.wg.Done()
@@ -172,7 +379,9 @@ func (w *LogWriter) backgroundFlusher() {
 
 		case <-w.flushTicker.C:
 			if w.mu.TryLock() {
-				_ = w.flushBuffer()
+				if err := w.flushBuffer(); err == nil && w.syncMode == SyncInterval {
+					_ = w.syncFile()
+				}
 				w.mu.Unlock()
 			}
 		}
@@ -231,6 +440,12 @@ func (w *LogWriter) Close() error {
 
 	// Close the current segment file
 	if w.file != nil {
+		if w.syncMode != SyncNever {
+			if err := w.syncFile(); err != nil {
+				w.mu.Unlock()
+				return fmt.Errorf("failed to sync segment file during close: %w", err)
+			}
+		}
 		if err := w.file.Close(); err != nil {
 			w.mu.Unlock()
 			return fmt.Errorf("failed to close segment file: %w", err)
@@ -275,12 +490,20 @@ func (w *LogWriter) openOrCreateSegment() error {
 	w.segmentID = segmentID
 	w.offset = offset
 
-	return nil
+	return w.writeSegmentHeader()
 }
 
 // rotateSegment closes the current segment and opens a new one.
 func (w *LogWriter) rotateSegment() error {
+	w.waitForCommit()
+
 	if w.file != nil {
+		// Make the outgoing segment durable before it is closed
+		if w.syncMode != SyncNever {
+			if err := w.syncFile(); err != nil {
+				return err
+			}
+		}
 		if err := w.file.Close(); err != nil {
 			return err
 		}
@@ -297,5 +520,74 @@ func (w *LogWriter) rotateSegment() error {
 	w.file = file
 	w.offset = 0
 
-	return nil
+	return w.writeSegmentHeader()
+}
+
+// stats returns the writer's segment and buffer statistics.
+func (w *LogWriter) stats() (WALStats, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	files, err := listSegments(w.dir)
+	if err != nil {
+		return WALStats{}, err
+	}
+
+	stats := WALStats{
+		SegmentID:    w.segmentID,
+		SegmentCount: len(files),
+	}
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			return WALStats{}, fmt.Errorf("failed to stat segment %s: %w", path, err)
+		}
+		stats.DiskBytes += info.Size()
+	}
+
+	w.bufMu.Lock()
+	stats.BufferedBytes = w.buf.Len()
+	w.bufMu.Unlock()
+
+	return stats, nil
+}
+
+// RemoveSegments deletes closed segment files in ascending ID order for as
+// long as reclaimable reports true, stopping at the first segment that must
+// be kept. The active segment is never removed. Writes are blocked for the
+// duration of the call. It returns the number of segments removed.
+func (w *LogWriter) RemoveSegments(reclaimable func(path string) (bool, error)) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, ErrWALClosed
+	}
+
+	files, err := listSegments(w.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, path := range files {
+		if segmentIDFromPath(path) >= w.segmentID {
+			break
+		}
+
+		ok, err := reclaimable(path)
+		if err != nil {
+			return removed, fmt.Errorf("failed to inspect segment %s: %w", path, err)
+		}
+		if !ok {
+			break
+		}
+
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove segment %s: %w", path, err)
+		}
+		removed++
+	}
+
+	return removed, nil
 }